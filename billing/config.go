@@ -0,0 +1,102 @@
+package billing
+
+import (
+	"time"
+
+	"encore.app/billing/domain"
+	"encore.app/billing/infrastructure"
+)
+
+// reopenGracePeriod is how long after closing a bill can still be reopened
+// through the reopen endpoint.
+const reopenGracePeriod = 15 * time.Minute
+
+// prorationPrecision is the default precision of the proration of
+// subscription plan changes: DAY prorates by calendar day, in the timezone of
+// the subscription, and SECOND by second.
+const prorationPrecision = domain.ProrationPrecisionDay
+
+// catalogPriceConversion sets whether SKUs without a price in the currency
+// of a bill can still be added to it, at their price converted with the
+// exchange rates. When false, such SKUs are rejected.
+const catalogPriceConversion = false
+
+// billIdleTimeout is how long an open bill waits for a new line item before
+// it expires: it is closed when it has items and voided when it is empty.
+// Zero disables the expiry.
+const billIdleTimeout = 24 * time.Hour
+
+// billCloseWaitTimeout is how long closing a bill waits for the bill
+// workflow to assign its invoice number before responding without it.
+const billCloseWaitTimeout = 10 * time.Second
+
+// invoiceNumbering configures the gap-free invoice numbers assigned to bills
+// when they are closed, sequential per series and year.
+var invoiceNumbering = domain.InvoiceNumbering{
+	Series: "INV",
+	Format: "{SERIES}-{YEAR}-{NUMBER}",
+	Digits: 6,
+}
+
+// paymentConfirmationTimeout is how long the payment workflow waits for the
+// provider to confirm a pending payment before it asks the provider directly.
+const paymentConfirmationTimeout = 30 * time.Minute
+
+// dunningSchedule is when unpaid bills are reminded, counted from their
+// closing. Bills still unpaid at the last offset become OVERDUE.
+// An empty schedule disables dunning.
+var dunningSchedule = []time.Duration{
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	14 * 24 * time.Hour,
+}
+
+// paymentProviderOptions configures the in-process fake payment provider that
+// charges closed bills, e.g. to simulate declines, timeouts or delayed
+// confirmations.
+var paymentProviderOptions = infrastructure.FakePaymentProviderOptions{}
+
+// taxRates configures the taxes calculated when a bill is closed.
+// Rates apply per bill currency, rates with a Region only apply to bills
+// opened for that region and take precedence over the currency-wide rates.
+// Rate is expressed in basis points (1800 = 18%).
+var taxRates = []domain.TaxRate{
+	{Code: "VAT", Name: "VAT", Currency: domain.CurrencyGEL, Rate: 1800, Inclusive: true},
+	{Code: "SALES_TAX", Name: "Sales tax", Currency: domain.CurrencyUSD, Region: "US-CA", Rate: 725, Inclusive: false},
+	{Code: "SALES_TAX", Name: "Sales tax", Currency: domain.CurrencyUSD, Region: "US-NY", Rate: 400, Inclusive: false},
+}
+
+// usageMeters configures the metered features usage can be reported for
+// through the usage endpoint. The usage of each meter is billed as a single
+// line item when the bill closes, priced in the bill currency per unit or by
+// GRADUATED or VOLUME tiers. Tier unit prices apply per PerUnits units.
+var usageMeters = []domain.Meter{
+	{Code: "api_calls", Name: "API calls", Unit: "call", Currency: domain.CurrencyUSD, Price: domain.Price{
+		Model:    domain.PriceModelGraduated,
+		PerUnits: 100,
+		Tiers: []domain.PriceTier{
+			{UpTo: 1000, UnitPrice: 0},
+			{UpTo: 100000, UnitPrice: 15},
+			{UnitPrice: 10},
+		},
+	}},
+	{Code: "api_calls", Name: "API calls", Unit: "call", Currency: domain.CurrencyGEL, Price: domain.Price{
+		Model:    domain.PriceModelGraduated,
+		PerUnits: 100,
+		Tiers: []domain.PriceTier{
+			{UpTo: 1000, UnitPrice: 0},
+			{UpTo: 100000, UnitPrice: 40},
+			{UnitPrice: 27},
+		},
+	}},
+	{Code: "storage_gb_hours", Name: "Storage", Unit: "GB-hour", Currency: domain.CurrencyUSD, Price: domain.Price{
+		Model: domain.PriceModelVolume,
+		Tiers: []domain.PriceTier{
+			{UpTo: 720, UnitPrice: 2},
+			{UnitPrice: 1, FlatPrice: 500},
+		},
+	}},
+	{Code: "storage_gb_hours", Name: "Storage", Unit: "GB-hour", Currency: domain.CurrencyGEL, Price: domain.Price{
+		Model: domain.PriceModelPerUnit, UnitPrice: 6,
+	}},
+}
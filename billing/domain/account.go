@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Account represents a customer that owns bills.
+type Account struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
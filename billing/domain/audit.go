@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// AuditAction represents an action recorded in the audit trail of a bill.
+type AuditAction string
+
+const (
+	// AuditActionReopen is recorded when a closed bill is reopened.
+	AuditActionReopen AuditAction = "REOPEN"
+	// AuditActionVoid is recorded when an open bill is voided.
+	AuditActionVoid AuditAction = "VOID"
+	// AuditActionExpire is recorded when an idle bill is closed or voided
+	// by the inactivity timeout.
+	AuditActionExpire AuditAction = "EXPIRE"
+	// AuditActionRemind is recorded when a payment reminder is sent for an
+	// unpaid bill.
+	AuditActionRemind AuditAction = "REMIND"
+	// AuditActionOverdue is recorded when an unpaid bill becomes overdue at
+	// the end of its dunning schedule.
+	AuditActionOverdue AuditAction = "OVERDUE"
+	// AuditActionCouponRejected is recorded when a coupon is removed from a
+	// bill because its redemption limit was reached when the bill closed.
+	AuditActionCouponRejected AuditAction = "COUPON_REJECTED"
+)
+
+// AuditEntry represents a single entry of the audit trail of a bill.
+type AuditEntry struct {
+	ID        int64       `json:"id"`
+	BillingID string      `json:"billingId"`
+	Action    AuditAction `json:"action"`
+	Reason    string      `json:"reason"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
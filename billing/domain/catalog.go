@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+// Product represents a product of the catalog, sold through its SKUs.
+// Archived products are kept for the bills that reference them, but their
+// SKUs can no longer be added to bills.
+type Product struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	SKUs        []SKU      `json:"skus"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	ArchivedAt  *time.Time `json:"archivedAt"`
+}
+
+// IsArchived returns true if the product has been archived.
+func (p Product) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
+
+// SKU represents a sellable unit of a product, identified by a Code unique
+// in the catalog, with the name, unit and unit price, in the smallest unit of
+// Currency, items added by SKU are billed with. Prices holds its unit prices
+// in the other currencies it is sold in. Every change of a SKU creates a new
+// Version, and items keep the version they were priced with, so that past
+// bills stay reproducible.
+type SKU struct {
+	Code       string     `json:"code"`
+	ProductID  string     `json:"productId"`
+	Name       string     `json:"name"`
+	Unit       string     `json:"unit"`
+	Currency   Currency   `json:"currency"`
+	UnitPrice  int64      `json:"unitPrice"`
+	Prices     PriceList  `json:"prices"`
+	Version    int64      `json:"version"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	ArchivedAt *time.Time `json:"archivedAt"`
+}
+
+// IsArchived returns true if the SKU has been archived.
+func (s SKU) IsArchived() bool {
+	return s.ArchivedAt != nil
+}
+
+// PriceList represents unit prices keyed by currency, each in the smallest
+// unit of its currency.
+type PriceList map[Currency]int64
+
+// UnitPriceIn returns the unit price of the SKU in currency, and false when
+// the SKU has no price in that currency.
+func (s SKU) UnitPriceIn(currency Currency) (int64, bool) {
+	if currency == s.Currency {
+		return s.UnitPrice, true
+	}
+	unitPrice, found := s.Prices[currency]
+	return unitPrice, found
+}
+
+// Item returns the line item billing quantity units of the SKU on a bill, at
+// unitPrice, the unit price of the SKU in the currency of the bill.
+func (s SKU) Item(billingID string, quantity, unitPrice int64) (Item, error) {
+	if s.IsArchived() {
+		return Item{}, ErrSKUArchived
+	}
+
+	price, err := LineAmount(quantity, unitPrice)
+	if err != nil {
+		return Item{}, err
+	}
+
+	return Item{
+		BillingID:      billingID,
+		Name:           s.Name,
+		Quantity:       quantity,
+		Unit:           s.Unit,
+		UnitPrice:      unitPrice,
+		Price:          price,
+		SKU:            s.Code,
+		CatalogVersion: s.Version,
+	}, nil
+}
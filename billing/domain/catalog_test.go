@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSKU_Item(t *testing.T) {
+	sku := domain.SKU{
+		Code:      "ESP-DBL",
+		ProductID: "Prod-espresso",
+		Name:      "Double espresso",
+		Unit:      "cup",
+		Currency:  domain.CurrencyUSD,
+		UnitPrice: 450,
+		Version:   3,
+	}
+
+	item, err := sku.Item("bill-1", 2, 1250)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Item{
+		BillingID:      "bill-1",
+		Name:           "Double espresso",
+		Quantity:       2,
+		Unit:           "cup",
+		UnitPrice:      1250,
+		Price:          2500,
+		SKU:            "ESP-DBL",
+		CatalogVersion: 3,
+	}, item)
+
+	_, err = sku.Item("bill-1", math.MaxInt64/2, 3)
+	assert.ErrorIs(t, err, domain.ErrAmountOverflow)
+
+	archivedAt := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	sku.ArchivedAt = &archivedAt
+	_, err = sku.Item("bill-1", 1, 450)
+	assert.ErrorIs(t, err, domain.ErrSKUArchived)
+}
+
+func TestSKU_UnitPriceIn(t *testing.T) {
+	sku := domain.SKU{
+		Code:      "ESP-DBL",
+		Currency:  domain.CurrencyUSD,
+		UnitPrice: 450,
+		Prices:    domain.PriceList{domain.CurrencyGEL: 1250},
+	}
+
+	unitPrice, found := sku.UnitPriceIn(domain.CurrencyUSD)
+	assert.True(t, found)
+	assert.Equal(t, int64(450), unitPrice)
+
+	unitPrice, found = sku.UnitPriceIn(domain.CurrencyGEL)
+	assert.True(t, found)
+	assert.Equal(t, int64(1250), unitPrice)
+
+	sku.Prices = nil
+	_, found = sku.UnitPriceIn(domain.CurrencyGEL)
+	assert.False(t, found)
+}
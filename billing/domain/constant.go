@@ -4,9 +4,42 @@ const (
 	// SignalAddLineItem is the Temporal signal name used to add a new Item to a Bill.
 	SignalAddLineItem string = "ADD_LINE_ITEM"
 
+	// SignalVoidLineItem is the Temporal signal name used to void an Item of a Bill.
+	SignalVoidLineItem string = "VOID_LINE_ITEM"
+
+	// SignalApplyDiscount is the Temporal signal name used to apply a Discount to a Bill.
+	SignalApplyDiscount string = "APPLY_DISCOUNT"
+
 	// SignalCloseBill is the Temporal signal name used to request closing a Bill.
 	SignalCloseBill string = "CLOSE_BILL"
 
+	// SignalVoidBill is the Temporal signal name used to request voiding a Bill.
+	SignalVoidBill string = "VOID_BILL"
+
+	// SignalPaymentConfirmation is the Temporal signal name used to confirm a
+	// pending provider payment to the payment workflow of a Bill.
+	SignalPaymentConfirmation string = "PAYMENT_CONFIRMATION"
+
+	// SignalBillSettled is the Temporal signal name used to stop the dunning
+	// workflow of a Bill once its balance is settled.
+	SignalBillSettled string = "BILL_SETTLED"
+
+	// SignalPauseSubscription is the Temporal signal name used to pause a Subscription.
+	SignalPauseSubscription string = "PAUSE_SUBSCRIPTION"
+
+	// SignalResumeSubscription is the Temporal signal name used to resume a paused Subscription.
+	SignalResumeSubscription string = "RESUME_SUBSCRIPTION"
+
+	// SignalCancelSubscription is the Temporal signal name used to cancel a Subscription.
+	SignalCancelSubscription string = "CANCEL_SUBSCRIPTION"
+
+	// SignalChangeSubscriptionPlan is the Temporal signal name used to change
+	// the plan of a Subscription, prorating the change on its open bill.
+	SignalChangeSubscriptionPlan string = "CHANGE_SUBSCRIPTION_PLAN"
+
+	// QueryTypeGetSubscription is the Temporal query type used to fetch the current state of a Subscription.
+	QueryTypeGetSubscription string = "getSubscription"
+
 	// QueryTypeGetBilling is the Temporal query type used to fetch the current state of a Bill.
 	QueryTypeGetBilling string = "getBill"
 
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Coupon represents a promotion code that discounts the bills it is applied
+// to by DiscountValue, interpreted as for a Discount of DiscountType. A
+// coupon can be applied within its validity window, from ValidFrom until
+// ValidUntil when they are set, to bills in one of its Currencies, or in any
+// currency when it has none. MaxRedemptions and MaxRedemptionsPerAccount
+// limit how many bills it can be redeemed by, overall and per account; zero
+// means unlimited. Redemptions is the number of bills it is redeemed by.
+type Coupon struct {
+	Code                     string       `json:"code"`
+	Description              string       `json:"description"`
+	DiscountType             DiscountType `json:"discountType"`
+	DiscountValue            int64        `json:"discountValue"`
+	Currencies               []Currency   `json:"currencies"`
+	ValidFrom                *time.Time   `json:"validFrom"`
+	ValidUntil               *time.Time   `json:"validUntil"`
+	MaxRedemptions           int64        `json:"maxRedemptions"`
+	MaxRedemptionsPerAccount int64        `json:"maxRedemptionsPerAccount"`
+	Redemptions              int64        `json:"redemptions"`
+	CreatedAt                time.Time    `json:"createdAt"`
+}
+
+// IsValidAt returns true if t is within the validity window of the coupon.
+func (c Coupon) IsValidAt(t time.Time) bool {
+	if c.ValidFrom != nil && t.Before(*c.ValidFrom) {
+		return false
+	}
+	if c.ValidUntil != nil && !t.Before(*c.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// AcceptsCurrency returns true if the coupon can be applied to bills in currency.
+func (c Coupon) AcceptsCurrency(currency Currency) bool {
+	if len(c.Currencies) == 0 {
+		return true
+	}
+	for _, accepted := range c.Currencies {
+		if accepted == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRedeem returns true if the coupon can be redeemed once more, given the
+// number of bills it is already redeemed by overall and by the account.
+func (c Coupon) CanRedeem(redemptions, accountRedemptions int64) bool {
+	if c.MaxRedemptions > 0 && redemptions >= c.MaxRedemptions {
+		return false
+	}
+	if c.MaxRedemptionsPerAccount > 0 && accountRedemptions >= c.MaxRedemptionsPerAccount {
+		return false
+	}
+	return true
+}
+
+// Discount returns the bill-level discount the coupon applies to a bill.
+func (c Coupon) Discount(billingID string) Discount {
+	description := c.Description
+	if description == "" {
+		description = "Coupon " + c.Code
+	}
+
+	return Discount{
+		BillingID:   billingID,
+		Type:        c.DiscountType,
+		Value:       c.DiscountValue,
+		Description: description,
+		CouponCode:  c.Code,
+	}
+}
+
+// CouponReservation represents the input of the reservation of the
+// redemptions of the coupons applied to a Bill when it closes.
+type CouponReservation struct {
+	Bill       Bill      `json:"bill"`
+	ReservedAt time.Time `json:"reservedAt"`
+}
+
+// CouponCodes returns the codes of the coupons applied to the bill, sorted.
+func (b *Bill) CouponCodes() []string {
+	var codes []string
+	for _, discount := range b.Discounts {
+		if discount.CouponCode != "" {
+			codes = append(codes, discount.CouponCode)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// HasCoupon returns true if the coupon is applied to the bill.
+func (b *Bill) HasCoupon(code string) bool {
+	for _, discount := range b.Discounts {
+		if discount.CouponCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveCoupon removes the discount of a coupon from the bill and updates
+// the total.
+func (b *Bill) RemoveCoupon(code string) {
+	discounts := b.Discounts[:0]
+	for _, discount := range b.Discounts {
+		if discount.CouponCode != code {
+			discounts = append(discounts, discount)
+		}
+	}
+	b.Discounts = discounts
+	b.Total = b.GetTotal()
+}
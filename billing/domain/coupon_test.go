@@ -0,0 +1,106 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoupon_IsValidAt(t *testing.T) {
+	validFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	validUntil := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	coupon := domain.Coupon{Code: "WINTER", ValidFrom: &validFrom, ValidUntil: &validUntil}
+
+	assert.False(t, coupon.IsValidAt(validFrom.Add(-time.Second)))
+	assert.True(t, coupon.IsValidAt(validFrom))
+	assert.True(t, coupon.IsValidAt(validUntil.Add(-time.Second)))
+	assert.False(t, coupon.IsValidAt(validUntil))
+	assert.True(t, domain.Coupon{Code: "ALWAYS"}.IsValidAt(validUntil))
+}
+
+func TestCoupon_AcceptsCurrency(t *testing.T) {
+	assert.True(t, domain.Coupon{Code: "ANY"}.AcceptsCurrency(domain.CurrencyGEL))
+
+	coupon := domain.Coupon{Code: "USDONLY", Currencies: []domain.Currency{domain.CurrencyUSD}}
+	assert.True(t, coupon.AcceptsCurrency(domain.CurrencyUSD))
+	assert.False(t, coupon.AcceptsCurrency(domain.CurrencyGEL))
+}
+
+func TestCoupon_CanRedeem(t *testing.T) {
+	testCases := []struct {
+		condition          string
+		coupon             domain.Coupon
+		redemptions        int64
+		accountRedemptions int64
+		expected           bool
+	}{
+		{
+			condition:   "unlimited",
+			coupon:      domain.Coupon{Code: "FREE"},
+			redemptions: 1000,
+			expected:    true,
+		},
+		{
+			condition:   "below the limit",
+			coupon:      domain.Coupon{Code: "LIMITED", MaxRedemptions: 3},
+			redemptions: 2,
+			expected:    true,
+		},
+		{
+			condition:   "limit reached",
+			coupon:      domain.Coupon{Code: "LIMITED", MaxRedemptions: 3},
+			redemptions: 3,
+			expected:    false,
+		},
+		{
+			condition:          "account limit reached",
+			coupon:             domain.Coupon{Code: "ONCE", MaxRedemptions: 3, MaxRedemptionsPerAccount: 1},
+			redemptions:        1,
+			accountRedemptions: 1,
+			expected:           false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.condition, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.coupon.CanRedeem(tc.redemptions, tc.accountRedemptions))
+		})
+	}
+}
+
+func TestCoupon_Discount(t *testing.T) {
+	coupon := domain.Coupon{Code: "WELCOME10", DiscountType: domain.DiscountTypePercentage, DiscountValue: 1000}
+	assert.Equal(t, domain.Discount{
+		BillingID:   "bill-1",
+		Type:        domain.DiscountTypePercentage,
+		Value:       1000,
+		Description: "Coupon WELCOME10",
+		CouponCode:  "WELCOME10",
+	}, coupon.Discount("bill-1"))
+
+	coupon.Description = "Welcome offer"
+	assert.Equal(t, "Welcome offer", coupon.Discount("bill-1").Description)
+}
+
+func TestBill_RemoveCoupon(t *testing.T) {
+	bill := domain.Bill{
+		BillingID: "bill-1",
+		Currency:  domain.CurrencyUSD,
+		Items:     []domain.Item{{ID: 1, Quantity: 1, UnitPrice: 10000, Price: 10000}},
+	}
+	_ = bill.ApplyDiscount(domain.Discount{Type: domain.DiscountTypeFixed, Value: 500, Description: "Loyalty"})
+	_ = bill.ApplyDiscount(domain.Discount{Type: domain.DiscountTypePercentage, Value: 1000, CouponCode: "WELCOME10"})
+	_ = bill.ApplyDiscount(domain.Discount{Type: domain.DiscountTypeFixed, Value: 200, CouponCode: "SPRING"})
+
+	assert.Equal(t, []string{"SPRING", "WELCOME10"}, bill.CouponCodes())
+	assert.True(t, bill.HasCoupon("WELCOME10"))
+	assert.Equal(t, int64(8350), bill.Total)
+
+	bill.RemoveCoupon("WELCOME10")
+	assert.False(t, bill.HasCoupon("WELCOME10"))
+	assert.Equal(t, []string{"SPRING"}, bill.CouponCodes())
+	assert.Len(t, bill.Discounts, 2)
+	assert.Equal(t, int64(9300), bill.Total)
+}
@@ -0,0 +1,106 @@
+package domain
+
+import "time"
+
+// CreditNote represents a correction issued against a closed bill, crediting
+// part of its items or of the bill as a whole. Amount is the sum of its lines,
+// in the smallest unit of Currency, which is always the bill currency.
+type CreditNote struct {
+	ID        int64            `json:"id"`
+	Number    string           `json:"number"`
+	BillingID string           `json:"billingId"`
+	Currency  Currency         `json:"currency"`
+	Amount    int64            `json:"amount"`
+	Reason    string           `json:"reason"`
+	Lines     []CreditNoteLine `json:"lines"`
+	IssuedAt  time.Time        `json:"issuedAt"`
+}
+
+// CreditNoteLine represents the amount credited for a single item of the
+// bill (ItemID is set) or for the bill as a whole (ItemID is zero).
+type CreditNoteLine struct {
+	ItemID int64 `json:"itemId"`
+	Amount int64 `json:"amount"`
+}
+
+// FindCreditNote returns the credit note of the bill with the given number.
+func (b *Bill) FindCreditNote(number string) (CreditNote, bool) {
+	for _, note := range b.CreditNotes {
+		if note.Number == number {
+			return note, true
+		}
+	}
+	return CreditNote{}, false
+}
+
+// GetCreditedTotal calculates the sum of all credit notes of the bill.
+func (b *Bill) GetCreditedTotal() int64 {
+	var total int64
+	for _, note := range b.CreditNotes {
+		total += note.Amount
+	}
+	return total
+}
+
+// getItemCreditedTotal calculates the amount credited for a single item.
+func (b *Bill) getItemCreditedTotal(itemID int64) int64 {
+	var total int64
+	for _, note := range b.CreditNotes {
+		for _, line := range note.Lines {
+			if line.ItemID == itemID {
+				total += line.Amount
+			}
+		}
+	}
+	return total
+}
+
+// ApplyCreditNote adds a credit note to a closed bill and updates its status.
+// Item lines cannot credit more than the item amount and the credit notes of
+// a bill cannot credit more than its grand total. A credit note on an already
+// paid bill leaves a negative outstanding balance, i.e. a refund is due.
+func (b *Bill) ApplyCreditNote(note CreditNote) error {
+	if b.IsVoided() {
+		return ErrBillVoided
+	}
+	if !b.IsClosed() {
+		return ErrBillNotClosed
+	}
+	if len(note.Lines) == 0 {
+		return ErrInvalidPrice
+	}
+
+	var amount int64
+	lineAmounts := make(map[int64]int64)
+	for _, line := range note.Lines {
+		if line.Amount <= 0 {
+			return ErrInvalidPrice
+		}
+		amount += line.Amount
+		if line.ItemID == 0 {
+			continue
+		}
+
+		item, found := b.FindItem(line.ItemID)
+		if !found {
+			return ErrItemNotFound
+		}
+		if item.IsVoided() {
+			return ErrItemVoided
+		}
+
+		lineAmounts[line.ItemID] += line.Amount
+		if b.getItemCreditedTotal(line.ItemID)+lineAmounts[line.ItemID] > item.Amount() {
+			return ErrCreditExceedsBalance
+		}
+	}
+
+	if b.GetCreditedTotal()+amount > b.GetGrandTotal() {
+		return ErrCreditExceedsBalance
+	}
+
+	note.Amount = amount
+	b.CreditNotes = append(b.CreditNotes, note)
+	b.refreshPaymentStatus()
+	return nil
+}
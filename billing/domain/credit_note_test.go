@@ -0,0 +1,98 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBill_ApplyCreditNote(t *testing.T) {
+	voidedAt := time.Now()
+	closedBill := func() *domain.Bill {
+		return &domain.Bill{
+			Status: domain.BillStatusClosed,
+			Items: []domain.Item{
+				{ID: 1, Price: 600},
+				{ID: 2, Price: 400},
+				{ID: 3, Price: 200, VoidedAt: &voidedAt},
+			},
+		}
+	}
+
+	t.Run("item and bill-level lines", func(t *testing.T) {
+		bill := closedBill()
+
+		err := bill.ApplyCreditNote(domain.CreditNote{Number: "CN-1", Lines: []domain.CreditNoteLine{
+			{ItemID: 1, Amount: 200},
+			{Amount: 100},
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(300), bill.CreditNotes[0].Amount)
+		assert.Equal(t, int64(300), bill.GetCreditedTotal())
+		assert.Equal(t, int64(700), bill.GetOutstandingBalance())
+		assert.Equal(t, domain.BillStatusClosed, bill.Status)
+
+		note, found := bill.FindCreditNote("CN-1")
+		assert.True(t, found)
+		assert.Len(t, note.Lines, 2)
+	})
+
+	t.Run("credit settles a partially paid bill", func(t *testing.T) {
+		bill := closedBill()
+		assert.NoError(t, bill.ApplyPayment(domain.Payment{BillAmount: 700}))
+		assert.Equal(t, domain.BillStatusPartiallyPaid, bill.Status)
+
+		assert.NoError(t, bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{{ItemID: 2, Amount: 300}}}))
+		assert.Equal(t, domain.BillStatusPaid, bill.Status)
+		assert.Equal(t, int64(0), bill.GetOutstandingBalance())
+	})
+
+	t.Run("credit on a paid bill is owed back", func(t *testing.T) {
+		bill := closedBill()
+		assert.NoError(t, bill.ApplyPayment(domain.Payment{BillAmount: 1000}))
+
+		assert.NoError(t, bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{{ItemID: 1, Amount: 600}}}))
+		assert.Equal(t, domain.BillStatusPaid, bill.Status)
+		assert.Equal(t, int64(-600), bill.GetOutstandingBalance())
+	})
+
+	t.Run("item credit exceeds the item amount", func(t *testing.T) {
+		bill := closedBill()
+		assert.NoError(t, bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{{ItemID: 2, Amount: 300}}}))
+
+		err := bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{
+			{ItemID: 2, Amount: 50},
+			{ItemID: 2, Amount: 51},
+		}})
+		assert.ErrorIs(t, err, domain.ErrCreditExceedsBalance)
+		assert.Len(t, bill.CreditNotes, 1)
+	})
+
+	t.Run("credit exceeds the grand total", func(t *testing.T) {
+		bill := closedBill()
+		assert.NoError(t, bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{{Amount: 900}}}))
+
+		assert.ErrorIs(t, bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{{Amount: 101}}}), domain.ErrCreditExceedsBalance)
+	})
+
+	t.Run("invalid lines", func(t *testing.T) {
+		bill := closedBill()
+
+		assert.ErrorIs(t, bill.ApplyCreditNote(domain.CreditNote{}), domain.ErrInvalidPrice)
+		assert.ErrorIs(t, bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{{Amount: 0}}}), domain.ErrInvalidPrice)
+		assert.ErrorIs(t, bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{{ItemID: 9, Amount: 100}}}), domain.ErrItemNotFound)
+		assert.ErrorIs(t, bill.ApplyCreditNote(domain.CreditNote{Lines: []domain.CreditNoteLine{{ItemID: 3, Amount: 100}}}), domain.ErrItemVoided)
+		assert.Empty(t, bill.CreditNotes)
+	})
+
+	t.Run("bill is not closed", func(t *testing.T) {
+		open := &domain.Bill{Status: domain.BillStatusOpen}
+		voided := &domain.Bill{Status: domain.BillStatusVoided}
+		lines := []domain.CreditNoteLine{{Amount: 100}}
+
+		assert.ErrorIs(t, open.ApplyCreditNote(domain.CreditNote{Lines: lines}), domain.ErrBillNotClosed)
+		assert.ErrorIs(t, voided.ApplyCreditNote(domain.CreditNote{Lines: lines}), domain.ErrBillVoided)
+	})
+}
@@ -0,0 +1,141 @@
+package domain
+
+import "time"
+
+// DiscountType represents how the value of a discount is interpreted.
+type DiscountType string
+
+const (
+	// DiscountTypePercentage represents a percentage discount, Value is expressed
+	// in basis points (1000 = 10%).
+	DiscountTypePercentage DiscountType = "PERCENTAGE"
+	// DiscountTypeFixed represents a fixed-amount discount, Value is expressed
+	// in the smallest unit of the bill currency.
+	DiscountTypeFixed DiscountType = "FIXED"
+)
+
+// MaxPercentageBasisPoints is the basis points value of a 100% discount.
+const MaxPercentageBasisPoints int64 = 10000
+
+// Discount represents a discount applied either to a single item of a bill
+// (ItemID is set) or to the whole bill (ItemID is zero).
+// Amount is the calculated discount in the smallest currency unit.
+// CouponCode is set for the discounts applied by a coupon.
+type Discount struct {
+	ID             int64        `json:"id"`
+	BillingID      string       `json:"billingId"`
+	ItemID         int64        `json:"itemId"`
+	Type           DiscountType `json:"type"`
+	Value          int64        `json:"value"`
+	Description    string       `json:"description"`
+	Amount         int64        `json:"amount"`
+	CouponCode     string       `json:"couponCode"`
+	IdempotencyKey string       `json:"idempotencyKey"`
+	CreatedAt      time.Time    `json:"createdAt"`
+}
+
+// IsBillLevel returns true if the discount applies to the whole bill.
+func (d Discount) IsBillLevel() bool {
+	return d.ItemID == 0
+}
+
+// calculate returns the discount amount for the given base amount.
+// The result never exceeds the base amount.
+func (d Discount) calculate(base int64) int64 {
+	if base <= 0 {
+		return 0
+	}
+
+	var amount int64
+	switch d.Type {
+	case DiscountTypePercentage:
+		amount = PercentageOf(base, d.Value)
+	case DiscountTypeFixed:
+		amount = d.Value
+	}
+
+	if amount > base {
+		return base
+	}
+	return amount
+}
+
+// PercentageOf returns basisPoints of amount, rounded half up to the smallest
+// currency unit, without overflowing for large amounts.
+func PercentageOf(amount, basisPoints int64) int64 {
+	whole := amount / MaxPercentageBasisPoints * basisPoints
+	rest := (amount%MaxPercentageBasisPoints*basisPoints + MaxPercentageBasisPoints/2) / MaxPercentageBasisPoints
+	return whole + rest
+}
+
+// ApplyDiscount adds a discount to the bill and updates the total.
+// Item-level discounts must reference an existing, non-voided item.
+func (b *Bill) ApplyDiscount(discount Discount) error {
+	if !discount.IsBillLevel() {
+		item, found := b.FindItem(discount.ItemID)
+		if !found {
+			return ErrItemNotFound
+		}
+		if item.IsVoided() {
+			return ErrItemVoided
+		}
+	}
+
+	b.Discounts = append(b.Discounts, discount)
+	b.Total = b.GetTotal()
+	return nil
+}
+
+// CalculateDiscounts returns the bill discounts with their Amount calculated.
+// Item-level discounts are applied first on the item amount, bill-level
+// discounts are then applied on the remaining bill amount. Discounts of
+// voided items are calculated as zero.
+func (b *Bill) CalculateDiscounts() []Discount {
+	if len(b.Discounts) == 0 {
+		return nil
+	}
+
+	remainingByItem := make(map[int64]int64, len(b.Items))
+	for _, item := range b.Items {
+		if item.IsVoided() {
+			continue
+		}
+		remainingByItem[item.ID] = item.Amount()
+	}
+
+	discounts := make([]Discount, len(b.Discounts))
+	copy(discounts, b.Discounts)
+
+	remaining := b.GetSubtotal()
+	for i, discount := range discounts {
+		if discount.IsBillLevel() {
+			continue
+		}
+
+		amount := discount.calculate(remainingByItem[discount.ItemID])
+		remainingByItem[discount.ItemID] -= amount
+		remaining -= amount
+		discounts[i].Amount = amount
+	}
+
+	for i, discount := range discounts {
+		if !discount.IsBillLevel() {
+			continue
+		}
+
+		amount := discount.calculate(remaining)
+		remaining -= amount
+		discounts[i].Amount = amount
+	}
+
+	return discounts
+}
+
+// GetDiscountTotal calculates the sum of all discount amounts in the bill.
+func (b *Bill) GetDiscountTotal() int64 {
+	var total int64
+	for _, discount := range b.CalculateDiscounts() {
+		total += discount.Amount
+	}
+	return total
+}
@@ -0,0 +1,146 @@
+package domain_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentageOf(t *testing.T) {
+	testCases := []struct {
+		name        string
+		amount      int64
+		basisPoints int64
+		expected    int64
+	}{
+		{name: "ten percent", amount: 1000, basisPoints: 1000, expected: 100},
+		{name: "rounds half up", amount: 1005, basisPoints: 1000, expected: 101},
+		{name: "rounds down", amount: 1004, basisPoints: 1000, expected: 100},
+		{name: "full amount", amount: 1234, basisPoints: 10000, expected: 1234},
+		{name: "large amount does not overflow", amount: math.MaxInt64, basisPoints: 10000, expected: math.MaxInt64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, domain.PercentageOf(tc.amount, tc.basisPoints))
+		})
+	}
+}
+
+func TestBill_ApplyDiscount(t *testing.T) {
+	voidedAt := time.Now()
+	bill := &domain.Bill{
+		Items: []domain.Item{
+			{ID: 1, Price: 1000},
+			{ID: 2, Price: 500, VoidedAt: &voidedAt},
+		},
+		Total: 1000,
+	}
+
+	assert.Equal(t, domain.ErrItemNotFound, bill.ApplyDiscount(domain.Discount{ItemID: 3, Type: domain.DiscountTypeFixed, Value: 100}))
+	assert.Equal(t, domain.ErrItemVoided, bill.ApplyDiscount(domain.Discount{ItemID: 2, Type: domain.DiscountTypeFixed, Value: 100}))
+	assert.Empty(t, bill.Discounts)
+
+	assert.NoError(t, bill.ApplyDiscount(domain.Discount{ItemID: 1, Type: domain.DiscountTypeFixed, Value: 100}))
+	assert.Len(t, bill.Discounts, 1)
+	assert.Equal(t, int64(900), bill.Total)
+}
+
+func TestBill_CalculateDiscounts(t *testing.T) {
+	voidedAt := time.Now()
+
+	testCases := []struct {
+		name             string
+		bill             domain.Bill
+		expectedAmounts  []int64
+		expectedDiscount int64
+		expectedTotal    int64
+	}{
+		{
+			name:             "no discounts",
+			bill:             domain.Bill{Items: []domain.Item{{ID: 1, Price: 1000}}},
+			expectedAmounts:  nil,
+			expectedDiscount: 0,
+			expectedTotal:    1000,
+		},
+		{
+			name: "item percentage and fixed discounts",
+			bill: domain.Bill{
+				Items: []domain.Item{{ID: 1, Price: 1000}, {ID: 2, Price: 500}},
+				Discounts: []domain.Discount{
+					{ItemID: 1, Type: domain.DiscountTypePercentage, Value: 1000},
+					{ItemID: 2, Type: domain.DiscountTypeFixed, Value: 50},
+				},
+			},
+			expectedAmounts:  []int64{100, 50},
+			expectedDiscount: 150,
+			expectedTotal:    1350,
+		},
+		{
+			name: "bill-level discount applies after item discounts",
+			bill: domain.Bill{
+				Items: []domain.Item{{ID: 1, Price: 1000}, {ID: 2, Price: 1000}},
+				Discounts: []domain.Discount{
+					{Type: domain.DiscountTypePercentage, Value: 5000},
+					{ItemID: 1, Type: domain.DiscountTypeFixed, Value: 200},
+				},
+			},
+			expectedAmounts:  []int64{900, 200},
+			expectedDiscount: 1100,
+			expectedTotal:    900,
+		},
+		{
+			name: "fixed discount is capped at the item amount",
+			bill: domain.Bill{
+				Items: []domain.Item{{ID: 1, Price: 300}, {ID: 2, Price: 700}},
+				Discounts: []domain.Discount{
+					{ItemID: 1, Type: domain.DiscountTypeFixed, Value: 500},
+				},
+			},
+			expectedAmounts:  []int64{300},
+			expectedDiscount: 300,
+			expectedTotal:    700,
+		},
+		{
+			name: "bill-level discounts never make the total negative",
+			bill: domain.Bill{
+				Items: []domain.Item{{ID: 1, Price: 1000}},
+				Discounts: []domain.Discount{
+					{Type: domain.DiscountTypeFixed, Value: 800},
+					{Type: domain.DiscountTypeFixed, Value: 800},
+				},
+			},
+			expectedAmounts:  []int64{800, 200},
+			expectedDiscount: 1000,
+			expectedTotal:    0,
+		},
+		{
+			name: "discount of a voided item is zero",
+			bill: domain.Bill{
+				Items: []domain.Item{{ID: 1, Price: 1000, VoidedAt: &voidedAt}, {ID: 2, Price: 500}},
+				Discounts: []domain.Discount{
+					{ItemID: 1, Type: domain.DiscountTypePercentage, Value: 1000},
+				},
+			},
+			expectedAmounts:  []int64{0},
+			expectedDiscount: 0,
+			expectedTotal:    500,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var amounts []int64
+			for _, discount := range tc.bill.CalculateDiscounts() {
+				amounts = append(amounts, discount.Amount)
+			}
+
+			assert.Equal(t, tc.expectedAmounts, amounts)
+			assert.Equal(t, tc.expectedDiscount, tc.bill.GetDiscountTotal())
+			assert.Equal(t, tc.expectedTotal, tc.bill.GetTotal())
+		})
+	}
+}
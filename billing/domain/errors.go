@@ -4,13 +4,49 @@ import "errors"
 
 // Domain errors
 var (
-	ErrBillNotFound        = errors.New("bill not found")
-	ErrBillClosed          = errors.New("bill is already closed")
-	ErrInvalidCurrency     = errors.New("invalid currency")
-	ErrInvalidPrice        = errors.New("invalid price")
-	ErrInvalidItemName     = errors.New("invalid item name")
-	ErrWorkflowNotFound    = errors.New("workflow not found")
-	ErrFailedToConvertBill = errors.New("failed to convert bill currency")
+	ErrBillNotFound            = errors.New("bill not found")
+	ErrBillClosed              = errors.New("bill is already closed")
+	ErrInvalidCurrency         = errors.New("invalid currency")
+	ErrInvalidPrice            = errors.New("invalid price")
+	ErrInvalidItemName         = errors.New("invalid item name")
+	ErrWorkflowNotFound        = errors.New("workflow not found")
+	ErrFailedToConvertBill     = errors.New("failed to convert bill currency")
+	ErrItemNotFound            = errors.New("item not found")
+	ErrItemVoided              = errors.New("item is already voided")
+	ErrAmountOverflow          = errors.New("amount overflow")
+	ErrBillNotClosed           = errors.New("bill is not closed")
+	ErrReopenWindowExpired     = errors.New("reopen grace period has expired")
+	ErrBillVoided              = errors.New("bill is voided")
+	ErrSpendingCapExceeded     = errors.New("item would exceed the spending cap of the bill")
+	ErrInvalidRecurrence       = errors.New("invalid recurrence")
+	ErrAccountNotFound         = errors.New("account not found")
+	ErrAccountHasBills         = errors.New("account has bills")
+	ErrAccountHasCredit        = errors.New("account has wallet credit")
+	ErrBillPaid                = errors.New("bill is already paid")
+	ErrBillHasPayments         = errors.New("bill has payments")
+	ErrPaymentExceedsBalance   = errors.New("payment exceeds the outstanding balance")
+	ErrPaymentConflict         = errors.New("bill payments changed concurrently")
+	ErrBillHasCreditNotes      = errors.New("bill has credit notes")
+	ErrCreditExceedsBalance    = errors.New("credit exceeds the creditable amount")
+	ErrProviderTimeout         = errors.New("payment provider timed out")
+	ErrProviderPaymentNotFound = errors.New("provider payment not found")
+	ErrPaymentNotConfirmed     = errors.New("payment was not confirmed by the provider")
+	ErrSubscriptionNotFound    = errors.New("subscription not found")
+	ErrSubscriptionPaused      = errors.New("subscription is already paused")
+	ErrSubscriptionNotPaused   = errors.New("subscription is not paused")
+	ErrSubscriptionCancelled   = errors.New("subscription is cancelled")
+	ErrInvalidProration        = errors.New("invalid proration")
+	ErrProductNotFound         = errors.New("product not found")
+	ErrProductArchived         = errors.New("product is archived")
+	ErrSKUNotFound             = errors.New("sku not found")
+	ErrSKUExists               = errors.New("sku already exists")
+	ErrSKUArchived             = errors.New("sku is archived")
+	ErrCouponNotFound          = errors.New("coupon not found")
+	ErrCouponExists            = errors.New("coupon already exists")
+	ErrCouponNotActive         = errors.New("coupon is not active")
+	ErrCouponCurrency          = errors.New("coupon is not valid for the bill currency")
+	ErrCouponExhausted         = errors.New("coupon redemption limit reached")
+	ErrCouponApplied           = errors.New("coupon is already applied to the bill")
 )
 
 // ValidationError represents validation errors
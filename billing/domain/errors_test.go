@@ -14,6 +14,42 @@ func TestDomainErrors(t *testing.T) {
 	assert.EqualError(t, domain.ErrInvalidPrice, "invalid price")
 	assert.EqualError(t, domain.ErrInvalidItemName, "invalid item name")
 	assert.EqualError(t, domain.ErrWorkflowNotFound, "workflow not found")
+	assert.EqualError(t, domain.ErrItemNotFound, "item not found")
+	assert.EqualError(t, domain.ErrItemVoided, "item is already voided")
+	assert.EqualError(t, domain.ErrAmountOverflow, "amount overflow")
+	assert.EqualError(t, domain.ErrBillNotClosed, "bill is not closed")
+	assert.EqualError(t, domain.ErrReopenWindowExpired, "reopen grace period has expired")
+	assert.EqualError(t, domain.ErrBillVoided, "bill is voided")
+	assert.EqualError(t, domain.ErrSpendingCapExceeded, "item would exceed the spending cap of the bill")
+	assert.EqualError(t, domain.ErrInvalidRecurrence, "invalid recurrence")
+	assert.EqualError(t, domain.ErrAccountNotFound, "account not found")
+	assert.EqualError(t, domain.ErrAccountHasBills, "account has bills")
+	assert.EqualError(t, domain.ErrAccountHasCredit, "account has wallet credit")
+	assert.EqualError(t, domain.ErrBillPaid, "bill is already paid")
+	assert.EqualError(t, domain.ErrBillHasPayments, "bill has payments")
+	assert.EqualError(t, domain.ErrPaymentExceedsBalance, "payment exceeds the outstanding balance")
+	assert.EqualError(t, domain.ErrPaymentConflict, "bill payments changed concurrently")
+	assert.EqualError(t, domain.ErrBillHasCreditNotes, "bill has credit notes")
+	assert.EqualError(t, domain.ErrCreditExceedsBalance, "credit exceeds the creditable amount")
+	assert.EqualError(t, domain.ErrProviderTimeout, "payment provider timed out")
+	assert.EqualError(t, domain.ErrProviderPaymentNotFound, "provider payment not found")
+	assert.EqualError(t, domain.ErrPaymentNotConfirmed, "payment was not confirmed by the provider")
+	assert.EqualError(t, domain.ErrSubscriptionNotFound, "subscription not found")
+	assert.EqualError(t, domain.ErrSubscriptionPaused, "subscription is already paused")
+	assert.EqualError(t, domain.ErrSubscriptionNotPaused, "subscription is not paused")
+	assert.EqualError(t, domain.ErrSubscriptionCancelled, "subscription is cancelled")
+	assert.EqualError(t, domain.ErrInvalidProration, "invalid proration")
+	assert.EqualError(t, domain.ErrProductNotFound, "product not found")
+	assert.EqualError(t, domain.ErrProductArchived, "product is archived")
+	assert.EqualError(t, domain.ErrSKUNotFound, "sku not found")
+	assert.EqualError(t, domain.ErrSKUExists, "sku already exists")
+	assert.EqualError(t, domain.ErrSKUArchived, "sku is archived")
+	assert.EqualError(t, domain.ErrCouponNotFound, "coupon not found")
+	assert.EqualError(t, domain.ErrCouponExists, "coupon already exists")
+	assert.EqualError(t, domain.ErrCouponNotActive, "coupon is not active")
+	assert.EqualError(t, domain.ErrCouponCurrency, "coupon is not valid for the bill currency")
+	assert.EqualError(t, domain.ErrCouponExhausted, "coupon redemption limit reached")
+	assert.EqualError(t, domain.ErrCouponApplied, "coupon is already applied to the bill")
 }
 
 func TestValidationError(t *testing.T) {
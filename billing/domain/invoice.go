@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InvoiceNumbering configures the invoice numbers assigned to bills when they
+// are closed. Numbers are sequential and gap-free per Series and year.
+// Format may contain the placeholders {SERIES}, {YEAR} and {NUMBER}, the
+// latter zero-padded to Digits.
+type InvoiceNumbering struct {
+	Series string
+	Format string
+	Digits int
+}
+
+// InvoiceNumber formats the invoice number with the given sequence number
+// of the year.
+func (n InvoiceNumbering) InvoiceNumber(year int, number int64) string {
+	return strings.NewReplacer(
+		"{SERIES}", n.Series,
+		"{YEAR}", fmt.Sprintf("%04d", year),
+		"{NUMBER}", fmt.Sprintf("%0*d", n.Digits, number),
+	).Replace(n.Format)
+}
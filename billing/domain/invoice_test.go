@@ -0,0 +1,46 @@
+package domain_test
+
+import (
+	"testing"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoiceNumbering_InvoiceNumber(t *testing.T) {
+	testCases := []struct {
+		condition string
+		numbering domain.InvoiceNumbering
+		year      int
+		number    int64
+		expected  string
+	}{
+		{
+			condition: "series, year and padded number",
+			numbering: domain.InvoiceNumbering{Series: "INV", Format: "{SERIES}-{YEAR}-{NUMBER}", Digits: 6},
+			year:      2025,
+			number:    42,
+			expected:  "INV-2025-000042",
+		},
+		{
+			condition: "number wider than digits",
+			numbering: domain.InvoiceNumbering{Series: "INV", Format: "{SERIES}{NUMBER}", Digits: 2},
+			year:      2025,
+			number:    1234,
+			expected:  "INV1234",
+		},
+		{
+			condition: "custom prefix without series",
+			numbering: domain.InvoiceNumbering{Series: "B2B", Format: "TBS/{YEAR}/{NUMBER}", Digits: 4},
+			year:      2026,
+			number:    7,
+			expected:  "TBS/2026/0007",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.condition, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.numbering.InvoiceNumber(tc.year, tc.number))
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+// Metadata holds arbitrary key/value pairs attached to bills and items by
+// clients, such as order IDs, table numbers or cost centers.
+type Metadata map[string]string
+
+const (
+	// MaxMetadataKeys is the maximum number of keys of a metadata map.
+	MaxMetadataKeys = 20
+	// MaxMetadataKeyLength is the maximum length of a metadata key, in bytes.
+	MaxMetadataKeyLength = 40
+	// MaxMetadataValueLength is the maximum length of a metadata value, in bytes.
+	MaxMetadataValueLength = 500
+)
@@ -12,6 +12,7 @@ package mock_domain
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "encore.app/billing/domain"
 	gomock "go.uber.org/mock/gomock"
@@ -41,18 +42,135 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ApplyWalletCredit mocks base method.
+func (m *MockRepository) ApplyWalletCredit(ctx context.Context, bill domain.Bill, appliedAt time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyWalletCredit", ctx, bill, appliedAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyWalletCredit indicates an expected call of ApplyWalletCredit.
+func (mr *MockRepositoryMockRecorder) ApplyWalletCredit(ctx, bill, appliedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyWalletCredit", reflect.TypeOf((*MockRepository)(nil).ApplyWalletCredit), ctx, bill, appliedAt)
+}
+
+// ArchiveProduct mocks base method.
+func (m *MockRepository) ArchiveProduct(ctx context.Context, productID string, archivedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveProduct", ctx, productID, archivedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveProduct indicates an expected call of ArchiveProduct.
+func (mr *MockRepositoryMockRecorder) ArchiveProduct(ctx, productID, archivedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveProduct", reflect.TypeOf((*MockRepository)(nil).ArchiveProduct), ctx, productID, archivedAt)
+}
+
+// ArchiveSKU mocks base method.
+func (m *MockRepository) ArchiveSKU(ctx context.Context, code string, archivedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveSKU", ctx, code, archivedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveSKU indicates an expected call of ArchiveSKU.
+func (mr *MockRepositoryMockRecorder) ArchiveSKU(ctx, code, archivedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveSKU", reflect.TypeOf((*MockRepository)(nil).ArchiveSKU), ctx, code, archivedAt)
+}
+
 // CloseBilling mocks base method.
-func (m *MockRepository) CloseBilling(ctx context.Context, billing domain.Bill) error {
+func (m *MockRepository) CloseBilling(ctx context.Context, billing *domain.Bill, numbering domain.InvoiceNumbering) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CloseBilling", ctx, billing)
+	ret := m.ctrl.Call(m, "CloseBilling", ctx, billing, numbering)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CloseBilling indicates an expected call of CloseBilling.
-func (mr *MockRepositoryMockRecorder) CloseBilling(ctx, billing any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CloseBilling(ctx, billing, numbering any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseBilling", reflect.TypeOf((*MockRepository)(nil).CloseBilling), ctx, billing, numbering)
+}
+
+// CountCouponRedemptions mocks base method.
+func (m *MockRepository) CountCouponRedemptions(ctx context.Context, code, accountID string) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCouponRedemptions", ctx, code, accountID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountCouponRedemptions indicates an expected call of CountCouponRedemptions.
+func (mr *MockRepositoryMockRecorder) CountCouponRedemptions(ctx, code, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCouponRedemptions", reflect.TypeOf((*MockRepository)(nil).CountCouponRedemptions), ctx, code, accountID)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockRepository) DeleteAccount(ctx context.Context, accountID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, accountID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockRepositoryMockRecorder) DeleteAccount(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockRepository)(nil).DeleteAccount), ctx, accountID)
+}
+
+// ExpireBilling mocks base method.
+func (m *MockRepository) ExpireBilling(ctx context.Context, entry domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireBilling", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireBilling indicates an expected call of ExpireBilling.
+func (mr *MockRepositoryMockRecorder) ExpireBilling(ctx, entry any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseBilling", reflect.TypeOf((*MockRepository)(nil).CloseBilling), ctx, billing)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireBilling", reflect.TypeOf((*MockRepository)(nil).ExpireBilling), ctx, entry)
+}
+
+// GetAccount mocks base method.
+func (m *MockRepository) GetAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", ctx, accountID)
+	ret0, _ := ret[0].(domain.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockRepositoryMockRecorder) GetAccount(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockRepository)(nil).GetAccount), ctx, accountID)
+}
+
+// GetAuditEntriesByBillID mocks base method.
+func (m *MockRepository) GetAuditEntriesByBillID(ctx context.Context, billID string) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntriesByBillID", ctx, billID)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntriesByBillID indicates an expected call of GetAuditEntriesByBillID.
+func (mr *MockRepositoryMockRecorder) GetAuditEntriesByBillID(ctx, billID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntriesByBillID", reflect.TypeOf((*MockRepository)(nil).GetAuditEntriesByBillID), ctx, billID)
 }
 
 // GetBill mocks base method.
@@ -70,6 +188,66 @@ func (mr *MockRepositoryMockRecorder) GetBill(ctx, billingID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBill", reflect.TypeOf((*MockRepository)(nil).GetBill), ctx, billingID)
 }
 
+// GetBillsByAccountID mocks base method.
+func (m *MockRepository) GetBillsByAccountID(ctx context.Context, accountID string, metadata domain.Metadata) ([]domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBillsByAccountID", ctx, accountID, metadata)
+	ret0, _ := ret[0].([]domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBillsByAccountID indicates an expected call of GetBillsByAccountID.
+func (mr *MockRepositoryMockRecorder) GetBillsByAccountID(ctx, accountID, metadata any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBillsByAccountID", reflect.TypeOf((*MockRepository)(nil).GetBillsByAccountID), ctx, accountID, metadata)
+}
+
+// GetCoupon mocks base method.
+func (m *MockRepository) GetCoupon(ctx context.Context, code string) (domain.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoupon", ctx, code)
+	ret0, _ := ret[0].(domain.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCoupon indicates an expected call of GetCoupon.
+func (mr *MockRepositoryMockRecorder) GetCoupon(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoupon", reflect.TypeOf((*MockRepository)(nil).GetCoupon), ctx, code)
+}
+
+// GetCreditNotesByBillID mocks base method.
+func (m *MockRepository) GetCreditNotesByBillID(ctx context.Context, billID string) ([]domain.CreditNote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreditNotesByBillID", ctx, billID)
+	ret0, _ := ret[0].([]domain.CreditNote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreditNotesByBillID indicates an expected call of GetCreditNotesByBillID.
+func (mr *MockRepositoryMockRecorder) GetCreditNotesByBillID(ctx, billID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreditNotesByBillID", reflect.TypeOf((*MockRepository)(nil).GetCreditNotesByBillID), ctx, billID)
+}
+
+// GetDiscountsByBillID mocks base method.
+func (m *MockRepository) GetDiscountsByBillID(ctx context.Context, billID string) ([]domain.Discount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDiscountsByBillID", ctx, billID)
+	ret0, _ := ret[0].([]domain.Discount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDiscountsByBillID indicates an expected call of GetDiscountsByBillID.
+func (mr *MockRepositoryMockRecorder) GetDiscountsByBillID(ctx, billID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDiscountsByBillID", reflect.TypeOf((*MockRepository)(nil).GetDiscountsByBillID), ctx, billID)
+}
+
 // GetExchangeByBillID mocks base method.
 func (m *MockRepository) GetExchangeByBillID(ctx context.Context, billID string) (domain.BillExchange, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +278,212 @@ func (mr *MockRepositoryMockRecorder) GetItemsByBillID(ctx, billID any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItemsByBillID", reflect.TypeOf((*MockRepository)(nil).GetItemsByBillID), ctx, billID)
 }
 
+// GetPaymentsByBillID mocks base method.
+func (m *MockRepository) GetPaymentsByBillID(ctx context.Context, billID string) ([]domain.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentsByBillID", ctx, billID)
+	ret0, _ := ret[0].([]domain.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentsByBillID indicates an expected call of GetPaymentsByBillID.
+func (mr *MockRepositoryMockRecorder) GetPaymentsByBillID(ctx, billID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentsByBillID", reflect.TypeOf((*MockRepository)(nil).GetPaymentsByBillID), ctx, billID)
+}
+
+// GetProduct mocks base method.
+func (m *MockRepository) GetProduct(ctx context.Context, productID string) (domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProduct", ctx, productID)
+	ret0, _ := ret[0].(domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProduct indicates an expected call of GetProduct.
+func (mr *MockRepositoryMockRecorder) GetProduct(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockRepository)(nil).GetProduct), ctx, productID)
+}
+
+// GetProducts mocks base method.
+func (m *MockRepository) GetProducts(ctx context.Context) ([]domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProducts", ctx)
+	ret0, _ := ret[0].([]domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProducts indicates an expected call of GetProducts.
+func (mr *MockRepositoryMockRecorder) GetProducts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockRepository)(nil).GetProducts), ctx)
+}
+
+// GetSKU mocks base method.
+func (m *MockRepository) GetSKU(ctx context.Context, code string) (domain.SKU, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSKU", ctx, code)
+	ret0, _ := ret[0].(domain.SKU)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSKU indicates an expected call of GetSKU.
+func (mr *MockRepositoryMockRecorder) GetSKU(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSKU", reflect.TypeOf((*MockRepository)(nil).GetSKU), ctx, code)
+}
+
+// GetSKUVersion mocks base method.
+func (m *MockRepository) GetSKUVersion(ctx context.Context, code string, version int64) (domain.SKU, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSKUVersion", ctx, code, version)
+	ret0, _ := ret[0].(domain.SKU)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSKUVersion indicates an expected call of GetSKUVersion.
+func (mr *MockRepositoryMockRecorder) GetSKUVersion(ctx, code, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSKUVersion", reflect.TypeOf((*MockRepository)(nil).GetSKUVersion), ctx, code, version)
+}
+
+// GetSubscription mocks base method.
+func (m *MockRepository) GetSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscription indicates an expected call of GetSubscription.
+func (mr *MockRepositoryMockRecorder) GetSubscription(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockRepository)(nil).GetSubscription), ctx, subscriptionID)
+}
+
+// GetTaxLinesByBillID mocks base method.
+func (m *MockRepository) GetTaxLinesByBillID(ctx context.Context, billID string) ([]domain.TaxLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxLinesByBillID", ctx, billID)
+	ret0, _ := ret[0].([]domain.TaxLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxLinesByBillID indicates an expected call of GetTaxLinesByBillID.
+func (mr *MockRepositoryMockRecorder) GetTaxLinesByBillID(ctx, billID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxLinesByBillID", reflect.TypeOf((*MockRepository)(nil).GetTaxLinesByBillID), ctx, billID)
+}
+
+// GetUsageTotalsByBillID mocks base method.
+func (m *MockRepository) GetUsageTotalsByBillID(ctx context.Context, billID string) ([]domain.UsageTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsageTotalsByBillID", ctx, billID)
+	ret0, _ := ret[0].([]domain.UsageTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsageTotalsByBillID indicates an expected call of GetUsageTotalsByBillID.
+func (mr *MockRepositoryMockRecorder) GetUsageTotalsByBillID(ctx, billID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsageTotalsByBillID", reflect.TypeOf((*MockRepository)(nil).GetUsageTotalsByBillID), ctx, billID)
+}
+
+// GetWallet mocks base method.
+func (m *MockRepository) GetWallet(ctx context.Context, accountID string) (domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, accountID)
+	ret0, _ := ret[0].(domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockRepositoryMockRecorder) GetWallet(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockRepository)(nil).GetWallet), ctx, accountID)
+}
+
+// MarkBillingClosing mocks base method.
+func (m *MockRepository) MarkBillingClosing(ctx context.Context, billingID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkBillingClosing", ctx, billingID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkBillingClosing indicates an expected call of MarkBillingClosing.
+func (mr *MockRepositoryMockRecorder) MarkBillingClosing(ctx, billingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkBillingClosing", reflect.TypeOf((*MockRepository)(nil).MarkBillingClosing), ctx, billingID)
+}
+
+// MarkBillingOverdue mocks base method.
+func (m *MockRepository) MarkBillingOverdue(ctx context.Context, entry domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkBillingOverdue", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkBillingOverdue indicates an expected call of MarkBillingOverdue.
+func (mr *MockRepositoryMockRecorder) MarkBillingOverdue(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkBillingOverdue", reflect.TypeOf((*MockRepository)(nil).MarkBillingOverdue), ctx, entry)
+}
+
+// RemindBilling mocks base method.
+func (m *MockRepository) RemindBilling(ctx context.Context, entry domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemindBilling", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemindBilling indicates an expected call of RemindBilling.
+func (mr *MockRepositoryMockRecorder) RemindBilling(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemindBilling", reflect.TypeOf((*MockRepository)(nil).RemindBilling), ctx, entry)
+}
+
+// ReopenBill mocks base method.
+func (m *MockRepository) ReopenBill(ctx context.Context, entry domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenBill", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReopenBill indicates an expected call of ReopenBill.
+func (mr *MockRepositoryMockRecorder) ReopenBill(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenBill", reflect.TypeOf((*MockRepository)(nil).ReopenBill), ctx, entry)
+}
+
+// ReserveCoupons mocks base method.
+func (m *MockRepository) ReserveCoupons(ctx context.Context, bill domain.Bill, reservedAt time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveCoupons", ctx, bill, reservedAt)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveCoupons indicates an expected call of ReserveCoupons.
+func (mr *MockRepositoryMockRecorder) ReserveCoupons(ctx, bill, reservedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveCoupons", reflect.TypeOf((*MockRepository)(nil).ReserveCoupons), ctx, bill, reservedAt)
+}
+
 // RevertBillClosing mocks base method.
 func (m *MockRepository) RevertBillClosing(ctx context.Context, billingID string) error {
 	m.ctrl.T.Helper()
@@ -114,6 +498,20 @@ func (mr *MockRepositoryMockRecorder) RevertBillClosing(ctx, billingID any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertBillClosing", reflect.TypeOf((*MockRepository)(nil).RevertBillClosing), ctx, billingID)
 }
 
+// SaveAccount mocks base method.
+func (m *MockRepository) SaveAccount(ctx context.Context, account *domain.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAccount", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAccount indicates an expected call of SaveAccount.
+func (mr *MockRepositoryMockRecorder) SaveAccount(ctx, account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccount", reflect.TypeOf((*MockRepository)(nil).SaveAccount), ctx, account)
+}
+
 // SaveBill mocks base method.
 func (m *MockRepository) SaveBill(ctx context.Context, bill *domain.Bill) error {
 	m.ctrl.T.Helper()
@@ -128,6 +526,48 @@ func (mr *MockRepositoryMockRecorder) SaveBill(ctx, bill any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBill", reflect.TypeOf((*MockRepository)(nil).SaveBill), ctx, bill)
 }
 
+// SaveCoupon mocks base method.
+func (m *MockRepository) SaveCoupon(ctx context.Context, coupon *domain.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCoupon", ctx, coupon)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCoupon indicates an expected call of SaveCoupon.
+func (mr *MockRepositoryMockRecorder) SaveCoupon(ctx, coupon any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCoupon", reflect.TypeOf((*MockRepository)(nil).SaveCoupon), ctx, coupon)
+}
+
+// SaveCreditNotes mocks base method.
+func (m *MockRepository) SaveCreditNotes(ctx context.Context, bill *domain.Bill) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCreditNotes", ctx, bill)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCreditNotes indicates an expected call of SaveCreditNotes.
+func (mr *MockRepositoryMockRecorder) SaveCreditNotes(ctx, bill any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCreditNotes", reflect.TypeOf((*MockRepository)(nil).SaveCreditNotes), ctx, bill)
+}
+
+// SaveDiscount mocks base method.
+func (m *MockRepository) SaveDiscount(ctx context.Context, discount *domain.Discount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDiscount", ctx, discount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDiscount indicates an expected call of SaveDiscount.
+func (mr *MockRepositoryMockRecorder) SaveDiscount(ctx, discount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDiscount", reflect.TypeOf((*MockRepository)(nil).SaveDiscount), ctx, discount)
+}
+
 // SaveExchange mocks base method.
 func (m *MockRepository) SaveExchange(ctx context.Context, bill *domain.Bill) error {
 	m.ctrl.T.Helper()
@@ -155,3 +595,172 @@ func (mr *MockRepositoryMockRecorder) SaveItem(ctx, item any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveItem", reflect.TypeOf((*MockRepository)(nil).SaveItem), ctx, item)
 }
+
+// SavePayments mocks base method.
+func (m *MockRepository) SavePayments(ctx context.Context, bill *domain.Bill) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePayments", ctx, bill)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePayments indicates an expected call of SavePayments.
+func (mr *MockRepositoryMockRecorder) SavePayments(ctx, bill any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePayments", reflect.TypeOf((*MockRepository)(nil).SavePayments), ctx, bill)
+}
+
+// SaveProduct mocks base method.
+func (m *MockRepository) SaveProduct(ctx context.Context, product *domain.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveProduct", ctx, product)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveProduct indicates an expected call of SaveProduct.
+func (mr *MockRepositoryMockRecorder) SaveProduct(ctx, product any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProduct", reflect.TypeOf((*MockRepository)(nil).SaveProduct), ctx, product)
+}
+
+// SaveSKU mocks base method.
+func (m *MockRepository) SaveSKU(ctx context.Context, sku *domain.SKU) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSKU", ctx, sku)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSKU indicates an expected call of SaveSKU.
+func (mr *MockRepositoryMockRecorder) SaveSKU(ctx, sku any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSKU", reflect.TypeOf((*MockRepository)(nil).SaveSKU), ctx, sku)
+}
+
+// SaveSubscription mocks base method.
+func (m *MockRepository) SaveSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSubscription", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSubscription indicates an expected call of SaveSubscription.
+func (mr *MockRepositoryMockRecorder) SaveSubscription(ctx, subscription any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSubscription", reflect.TypeOf((*MockRepository)(nil).SaveSubscription), ctx, subscription)
+}
+
+// SaveTaxLines mocks base method.
+func (m *MockRepository) SaveTaxLines(ctx context.Context, bill *domain.Bill) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTaxLines", ctx, bill)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTaxLines indicates an expected call of SaveTaxLines.
+func (mr *MockRepositoryMockRecorder) SaveTaxLines(ctx, bill any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTaxLines", reflect.TypeOf((*MockRepository)(nil).SaveTaxLines), ctx, bill)
+}
+
+// SaveUsageEvents mocks base method.
+func (m *MockRepository) SaveUsageEvents(ctx context.Context, billingID string, events []domain.UsageEvent) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUsageEvents", ctx, billingID, events)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveUsageEvents indicates an expected call of SaveUsageEvents.
+func (mr *MockRepositoryMockRecorder) SaveUsageEvents(ctx, billingID, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUsageEvents", reflect.TypeOf((*MockRepository)(nil).SaveUsageEvents), ctx, billingID, events)
+}
+
+// TopUpWallet mocks base method.
+func (m *MockRepository) TopUpWallet(ctx context.Context, entry *domain.WalletEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopUpWallet", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TopUpWallet indicates an expected call of TopUpWallet.
+func (mr *MockRepositoryMockRecorder) TopUpWallet(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopUpWallet", reflect.TypeOf((*MockRepository)(nil).TopUpWallet), ctx, entry)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockRepository) UpdateAccount(ctx context.Context, account *domain.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockRepositoryMockRecorder) UpdateAccount(ctx, account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockRepository)(nil).UpdateAccount), ctx, account)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockRepository) UpdateProduct(ctx context.Context, product *domain.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, product)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockRepositoryMockRecorder) UpdateProduct(ctx, product any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockRepository)(nil).UpdateProduct), ctx, product)
+}
+
+// UpdateSKU mocks base method.
+func (m *MockRepository) UpdateSKU(ctx context.Context, sku *domain.SKU) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSKU", ctx, sku)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSKU indicates an expected call of UpdateSKU.
+func (mr *MockRepositoryMockRecorder) UpdateSKU(ctx, sku any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSKU", reflect.TypeOf((*MockRepository)(nil).UpdateSKU), ctx, sku)
+}
+
+// VoidBilling mocks base method.
+func (m *MockRepository) VoidBilling(ctx context.Context, entry domain.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidBilling", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VoidBilling indicates an expected call of VoidBilling.
+func (mr *MockRepositoryMockRecorder) VoidBilling(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidBilling", reflect.TypeOf((*MockRepository)(nil).VoidBilling), ctx, entry)
+}
+
+// VoidItem mocks base method.
+func (m *MockRepository) VoidItem(ctx context.Context, item domain.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VoidItem indicates an expected call of VoidItem.
+func (mr *MockRepositoryMockRecorder) VoidItem(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidItem", reflect.TypeOf((*MockRepository)(nil).VoidItem), ctx, item)
+}
@@ -1,18 +1,34 @@
 package domain
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // Bill represents the core domain entity for billing.
 type Bill struct {
-	ID         int64        `json:"id"`
-	BillingID  string       `json:"billingId"`
-	Status     BillStatus   `json:"status"`
-	Currency   Currency     `json:"currency"`
-	Total      int64        `json:"total"`
-	Items      []Item       `json:"items"`
-	Conversion BillExchange `json:"conversion"`
-	CreatedAt  time.Time    `json:"createdAt"`
-	ClosedAt   *time.Time   `json:"closedAt"`
+	ID             int64        `json:"id"`
+	BillingID      string       `json:"billingId"`
+	InvoiceNumber  string       `json:"invoiceNumber"`
+	AccountID      string       `json:"accountId"`
+	SubscriptionID string       `json:"subscriptionId"`
+	Status         BillStatus   `json:"status"`
+	Currency       Currency     `json:"currency"`
+	Region         string       `json:"region"`
+	Total          int64        `json:"total"`
+	MaxTotal       int64        `json:"maxTotal"`
+	Items          []Item       `json:"items"`
+	Discounts      []Discount   `json:"discounts"`
+	Taxes          []TaxLine    `json:"taxes"`
+	Conversion     BillExchange `json:"conversion"`
+	Payments       []Payment    `json:"payments"`
+	CreditNotes    []CreditNote `json:"creditNotes"`
+	CreditApplied  int64        `json:"creditApplied"`
+	PeriodEnd      *time.Time   `json:"periodEnd"`
+	Metadata       Metadata     `json:"metadata"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	ClosedAt       *time.Time   `json:"closedAt"`
+	VoidedAt       *time.Time   `json:"voidedAt"`
 }
 
 // BillStatus represents the possible states of a bill.
@@ -22,8 +38,24 @@ const (
 	// BillStatusOpen represents the status OPEN.
 	BillStatusOpen BillStatus = "OPEN"
 
+	// BillStatusClosing represents an open bill whose closing has started:
+	// its usage is aggregated and no more usage is recorded for it.
+	BillStatusClosing BillStatus = "CLOSING"
+
 	// BillStatusClosed represents the status CLOSED.
 	BillStatusClosed BillStatus = "CLOSED"
+
+	// BillStatusVoided represents the status VOIDED, a bill cancelled while open.
+	BillStatusVoided BillStatus = "VOIDED"
+
+	// BillStatusPartiallyPaid represents a closed bill with an outstanding balance left after payments.
+	BillStatusPartiallyPaid BillStatus = "PARTIALLY_PAID"
+
+	// BillStatusPaid represents a closed bill whose balance is fully paid.
+	BillStatusPaid BillStatus = "PAID"
+
+	// BillStatusOverdue represents a closed bill still unpaid at the end of its dunning schedule.
+	BillStatusOverdue BillStatus = "OVERDUE"
 )
 
 // Currency represents supported currencies.
@@ -38,12 +70,75 @@ const (
 )
 
 // Item represents a line item in a bill.
+// Price is the line total, i.e. Quantity × UnitPrice, in the smallest currency unit.
+// Items priced by tiers have no single unit price: their Price is the sum of
+// the amounts of their Tiers. Items added by SKU keep the SKU and the catalog
+// version of the SKU they were priced with.
+// A voided item is kept in the bill history but excluded from the total.
 type Item struct {
-	ID             int64  `json:"id"`
-	BillingID      string `json:"billingId"`
-	Name           string `json:"name"`
-	Price          int64  `json:"price"`
-	IdempotencyKey string `json:"idempotencyKey"`
+	ID             int64      `json:"id"`
+	BillingID      string     `json:"billingId"`
+	Name           string     `json:"name"`
+	Quantity       int64      `json:"quantity"`
+	Unit           string     `json:"unit"`
+	UnitPrice      int64      `json:"unitPrice"`
+	Price          int64      `json:"price"`
+	IdempotencyKey string     `json:"idempotencyKey"`
+	Metadata       Metadata   `json:"metadata"`
+	Tiers          []ItemTier `json:"tiers"`
+	SKU            string     `json:"sku"`
+	CatalogVersion int64      `json:"catalogVersion"`
+	VoidedAt       *time.Time `json:"voidedAt"`
+}
+
+// IsVoided returns true if the item has been voided.
+func (i Item) IsVoided() bool {
+	return i.VoidedAt != nil
+}
+
+// GetQuantity returns the item quantity.
+// Items created before quantities were introduced count as a single unit.
+func (i Item) GetQuantity() int64 {
+	if i.Quantity <= 0 {
+		return 1
+	}
+	return i.Quantity
+}
+
+// GetUnitPrice returns the item unit price.
+// Items created before unit prices were introduced use Price as unit price.
+// Items priced by tiers have no unit price.
+func (i Item) GetUnitPrice() int64 {
+	if i.UnitPrice <= 0 && !i.IsTiered() {
+		return i.Price
+	}
+	return i.UnitPrice
+}
+
+// IsTiered returns true if the item is priced by tiers.
+func (i Item) IsTiered() bool {
+	return len(i.Tiers) > 0
+}
+
+// Amount returns the line total of the item, i.e. quantity × unit price, or
+// the sum of its tiers for items priced by tiers.
+func (i Item) Amount() int64 {
+	if i.IsTiered() {
+		return i.Price
+	}
+	return i.GetQuantity() * i.GetUnitPrice()
+}
+
+// LineAmount multiplies quantity by unitPrice and returns ErrAmountOverflow
+// when the result does not fit into an int64.
+func LineAmount(quantity, unitPrice int64) (int64, error) {
+	if quantity < 0 || unitPrice < 0 {
+		return 0, ErrInvalidPrice
+	}
+	if unitPrice != 0 && quantity > math.MaxInt64/unitPrice {
+		return 0, ErrAmountOverflow
+	}
+	return quantity * unitPrice, nil
 }
 
 // BillExchange represents currency conversion info for a bill.
@@ -56,31 +151,121 @@ type BillExchange struct {
 	Total          int64    `json:"total"`
 }
 
+// Convert converts an amount in the smallest unit of the base currency to the
+// smallest unit of the target currency at the rate of the exchange.
+func (e BillExchange) Convert(amount int64) int64 {
+	return int64(math.Round(float64(amount) / 100 * e.Rate * 100))
+}
+
 // AddItem adds a line item to the bill and updates the total.
 func (b *Bill) AddItem(item Item) {
 	b.Items = append(b.Items, item)
-	b.Total += item.Price
+	b.Total = b.GetTotal()
+}
+
+// ExceedsSpendingCap returns true if adding item would push the total of the
+// bill past its MaxTotal. Bills without a MaxTotal have no spending cap.
+func (b *Bill) ExceedsSpendingCap(item Item) bool {
+	if b.MaxTotal <= 0 {
+		return false
+	}
+
+	next := *b
+	next.Items = append(append([]Item(nil), b.Items...), item)
+	return next.GetTotal() > b.MaxTotal
+}
+
+// FindItem returns the item with the given ID.
+func (b *Bill) FindItem(itemID int64) (Item, bool) {
+	for _, item := range b.Items {
+		if item.ID == itemID {
+			return item, true
+		}
+	}
+
+	return Item{}, false
+}
+
+// VoidItem marks the item with the given ID as voided at a given timestamp
+// and updates the total. The item stays in the bill's item history.
+func (b *Bill) VoidItem(itemID int64, voidedAt time.Time) error {
+	for i := range b.Items {
+		if b.Items[i].ID != itemID {
+			continue
+		}
+
+		if b.Items[i].IsVoided() {
+			return ErrItemVoided
+		}
+
+		b.Items[i].VoidedAt = &voidedAt
+		b.Total = b.GetTotal()
+		return nil
+	}
+
+	return ErrItemNotFound
 }
 
 // Close marks the bill as closed at a given timestamp and updates the total.
+// A bill fully covered by wallet credit is closed as paid.
 func (b *Bill) Close(closedAt time.Time) {
 	b.ClosedAt = &closedAt
 	b.Status = BillStatusClosed
 	b.Total = b.GetTotal()
+	if b.CreditApplied > 0 && b.GetOutstandingBalance() <= 0 {
+		b.Status = BillStatusPaid
+	}
+}
+
+// Void marks the bill as voided at a given timestamp.
+// A voided bill keeps its items but is excluded from totals and reports.
+func (b *Bill) Void(voidedAt time.Time) {
+	b.VoidedAt = &voidedAt
+	b.Status = BillStatusVoided
+}
+
+// GetSubtotal calculates the sum of all non-voided item amounts in the bill.
+func (b *Bill) GetSubtotal() int64 {
+	var subtotal int64
+	for _, item := range b.Items {
+		if item.IsVoided() {
+			continue
+		}
+		subtotal += item.Amount()
+	}
+	return subtotal
 }
 
-// GetTotal calculates the sum of all item prices in the bill.
+// GetTotal calculates the bill subtotal minus all discounts.
 func (b *Bill) GetTotal() int64 {
-	var total int64
+	return b.GetSubtotal() - b.GetDiscountTotal()
+}
+
+// HasActiveItems returns true if the bill has at least one non-voided item.
+func (b *Bill) HasActiveItems() bool {
 	for _, item := range b.Items {
-		total += item.Price
+		if !item.IsVoided() {
+			return true
+		}
 	}
-	return total
+	return false
 }
 
-// IsClosed returns true if the bill is closed.
+// IsClosed returns true if the bill is closed, including closed bills
+// that are partially or fully paid.
 func (b *Bill) IsClosed() bool {
-	return b.Status == BillStatusClosed
+	return b.Status == BillStatusClosed || b.Status == BillStatusPartiallyPaid || b.Status == BillStatusPaid ||
+		b.Status == BillStatusOverdue
+}
+
+// IsPaid returns true if the bill is fully paid.
+func (b *Bill) IsPaid() bool {
+	return b.Status == BillStatusPaid
+}
+
+// IsVoided returns true if the bill is voided.
+func (b *Bill) IsVoided() bool {
+	return b.Status == BillStatusVoided
 }
 
 // IsOpen returns true if the bill is open.
@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -18,6 +19,25 @@ func TestBill_AddItem(t *testing.T) {
 	assert.Equal(t, int64(1000), bill.Total)
 }
 
+func TestBill_ExceedsSpendingCap(t *testing.T) {
+	bill := &domain.Bill{
+		Status:   domain.BillStatusOpen,
+		MaxTotal: 1500,
+		Items:    []domain.Item{{Price: 1000, Name: "Espresso"}},
+	}
+
+	assert.False(t, bill.ExceedsSpendingCap(domain.Item{Price: 500, Name: "Croissant"}))
+	assert.True(t, bill.ExceedsSpendingCap(domain.Item{Price: 501, Name: "Cake"}))
+	assert.Len(t, bill.Items, 1)
+
+	// the cap applies to the total after discounts.
+	bill.Discounts = []domain.Discount{{Type: domain.DiscountTypeFixed, Value: 200}}
+	assert.False(t, bill.ExceedsSpendingCap(domain.Item{Price: 700, Name: "Cake"}))
+
+	uncapped := &domain.Bill{Status: domain.BillStatusOpen}
+	assert.False(t, uncapped.ExceedsSpendingCap(domain.Item{Price: math.MaxInt32, Name: "Cake"}))
+}
+
 func TestBill_GetTotal(t *testing.T) {
 	bill := &domain.Bill{
 		Items: []domain.Item{
@@ -30,6 +50,80 @@ func TestBill_GetTotal(t *testing.T) {
 	assert.Equal(t, int64(1500), total)
 }
 
+func TestBill_GetTotal_WithQuantity(t *testing.T) {
+	bill := &domain.Bill{
+		Items: []domain.Item{
+			{Quantity: 3, UnitPrice: 450, Price: 1350},
+			{Price: 500},
+		},
+	}
+
+	assert.Equal(t, int64(1850), bill.GetTotal())
+}
+
+func TestItem_QuantityDefaults(t *testing.T) {
+	item := domain.Item{Price: 1000}
+
+	assert.Equal(t, int64(1), item.GetQuantity())
+	assert.Equal(t, int64(1000), item.GetUnitPrice())
+	assert.Equal(t, int64(1000), item.Amount())
+}
+
+func TestLineAmount(t *testing.T) {
+	amount, err := domain.LineAmount(3, 450)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1350), amount)
+
+	_, err = domain.LineAmount(math.MaxInt64/2, 3)
+	assert.Equal(t, domain.ErrAmountOverflow, err)
+
+	_, err = domain.LineAmount(-1, 450)
+	assert.Equal(t, domain.ErrInvalidPrice, err)
+}
+
+func TestBill_GetTotal_SkipsVoidedItems(t *testing.T) {
+	voidedAt := time.Now()
+	bill := &domain.Bill{
+		Items: []domain.Item{
+			{ID: 1, Price: 1000},
+			{ID: 2, Price: 500, VoidedAt: &voidedAt},
+		},
+	}
+
+	assert.Equal(t, int64(1000), bill.GetTotal())
+}
+
+func TestBill_VoidItem(t *testing.T) {
+	bill := &domain.Bill{
+		Items:  []domain.Item{{ID: 1, Price: 1000}, {ID: 2, Price: 500}},
+		Total:  1500,
+		Status: domain.BillStatusOpen,
+	}
+
+	voidedAt := time.Now()
+	err := bill.VoidItem(2, voidedAt)
+
+	assert.NoError(t, err)
+	assert.Len(t, bill.Items, 2)
+	assert.True(t, bill.Items[1].IsVoided())
+	assert.Equal(t, voidedAt, *bill.Items[1].VoidedAt)
+	assert.Equal(t, int64(1000), bill.Total)
+
+	assert.Equal(t, domain.ErrItemVoided, bill.VoidItem(2, voidedAt))
+	assert.Equal(t, domain.ErrItemNotFound, bill.VoidItem(3, voidedAt))
+}
+
+func TestBill_FindItem(t *testing.T) {
+	bill := &domain.Bill{Items: []domain.Item{{ID: 1, Name: "Sparkling"}}}
+
+	item, found := bill.FindItem(1)
+	assert.True(t, found)
+	assert.Equal(t, "Sparkling", item.Name)
+
+	_, found = bill.FindItem(2)
+	assert.False(t, found)
+}
+
 func TestBill_Close(t *testing.T) {
 	bill := &domain.Bill{
 		Items:  []domain.Item{{Price: 1000}, {Price: 500}},
@@ -55,3 +149,41 @@ func TestBill_IsOpen_IsClosed(t *testing.T) {
 	assert.False(t, bill.IsOpen())
 	assert.True(t, bill.IsClosed())
 }
+
+func TestBill_Void(t *testing.T) {
+	bill := &domain.Bill{
+		Items:  []domain.Item{{Price: 1000}},
+		Status: domain.BillStatusOpen,
+	}
+
+	now := time.Now()
+	bill.Void(now)
+
+	assert.Equal(t, domain.BillStatusVoided, bill.Status)
+	assert.Equal(t, now, *bill.VoidedAt)
+	assert.Len(t, bill.Items, 1)
+	assert.True(t, bill.IsVoided())
+	assert.False(t, bill.IsOpen())
+	assert.False(t, bill.IsClosed())
+}
+
+func TestBill_HasActiveItems(t *testing.T) {
+	voidedAt := time.Now()
+
+	bill := &domain.Bill{}
+	assert.False(t, bill.HasActiveItems())
+
+	bill.Items = []domain.Item{{ID: 1, Price: 1000, VoidedAt: &voidedAt}}
+	assert.False(t, bill.HasActiveItems())
+
+	bill.Items = append(bill.Items, domain.Item{ID: 2, Price: 500})
+	assert.True(t, bill.HasActiveItems())
+}
+
+func TestBillExchange_Convert(t *testing.T) {
+	exchange := domain.BillExchange{BaseCurrency: domain.CurrencyUSD, TargetCurrency: domain.CurrencyGEL, Rate: 2.7778}
+
+	assert.Equal(t, int64(2778), exchange.Convert(1000))
+	assert.Equal(t, int64(3), exchange.Convert(1))
+	assert.Equal(t, int64(0), exchange.Convert(0))
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// Payment represents a payment recorded against a closed bill.
+// Amount is expressed in the smallest unit of the payment Currency and
+// BillAmount is the same payment converted to the bill currency with Rate.
+type Payment struct {
+	ID         int64     `json:"id"`
+	BillingID  string    `json:"billingId"`
+	Amount     int64     `json:"amount"`
+	Currency   Currency  `json:"currency"`
+	Rate       float64   `json:"rate"`
+	BillAmount int64     `json:"billAmount"`
+	Reference  string    `json:"reference"`
+	PaidAt     time.Time `json:"paidAt"`
+}
+
+// GetPaidTotal calculates the sum of all payments in the bill currency.
+func (b *Bill) GetPaidTotal() int64 {
+	var total int64
+	for _, p := range b.Payments {
+		total += p.BillAmount
+	}
+	return total
+}
+
+// GetOutstandingBalance calculates the amount still due for the bill,
+// i.e. the grand total minus the wallet credit applied, all payments and
+// credit notes. A negative balance is owed back to the customer.
+func (b *Bill) GetOutstandingBalance() int64 {
+	return b.GetAmountDue() - b.GetPaidTotal() - b.GetCreditedTotal()
+}
+
+// ApplyPayment records a payment against a closed bill and updates its status
+// to PARTIALLY_PAID, or to PAID once the outstanding balance is settled.
+func (b *Bill) ApplyPayment(payment Payment) error {
+	if b.IsVoided() {
+		return ErrBillVoided
+	}
+	if b.IsOpen() {
+		return ErrBillNotClosed
+	}
+	if b.IsPaid() {
+		return ErrBillPaid
+	}
+	if payment.BillAmount <= 0 {
+		return ErrInvalidPrice
+	}
+	if payment.BillAmount > b.GetOutstandingBalance() {
+		return ErrPaymentExceedsBalance
+	}
+
+	b.Payments = append(b.Payments, payment)
+	b.refreshPaymentStatus()
+	return nil
+}
+
+// refreshPaymentStatus updates the status of a closed bill from its
+// outstanding balance: PAID once it is settled, PARTIALLY_PAID while
+// payments leave a balance and CLOSED otherwise. Overdue bills stay OVERDUE
+// until they are settled.
+func (b *Bill) refreshPaymentStatus() {
+	switch {
+	case b.GetOutstandingBalance() <= 0:
+		b.Status = BillStatusPaid
+	case b.Status == BillStatusOverdue:
+	case len(b.Payments) > 0:
+		b.Status = BillStatusPartiallyPaid
+	default:
+		b.Status = BillStatusClosed
+	}
+}
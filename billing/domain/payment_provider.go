@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PaymentProvider defines the operations of a payment gateway used to
+// collect the outstanding balance of closed bills. Operations that the
+// provider completes asynchronously return a PENDING payment, whose outcome
+// is confirmed later through SignalPaymentConfirmation or Status.
+type PaymentProvider interface {
+	Authorize(ctx context.Context, req PaymentRequest) (ProviderPayment, error)
+	Capture(ctx context.Context, paymentID string) (ProviderPayment, error)
+	Refund(ctx context.Context, paymentID string, amount int64) (ProviderPayment, error)
+	Status(ctx context.Context, paymentID string) (ProviderPayment, error)
+}
+
+// PaymentRequest represents a request to charge the outstanding balance of a
+// bill. IdempotencyKey makes sure a retried authorization is charged once.
+type PaymentRequest struct {
+	BillingID      string   `json:"billingId"`
+	AccountID      string   `json:"accountId"`
+	Amount         int64    `json:"amount"`
+	Currency       Currency `json:"currency"`
+	IdempotencyKey string   `json:"idempotencyKey"`
+}
+
+// ProviderPayment represents a payment as known by the payment provider.
+type ProviderPayment struct {
+	ID            string                `json:"id"`
+	BillingID     string                `json:"billingId"`
+	Status        ProviderPaymentStatus `json:"status"`
+	Amount        int64                 `json:"amount"`
+	Currency      Currency              `json:"currency"`
+	DeclineReason string                `json:"declineReason"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
+}
+
+// ProviderPaymentStatus represents the possible states of a provider payment.
+type ProviderPaymentStatus string
+
+const (
+	// ProviderPaymentPending represents an operation awaiting confirmation.
+	ProviderPaymentPending ProviderPaymentStatus = "PENDING"
+	// ProviderPaymentAuthorized represents an authorized, not yet captured payment.
+	ProviderPaymentAuthorized ProviderPaymentStatus = "AUTHORIZED"
+	// ProviderPaymentCaptured represents a collected payment.
+	ProviderPaymentCaptured ProviderPaymentStatus = "CAPTURED"
+	// ProviderPaymentDeclined represents a payment declined by the provider.
+	ProviderPaymentDeclined ProviderPaymentStatus = "DECLINED"
+	// ProviderPaymentRefunded represents a refunded payment.
+	ProviderPaymentRefunded ProviderPaymentStatus = "REFUNDED"
+)
+
+// IsPending returns true if the payment awaits confirmation by the provider.
+func (p ProviderPayment) IsPending() bool {
+	return p.Status == ProviderPaymentPending
+}
+
+// PaymentWorkflowID returns the ID of the payment workflow of a bill.
+func PaymentWorkflowID(billingID string) string {
+	return "payment-" + billingID
+}
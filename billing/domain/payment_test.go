@@ -0,0 +1,79 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBill_ApplyPayment(t *testing.T) {
+	paidAt := time.Now()
+	closedBill := func() *domain.Bill {
+		return &domain.Bill{
+			Status: domain.BillStatusClosed,
+			Items:  []domain.Item{{ID: 1, Price: 1000}},
+		}
+	}
+
+	t.Run("partial then full payment", func(t *testing.T) {
+		bill := closedBill()
+
+		assert.NoError(t, bill.ApplyPayment(domain.Payment{BillAmount: 400, PaidAt: paidAt}))
+		assert.Equal(t, domain.BillStatusPartiallyPaid, bill.Status)
+		assert.Equal(t, int64(400), bill.GetPaidTotal())
+		assert.Equal(t, int64(600), bill.GetOutstandingBalance())
+		assert.True(t, bill.IsClosed())
+
+		assert.NoError(t, bill.ApplyPayment(domain.Payment{BillAmount: 600, PaidAt: paidAt}))
+		assert.Equal(t, domain.BillStatusPaid, bill.Status)
+		assert.Equal(t, int64(0), bill.GetOutstandingBalance())
+		assert.True(t, bill.IsPaid())
+		assert.True(t, bill.IsClosed())
+		assert.Len(t, bill.Payments, 2)
+	})
+
+	t.Run("outstanding balance includes exclusive taxes", func(t *testing.T) {
+		bill := closedBill()
+		bill.Taxes = []domain.TaxLine{{Code: "SALES_TAX", Amount: 73}}
+
+		assert.Equal(t, int64(1073), bill.GetOutstandingBalance())
+		assert.NoError(t, bill.ApplyPayment(domain.Payment{BillAmount: 1073}))
+		assert.Equal(t, domain.BillStatusPaid, bill.Status)
+	})
+
+	t.Run("overdue bill stays overdue until settled", func(t *testing.T) {
+		bill := closedBill()
+		bill.Status = domain.BillStatusOverdue
+		assert.True(t, bill.IsClosed())
+
+		assert.NoError(t, bill.ApplyPayment(domain.Payment{BillAmount: 400}))
+		assert.Equal(t, domain.BillStatusOverdue, bill.Status)
+
+		assert.NoError(t, bill.ApplyPayment(domain.Payment{BillAmount: 600}))
+		assert.Equal(t, domain.BillStatusPaid, bill.Status)
+	})
+
+	t.Run("overpayment", func(t *testing.T) {
+		bill := closedBill()
+
+		assert.ErrorIs(t, bill.ApplyPayment(domain.Payment{BillAmount: 1001}), domain.ErrPaymentExceedsBalance)
+		assert.Equal(t, domain.BillStatusClosed, bill.Status)
+		assert.Empty(t, bill.Payments)
+	})
+
+	t.Run("non positive amount", func(t *testing.T) {
+		assert.ErrorIs(t, closedBill().ApplyPayment(domain.Payment{BillAmount: 0}), domain.ErrInvalidPrice)
+	})
+
+	t.Run("bill is not closed", func(t *testing.T) {
+		open := &domain.Bill{Status: domain.BillStatusOpen}
+		voided := &domain.Bill{Status: domain.BillStatusVoided}
+		paid := &domain.Bill{Status: domain.BillStatusPaid}
+
+		assert.ErrorIs(t, open.ApplyPayment(domain.Payment{BillAmount: 100}), domain.ErrBillNotClosed)
+		assert.ErrorIs(t, voided.ApplyPayment(domain.Payment{BillAmount: 100}), domain.ErrBillVoided)
+		assert.ErrorIs(t, paid.ApplyPayment(domain.Payment{BillAmount: 100}), domain.ErrBillPaid)
+	})
+}
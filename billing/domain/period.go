@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Recurrence represents a recurring billing period after which a bill is
+// closed automatically.
+type Recurrence string
+
+const (
+	// RecurrenceEndOfDay closes the bill at the end of the day.
+	RecurrenceEndOfDay Recurrence = "END_OF_DAY"
+	// RecurrenceEndOfWeek closes the bill at the end of the ISO week, on Sunday at midnight.
+	RecurrenceEndOfWeek Recurrence = "END_OF_WEEK"
+	// RecurrenceEndOfMonth closes the bill at the end of the calendar month.
+	RecurrenceEndOfMonth Recurrence = "END_OF_MONTH"
+)
+
+// PeriodEnd returns the end of the period of the given recurrence that
+// contains from, evaluated in the given location. The end is exclusive:
+// the end of month for January is February 1st at midnight.
+func PeriodEnd(recurrence Recurrence, from time.Time, loc *time.Location) (time.Time, error) {
+	local := from.In(loc)
+	year, month, day := local.Date()
+
+	switch recurrence {
+	case RecurrenceEndOfDay:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, loc), nil
+	case RecurrenceEndOfWeek:
+		daysUntilMonday := (8 - int(local.Weekday())) % 7
+		if daysUntilMonday == 0 {
+			daysUntilMonday = 7
+		}
+		return time.Date(year, month, day+daysUntilMonday, 0, 0, 0, 0, loc), nil
+	case RecurrenceEndOfMonth:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, loc), nil
+	default:
+		return time.Time{}, ErrInvalidRecurrence
+	}
+}
@@ -0,0 +1,91 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodEnd(t *testing.T) {
+	tbilisi, err := time.LoadLocation("Asia/Tbilisi")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		recurrence  domain.Recurrence
+		from        time.Time
+		loc         *time.Location
+		expected    time.Time
+		expectedErr error
+	}{
+		{
+			name:       "end of day",
+			recurrence: domain.RecurrenceEndOfDay,
+			from:       time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC),
+			loc:        time.UTC,
+			expected:   time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "end of week from a wednesday",
+			recurrence: domain.RecurrenceEndOfWeek,
+			from:       time.Date(2024, 3, 13, 9, 0, 0, 0, time.UTC),
+			loc:        time.UTC,
+			expected:   time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "end of week from a sunday",
+			recurrence: domain.RecurrenceEndOfWeek,
+			from:       time.Date(2024, 3, 17, 23, 0, 0, 0, time.UTC),
+			loc:        time.UTC,
+			expected:   time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "end of week from a monday",
+			recurrence: domain.RecurrenceEndOfWeek,
+			from:       time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+			loc:        time.UTC,
+			expected:   time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "end of month in leap february",
+			recurrence: domain.RecurrenceEndOfMonth,
+			from:       time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+			loc:        time.UTC,
+			expected:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "end of month in december",
+			recurrence: domain.RecurrenceEndOfMonth,
+			from:       time.Date(2024, 12, 5, 0, 0, 0, 0, time.UTC),
+			loc:        time.UTC,
+			expected:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "end of month evaluated in the bill time zone",
+			recurrence: domain.RecurrenceEndOfMonth,
+			// 22:00 UTC on March 31st is already April 1st in Tbilisi (UTC+4).
+			from:     time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC),
+			loc:      tbilisi,
+			expected: time.Date(2024, 5, 1, 0, 0, 0, 0, tbilisi),
+		},
+		{
+			name:        "invalid recurrence",
+			recurrence:  domain.Recurrence("EVERY_FORTNIGHT"),
+			from:        time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+			loc:         time.UTC,
+			expectedErr: domain.ErrInvalidRecurrence,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			end, err := domain.PeriodEnd(tc.recurrence, tc.from, tc.loc)
+			assert.Equal(t, tc.expectedErr, err)
+			assert.True(t, tc.expected.Equal(end), "expected %s, got %s", tc.expected, end)
+		})
+	}
+}
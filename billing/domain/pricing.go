@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// PriceModel represents how a Price is evaluated for a quantity.
+type PriceModel string
+
+const (
+	// PriceModelPerUnit prices every unit at the same unit price.
+	PriceModelPerUnit PriceModel = "PER_UNIT"
+	// PriceModelGraduated prices the units within each tier at the price of
+	// that tier, e.g. the first 1000 units at one price and the rest at another.
+	PriceModelGraduated PriceModel = "GRADUATED"
+	// PriceModelVolume prices all units at the price of the tier the
+	// quantity reaches.
+	PriceModelVolume PriceModel = "VOLUME"
+)
+
+// Price represents a price definition, evaluated for the quantity of a line
+// item when the item is built. PER_UNIT prices charge UnitPrice per unit.
+// Tiered prices, GRADUATED or VOLUME, charge the unit price of their Tiers per
+// PerUnits units (1 by default), so that prices below the smallest currency
+// unit, e.g. 0.15 cents per API call, can be expressed as 15 per 100 units.
+// The amount of every tier is rounded half up to the smallest currency unit.
+type Price struct {
+	Model     PriceModel  `json:"model"`
+	UnitPrice int64       `json:"unitPrice"`
+	PerUnits  int64       `json:"perUnits"`
+	Tiers     []PriceTier `json:"tiers"`
+}
+
+// PriceTier represents a tier of a tiered Price, covering the units up to and
+// including UpTo, or all remaining units when UpTo is zero. FlatPrice is
+// charged once, on top of the unit price, when the tier is reached.
+type PriceTier struct {
+	UpTo      int64 `json:"upTo"`
+	UnitPrice int64 `json:"unitPrice"`
+	FlatPrice int64 `json:"flatPrice"`
+}
+
+// ItemTier represents the units of a line item priced by a tier of its price,
+// from FirstUnit to LastUnit. Tier is the 1-based position of the tier.
+// Amount is the price of the units plus the flat price of the tier.
+type ItemTier struct {
+	Tier      int   `json:"tier"`
+	FirstUnit int64 `json:"firstUnit"`
+	LastUnit  int64 `json:"lastUnit"`
+	Quantity  int64 `json:"quantity"`
+	UnitPrice int64 `json:"unitPrice"`
+	PerUnits  int64 `json:"perUnits"`
+	FlatPrice int64 `json:"flatPrice"`
+	Amount    int64 `json:"amount"`
+}
+
+// IsTiered returns true if the price is evaluated by tiers.
+func (p Price) IsTiered() bool {
+	return p.Model == PriceModelGraduated || p.Model == PriceModelVolume
+}
+
+// Validate returns ErrInvalidPrice when the price cannot be evaluated: prices
+// must not be negative, and tiers must have increasing bounds, the last tier
+// being unbounded.
+func (p Price) Validate() error {
+	switch p.Model {
+	case PriceModelPerUnit:
+		if p.UnitPrice < 0 {
+			return fmt.Errorf("%w: unit price must not be negative", ErrInvalidPrice)
+		}
+		if len(p.Tiers) > 0 {
+			return fmt.Errorf("%w: per unit prices have no tiers", ErrInvalidPrice)
+		}
+		return nil
+	case PriceModelGraduated, PriceModelVolume:
+	default:
+		return fmt.Errorf("%w: unknown price model %q", ErrInvalidPrice, p.Model)
+	}
+
+	if p.PerUnits < 0 {
+		return fmt.Errorf("%w: per units must not be negative", ErrInvalidPrice)
+	}
+	if len(p.Tiers) == 0 {
+		return fmt.Errorf("%w: tiered prices need at least one tier", ErrInvalidPrice)
+	}
+
+	var previous int64
+	for i, tier := range p.Tiers {
+		if tier.UnitPrice < 0 || tier.FlatPrice < 0 {
+			return fmt.Errorf("%w: tier %d prices must not be negative", ErrInvalidPrice, i+1)
+		}
+
+		last := i == len(p.Tiers)-1
+		switch {
+		case last && tier.UpTo != 0:
+			return fmt.Errorf("%w: the last tier must be unbounded", ErrInvalidPrice)
+		case !last && tier.UpTo <= previous:
+			return fmt.Errorf("%w: tier %d must end after unit %d", ErrInvalidPrice, i+1, previous)
+		}
+		previous = tier.UpTo
+	}
+
+	return nil
+}
+
+// Evaluate returns the amount of quantity units at the price, in the smallest
+// currency unit, and the breakdown of the amount by tier for tiered prices.
+// It returns ErrAmountOverflow when the amount does not fit into an int64.
+func (p Price) Evaluate(quantity int64) (int64, []ItemTier, error) {
+	if err := p.Validate(); err != nil {
+		return 0, nil, err
+	}
+	if quantity < 0 {
+		return 0, nil, fmt.Errorf("%w: quantity must not be negative", ErrInvalidPrice)
+	}
+
+	switch p.Model {
+	case PriceModelGraduated:
+		return p.evaluateGraduated(quantity)
+	case PriceModelVolume:
+		return p.evaluateVolume(quantity)
+	default:
+		amount, err := LineAmount(quantity, p.UnitPrice)
+		return amount, nil, err
+	}
+}
+
+// evaluateGraduated prices the units of every tier reached by quantity at
+// the price of their tier.
+func (p Price) evaluateGraduated(quantity int64) (int64, []ItemTier, error) {
+	var total int64
+	var tiers []ItemTier
+
+	var previous int64
+	for i, tier := range p.Tiers {
+		if quantity <= previous {
+			break
+		}
+
+		last := quantity
+		if tier.UpTo != 0 && tier.UpTo < quantity {
+			last = tier.UpTo
+		}
+
+		line, err := p.itemTier(i, tier, previous+1, last)
+		if err != nil {
+			return 0, nil, err
+		}
+		if total, err = addAmounts(total, line.Amount); err != nil {
+			return 0, nil, err
+		}
+
+		tiers = append(tiers, line)
+		previous = last
+	}
+
+	return total, tiers, nil
+}
+
+// evaluateVolume prices all units at the price of the tier reached by quantity.
+func (p Price) evaluateVolume(quantity int64) (int64, []ItemTier, error) {
+	if quantity == 0 {
+		return 0, nil, nil
+	}
+
+	for i, tier := range p.Tiers {
+		if tier.UpTo != 0 && quantity > tier.UpTo {
+			continue
+		}
+
+		line, err := p.itemTier(i, tier, 1, quantity)
+		if err != nil {
+			return 0, nil, err
+		}
+		return line.Amount, []ItemTier{line}, nil
+	}
+
+	// unreachable for a valid price, whose last tier is unbounded
+	return 0, nil, ErrInvalidPrice
+}
+
+// itemTier prices the units from first to last at the price of the tier at index i.
+func (p Price) itemTier(i int, tier PriceTier, first, last int64) (ItemTier, error) {
+	perUnits := p.PerUnits
+	if perUnits <= 0 {
+		perUnits = 1
+	}
+
+	quantity := last - first + 1
+	amount := mulDivRoundBig(quantity, tier.UnitPrice, perUnits)
+	if !amount.IsInt64() {
+		return ItemTier{}, ErrAmountOverflow
+	}
+
+	total, err := addAmounts(amount.Int64(), tier.FlatPrice)
+	if err != nil {
+		return ItemTier{}, err
+	}
+
+	return ItemTier{
+		Tier:      i + 1,
+		FirstUnit: first,
+		LastUnit:  last,
+		Quantity:  quantity,
+		UnitPrice: tier.UnitPrice,
+		PerUnits:  perUnits,
+		FlatPrice: tier.FlatPrice,
+		Amount:    total,
+	}, nil
+}
+
+// addAmounts adds two non-negative amounts and returns ErrAmountOverflow when
+// the sum does not fit into an int64.
+func addAmounts(a, b int64) (int64, error) {
+	if a > math.MaxInt64-b {
+		return 0, ErrAmountOverflow
+	}
+	return a + b, nil
+}
@@ -0,0 +1,378 @@
+package domain_test
+
+import (
+	"math"
+	"testing"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// graduatedAPICalls prices API calls per 100 calls: the first 1000 calls are
+// free, the next 4000 cost 0.15 per 100 and the rest 0.10 per 100.
+var graduatedAPICalls = domain.Price{
+	Model:    domain.PriceModelGraduated,
+	PerUnits: 100,
+	Tiers: []domain.PriceTier{
+		{UpTo: 1000, UnitPrice: 0},
+		{UpTo: 5000, UnitPrice: 15},
+		{UnitPrice: 10},
+	},
+}
+
+// volumeSeats prices all seats at 0.20, 0.15 or 0.10 depending on the number
+// of seats, with a flat fee above 1000 seats.
+var volumeSeats = domain.Price{
+	Model: domain.PriceModelVolume,
+	Tiers: []domain.PriceTier{
+		{UpTo: 100, UnitPrice: 20},
+		{UpTo: 1000, UnitPrice: 15},
+		{UnitPrice: 10, FlatPrice: 500},
+	},
+}
+
+func TestPrice_Evaluate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		price         domain.Price
+		quantity      int64
+		expected      int64
+		expectedTiers []domain.ItemTier
+	}{
+		{
+			name:     "per unit price",
+			price:    domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 25},
+			quantity: 4,
+			expected: 100,
+		},
+		{
+			name:     "graduated price without usage",
+			price:    graduatedAPICalls,
+			quantity: 0,
+			expected: 0,
+		},
+		{
+			name:     "graduated price within the first tier",
+			price:    graduatedAPICalls,
+			quantity: 1000,
+			expected: 0,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1000, Quantity: 1000, UnitPrice: 0, PerUnits: 100, Amount: 0},
+			},
+		},
+		{
+			name:     "graduated price rounds a fraction below half down",
+			price:    graduatedAPICalls,
+			quantity: 1049,
+			expected: 7,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1000, Quantity: 1000, UnitPrice: 0, PerUnits: 100, Amount: 0},
+				{Tier: 2, FirstUnit: 1001, LastUnit: 1049, Quantity: 49, UnitPrice: 15, PerUnits: 100, Amount: 7},
+			},
+		},
+		{
+			name:     "graduated price rounds half up",
+			price:    graduatedAPICalls,
+			quantity: 1050,
+			expected: 8,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1000, Quantity: 1000, UnitPrice: 0, PerUnits: 100, Amount: 0},
+				{Tier: 2, FirstUnit: 1001, LastUnit: 1050, Quantity: 50, UnitPrice: 15, PerUnits: 100, Amount: 8},
+			},
+		},
+		{
+			name:     "graduated price at a tier boundary",
+			price:    graduatedAPICalls,
+			quantity: 5000,
+			expected: 600,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1000, Quantity: 1000, UnitPrice: 0, PerUnits: 100, Amount: 0},
+				{Tier: 2, FirstUnit: 1001, LastUnit: 5000, Quantity: 4000, UnitPrice: 15, PerUnits: 100, Amount: 600},
+			},
+		},
+		{
+			name:     "graduated price across all tiers",
+			price:    graduatedAPICalls,
+			quantity: 7333,
+			expected: 600 + 233,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1000, Quantity: 1000, UnitPrice: 0, PerUnits: 100, Amount: 0},
+				{Tier: 2, FirstUnit: 1001, LastUnit: 5000, Quantity: 4000, UnitPrice: 15, PerUnits: 100, Amount: 600},
+				{Tier: 3, FirstUnit: 5001, LastUnit: 7333, Quantity: 2333, UnitPrice: 10, PerUnits: 100, Amount: 233},
+			},
+		},
+		{
+			name: "graduated price rounds every tier on its own",
+			price: domain.Price{
+				Model:    domain.PriceModelGraduated,
+				PerUnits: 2,
+				Tiers:    []domain.PriceTier{{UpTo: 1, UnitPrice: 1}, {UnitPrice: 1}},
+			},
+			quantity: 2,
+			expected: 2,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1, Quantity: 1, UnitPrice: 1, PerUnits: 2, Amount: 1},
+				{Tier: 2, FirstUnit: 2, LastUnit: 2, Quantity: 1, UnitPrice: 1, PerUnits: 2, Amount: 1},
+			},
+		},
+		{
+			name: "graduated price charges the flat price of every tier reached",
+			price: domain.Price{
+				Model: domain.PriceModelGraduated,
+				Tiers: []domain.PriceTier{
+					{UpTo: 10, UnitPrice: 100, FlatPrice: 500},
+					{UnitPrice: 50, FlatPrice: 1000},
+				},
+			},
+			quantity: 11,
+			expected: 1500 + 1050,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 10, Quantity: 10, UnitPrice: 100, PerUnits: 1, FlatPrice: 500, Amount: 1500},
+				{Tier: 2, FirstUnit: 11, LastUnit: 11, Quantity: 1, UnitPrice: 50, PerUnits: 1, FlatPrice: 1000, Amount: 1050},
+			},
+		},
+		{
+			name:     "volume price without usage",
+			price:    volumeSeats,
+			quantity: 0,
+			expected: 0,
+		},
+		{
+			name:     "volume price at the end of the first tier",
+			price:    volumeSeats,
+			quantity: 100,
+			expected: 2000,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 100, Quantity: 100, UnitPrice: 20, PerUnits: 1, Amount: 2000},
+			},
+		},
+		{
+			name:     "volume price prices all units at the tier reached",
+			price:    volumeSeats,
+			quantity: 101,
+			expected: 1515,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 2, FirstUnit: 1, LastUnit: 101, Quantity: 101, UnitPrice: 15, PerUnits: 1, Amount: 1515},
+			},
+		},
+		{
+			name:     "volume price with the flat price of the last tier",
+			price:    volumeSeats,
+			quantity: 1001,
+			expected: 10510,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 3, FirstUnit: 1, LastUnit: 1001, Quantity: 1001, UnitPrice: 10, PerUnits: 1, FlatPrice: 500, Amount: 10510},
+			},
+		},
+		{
+			name: "volume price rounds half up",
+			price: domain.Price{
+				Model:    domain.PriceModelVolume,
+				PerUnits: 1000,
+				Tiers:    []domain.PriceTier{{UpTo: 1000000, UnitPrice: 3}, {UnitPrice: 2}},
+			},
+			quantity: 1500,
+			expected: 5,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1500, Quantity: 1500, UnitPrice: 3, PerUnits: 1000, Amount: 5},
+			},
+		},
+		{
+			name: "volume price rounds a fraction below half down",
+			price: domain.Price{
+				Model:    domain.PriceModelVolume,
+				PerUnits: 1000,
+				Tiers:    []domain.PriceTier{{UpTo: 1000000, UnitPrice: 3}, {UnitPrice: 2}},
+			},
+			quantity: 1499,
+			expected: 4,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1499, Quantity: 1499, UnitPrice: 3, PerUnits: 1000, Amount: 4},
+			},
+		},
+		{
+			name: "large quantities do not overflow before dividing",
+			price: domain.Price{
+				Model:    domain.PriceModelVolume,
+				PerUnits: 4,
+				Tiers:    []domain.PriceTier{{UnitPrice: 2}},
+			},
+			quantity: math.MaxInt64,
+			expected: 4611686018427387904,
+			expectedTiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: math.MaxInt64, Quantity: math.MaxInt64, UnitPrice: 2, PerUnits: 4, Amount: 4611686018427387904},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			amount, tiers, err := tc.price.Evaluate(tc.quantity)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, amount)
+			assert.Equal(t, tc.expectedTiers, tiers)
+		})
+	}
+}
+
+func TestPrice_EvaluateErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		price       domain.Price
+		quantity    int64
+		expectedErr error
+	}{
+		{
+			name:        "negative quantity",
+			price:       graduatedAPICalls,
+			quantity:    -1,
+			expectedErr: domain.ErrInvalidPrice,
+		},
+		{
+			name:        "per unit amount overflow",
+			price:       domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 2},
+			quantity:    math.MaxInt64,
+			expectedErr: domain.ErrAmountOverflow,
+		},
+		{
+			name: "tier amount overflow",
+			price: domain.Price{
+				Model: domain.PriceModelGraduated,
+				Tiers: []domain.PriceTier{{UnitPrice: math.MaxInt64}},
+			},
+			quantity:    2,
+			expectedErr: domain.ErrAmountOverflow,
+		},
+		{
+			name: "tier flat price overflow",
+			price: domain.Price{
+				Model: domain.PriceModelVolume,
+				Tiers: []domain.PriceTier{{UnitPrice: 1, FlatPrice: math.MaxInt64}},
+			},
+			quantity:    1,
+			expectedErr: domain.ErrAmountOverflow,
+		},
+		{
+			name: "total of the tiers overflow",
+			price: domain.Price{
+				Model: domain.PriceModelGraduated,
+				Tiers: []domain.PriceTier{{UpTo: 1, UnitPrice: math.MaxInt64}, {UnitPrice: 1}},
+			},
+			quantity:    2,
+			expectedErr: domain.ErrAmountOverflow,
+		},
+		{
+			name:        "invalid price",
+			price:       domain.Price{Model: domain.PriceModelVolume},
+			quantity:    1,
+			expectedErr: domain.ErrInvalidPrice,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := tc.price.Evaluate(tc.quantity)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestPrice_Validate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		price           domain.Price
+		expectedMessage string
+	}{
+		{
+			name:  "per unit price",
+			price: domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 1},
+		},
+		{
+			name:  "graduated price",
+			price: graduatedAPICalls,
+		},
+		{
+			name:  "volume price",
+			price: volumeSeats,
+		},
+		{
+			name:            "unknown model",
+			price:           domain.Price{UnitPrice: 1},
+			expectedMessage: `invalid price: unknown price model ""`,
+		},
+		{
+			name:            "negative unit price",
+			price:           domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: -1},
+			expectedMessage: "invalid price: unit price must not be negative",
+		},
+		{
+			name: "per unit price with tiers",
+			price: domain.Price{
+				Model: domain.PriceModelPerUnit,
+				Tiers: []domain.PriceTier{{UnitPrice: 1}},
+			},
+			expectedMessage: "invalid price: per unit prices have no tiers",
+		},
+		{
+			name:            "tiered price without tiers",
+			price:           domain.Price{Model: domain.PriceModelGraduated},
+			expectedMessage: "invalid price: tiered prices need at least one tier",
+		},
+		{
+			name: "negative per units",
+			price: domain.Price{
+				Model:    domain.PriceModelVolume,
+				PerUnits: -100,
+				Tiers:    []domain.PriceTier{{UnitPrice: 1}},
+			},
+			expectedMessage: "invalid price: per units must not be negative",
+		},
+		{
+			name: "negative tier price",
+			price: domain.Price{
+				Model: domain.PriceModelGraduated,
+				Tiers: []domain.PriceTier{{UpTo: 10, UnitPrice: 1}, {UnitPrice: 1, FlatPrice: -1}},
+			},
+			expectedMessage: "invalid price: tier 2 prices must not be negative",
+		},
+		{
+			name: "bounded last tier",
+			price: domain.Price{
+				Model: domain.PriceModelGraduated,
+				Tiers: []domain.PriceTier{{UpTo: 10, UnitPrice: 2}, {UpTo: 100, UnitPrice: 1}},
+			},
+			expectedMessage: "invalid price: the last tier must be unbounded",
+		},
+		{
+			name: "tier bounds not increasing",
+			price: domain.Price{
+				Model: domain.PriceModelVolume,
+				Tiers: []domain.PriceTier{{UpTo: 100, UnitPrice: 2}, {UpTo: 100, UnitPrice: 1}, {UnitPrice: 1}},
+			},
+			expectedMessage: "invalid price: tier 2 must end after unit 100",
+		},
+		{
+			name: "unbounded tier before the last one",
+			price: domain.Price{
+				Model: domain.PriceModelVolume,
+				Tiers: []domain.PriceTier{{UnitPrice: 2}, {UnitPrice: 1}},
+			},
+			expectedMessage: "invalid price: tier 1 must end after unit 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.price.Validate()
+
+			if tc.expectedMessage == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, domain.ErrInvalidPrice)
+			assert.EqualError(t, err, tc.expectedMessage)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProrationPrecision represents the unit in which the used and unused parts
+// of a billing period are measured when a change is prorated.
+type ProrationPrecision string
+
+const (
+	// ProrationPrecisionDay measures periods in calendar days, the day of the
+	// change counting as remaining.
+	ProrationPrecisionDay ProrationPrecision = "DAY"
+	// ProrationPrecisionSecond measures periods in seconds.
+	ProrationPrecisionSecond ProrationPrecision = "SECOND"
+)
+
+// Proration represents the part of a billing period remaining after a
+// change, as Remaining out of Total days or seconds.
+type Proration struct {
+	Precision   ProrationPrecision `json:"precision"`
+	PeriodStart time.Time          `json:"periodStart"`
+	PeriodEnd   time.Time          `json:"periodEnd"`
+	ChangedAt   time.Time          `json:"changedAt"`
+	Remaining   int64              `json:"remaining"`
+	Total       int64              `json:"total"`
+}
+
+// NewProration returns the proration of a change at changedAt within the
+// billing period from periodStart to periodEnd, exclusive. Days are counted
+// in loc. A change before the period prorates the whole period and a change
+// after it prorates nothing.
+func NewProration(periodStart, periodEnd, changedAt time.Time, precision ProrationPrecision, loc *time.Location) (Proration, error) {
+	if !periodEnd.After(periodStart) {
+		return Proration{}, fmt.Errorf("%w: period must end after it starts", ErrInvalidProration)
+	}
+
+	if changedAt.Before(periodStart) {
+		changedAt = periodStart
+	}
+	if changedAt.After(periodEnd) {
+		changedAt = periodEnd
+	}
+
+	p := Proration{
+		Precision:   precision,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		ChangedAt:   changedAt,
+	}
+
+	switch precision {
+	case ProrationPrecisionDay:
+		p.Total = daysBetween(periodStart, periodEnd, loc)
+		p.Remaining = daysBetween(changedAt, periodEnd, loc)
+	case ProrationPrecisionSecond:
+		p.Total = int64(periodEnd.Sub(periodStart) / time.Second)
+		p.Remaining = int64(periodEnd.Sub(changedAt) / time.Second)
+	default:
+		return Proration{}, fmt.Errorf("%w: unknown precision %q", ErrInvalidProration, precision)
+	}
+
+	return p, nil
+}
+
+// Amount returns the part of amount for the remaining part of the period,
+// rounded half up to the smallest currency unit. Periods shorter than the
+// precision, e.g. less than a day, prorate nothing.
+func (p Proration) Amount(amount int64) int64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return mulDivRound(amount, p.Remaining, p.Total)
+}
+
+// daysBetween returns the number of calendar days in loc from the day of from
+// to the day of to. A period ending at midnight does not include the day it
+// ends on.
+func daysBetween(from, to time.Time, loc *time.Location) int64 {
+	fromDate := civilDate(from.In(loc))
+	toDate := civilDate(to.In(loc))
+	return int64(toDate.Sub(fromDate) / (24 * time.Hour))
+}
+
+// civilDate returns the date of t at midnight UTC, so that days can be
+// counted regardless of daylight saving time changes.
+func civilDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
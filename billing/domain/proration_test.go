@@ -0,0 +1,146 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProration(t *testing.T) {
+	tbilisi, err := time.LoadLocation("Asia/Tbilisi")
+	assert.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	periodStart := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name              string
+		periodStart       time.Time
+		periodEnd         time.Time
+		changedAt         time.Time
+		precision         domain.ProrationPrecision
+		loc               *time.Location
+		expectedRemaining int64
+		expectedTotal     int64
+		expectedChangedAt time.Time
+	}{
+		{
+			name:              "by day, the day of the change counting as remaining",
+			periodStart:       periodStart,
+			periodEnd:         periodEnd,
+			changedAt:         time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC),
+			precision:         domain.ProrationPrecisionDay,
+			loc:               time.UTC,
+			expectedRemaining: 8,
+			expectedTotal:     17,
+			expectedChangedAt: time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:              "by second",
+			periodStart:       periodStart,
+			periodEnd:         periodEnd,
+			changedAt:         time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC),
+			precision:         domain.ProrationPrecisionSecond,
+			loc:               time.UTC,
+			expectedRemaining: (7*24 + 12) * 3600,
+			expectedTotal:     (16*24 + 14) * 3600,
+			expectedChangedAt: time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:              "by day in the timezone of the period",
+			periodStart:       periodStart,
+			periodEnd:         time.Date(2025, 2, 1, 0, 0, 0, 0, tbilisi),
+			changedAt:         time.Date(2025, 1, 23, 22, 0, 0, 0, time.UTC),
+			precision:         domain.ProrationPrecisionDay,
+			loc:               tbilisi,
+			expectedRemaining: 8,
+			expectedTotal:     17,
+			expectedChangedAt: time.Date(2025, 1, 23, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:              "by day across a daylight saving time change",
+			periodStart:       time.Date(2025, 3, 1, 0, 0, 0, 0, newYork),
+			periodEnd:         time.Date(2025, 4, 1, 0, 0, 0, 0, newYork),
+			changedAt:         time.Date(2025, 3, 10, 12, 0, 0, 0, newYork),
+			precision:         domain.ProrationPrecisionDay,
+			loc:               newYork,
+			expectedRemaining: 22,
+			expectedTotal:     31,
+			expectedChangedAt: time.Date(2025, 3, 10, 12, 0, 0, 0, newYork),
+		},
+		{
+			name:              "change before the period prorates the whole period",
+			periodStart:       periodStart,
+			periodEnd:         periodEnd,
+			changedAt:         periodStart.Add(-time.Hour),
+			precision:         domain.ProrationPrecisionSecond,
+			loc:               time.UTC,
+			expectedRemaining: (16*24 + 14) * 3600,
+			expectedTotal:     (16*24 + 14) * 3600,
+			expectedChangedAt: periodStart,
+		},
+		{
+			name:              "change after the period prorates nothing",
+			periodStart:       periodStart,
+			periodEnd:         periodEnd,
+			changedAt:         periodEnd.Add(time.Hour),
+			precision:         domain.ProrationPrecisionDay,
+			loc:               time.UTC,
+			expectedRemaining: 0,
+			expectedTotal:     17,
+			expectedChangedAt: periodEnd,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proration, err := domain.NewProration(tc.periodStart, tc.periodEnd, tc.changedAt, tc.precision, tc.loc)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.precision, proration.Precision)
+			assert.Equal(t, tc.expectedRemaining, proration.Remaining)
+			assert.Equal(t, tc.expectedTotal, proration.Total)
+			assert.True(t, tc.expectedChangedAt.Equal(proration.ChangedAt))
+		})
+	}
+}
+
+func TestNewProrationErrors(t *testing.T) {
+	periodStart := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	_, err := domain.NewProration(periodStart, periodStart, periodStart, domain.ProrationPrecisionDay, time.UTC)
+	assert.ErrorIs(t, err, domain.ErrInvalidProration)
+
+	_, err = domain.NewProration(periodStart, periodStart.Add(time.Hour), periodStart, "HOUR", time.UTC)
+	assert.ErrorIs(t, err, domain.ErrInvalidProration)
+}
+
+func TestProration_Amount(t *testing.T) {
+	byDay := domain.Proration{Precision: domain.ProrationPrecisionDay, Remaining: 8, Total: 17}
+	assert.Equal(t, int64(2306), byDay.Amount(4900))
+
+	bySecond := domain.Proration{Precision: domain.ProrationPrecisionSecond, Remaining: (7*24 + 12) * 3600, Total: (16*24 + 14) * 3600}
+	assert.Equal(t, int64(2216), bySecond.Amount(4900))
+
+	quarter := domain.Proration{Remaining: 1, Total: 4}
+	assert.Equal(t, int64(1), quarter.Amount(2), "half a unit rounds up")
+	assert.Equal(t, int64(0), quarter.Amount(1), "a quarter of a unit rounds down")
+
+	assert.Equal(t, int64(4900), domain.Proration{Remaining: 17, Total: 17}.Amount(4900))
+	assert.Equal(t, int64(0), domain.Proration{Remaining: 0, Total: 17}.Amount(4900))
+
+	// periods shorter than a day prorate nothing by day
+	shortPeriod, err := domain.NewProration(
+		time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC),
+		domain.ProrationPrecisionDay,
+		time.UTC,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), shortPeriod.Amount(4900))
+}
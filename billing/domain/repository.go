@@ -2,21 +2,84 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 // Repository defines the interface for all data operations
-// Consolidated for simplicity - handles bills, items, and exchanges
+// Consolidated for simplicity - handles accounts, wallets, subscriptions, catalog, coupons, bills, items, usage, discounts, taxes, payments, credit notes, and exchanges
 type Repository interface {
+	// Account operations
+	SaveAccount(ctx context.Context, account *Account) error
+	UpdateAccount(ctx context.Context, account *Account) error
+	GetAccount(ctx context.Context, accountID string) (Account, error)
+	DeleteAccount(ctx context.Context, accountID string) error
+
+	// Wallet operations
+	TopUpWallet(ctx context.Context, entry *WalletEntry) error
+	GetWallet(ctx context.Context, accountID string) (Wallet, error)
+	ApplyWalletCredit(ctx context.Context, bill Bill, appliedAt time.Time) (int64, error)
+
+	// Subscription operations
+	SaveSubscription(ctx context.Context, subscription *Subscription) error
+	GetSubscription(ctx context.Context, subscriptionID string) (Subscription, error)
+
+	// Catalog operations
+	SaveProduct(ctx context.Context, product *Product) error
+	UpdateProduct(ctx context.Context, product *Product) error
+	GetProduct(ctx context.Context, productID string) (Product, error)
+	GetProducts(ctx context.Context) ([]Product, error)
+	ArchiveProduct(ctx context.Context, productID string, archivedAt time.Time) error
+	SaveSKU(ctx context.Context, sku *SKU) error
+	UpdateSKU(ctx context.Context, sku *SKU) error
+	GetSKU(ctx context.Context, code string) (SKU, error)
+	GetSKUVersion(ctx context.Context, code string, version int64) (SKU, error)
+	ArchiveSKU(ctx context.Context, code string, archivedAt time.Time) error
+
+	// Coupon operations
+	SaveCoupon(ctx context.Context, coupon *Coupon) error
+	GetCoupon(ctx context.Context, code string) (Coupon, error)
+	CountCouponRedemptions(ctx context.Context, code string, accountID string) (int64, int64, error)
+	ReserveCoupons(ctx context.Context, bill Bill, reservedAt time.Time) ([]string, error)
+
 	// Bill operations
 	GetBill(ctx context.Context, billingID string) (Bill, error)
+	GetBillsByAccountID(ctx context.Context, accountID string, metadata Metadata) ([]Bill, error)
 	SaveBill(ctx context.Context, bill *Bill) error
-	CloseBilling(ctx context.Context, billing Bill) error
+	MarkBillingClosing(ctx context.Context, billingID string) error
+	CloseBilling(ctx context.Context, billing *Bill, numbering InvoiceNumbering) error
 	RevertBillClosing(ctx context.Context, billingID string) error
+	ReopenBill(ctx context.Context, entry AuditEntry) error
+	VoidBilling(ctx context.Context, entry AuditEntry) error
+	ExpireBilling(ctx context.Context, entry AuditEntry) error
+	RemindBilling(ctx context.Context, entry AuditEntry) error
+	MarkBillingOverdue(ctx context.Context, entry AuditEntry) error
+	GetAuditEntriesByBillID(ctx context.Context, billID string) ([]AuditEntry, error)
 
 	// Item operations
 	SaveItem(ctx context.Context, item *Item) error
+	VoidItem(ctx context.Context, item Item) error
 	GetItemsByBillID(ctx context.Context, billID string) ([]Item, error)
 
+	// Usage operations
+	SaveUsageEvents(ctx context.Context, billingID string, events []UsageEvent) (int64, error)
+	GetUsageTotalsByBillID(ctx context.Context, billID string) ([]UsageTotal, error)
+
+	// Discount operations
+	SaveDiscount(ctx context.Context, discount *Discount) error
+	GetDiscountsByBillID(ctx context.Context, billID string) ([]Discount, error)
+
+	// Tax operations
+	SaveTaxLines(ctx context.Context, bill *Bill) error
+	GetTaxLinesByBillID(ctx context.Context, billID string) ([]TaxLine, error)
+
+	// Payment operations
+	SavePayments(ctx context.Context, bill *Bill) error
+	GetPaymentsByBillID(ctx context.Context, billID string) ([]Payment, error)
+
+	// Credit note operations
+	SaveCreditNotes(ctx context.Context, bill *Bill) error
+	GetCreditNotesByBillID(ctx context.Context, billID string) ([]CreditNote, error)
+
 	// Exchange operations
 	SaveExchange(ctx context.Context, bill *Bill) error
 	GetExchangeByBillID(ctx context.Context, billID string) (BillExchange, error)
@@ -0,0 +1,258 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Subscription represents a recurring plan of an account. Every cycle, a
+// bill is opened with the plan items and closed at the end of the period of
+// its Recurrence, evaluated in Timezone.
+type Subscription struct {
+	ID               int64              `json:"id"`
+	SubscriptionID   string             `json:"subscriptionId"`
+	AccountID        string             `json:"accountId"`
+	Status           SubscriptionStatus `json:"status"`
+	Currency         Currency           `json:"currency"`
+	Region           string             `json:"region"`
+	Recurrence       Recurrence         `json:"recurrence"`
+	Timezone         string             `json:"timezone"`
+	Items            []PlanItem         `json:"items"`
+	Cycle            int64              `json:"cycle"`
+	CurrentBillingID string             `json:"currentBillingId"`
+	CurrentPeriodEnd *time.Time         `json:"currentPeriodEnd"`
+	CreatedAt        time.Time          `json:"createdAt"`
+	PausedAt         *time.Time         `json:"pausedAt"`
+	CancelledAt      *time.Time         `json:"cancelledAt"`
+}
+
+// PlanItem represents an item billed every cycle of a subscription.
+type PlanItem struct {
+	Name      string `json:"name"`
+	Quantity  int64  `json:"quantity"`
+	Unit      string `json:"unit"`
+	UnitPrice int64  `json:"unitPrice"`
+}
+
+// SubscriptionStatus represents the possible states of a subscription.
+type SubscriptionStatus string
+
+const (
+	// SubscriptionStatusActive represents a subscription opening a bill every cycle.
+	SubscriptionStatusActive SubscriptionStatus = "ACTIVE"
+	// SubscriptionStatusPaused represents a subscription that opens no new
+	// bill until it is resumed.
+	SubscriptionStatusPaused SubscriptionStatus = "PAUSED"
+	// SubscriptionStatusCancelled represents a subscription that opens no new bill anymore.
+	SubscriptionStatusCancelled SubscriptionStatus = "CANCELLED"
+)
+
+// IsActive returns true if the subscription is active.
+func (s *Subscription) IsActive() bool {
+	return s.Status == SubscriptionStatusActive
+}
+
+// IsPaused returns true if the subscription is paused.
+func (s *Subscription) IsPaused() bool {
+	return s.Status == SubscriptionStatusPaused
+}
+
+// IsCancelled returns true if the subscription is cancelled.
+func (s *Subscription) IsCancelled() bool {
+	return s.Status == SubscriptionStatusCancelled
+}
+
+// Pause pauses an active subscription. The bill of the current cycle is
+// still closed at the end of its period.
+func (s *Subscription) Pause(pausedAt time.Time) error {
+	switch {
+	case s.IsCancelled():
+		return ErrSubscriptionCancelled
+	case s.IsPaused():
+		return ErrSubscriptionPaused
+	}
+
+	s.Status = SubscriptionStatusPaused
+	s.PausedAt = &pausedAt
+	return nil
+}
+
+// Resume resumes a paused subscription.
+func (s *Subscription) Resume() error {
+	switch {
+	case s.IsCancelled():
+		return ErrSubscriptionCancelled
+	case !s.IsPaused():
+		return ErrSubscriptionNotPaused
+	}
+
+	s.Status = SubscriptionStatusActive
+	s.PausedAt = nil
+	return nil
+}
+
+// Cancel cancels a subscription. The bill of the current cycle is still
+// closed at the end of its period.
+func (s *Subscription) Cancel(cancelledAt time.Time) error {
+	if s.IsCancelled() {
+		return ErrSubscriptionCancelled
+	}
+
+	s.Status = SubscriptionStatusCancelled
+	s.CancelledAt = &cancelledAt
+	return nil
+}
+
+// ChangePlan replaces the plan items of the subscription, billed in full from
+// the next cycle on, and returns the items prorating the change on bill, the
+// open bill of the current cycle, if any: a credit for the unused part of the
+// period on the current plan and a charge for its remaining part on the new
+// plan, measured with the given precision from changedAt.
+func (s *Subscription) ChangePlan(items []PlanItem, bill *Bill, changedAt time.Time, precision ProrationPrecision) ([]Item, error) {
+	if s.IsCancelled() {
+		return nil, ErrSubscriptionCancelled
+	}
+
+	loc, err := s.location()
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustments []Item
+	if bill != nil && bill.PeriodEnd != nil {
+		proration, err := NewProration(bill.CreatedAt, *bill.PeriodEnd, changedAt, precision, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("%s-proration-%d", bill.BillingID, changedAt.UnixNano())
+		for i, planItem := range s.Items {
+			item, err := prorationItem(bill.BillingID, planItem, proration, true)
+			if err != nil {
+				return nil, err
+			}
+			if item.Price != 0 {
+				item.IdempotencyKey = fmt.Sprintf("%s-credit-%d", key, i)
+				adjustments = append(adjustments, item)
+			}
+		}
+		for i, planItem := range items {
+			item, err := prorationItem(bill.BillingID, planItem, proration, false)
+			if err != nil {
+				return nil, err
+			}
+			if item.Price != 0 {
+				item.IdempotencyKey = fmt.Sprintf("%s-charge-%d", key, i)
+				adjustments = append(adjustments, item)
+			}
+		}
+	}
+
+	s.Items = items
+	return adjustments, nil
+}
+
+// prorationItem returns the item crediting, or charging, the remaining part
+// of the period of a plan item. The item is a single unit priced at the
+// prorated line amount, negative for a credit.
+func prorationItem(billingID string, planItem PlanItem, proration Proration, credit bool) (Item, error) {
+	amount, err := LineAmount(max(planItem.Quantity, 1), planItem.UnitPrice)
+	if err != nil {
+		return Item{}, err
+	}
+	amount = proration.Amount(amount)
+
+	name := "Remaining time on " + planItem.Name
+	kind := "charge"
+	if credit {
+		name = "Unused time on " + planItem.Name
+		kind = "credit"
+		amount = -amount
+	}
+
+	return Item{
+		BillingID: billingID,
+		Name:      name,
+		Quantity:  1,
+		UnitPrice: amount,
+		Price:     amount,
+		Metadata: Metadata{
+			"proration":          kind,
+			"prorationPrecision": string(proration.Precision),
+			"prorationRemaining": fmt.Sprintf("%d/%d", proration.Remaining, proration.Total),
+			"prorationChangedAt": proration.ChangedAt.UTC().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// IsProrationCredit returns true if the item credits the unused time of a
+// plan item after a plan change, the only kind of item priced below zero.
+func (i Item) IsProrationCredit() bool {
+	return i.Price < 0 && i.Metadata["proration"] == "credit"
+}
+
+// location returns the location the periods of the subscription are evaluated in.
+func (s *Subscription) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load timezone %q: %w", s.Timezone, err)
+	}
+	return loc, nil
+}
+
+// StartCycle starts the next cycle of the subscription at start and returns
+// the bill of the cycle, with the plan items and the end of the period
+// containing start.
+func (s *Subscription) StartCycle(start time.Time) (Bill, error) {
+	loc, err := s.location()
+	if err != nil {
+		return Bill{}, err
+	}
+
+	periodEnd, err := PeriodEnd(s.Recurrence, start, loc)
+	if err != nil {
+		return Bill{}, err
+	}
+
+	bill := Bill{
+		BillingID:      fmt.Sprintf("%s-%d", s.SubscriptionID, s.Cycle+1),
+		AccountID:      s.AccountID,
+		SubscriptionID: s.SubscriptionID,
+		Status:         BillStatusOpen,
+		Currency:       s.Currency,
+		Region:         s.Region,
+		Items:          []Item{},
+		PeriodEnd:      &periodEnd,
+		CreatedAt:      start,
+	}
+
+	for i, planItem := range s.Items {
+		quantity := planItem.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		price, err := LineAmount(quantity, planItem.UnitPrice)
+		if err != nil {
+			return Bill{}, err
+		}
+
+		bill.AddItem(Item{
+			BillingID:      bill.BillingID,
+			Name:           planItem.Name,
+			Quantity:       quantity,
+			Unit:           planItem.Unit,
+			UnitPrice:      planItem.UnitPrice,
+			Price:          price,
+			IdempotencyKey: fmt.Sprintf("%s-plan-%d", bill.BillingID, i),
+		})
+	}
+
+	s.Cycle++
+	s.CurrentBillingID = bill.BillingID
+	s.CurrentPeriodEnd = &periodEnd
+	return bill, nil
+}
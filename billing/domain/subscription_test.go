@@ -0,0 +1,267 @@
+package domain_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscription_StatusTransitions(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		status         domain.SubscriptionStatus
+		change         func(s *domain.Subscription) error
+		expectedStatus domain.SubscriptionStatus
+		expectedErr    error
+	}{
+		{
+			name:           "pause active subscription",
+			status:         domain.SubscriptionStatusActive,
+			change:         func(s *domain.Subscription) error { return s.Pause(now) },
+			expectedStatus: domain.SubscriptionStatusPaused,
+		},
+		{
+			name:           "pause paused subscription",
+			status:         domain.SubscriptionStatusPaused,
+			change:         func(s *domain.Subscription) error { return s.Pause(now) },
+			expectedStatus: domain.SubscriptionStatusPaused,
+			expectedErr:    domain.ErrSubscriptionPaused,
+		},
+		{
+			name:           "pause cancelled subscription",
+			status:         domain.SubscriptionStatusCancelled,
+			change:         func(s *domain.Subscription) error { return s.Pause(now) },
+			expectedStatus: domain.SubscriptionStatusCancelled,
+			expectedErr:    domain.ErrSubscriptionCancelled,
+		},
+		{
+			name:           "resume paused subscription",
+			status:         domain.SubscriptionStatusPaused,
+			change:         func(s *domain.Subscription) error { return s.Resume() },
+			expectedStatus: domain.SubscriptionStatusActive,
+		},
+		{
+			name:           "resume active subscription",
+			status:         domain.SubscriptionStatusActive,
+			change:         func(s *domain.Subscription) error { return s.Resume() },
+			expectedStatus: domain.SubscriptionStatusActive,
+			expectedErr:    domain.ErrSubscriptionNotPaused,
+		},
+		{
+			name:           "resume cancelled subscription",
+			status:         domain.SubscriptionStatusCancelled,
+			change:         func(s *domain.Subscription) error { return s.Resume() },
+			expectedStatus: domain.SubscriptionStatusCancelled,
+			expectedErr:    domain.ErrSubscriptionCancelled,
+		},
+		{
+			name:           "cancel paused subscription",
+			status:         domain.SubscriptionStatusPaused,
+			change:         func(s *domain.Subscription) error { return s.Cancel(now) },
+			expectedStatus: domain.SubscriptionStatusCancelled,
+		},
+		{
+			name:           "cancel cancelled subscription",
+			status:         domain.SubscriptionStatusCancelled,
+			change:         func(s *domain.Subscription) error { return s.Cancel(now) },
+			expectedStatus: domain.SubscriptionStatusCancelled,
+			expectedErr:    domain.ErrSubscriptionCancelled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subscription := domain.Subscription{SubscriptionID: "Sub-1", Status: tc.status}
+
+			err := tc.change(&subscription)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expectedStatus, subscription.Status)
+		})
+	}
+}
+
+func TestSubscription_PauseAndResumeTrackPausedAt(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	subscription := domain.Subscription{SubscriptionID: "Sub-1", Status: domain.SubscriptionStatusActive}
+
+	assert.NoError(t, subscription.Pause(now))
+	assert.Equal(t, &now, subscription.PausedAt)
+
+	assert.NoError(t, subscription.Resume())
+	assert.Nil(t, subscription.PausedAt)
+
+	assert.NoError(t, subscription.Cancel(now))
+	assert.Equal(t, &now, subscription.CancelledAt)
+}
+
+func TestSubscription_StartCycle(t *testing.T) {
+	subscription := domain.Subscription{
+		SubscriptionID: "Sub-1",
+		AccountID:      "Acc-1",
+		Status:         domain.SubscriptionStatusActive,
+		Currency:       domain.CurrencyUSD,
+		Region:         "US-CA",
+		Recurrence:     domain.RecurrenceEndOfMonth,
+		Items: []domain.PlanItem{
+			{Name: "Pro plan", UnitPrice: 4900},
+			{Name: "Seats", Quantity: 3, Unit: "seat", UnitPrice: 1000},
+		},
+	}
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	bill, err := subscription.StartCycle(start)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Sub-1-1", bill.BillingID)
+	assert.Equal(t, "Acc-1", bill.AccountID)
+	assert.Equal(t, "Sub-1", bill.SubscriptionID)
+	assert.Equal(t, domain.BillStatusOpen, bill.Status)
+	assert.Equal(t, domain.CurrencyUSD, bill.Currency)
+	assert.Equal(t, "US-CA", bill.Region)
+	assert.Equal(t, &periodEnd, bill.PeriodEnd)
+	assert.Equal(t, start, bill.CreatedAt)
+	assert.Equal(t, []domain.Item{
+		{BillingID: "Sub-1-1", Name: "Pro plan", Quantity: 1, UnitPrice: 4900, Price: 4900, IdempotencyKey: "Sub-1-1-plan-0"},
+		{BillingID: "Sub-1-1", Name: "Seats", Quantity: 3, Unit: "seat", UnitPrice: 1000, Price: 3000, IdempotencyKey: "Sub-1-1-plan-1"},
+	}, bill.Items)
+	assert.Equal(t, int64(7900), bill.Total)
+
+	assert.Equal(t, int64(1), subscription.Cycle)
+	assert.Equal(t, "Sub-1-1", subscription.CurrentBillingID)
+	assert.Equal(t, &periodEnd, subscription.CurrentPeriodEnd)
+
+	next, err := subscription.StartCycle(periodEnd)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Sub-1-2", next.BillingID)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), *next.PeriodEnd)
+	assert.Equal(t, int64(2), subscription.Cycle)
+}
+
+func TestSubscription_StartCycleInTimezone(t *testing.T) {
+	tbilisi, err := time.LoadLocation("Asia/Tbilisi")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	subscription := domain.Subscription{
+		SubscriptionID: "Sub-1",
+		Recurrence:     domain.RecurrenceEndOfDay,
+		Timezone:       "Asia/Tbilisi",
+		Items:          []domain.PlanItem{{Name: "Daily pass", UnitPrice: 500}},
+	}
+
+	bill, err := subscription.StartCycle(time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2025, 1, 17, 0, 0, 0, 0, tbilisi).Equal(*bill.PeriodEnd))
+}
+
+func TestSubscription_StartCycleInvalidRecurrence(t *testing.T) {
+	subscription := domain.Subscription{SubscriptionID: "Sub-1", Recurrence: "HOURLY"}
+
+	_, err := subscription.StartCycle(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidRecurrence)
+	assert.Equal(t, int64(0), subscription.Cycle)
+}
+
+func TestSubscription_ChangePlan(t *testing.T) {
+	periodEnd := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	bill := &domain.Bill{
+		BillingID: "Sub-1-1",
+		PeriodEnd: &periodEnd,
+		CreatedAt: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+	changedAt := time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC)
+	newPlan := []domain.PlanItem{
+		{Name: "Business plan", UnitPrice: 9900},
+		{Name: "Seats", Quantity: 3, Unit: "seat", UnitPrice: 1000},
+	}
+
+	subscription := domain.Subscription{
+		SubscriptionID: "Sub-1",
+		Status:         domain.SubscriptionStatusActive,
+		Items:          []domain.PlanItem{{Name: "Pro plan", UnitPrice: 4900}},
+	}
+
+	items, err := subscription.ChangePlan(newPlan, bill, changedAt, domain.ProrationPrecisionDay)
+
+	assert.NoError(t, err)
+	assert.Equal(t, newPlan, subscription.Items)
+
+	key := fmt.Sprintf("Sub-1-1-proration-%d", changedAt.UnixNano())
+	metadata := func(kind string) domain.Metadata {
+		return domain.Metadata{
+			"proration":          kind,
+			"prorationPrecision": "DAY",
+			"prorationRemaining": "8/17",
+			"prorationChangedAt": "2025-01-24T12:00:00Z",
+		}
+	}
+	assert.Equal(t, []domain.Item{
+		{BillingID: "Sub-1-1", Name: "Unused time on Pro plan", Quantity: 1, UnitPrice: -2306, Price: -2306, IdempotencyKey: key + "-credit-0", Metadata: metadata("credit")},
+		{BillingID: "Sub-1-1", Name: "Remaining time on Business plan", Quantity: 1, UnitPrice: 4659, Price: 4659, IdempotencyKey: key + "-charge-0", Metadata: metadata("charge")},
+		{BillingID: "Sub-1-1", Name: "Remaining time on Seats", Quantity: 1, UnitPrice: 1412, Price: 1412, IdempotencyKey: key + "-charge-1", Metadata: metadata("charge")},
+	}, items)
+	assert.True(t, items[0].IsProrationCredit())
+	assert.False(t, items[1].IsProrationCredit())
+}
+
+func TestSubscription_ChangePlanWithoutProration(t *testing.T) {
+	periodEnd := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	bill := &domain.Bill{BillingID: "Sub-1-1", PeriodEnd: &periodEnd, CreatedAt: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)}
+	newPlan := []domain.PlanItem{{Name: "Business plan", UnitPrice: 9900}}
+
+	testCases := []struct {
+		name        string
+		status      domain.SubscriptionStatus
+		bill        *domain.Bill
+		changedAt   time.Time
+		expectedErr error
+	}{
+		{
+			name:      "no open bill",
+			status:    domain.SubscriptionStatusPaused,
+			changedAt: time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "period already ended",
+			status:    domain.SubscriptionStatusActive,
+			bill:      bill,
+			changedAt: periodEnd.Add(time.Minute),
+		},
+		{
+			name:        "cancelled subscription",
+			status:      domain.SubscriptionStatusCancelled,
+			bill:        bill,
+			changedAt:   time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC),
+			expectedErr: domain.ErrSubscriptionCancelled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldPlan := []domain.PlanItem{{Name: "Pro plan", UnitPrice: 4900}}
+			subscription := domain.Subscription{SubscriptionID: "Sub-1", Status: tc.status, Items: oldPlan}
+
+			items, err := subscription.ChangePlan(newPlan, tc.bill, tc.changedAt, domain.ProrationPrecisionSecond)
+
+			assert.Empty(t, items)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Equal(t, oldPlan, subscription.Items)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, newPlan, subscription.Items)
+		})
+	}
+}
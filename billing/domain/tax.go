@@ -0,0 +1,145 @@
+package domain
+
+import "math/big"
+
+// TaxRate represents a tax configured for a currency, optionally narrowed
+// down to a region. Rate is expressed in basis points (1800 = 18%).
+// Inclusive taxes are already contained in the item prices, exclusive
+// taxes are added on top of the bill total.
+type TaxRate struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Currency  Currency `json:"currency"`
+	Region    string   `json:"region"`
+	Rate      int64    `json:"rate"`
+	Inclusive bool     `json:"inclusive"`
+}
+
+// TaxLine represents a tax calculated for a bill when it is closed.
+// Base is the taxable amount and Amount the tax, both in the smallest
+// currency unit.
+type TaxLine struct {
+	ID        int64  `json:"id"`
+	BillingID string `json:"billingId"`
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Rate      int64  `json:"rate"`
+	Inclusive bool   `json:"inclusive"`
+	Base      int64  `json:"base"`
+	Amount    int64  `json:"amount"`
+}
+
+// TaxEngine calculates the tax lines of a bill from a set of configured rates.
+type TaxEngine struct {
+	rates []TaxRate
+}
+
+// NewTaxEngine creates a TaxEngine with the given tax rates.
+func NewTaxEngine(rates []TaxRate) TaxEngine {
+	return TaxEngine{rates: rates}
+}
+
+// RatesFor returns the tax rates applicable to a currency and region.
+// Rates configured for the region take precedence over the rates configured
+// for the currency as a whole.
+func (e TaxEngine) RatesFor(currency Currency, region string) []TaxRate {
+	var regional, general []TaxRate
+	for _, rate := range e.rates {
+		if rate.Currency != currency {
+			continue
+		}
+
+		switch rate.Region {
+		case "":
+			general = append(general, rate)
+		case region:
+			regional = append(regional, rate)
+		}
+	}
+
+	if len(regional) > 0 {
+		return regional
+	}
+	return general
+}
+
+// Calculate returns the tax lines of a bill based on its total after discounts.
+// Inclusive taxes are extracted from the total, exclusive taxes are calculated
+// on the amount net of inclusive taxes.
+func (e TaxEngine) Calculate(bill Bill) []TaxLine {
+	rates := e.RatesFor(bill.Currency, bill.Region)
+	if len(rates) == 0 {
+		return nil
+	}
+
+	total := bill.GetTotal()
+
+	var inclusiveRate int64
+	for _, rate := range rates {
+		if rate.Inclusive {
+			inclusiveRate += rate.Rate
+		}
+	}
+	net := mulDivRound(total, MaxPercentageBasisPoints, MaxPercentageBasisPoints+inclusiveRate)
+
+	lines := make([]TaxLine, 0, len(rates))
+	for _, rate := range rates {
+		line := TaxLine{
+			BillingID: bill.BillingID,
+			Code:      rate.Code,
+			Name:      rate.Name,
+			Rate:      rate.Rate,
+			Inclusive: rate.Inclusive,
+			Base:      net,
+		}
+		if rate.Inclusive {
+			line.Amount = mulDivRound(total, rate.Rate, MaxPercentageBasisPoints+inclusiveRate)
+		} else {
+			line.Amount = PercentageOf(net, rate.Rate)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// mulDivRound returns a × b / c rounded half up, without overflowing on the
+// intermediate product.
+func mulDivRound(a, b, c int64) int64 {
+	return mulDivRoundBig(a, b, c).Int64()
+}
+
+// mulDivRoundBig returns a × b / c rounded half up, for non-negative operands,
+// as a big.Int that may not fit into an int64.
+func mulDivRoundBig(a, b, c int64) *big.Int {
+	product := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	divisor := big.NewInt(c)
+
+	quotient, remainder := new(big.Int).QuoRem(product, divisor, new(big.Int))
+	if remainder.Mul(remainder, big.NewInt(2)).Cmp(divisor) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return quotient
+}
+
+// GetTaxTotal calculates the sum of all tax line amounts in the bill,
+// including taxes that are already contained in the item prices.
+func (b *Bill) GetTaxTotal() int64 {
+	var total int64
+	for _, line := range b.Taxes {
+		total += line.Amount
+	}
+	return total
+}
+
+// GetGrandTotal calculates the amount due for the bill, i.e. the total after
+// discounts plus all exclusive taxes.
+func (b *Bill) GetGrandTotal() int64 {
+	total := b.GetTotal()
+	for _, line := range b.Taxes {
+		if !line.Inclusive {
+			total += line.Amount
+		}
+	}
+	return total
+}
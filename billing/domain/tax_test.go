@@ -0,0 +1,102 @@
+package domain_test
+
+import (
+	"testing"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTaxRates = []domain.TaxRate{
+	{Code: "VAT", Name: "VAT", Currency: domain.CurrencyGEL, Rate: 1800, Inclusive: true},
+	{Code: "SALES_TAX", Name: "Sales tax", Currency: domain.CurrencyUSD, Region: "US-CA", Rate: 725},
+	{Code: "CITY_TAX", Name: "City tax", Currency: domain.CurrencyUSD, Region: "US-CA", Rate: 100},
+	{Code: "SALES_TAX", Name: "Sales tax", Currency: domain.CurrencyUSD, Rate: 500},
+}
+
+func TestTaxEngine_RatesFor(t *testing.T) {
+	engine := domain.NewTaxEngine(testTaxRates)
+
+	assert.Len(t, engine.RatesFor(domain.CurrencyUSD, "US-CA"), 2)
+	assert.Equal(t, []domain.TaxRate{testTaxRates[3]}, engine.RatesFor(domain.CurrencyUSD, "US-TX"))
+	assert.Equal(t, []domain.TaxRate{testTaxRates[0]}, engine.RatesFor(domain.CurrencyGEL, ""))
+	assert.Empty(t, domain.NewTaxEngine(nil).RatesFor(domain.CurrencyGEL, ""))
+}
+
+func TestTaxEngine_Calculate(t *testing.T) {
+	engine := domain.NewTaxEngine(testTaxRates)
+
+	testCases := []struct {
+		name               string
+		bill               domain.Bill
+		expected           []domain.TaxLine
+		expectedTaxTotal   int64
+		expectedGrandTotal int64
+	}{
+		{
+			name: "tax-inclusive pricing",
+			bill: domain.Bill{
+				BillingID: "mock-billing-id",
+				Currency:  domain.CurrencyGEL,
+				Items:     []domain.Item{{ID: 1, Price: 11800}},
+			},
+			expected: []domain.TaxLine{
+				{BillingID: "mock-billing-id", Code: "VAT", Name: "VAT", Rate: 1800, Inclusive: true, Base: 10000, Amount: 1800},
+			},
+			expectedTaxTotal:   1800,
+			expectedGrandTotal: 11800,
+		},
+		{
+			name: "tax-exclusive pricing with regional rates",
+			bill: domain.Bill{
+				BillingID: "mock-billing-id",
+				Currency:  domain.CurrencyUSD,
+				Region:    "US-CA",
+				Items:     []domain.Item{{ID: 1, Price: 10000}},
+			},
+			expected: []domain.TaxLine{
+				{BillingID: "mock-billing-id", Code: "SALES_TAX", Name: "Sales tax", Rate: 725, Base: 10000, Amount: 725},
+				{BillingID: "mock-billing-id", Code: "CITY_TAX", Name: "City tax", Rate: 100, Base: 10000, Amount: 100},
+			},
+			expectedTaxTotal:   825,
+			expectedGrandTotal: 10825,
+		},
+		{
+			name: "taxes are calculated after discounts",
+			bill: domain.Bill{
+				BillingID: "mock-billing-id",
+				Currency:  domain.CurrencyUSD,
+				Items:     []domain.Item{{ID: 1, Price: 1000}},
+				Discounts: []domain.Discount{{Type: domain.DiscountTypeFixed, Value: 1}},
+			},
+			expected: []domain.TaxLine{
+				{BillingID: "mock-billing-id", Code: "SALES_TAX", Name: "Sales tax", Rate: 500, Base: 999, Amount: 50},
+			},
+			expectedTaxTotal:   50,
+			expectedGrandTotal: 1049,
+		},
+		{
+			name: "inclusive tax is rounded half up",
+			bill: domain.Bill{
+				BillingID: "mock-billing-id",
+				Currency:  domain.CurrencyGEL,
+				Items:     []domain.Item{{ID: 1, Price: 1000}},
+			},
+			expected: []domain.TaxLine{
+				{BillingID: "mock-billing-id", Code: "VAT", Name: "VAT", Rate: 1800, Inclusive: true, Base: 847, Amount: 153},
+			},
+			expectedTaxTotal:   153,
+			expectedGrandTotal: 1000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.bill.Taxes = engine.Calculate(tc.bill)
+
+			assert.Equal(t, tc.expected, tc.bill.Taxes)
+			assert.Equal(t, tc.expectedTaxTotal, tc.bill.GetTaxTotal())
+			assert.Equal(t, tc.expectedGrandTotal, tc.bill.GetGrandTotal())
+		})
+	}
+}
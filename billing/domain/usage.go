@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// UsageEvent represents a usage of a metered feature reported for an open
+// bill. Events are deduplicated by EventID within a bill, so they can be
+// safely retried by the client. Timestamp is when the usage happened and
+// RecordedAt when the event was received.
+type UsageEvent struct {
+	ID         int64     `json:"id"`
+	BillingID  string    `json:"billingId"`
+	EventID    string    `json:"eventId"`
+	Meter      string    `json:"meter"`
+	Quantity   int64     `json:"quantity"`
+	Timestamp  time.Time `json:"timestamp"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// UsageTotal represents the usage of a meter aggregated over the usage events
+// of a bill.
+type UsageTotal struct {
+	Meter          string    `json:"meter"`
+	Quantity       int64     `json:"quantity"`
+	Events         int64     `json:"events"`
+	LastRecordedAt time.Time `json:"lastRecordedAt"`
+}
+
+// Meter represents a metered feature priced in a currency, per unit or by tiers.
+// The usage of a meter is billed as a single line item when the bill closes.
+type Meter struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Unit     string   `json:"unit"`
+	Currency Currency `json:"currency"`
+	Price    Price    `json:"price"`
+}
+
+// MeterCatalog looks up the meters usage can be reported for.
+type MeterCatalog struct {
+	meters []Meter
+}
+
+// NewMeterCatalog creates a MeterCatalog with the given meters.
+func NewMeterCatalog(meters []Meter) MeterCatalog {
+	return MeterCatalog{meters: meters}
+}
+
+// Validate returns ErrInvalidPrice when the price of a meter cannot be evaluated.
+func (c MeterCatalog) Validate() error {
+	for _, meter := range c.meters {
+		if err := meter.Price.Validate(); err != nil {
+			return fmt.Errorf("meter %s in %s: %w", meter.Code, meter.Currency, err)
+		}
+	}
+
+	return nil
+}
+
+// Find returns the meter with the given code priced in currency.
+func (c MeterCatalog) Find(code string, currency Currency) (Meter, bool) {
+	for _, meter := range c.meters {
+		if meter.Code == code && meter.Currency == currency {
+			return meter, true
+		}
+	}
+
+	return Meter{}, false
+}
+
+// UsageItemKey returns the idempotency key of the line item billing the
+// usage of a meter, so that the usage is billed by a single item per meter
+// even when the bill is closed again after being reopened.
+func UsageItemKey(billingID, meter string) string {
+	return fmt.Sprintf("%s-usage-%s", billingID, meter)
+}
+
+// Item returns the line item billing the aggregated usage of the meter on a
+// bill. Items of tiered meters have no unit price but the breakdown of their
+// price by tier.
+func (m Meter) Item(billingID string, usage UsageTotal) (Item, error) {
+	price, tiers, err := m.Price.Evaluate(usage.Quantity)
+	if err != nil {
+		return Item{}, err
+	}
+
+	item := Item{
+		BillingID:      billingID,
+		Name:           m.Name,
+		Quantity:       usage.Quantity,
+		Unit:           m.Unit,
+		Price:          price,
+		Tiers:          tiers,
+		IdempotencyKey: UsageItemKey(billingID, m.Code),
+	}
+	if !m.Price.IsTiered() {
+		item.UnitPrice = m.Price.UnitPrice
+	}
+
+	return item, nil
+}
+
+// CapUsage returns the line item billing the aggregated usage of meter on the
+// bill, in place of the item already billing it, if any. When billing all of
+// the usage would push the total past the spending cap of the bill, the item
+// bills the largest quantity that keeps the bill within the cap, the quantity
+// recorded being kept in its metadata, and CapUsage returns true.
+func (b *Bill) CapUsage(meter Meter, usage UsageTotal) (Item, bool, error) {
+	item, err := meter.Item(b.BillingID, usage)
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	bill := *b
+	bill.Items = nil
+	for _, existing := range b.Items {
+		if existing.IdempotencyKey != item.IdempotencyKey {
+			bill.Items = append(bill.Items, existing)
+		}
+	}
+	if !bill.ExceedsSpendingCap(item) {
+		return item, false, nil
+	}
+
+	// the quantity is searched for the price of most meters growing with it,
+	// and the item returned is always checked against the cap.
+	capped := usage
+	capped.Quantity = 0
+	for low, high := int64(1), usage.Quantity-1; low <= high; {
+		quantity := low + (high-low)/2
+		candidate, err := meter.Item(b.BillingID, UsageTotal{Meter: usage.Meter, Quantity: quantity})
+		if err != nil {
+			return Item{}, false, err
+		}
+		if bill.ExceedsSpendingCap(candidate) {
+			high = quantity - 1
+			continue
+		}
+		capped.Quantity = quantity
+		low = quantity + 1
+	}
+
+	item, err = meter.Item(b.BillingID, capped)
+	if err != nil {
+		return Item{}, false, err
+	}
+	item.Metadata = Metadata{"usageRecorded": strconv.FormatInt(usage.Quantity, 10)}
+	return item, true, nil
+}
+
+// SetItem replaces the item with the same ID as item, keeping whether it was
+// voided, or adds item to the bill when it has no such item, and updates the
+// total.
+func (b *Bill) SetItem(item Item) {
+	for i := range b.Items {
+		if b.Items[i].ID != item.ID {
+			continue
+		}
+
+		item.VoidedAt = b.Items[i].VoidedAt
+		b.Items[i] = item
+		b.Total = b.GetTotal()
+		return
+	}
+
+	b.AddItem(item)
+}
@@ -0,0 +1,127 @@
+package domain_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeterCatalog_Find(t *testing.T) {
+	catalog := domain.NewMeterCatalog([]domain.Meter{
+		{Code: "api_calls", Name: "API calls", Currency: domain.CurrencyUSD, Price: domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 1}},
+		{Code: "api_calls", Name: "API calls", Currency: domain.CurrencyGEL, Price: domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 3}},
+	})
+
+	meter, found := catalog.Find("api_calls", domain.CurrencyGEL)
+	assert.True(t, found)
+	assert.Equal(t, int64(3), meter.Price.UnitPrice)
+
+	_, found = catalog.Find("storage_gb_hours", domain.CurrencyUSD)
+	assert.False(t, found)
+}
+
+func TestMeter_Item(t *testing.T) {
+	meter := domain.Meter{Code: "api_calls", Name: "API calls", Unit: "call", Currency: domain.CurrencyUSD, Price: domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 2}}
+
+	item, err := meter.Item("B-1", domain.UsageTotal{Meter: "api_calls", Quantity: 1500, Events: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Item{
+		BillingID:      "B-1",
+		Name:           "API calls",
+		Quantity:       1500,
+		Unit:           "call",
+		UnitPrice:      2,
+		Price:          3000,
+		IdempotencyKey: "B-1-usage-api_calls",
+	}, item)
+
+	_, err = meter.Item("B-1", domain.UsageTotal{Meter: "api_calls", Quantity: math.MaxInt64})
+	assert.ErrorIs(t, err, domain.ErrAmountOverflow)
+}
+
+func TestMeter_ItemTiered(t *testing.T) {
+	meter := domain.Meter{Code: "api_calls", Name: "API calls", Unit: "call", Currency: domain.CurrencyUSD, Price: graduatedAPICalls}
+
+	item, err := meter.Item("B-1", domain.UsageTotal{Meter: "api_calls", Quantity: 7333, Events: 12})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), item.UnitPrice)
+	assert.Equal(t, int64(833), item.Price)
+	assert.Len(t, item.Tiers, 3)
+	assert.True(t, item.IsTiered())
+	assert.Equal(t, int64(0), item.GetUnitPrice())
+	assert.Equal(t, int64(833), item.Amount())
+}
+
+func TestMeterCatalog_Validate(t *testing.T) {
+	catalog := domain.NewMeterCatalog([]domain.Meter{
+		{Code: "api_calls", Currency: domain.CurrencyUSD, Price: graduatedAPICalls},
+		{Code: "seats", Currency: domain.CurrencyUSD, Price: volumeSeats},
+	})
+	assert.NoError(t, catalog.Validate())
+
+	catalog = domain.NewMeterCatalog([]domain.Meter{
+		{Code: "api_calls", Currency: domain.CurrencyUSD, Price: graduatedAPICalls},
+		{Code: "storage_gb_hours", Currency: domain.CurrencyGEL},
+	})
+	err := catalog.Validate()
+	assert.ErrorIs(t, err, domain.ErrInvalidPrice)
+	assert.EqualError(t, err, `meter storage_gb_hours in GEL: invalid price: unknown price model ""`)
+}
+
+func TestBill_SetItem(t *testing.T) {
+	voidedAt := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	bill := domain.Bill{Items: []domain.Item{
+		{ID: 1, Name: "Setup fee", Price: 500},
+		{ID: 2, Name: "API calls", Quantity: 100, UnitPrice: 2, Price: 200},
+		{ID: 3, Name: "Storage", Quantity: 10, UnitPrice: 5, Price: 50, VoidedAt: &voidedAt},
+	}}
+
+	bill.SetItem(domain.Item{ID: 2, Name: "API calls", Quantity: 150, UnitPrice: 2, Price: 300})
+	bill.SetItem(domain.Item{ID: 3, Name: "Storage", Quantity: 20, UnitPrice: 5, Price: 100})
+	bill.SetItem(domain.Item{ID: 4, Name: "Egress", Quantity: 3, UnitPrice: 10, Price: 30})
+
+	assert.Len(t, bill.Items, 4)
+	assert.Equal(t, int64(150), bill.Items[1].Quantity)
+	assert.Equal(t, &voidedAt, bill.Items[2].VoidedAt)
+	assert.Equal(t, int64(20), bill.Items[2].Quantity)
+	assert.Equal(t, int64(500+300+30), bill.Total)
+}
+
+func TestBill_CapUsage(t *testing.T) {
+	meter := domain.Meter{Code: "api_calls", Name: "API calls", Unit: "call", Currency: domain.CurrencyUSD, Price: domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 2}}
+	bill := domain.Bill{BillingID: "B-1", MaxTotal: 1000, Items: []domain.Item{
+		{ID: 1, Name: "Setup fee", Price: 500},
+		{ID: 2, Name: "API calls", Quantity: 100, UnitPrice: 2, Price: 200, IdempotencyKey: "B-1-usage-api_calls"},
+	}}
+
+	item, capped, err := bill.CapUsage(meter, domain.UsageTotal{Meter: "api_calls", Quantity: 250})
+	assert.NoError(t, err)
+	assert.False(t, capped)
+	assert.Equal(t, int64(500), item.Price)
+
+	item, capped, err = bill.CapUsage(meter, domain.UsageTotal{Meter: "api_calls", Quantity: 400})
+	assert.NoError(t, err)
+	assert.True(t, capped)
+	assert.Equal(t, int64(250), item.Quantity)
+	assert.Equal(t, int64(500), item.Price)
+	assert.Equal(t, "400", item.Metadata["usageRecorded"])
+
+	_, _, err = bill.CapUsage(meter, domain.UsageTotal{Meter: "api_calls", Quantity: math.MaxInt64})
+	assert.ErrorIs(t, err, domain.ErrAmountOverflow)
+
+	tiered := domain.Meter{Code: "api_calls", Name: "API calls", Currency: domain.CurrencyUSD, Price: graduatedAPICalls}
+	item, capped, err = bill.CapUsage(tiered, domain.UsageTotal{Meter: "api_calls", Quantity: 7333})
+	assert.NoError(t, err)
+	assert.True(t, capped)
+	assert.Equal(t, int64(500), item.Price)
+
+	uncapped := domain.Bill{BillingID: "B-1", Items: bill.Items}
+	_, capped, err = uncapped.CapUsage(meter, domain.UsageTotal{Meter: "api_calls", Quantity: 1000000})
+	assert.NoError(t, err)
+	assert.False(t, capped)
+}
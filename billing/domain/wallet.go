@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// WalletEntryType represents the kind of a movement of wallet credit.
+type WalletEntryType string
+
+const (
+	// WalletEntryTypeTopUp represents credit prepaid by the account.
+	WalletEntryTypeTopUp WalletEntryType = "TOP_UP"
+	// WalletEntryTypeConsumption represents credit applied to a bill.
+	WalletEntryTypeConsumption WalletEntryType = "CONSUMPTION"
+	// WalletEntryTypeRelease represents credit given back by a bill, e.g.
+	// when it is voided.
+	WalletEntryTypeRelease WalletEntryType = "RELEASE"
+)
+
+// Wallet represents the prepaid credit of an account, as a balance per
+// currency kept by a ledger of entries.
+type Wallet struct {
+	AccountID string          `json:"accountId"`
+	Balances  []WalletBalance `json:"balances"`
+	Entries   []WalletEntry   `json:"entries"`
+}
+
+// WalletBalance represents the credit available in a currency, in its
+// smallest unit. A balance never goes negative.
+type WalletBalance struct {
+	Currency  Currency  `json:"currency"`
+	Balance   int64     `json:"balance"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// WalletEntry represents a movement of the credit of an account in
+// Currency. Amount is positive for credit added to the wallet and negative
+// for credit taken from it. Consumptions and releases reference the bill the
+// credit is applied to.
+type WalletEntry struct {
+	ID        int64           `json:"id"`
+	AccountID string          `json:"accountId"`
+	Currency  Currency        `json:"currency"`
+	Type      WalletEntryType `json:"type"`
+	Amount    int64           `json:"amount"`
+	BillingID string          `json:"billingId"`
+	Reference string          `json:"reference"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// WalletCreditApplication represents the input of the application of the
+// wallet credit of an account to a Bill when it closes.
+type WalletCreditApplication struct {
+	Bill      Bill      `json:"bill"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// CreditFor returns the credit to apply to the bill out of balance, the
+// credit available to it, given the credit the bill already holds, e.g. from
+// a previous close attempt. The credit covers the bill up to its grand total
+// less its payments and credit notes.
+func (b *Bill) CreditFor(balance, held int64) int64 {
+	due := b.GetGrandTotal() - b.GetPaidTotal() - b.GetCreditedTotal()
+	if due <= 0 {
+		return 0
+	}
+	return min(balance+held, due)
+}
+
+// GetAmountDue returns the grand total of the bill less the wallet credit
+// applied to it.
+func (b *Bill) GetAmountDue() int64 {
+	return b.GetGrandTotal() - b.CreditApplied
+}
@@ -0,0 +1,70 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBill_CreditFor(t *testing.T) {
+	bill := domain.Bill{
+		BillingID: "bill-1",
+		Currency:  domain.CurrencyUSD,
+		Items:     []domain.Item{{ID: 1, Quantity: 1, UnitPrice: 1000, Price: 1000}},
+		Taxes:     []domain.TaxLine{{Code: "SALES_TAX", Rate: 1000, Base: 1000, Amount: 100}},
+	}
+	bill.Total = bill.GetTotal()
+
+	testCases := []struct {
+		condition string
+		balance   int64
+		held      int64
+		expected  int64
+	}{
+		{condition: "no credit", expected: 0},
+		{condition: "credit below the grand total", balance: 400, expected: 400},
+		{condition: "credit above the grand total", balance: 5000, expected: 1100},
+		{condition: "credit held by a previous attempt", balance: 100, held: 400, expected: 500},
+		{condition: "credit held above the grand total", held: 1500, expected: 1100},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.condition, func(t *testing.T) {
+			assert.Equal(t, tc.expected, bill.CreditFor(tc.balance, tc.held))
+		})
+	}
+
+	bill.Payments = []domain.Payment{{BillAmount: 1100}}
+	assert.Equal(t, int64(0), bill.CreditFor(5000, 0))
+}
+
+func TestBill_CloseWithCredit(t *testing.T) {
+	closedAt := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+	newBill := func(credit int64) domain.Bill {
+		return domain.Bill{
+			BillingID:     "bill-1",
+			Status:        domain.BillStatusOpen,
+			Currency:      domain.CurrencyUSD,
+			Items:         []domain.Item{{ID: 1, Quantity: 1, UnitPrice: 1000, Price: 1000}},
+			CreditApplied: credit,
+		}
+	}
+
+	partial := newBill(400)
+	partial.Close(closedAt)
+	assert.Equal(t, domain.BillStatusClosed, partial.Status)
+	assert.Equal(t, int64(600), partial.GetAmountDue())
+	assert.Equal(t, int64(600), partial.GetOutstandingBalance())
+
+	covered := newBill(1000)
+	covered.Close(closedAt)
+	assert.Equal(t, domain.BillStatusPaid, covered.Status)
+	assert.Equal(t, int64(0), covered.GetOutstandingBalance())
+
+	empty := newBill(0)
+	empty.Items = nil
+	empty.Close(closedAt)
+	assert.Equal(t, domain.BillStatusClosed, empty.Status)
+}
@@ -2,14 +2,51 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 // BillingActivities defines the set of activities related to billing operations
 // that can be executed within a Temporal workflow.
 type BillingActivities interface {
-	SetBillingToCloseActivity(ctx context.Context, bill Bill) error
+	SetBillingToCloseActivity(ctx context.Context, bill Bill) (string, error)
 	UpsertBillingToDBActivity(ctx context.Context, bill Bill) error
-	InsertLineItemActivity(ctx context.Context, item Item) error
+	InsertLineItemActivity(ctx context.Context, item Item) (Item, error)
+	GetUsageTotalsActivity(ctx context.Context, billingID string) ([]UsageTotal, error)
+	AggregateUsageActivity(ctx context.Context, bill Bill) ([]Item, error)
+	VoidLineItemActivity(ctx context.Context, item Item) error
+	InsertDiscountActivity(ctx context.Context, discount Discount) (Discount, error)
+	ReserveCouponsActivity(ctx context.Context, reservation CouponReservation) ([]string, error)
+	ApplyWalletCreditActivity(ctx context.Context, application WalletCreditApplication) (int64, error)
+	CalculateBillTaxesActivity(ctx context.Context, bill Bill) ([]TaxLine, error)
+	InsertBillTaxesActivity(ctx context.Context, bill Bill) error
 	InsertBillExchangeActivity(ctx context.Context, bill Bill) error
 	RevertBillCloseActivity(ctx context.Context, bill Bill) error
+	SetBillingToVoidActivity(ctx context.Context, entry AuditEntry) error
+	ExpireBillingActivity(ctx context.Context, entry AuditEntry) error
+	IssueCreditNoteActivity(ctx context.Context, note CreditNote) (CreditNote, error)
+	RemindUnpaidBillActivity(ctx context.Context, entry AuditEntry) (bool, error)
+	SetBillingToOverdueActivity(ctx context.Context, entry AuditEntry) (bool, error)
+	UpsertSubscriptionActivity(ctx context.Context, subscription Subscription) error
+}
+
+// DunningRequest represents the input of the dunning workflow of a closed
+// Bill. The dunning schedule runs from ClosedAt.
+type DunningRequest struct {
+	BillingID string    `json:"billingId"`
+	ClosedAt  time.Time `json:"closedAt"`
+}
+
+// DunningWorkflowID returns the ID of the dunning workflow of a bill.
+func DunningWorkflowID(billingID string) string {
+	return "dunning-" + billingID
+}
+
+// PaymentActivities defines the set of activities that collect the
+// outstanding balance of closed bills through a PaymentProvider.
+type PaymentActivities interface {
+	AuthorizePaymentActivity(ctx context.Context, req PaymentRequest) (ProviderPayment, error)
+	CapturePaymentActivity(ctx context.Context, payment ProviderPayment) (ProviderPayment, error)
+	RefundPaymentActivity(ctx context.Context, payment ProviderPayment) (ProviderPayment, error)
+	GetPaymentStatusActivity(ctx context.Context, payment ProviderPayment) (ProviderPayment, error)
+	RecordProviderPaymentActivity(ctx context.Context, payment ProviderPayment) error
 }
@@ -1,6 +1,7 @@
 package billing
 
 import (
+	"sort"
 	"time"
 
 	"encore.app/billing/domain"
@@ -15,10 +16,19 @@ type (
 	}
 
 	// AddItemRequest represents the payload to add a new line item to a bill,
-	// including the item's name and price in the smallest currency unit.
+	// including the item's name, quantity, unit of measure and unit price in
+	// the smallest currency unit. Price is accepted as the unit price of a
+	// single-unit item when unitPrice is omitted. Items can instead be added
+	// by sku, priced from the catalog, without name, unit and price.
+	// Metadata holds optional client-defined key/value pairs.
 	AddItemRequest struct {
-		Name  string `json:"name"`
-		Price int64  `json:"price"`
+		SKU       string            `json:"sku"`
+		Name      string            `json:"name"`
+		Price     int64             `json:"price"`
+		Quantity  int64             `json:"quantity"`
+		Unit      string            `json:"unit"`
+		UnitPrice int64             `json:"unitPrice"`
+		Metadata  map[string]string `json:"metadata"`
 	}
 
 	// AddItemResponse represents the response after attempting to add a line item,
@@ -27,6 +37,29 @@ type (
 		CurrentBill Bill `json:"current_bill"`
 	}
 
+	// VoidItemResponse represents the response after voiding a line item,
+	// including the current state of the bill.
+	VoidItemResponse struct {
+		CurrentBill Bill `json:"current_bill"`
+	}
+
+	// ApplyDiscountRequest represents the payload to apply a discount to a bill.
+	// ItemID is optional, when omitted the discount applies to the whole bill.
+	// Type is either PERCENTAGE, with Value in basis points (1000 = 10%), or
+	// FIXED, with Value in the smallest currency unit.
+	ApplyDiscountRequest struct {
+		ItemID      int64  `json:"itemId"`
+		Type        string `json:"type"`
+		Value       int64  `json:"value"`
+		Description string `json:"description"`
+	}
+
+	// ApplyDiscountResponse represents the response after applying a discount,
+	// including the current state of the bill.
+	ApplyDiscountResponse struct {
+		CurrentBill Bill `json:"current_bill"`
+	}
+
 	// CloseBillingRequest represents the payload to request closing a bill,
 	// including the target currency for conversion.
 	CloseBillingRequest struct {
@@ -34,8 +67,17 @@ type (
 	}
 
 	// CloseBillingResponse represents the response after closing a bill,
-	// including totals in both the original and converted currencies.
+	// including its invoice number, the subtotal, discount, tax and grand
+	// total, the wallet credit applied and the amount left due, and the grand
+	// total in both the original and converted currencies.
 	CloseBillingResponse struct {
+		InvoiceNumber          string `json:"invoiceNumber"`
+		Subtotal               Amount `json:"subtotal"`
+		Discount               Amount `json:"discount"`
+		Tax                    Amount `json:"tax"`
+		GrandTotal             Amount `json:"grandTotal"`
+		CreditApplied          Amount `json:"creditApplied"`
+		AmountDue              Amount `json:"amountDue"`
 		OriginalCurrencyTotal  Amount `json:"originalCurrencyTotal"`
 		ConvertedCurrencyTotal Amount `json:"convertedCurrencyTotal"`
 	}
@@ -47,31 +89,311 @@ type (
 		FormattedAmount string `json:"formattedAmount"`
 	}
 
-	// OpenBillingRequest represents the payload to create a new bill,
-	// specifying the currency for the bill.
+	// ReopenBillRequest represents the payload to reopen a closed bill,
+	// including the reason recorded in the audit trail.
+	ReopenBillRequest struct {
+		Reason string `json:"reason"`
+	}
+
+	// ReopenBillResponse represents the response after reopening a bill,
+	// including the current state of the bill.
+	ReopenBillResponse struct {
+		CurrentBill Bill `json:"current_bill"`
+	}
+
+	// CancelBillRequest represents the payload to void an open bill,
+	// including the reason recorded in the audit trail.
+	CancelBillRequest struct {
+		Reason string `json:"reason"`
+	}
+
+	// CancelBillResponse represents the response after voiding a bill,
+	// including the final state of the bill.
+	CancelBillResponse struct {
+		CurrentBill Bill `json:"current_bill"`
+	}
+
+	// RecordPaymentRequest represents the payload to record a payment against
+	// a closed bill. Amount is expressed in the smallest unit of currency,
+	// which defaults to the bill currency. Reference is optional.
+	RecordPaymentRequest struct {
+		Amount    int64  `json:"amount"`
+		Currency  string `json:"currency"`
+		Reference string `json:"reference"`
+	}
+
+	// RecordPaymentResponse represents the response after recording a payment,
+	// including the current state of the bill.
+	RecordPaymentResponse struct {
+		CurrentBill Bill `json:"current_bill"`
+	}
+
+	// ConfirmPaymentRequest represents the confirmation of a pending payment
+	// sent by the payment provider. Status is AUTHORIZED, CAPTURED or DECLINED.
+	ConfirmPaymentRequest struct {
+		PaymentID     string `json:"paymentId"`
+		Status        string `json:"status"`
+		DeclineReason string `json:"declineReason"`
+	}
+
+	// IssueCreditNoteRequest represents the payload to issue a credit note
+	// against a closed bill. Each line credits an amount, in the smallest unit
+	// of the bill currency, for an item or, without itemId, for the whole bill.
+	IssueCreditNoteRequest struct {
+		Reason string           `json:"reason"`
+		Lines  []CreditNoteLine `json:"lines"`
+	}
+
+	// IssueCreditNoteResponse represents the response after issuing a credit
+	// note, including the current state of the bill.
+	IssueCreditNoteResponse struct {
+		CurrentBill Bill `json:"current_bill"`
+	}
+
+	// RecordUsageRequest represents the payload to record usage events of
+	// metered features for an open bill, at most 1000 at once.
+	RecordUsageRequest struct {
+		Events []UsageEvent `json:"events"`
+	}
+
+	// RecordUsageResponse represents the response after recording usage events,
+	// counting the events recorded and the duplicates of events already recorded.
+	RecordUsageResponse struct {
+		Recorded   int64 `json:"recorded"`
+		Duplicates int64 `json:"duplicates"`
+	}
+
+	// GetUsageResponse represents the usage recorded for a bill, per meter.
+	GetUsageResponse struct {
+		Usage []UsageTotal `json:"usage"`
+	}
+
+	// GetAuditTrailResponse represents the audit trail of a bill.
+	GetAuditTrailResponse struct {
+		Entries []AuditEntry `json:"entries"`
+	}
+
+	// CreateAccountRequest represents the payload to create a new customer account.
+	CreateAccountRequest struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	// UpdateAccountRequest represents the payload to update a customer account.
+	UpdateAccountRequest struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	// AccountResponse represents the response returned by the account APIs.
+	AccountResponse struct {
+		Account Account `json:"account"`
+	}
+
+	// GetAccountBillsRequest represents the filters of the account bills list.
+	// Each metadata filter is a key:value pair the bill metadata must contain.
+	GetAccountBillsRequest struct {
+		Metadata []string `query:"metadata"`
+	}
+
+	// GetAccountBillsResponse represents the bills owned by an account,
+	// most recent first.
+	GetAccountBillsResponse struct {
+		Bills []Bill `json:"bills"`
+	}
+
+	// TopUpWalletRequest represents the payload to add prepaid credit to the
+	// wallet of an account, in the smallest unit of currency (USD or GEL).
+	TopUpWalletRequest struct {
+		Currency  string `json:"currency"`
+		Amount    int64  `json:"amount"`
+		Reference string `json:"reference"`
+	}
+
+	// WalletResponse represents the response returned by the wallet APIs.
+	WalletResponse struct {
+		Wallet Wallet `json:"wallet"`
+	}
+
+	// OpenBillingRequest represents the payload to create a new bill for an
+	// account, specifying the currency for the bill and, optionally, the
+	// region used to select tax rates.
+	// A bill with a periodEnd, or a recurrence such as END_OF_MONTH evaluated
+	// in timezone (UTC by default), is closed automatically when the period ends.
+	// MaxTotal optionally caps the total of the bill, in the smallest unit of
+	// its currency: items that would push the total past it are rejected.
+	// Metadata holds optional client-defined key/value pairs.
 	OpenBillingRequest struct {
-		Currency string `json:"currency"`
+		AccountID  string            `json:"accountId"`
+		Currency   string            `json:"currency"`
+		Region     string            `json:"region"`
+		PeriodEnd  *time.Time        `json:"periodEnd"`
+		Recurrence string            `json:"recurrence"`
+		Timezone   string            `json:"timezone"`
+		MaxTotal   int64             `json:"maxTotal"`
+		Metadata   map[string]string `json:"metadata"`
 	}
 
 	// OpenBillingResponse represents the response after creating a new bill,
-	// including the billing ID and the currency of the bill.
+	// including the billing ID, the owning account and the currency of the bill.
 	OpenBillingResponse struct {
 		Currency  string `json:"currency"`
 		BillingID string `json:"billingId"`
+		AccountID string `json:"accountId"`
+	}
+
+	// CreateSubscriptionRequest represents the payload to subscribe an account
+	// to a recurring plan. Every period of the recurrence (END_OF_DAY,
+	// END_OF_WEEK or END_OF_MONTH), evaluated in timezone (UTC by default),
+	// a bill is opened with the plan items and closed when the period ends.
+	CreateSubscriptionRequest struct {
+		AccountID  string     `json:"accountId"`
+		Currency   string     `json:"currency"`
+		Region     string     `json:"region"`
+		Recurrence string     `json:"recurrence"`
+		Timezone   string     `json:"timezone"`
+		Items      []PlanItem `json:"items"`
+	}
+
+	// ChangeSubscriptionPlanRequest represents the payload to change the plan
+	// of a subscription. The change is prorated on the open bill of the
+	// current cycle by day or by second, as set by prorationPrecision (DAY or
+	// SECOND, the configured precision by default).
+	ChangeSubscriptionPlanRequest struct {
+		Items              []PlanItem `json:"items"`
+		ProrationPrecision string     `json:"prorationPrecision"`
+	}
+
+	// SubscriptionResponse represents the response returned by the subscription APIs.
+	SubscriptionResponse struct {
+		Subscription Subscription `json:"subscription"`
+	}
+
+	// ProductRequest represents the payload to create or update a product of
+	// the catalog, with its name and an optional description.
+	ProductRequest struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	// ProductResponse represents the response returned by the product APIs.
+	ProductResponse struct {
+		Product Product `json:"product"`
+	}
+
+	// ListProductsResponse represents the products of the catalog.
+	ListProductsResponse struct {
+		Products []Product `json:"products"`
+	}
+
+	// CreateSKURequest represents the payload to create a SKU of a product,
+	// identified by code, with its unit price in the smallest unit of
+	// currency (USD or GEL) and, in prices, its unit prices in other
+	// currencies, e.g. {"GEL": 1250}. Name defaults to the name of the product.
+	CreateSKURequest struct {
+		Code      string           `json:"code"`
+		Name      string           `json:"name"`
+		Unit      string           `json:"unit"`
+		Currency  string           `json:"currency"`
+		UnitPrice int64            `json:"unitPrice"`
+		Prices    map[string]int64 `json:"prices"`
+	}
+
+	// UpdateSKURequest represents the payload to update a SKU, creating its
+	// next version.
+	UpdateSKURequest struct {
+		Name      string           `json:"name"`
+		Unit      string           `json:"unit"`
+		Currency  string           `json:"currency"`
+		UnitPrice int64            `json:"unitPrice"`
+		Prices    map[string]int64 `json:"prices"`
+	}
+
+	// GetSKURequest represents the query of the GetSKU API, version selects
+	// a past version of the SKU, the current one by default.
+	GetSKURequest struct {
+		Version int64 `query:"version"`
+	}
+
+	// SKUResponse represents the response returned by the SKU APIs.
+	SKUResponse struct {
+		SKU SKU `json:"sku"`
+	}
+
+	// CreateCouponRequest represents the payload to create a coupon. Type is
+	// either PERCENTAGE, with Value in basis points (1000 = 10%), or FIXED,
+	// with Value in the smallest unit of its only currency. Currencies,
+	// validFrom, validUntil and the redemption limits are optional; a zero
+	// limit means unlimited.
+	CreateCouponRequest struct {
+		Code                     string     `json:"code"`
+		Description              string     `json:"description"`
+		Type                     string     `json:"type"`
+		Value                    int64      `json:"value"`
+		Currencies               []string   `json:"currencies"`
+		ValidFrom                *time.Time `json:"validFrom"`
+		ValidUntil               *time.Time `json:"validUntil"`
+		MaxRedemptions           int64      `json:"maxRedemptions"`
+		MaxRedemptionsPerAccount int64      `json:"maxRedemptionsPerAccount"`
+	}
+
+	// CouponResponse represents the response returned by the coupon APIs.
+	CouponResponse struct {
+		Coupon Coupon `json:"coupon"`
+	}
+
+	// ApplyCouponRequest represents the payload to apply a coupon to a bill.
+	ApplyCouponRequest struct {
+		Code string `json:"code"`
+	}
+
+	// ApplyCouponResponse represents the response after applying a coupon,
+	// including the current state of the bill.
+	ApplyCouponResponse struct {
+		CurrentBill Bill `json:"current_bill"`
 	}
 )
 
+func newAmount(c domain.Currency, amount int64) Amount {
+	return Amount{
+		Currency:        string(c),
+		Amount:          amount,
+		FormattedAmount: currency.FormatString(string(c), amount),
+	}
+}
+
 // Bill represents a billing record containing multiple items, currency info,
-// total amount, and status (open or closed).
+// total amount, payments, credit notes, and status (open, closed, partially paid, paid or voided).
 type Bill struct {
 	BillingID      string              `json:"billingId"`
+	InvoiceNumber  string              `json:"invoiceNumber"`
+	AccountID      string              `json:"accountId"`
+	SubscriptionID string              `json:"subscriptionId"`
 	Status         string              `json:"status"`
 	Currency       string              `json:"currency"`
+	Region         string              `json:"region"`
+	PeriodEnd      *time.Time          `json:"periodEnd"`
+	Metadata       map[string]string   `json:"metadata"`
+	Subtotal       int64               `json:"subtotal"`
+	DiscountTotal  int64               `json:"discountTotal"`
 	Total          int64               `json:"total"`
+	MaxTotal       int64               `json:"maxTotal"`
+	TaxTotal       int64               `json:"taxTotal"`
+	GrandTotal     int64               `json:"grandTotal"`
+	CreditApplied  int64               `json:"creditApplied"`
+	AmountDue      int64               `json:"amountDue"`
+	PaidTotal      int64               `json:"paidTotal"`
+	CreditedTotal  int64               `json:"creditedTotal"`
+	Outstanding    int64               `json:"outstandingBalance"`
 	Items          []Item              `json:"items"`
+	Discounts      []Discount          `json:"discounts"`
+	Taxes          []TaxLine           `json:"taxes"`
+	Payments       []Payment           `json:"payments"`
+	CreditNotes    []CreditNote        `json:"creditNotes"`
 	Conversion     BillExchangeResonse `json:"conversion"`
 	CreatedAt      time.Time           `json:"createdAt"`
 	ClosedAt       *time.Time          `json:"closedAt"`
+	VoidedAt       *time.Time          `json:"voidedAt"`
 	FormattedTotal string              `json:"formattedTotal"`
 }
 
@@ -81,30 +403,509 @@ func fromDomainBillToBillReponse(b domain.Bill) Bill {
 		items = append(items, fromDomainItemToResponse(i))
 	}
 
+	var discounts []Discount
+	for _, d := range b.CalculateDiscounts() {
+		discounts = append(discounts, fromDomainDiscountToResponse(b.Currency, d))
+	}
+
+	var taxes []TaxLine
+	for _, t := range b.Taxes {
+		taxes = append(taxes, fromDomainTaxLineToResponse(b.Currency, t))
+	}
+
+	var payments []Payment
+	for _, p := range b.Payments {
+		payments = append(payments, fromDomainPaymentToResponse(b.Currency, p))
+	}
+
+	var creditNotes []CreditNote
+	for _, n := range b.CreditNotes {
+		creditNotes = append(creditNotes, fromDomainCreditNoteToResponse(n))
+	}
+
 	return Bill{
 		BillingID:      b.BillingID,
+		InvoiceNumber:  b.InvoiceNumber,
+		AccountID:      b.AccountID,
+		SubscriptionID: b.SubscriptionID,
 		Status:         string(b.Status),
 		Currency:       string(b.Currency),
+		Region:         b.Region,
+		PeriodEnd:      b.PeriodEnd,
+		Metadata:       b.Metadata,
+		Subtotal:       b.GetSubtotal(),
+		DiscountTotal:  b.GetDiscountTotal(),
 		Total:          b.GetTotal(),
+		MaxTotal:       b.MaxTotal,
+		TaxTotal:       b.GetTaxTotal(),
+		GrandTotal:     b.GetGrandTotal(),
+		CreditApplied:  b.CreditApplied,
+		AmountDue:      b.GetAmountDue(),
+		PaidTotal:      b.GetPaidTotal(),
+		CreditedTotal:  b.GetCreditedTotal(),
+		Outstanding:    b.GetOutstandingBalance(),
 		Items:          items,
+		Discounts:      discounts,
+		Taxes:          taxes,
+		Payments:       payments,
+		CreditNotes:    creditNotes,
 		Conversion:     fromDomainBillingExchangeToResponse(b.Conversion),
 		FormattedTotal: currency.FormatString(string(b.Currency), b.GetTotal()),
 		CreatedAt:      b.CreatedAt,
 		ClosedAt:       b.ClosedAt,
+		VoidedAt:       b.VoidedAt,
 	}
 }
 
-// Item represents a line item in a bill, including price, name, and
-// optional idempotency key to prevent duplicate entries.
+// Item represents a line item in a bill, including name, quantity, unit price
+// and price, the line total in the smallest currency unit.
+// Items priced by tiers have no unit price and list the breakdown of their
+// price by tier instead.
+// Items added by SKU list the SKU and the catalog version they were priced with.
+// Voided items are listed with their voidedAt timestamp but do not count
+// towards the bill total.
 type Item struct {
-	Name  string `json:"name"`
-	Price int64  `json:"price"`
+	ID             int64             `json:"id"`
+	SKU            string            `json:"sku"`
+	CatalogVersion int64             `json:"catalogVersion"`
+	Name           string            `json:"name"`
+	Quantity       int64             `json:"quantity"`
+	Unit           string            `json:"unit"`
+	UnitPrice      int64             `json:"unitPrice"`
+	Price          int64             `json:"price"`
+	Tiers          []ItemTier        `json:"tiers"`
+	Metadata       map[string]string `json:"metadata"`
+	Voided         bool              `json:"voided"`
+	VoidedAt       *time.Time        `json:"voidedAt"`
+}
+
+// ItemTier represents the units of a tiered item priced by one tier, from
+// firstUnit to lastUnit, at unitPrice per perUnits units plus the flat price
+// of the tier.
+type ItemTier struct {
+	Tier      int   `json:"tier"`
+	FirstUnit int64 `json:"firstUnit"`
+	LastUnit  int64 `json:"lastUnit"`
+	Quantity  int64 `json:"quantity"`
+	UnitPrice int64 `json:"unitPrice"`
+	PerUnits  int64 `json:"perUnits"`
+	FlatPrice int64 `json:"flatPrice"`
+	Amount    int64 `json:"amount"`
 }
 
 func fromDomainItemToResponse(i domain.Item) Item {
 	return Item{
-		Name:  i.Name,
-		Price: i.Price,
+		ID:             i.ID,
+		SKU:            i.SKU,
+		CatalogVersion: i.CatalogVersion,
+		Name:           i.Name,
+		Quantity:       i.GetQuantity(),
+		Unit:           i.Unit,
+		UnitPrice:      i.GetUnitPrice(),
+		Price:          i.Amount(),
+		Tiers:          fromDomainItemTiersToResponse(i.Tiers),
+		Metadata:       i.Metadata,
+		Voided:         i.IsVoided(),
+		VoidedAt:       i.VoidedAt,
+	}
+}
+
+func fromDomainItemTiersToResponse(tiers []domain.ItemTier) []ItemTier {
+	if len(tiers) == 0 {
+		return nil
+	}
+
+	result := make([]ItemTier, len(tiers))
+	for i, t := range tiers {
+		result[i] = ItemTier{
+			Tier:      t.Tier,
+			FirstUnit: t.FirstUnit,
+			LastUnit:  t.LastUnit,
+			Quantity:  t.Quantity,
+			UnitPrice: t.UnitPrice,
+			PerUnits:  t.PerUnits,
+			FlatPrice: t.FlatPrice,
+			Amount:    t.Amount,
+		}
+	}
+	return result
+}
+
+// Discount represents a discount line of a bill. ItemID is zero for
+// bill-level discounts. Amount is the calculated discount in the smallest
+// currency unit.
+type Discount struct {
+	ID              int64  `json:"id"`
+	ItemID          int64  `json:"itemId"`
+	Type            string `json:"type"`
+	Value           int64  `json:"value"`
+	Description     string `json:"description"`
+	Amount          int64  `json:"amount"`
+	FormattedAmount string `json:"formattedAmount"`
+	CouponCode      string `json:"couponCode"`
+}
+
+func fromDomainDiscountToResponse(billCurrency domain.Currency, d domain.Discount) Discount {
+	return Discount{
+		ID:              d.ID,
+		ItemID:          d.ItemID,
+		Type:            string(d.Type),
+		Value:           d.Value,
+		Description:     d.Description,
+		Amount:          d.Amount,
+		FormattedAmount: currency.FormatString(string(billCurrency), d.Amount),
+		CouponCode:      d.CouponCode,
+	}
+}
+
+// TaxLine represents a tax calculated for a closed bill. Rate is expressed in
+// basis points, Base and Amount in the smallest currency unit. Inclusive taxes
+// are contained in the bill total, exclusive taxes are added to it.
+type TaxLine struct {
+	Code            string `json:"code"`
+	Name            string `json:"name"`
+	Rate            int64  `json:"rate"`
+	Inclusive       bool   `json:"inclusive"`
+	Base            int64  `json:"base"`
+	Amount          int64  `json:"amount"`
+	FormattedAmount string `json:"formattedAmount"`
+}
+
+func fromDomainTaxLineToResponse(billCurrency domain.Currency, t domain.TaxLine) TaxLine {
+	return TaxLine{
+		Code:            t.Code,
+		Name:            t.Name,
+		Rate:            t.Rate,
+		Inclusive:       t.Inclusive,
+		Base:            t.Base,
+		Amount:          t.Amount,
+		FormattedAmount: currency.FormatString(string(billCurrency), t.Amount),
+	}
+}
+
+// Payment represents a payment recorded against a bill, in the currency it
+// was paid in and converted to the bill currency.
+type Payment struct {
+	ID         int64     `json:"id"`
+	Amount     Amount    `json:"amount"`
+	Rate       float64   `json:"rate"`
+	BillAmount Amount    `json:"billAmount"`
+	Reference  string    `json:"reference"`
+	PaidAt     time.Time `json:"paidAt"`
+}
+
+func fromDomainPaymentToResponse(billCurrency domain.Currency, p domain.Payment) Payment {
+	return Payment{
+		ID:         p.ID,
+		Amount:     newAmount(p.Currency, p.Amount),
+		Rate:       p.Rate,
+		BillAmount: newAmount(billCurrency, p.BillAmount),
+		Reference:  p.Reference,
+		PaidAt:     p.PaidAt,
+	}
+}
+
+// CreditNote represents a credit note issued against a bill, in the bill
+// currency.
+type CreditNote struct {
+	ID       int64            `json:"id"`
+	Number   string           `json:"number"`
+	Amount   Amount           `json:"amount"`
+	Reason   string           `json:"reason"`
+	Lines    []CreditNoteLine `json:"lines"`
+	IssuedAt time.Time        `json:"issuedAt"`
+}
+
+// CreditNoteLine represents the amount credited for an item of the bill or,
+// when ItemID is zero, for the whole bill.
+type CreditNoteLine struct {
+	ItemID int64 `json:"itemId"`
+	Amount int64 `json:"amount"`
+}
+
+func fromDomainCreditNoteToResponse(n domain.CreditNote) CreditNote {
+	var lines []CreditNoteLine
+	for _, l := range n.Lines {
+		lines = append(lines, CreditNoteLine{ItemID: l.ItemID, Amount: l.Amount})
+	}
+
+	return CreditNote{
+		ID:       n.ID,
+		Number:   n.Number,
+		Amount:   newAmount(n.Currency, n.Amount),
+		Reason:   n.Reason,
+		Lines:    lines,
+		IssuedAt: n.IssuedAt,
+	}
+}
+
+// Account represents a customer account that owns bills.
+type Account struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func fromDomainAccountToResponse(a domain.Account) Account {
+	return Account{
+		ID:        a.ID,
+		Name:      a.Name,
+		Email:     a.Email,
+		CreatedAt: a.CreatedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
+
+// Wallet represents the prepaid credit of an account, with its balance in
+// every currency it was topped up in and its ledger, oldest entry first.
+type Wallet struct {
+	AccountID string        `json:"accountId"`
+	Balances  []Amount      `json:"balances"`
+	Entries   []WalletEntry `json:"entries"`
+}
+
+// WalletEntry represents a top-up of a wallet, or credit applied to or
+// released by a bill. Amount is negative for credit taken from the wallet.
+type WalletEntry struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Amount    Amount    `json:"amount"`
+	BillingID string    `json:"billingId"`
+	Reference string    `json:"reference"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func fromDomainWalletToResponse(w domain.Wallet) Wallet {
+	var balances []Amount
+	for _, b := range w.Balances {
+		balances = append(balances, newAmount(b.Currency, b.Balance))
+	}
+
+	var entries []WalletEntry
+	for _, e := range w.Entries {
+		entries = append(entries, WalletEntry{
+			ID:        e.ID,
+			Type:      string(e.Type),
+			Amount:    newAmount(e.Currency, e.Amount),
+			BillingID: e.BillingID,
+			Reference: e.Reference,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return Wallet{
+		AccountID: w.AccountID,
+		Balances:  balances,
+		Entries:   entries,
+	}
+}
+
+// Subscription represents a recurring plan of an account, its status
+// (active, paused or cancelled) and its current cycle and bill.
+type Subscription struct {
+	SubscriptionID   string     `json:"subscriptionId"`
+	AccountID        string     `json:"accountId"`
+	Status           string     `json:"status"`
+	Currency         string     `json:"currency"`
+	Region           string     `json:"region"`
+	Recurrence       string     `json:"recurrence"`
+	Timezone         string     `json:"timezone"`
+	Items            []PlanItem `json:"items"`
+	Cycle            int64      `json:"cycle"`
+	CurrentBillingID string     `json:"currentBillingId"`
+	CurrentPeriodEnd *time.Time `json:"currentPeriodEnd"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	PausedAt         *time.Time `json:"pausedAt"`
+	CancelledAt      *time.Time `json:"cancelledAt"`
+}
+
+// PlanItem represents an item billed every cycle of a subscription, with
+// its quantity (1 by default), unit and unit price in the smallest currency unit.
+type PlanItem struct {
+	Name      string `json:"name"`
+	Quantity  int64  `json:"quantity"`
+	Unit      string `json:"unit"`
+	UnitPrice int64  `json:"unitPrice"`
+}
+
+func fromDomainSubscriptionToResponse(s domain.Subscription) Subscription {
+	var items []PlanItem
+	for _, i := range s.Items {
+		items = append(items, PlanItem(i))
+	}
+
+	return Subscription{
+		SubscriptionID:   s.SubscriptionID,
+		AccountID:        s.AccountID,
+		Status:           string(s.Status),
+		Currency:         string(s.Currency),
+		Region:           s.Region,
+		Recurrence:       string(s.Recurrence),
+		Timezone:         s.Timezone,
+		Items:            items,
+		Cycle:            s.Cycle,
+		CurrentBillingID: s.CurrentBillingID,
+		CurrentPeriodEnd: s.CurrentPeriodEnd,
+		CreatedAt:        s.CreatedAt,
+		PausedAt:         s.PausedAt,
+		CancelledAt:      s.CancelledAt,
+	}
+}
+
+// Product represents a product of the catalog and its SKUs. Archived
+// products and SKUs are listed with their archivedAt timestamp.
+type Product struct {
+	ProductID   string     `json:"productId"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	SKUs        []SKU      `json:"skus"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	ArchivedAt  *time.Time `json:"archivedAt"`
+}
+
+// SKU represents a sellable unit of a product, with its unit price in the
+// smallest currency unit, its unit prices in other currencies, by currency,
+// and its current version.
+type SKU struct {
+	Code       string     `json:"code"`
+	ProductID  string     `json:"productId"`
+	Name       string     `json:"name"`
+	Unit       string     `json:"unit"`
+	UnitPrice  Amount     `json:"unitPrice"`
+	Prices     []Amount   `json:"prices"`
+	Version    int64      `json:"version"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	ArchivedAt *time.Time `json:"archivedAt"`
+}
+
+func fromDomainProductToResponse(p domain.Product) Product {
+	var skus []SKU
+	for _, s := range p.SKUs {
+		skus = append(skus, fromDomainSKUToResponse(s))
+	}
+
+	return Product{
+		ProductID:   p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		SKUs:        skus,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		ArchivedAt:  p.ArchivedAt,
+	}
+}
+
+func fromDomainSKUToResponse(s domain.SKU) SKU {
+	return SKU{
+		Code:       s.Code,
+		ProductID:  s.ProductID,
+		Name:       s.Name,
+		Unit:       s.Unit,
+		UnitPrice:  newAmount(s.Currency, s.UnitPrice),
+		Prices:     fromDomainPriceListToResponse(s.Prices),
+		Version:    s.Version,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+		ArchivedAt: s.ArchivedAt,
+	}
+}
+
+func fromDomainPriceListToResponse(prices domain.PriceList) []Amount {
+	currencies := make([]string, 0, len(prices))
+	for c := range prices {
+		currencies = append(currencies, string(c))
+	}
+	sort.Strings(currencies)
+
+	var result []Amount
+	for _, c := range currencies {
+		result = append(result, newAmount(domain.Currency(c), prices[domain.Currency(c)]))
+	}
+	return result
+}
+
+func fromRequestPriceList(prices map[string]int64) domain.PriceList {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	result := make(domain.PriceList, len(prices))
+	for c, price := range prices {
+		result[domain.Currency(c)] = price
+	}
+	return result
+}
+
+// Coupon represents a promotion code and the number of bills it is redeemed by.
+type Coupon struct {
+	Code                     string     `json:"code"`
+	Description              string     `json:"description"`
+	Type                     string     `json:"type"`
+	Value                    int64      `json:"value"`
+	Currencies               []string   `json:"currencies"`
+	ValidFrom                *time.Time `json:"validFrom"`
+	ValidUntil               *time.Time `json:"validUntil"`
+	MaxRedemptions           int64      `json:"maxRedemptions"`
+	MaxRedemptionsPerAccount int64      `json:"maxRedemptionsPerAccount"`
+	Redemptions              int64      `json:"redemptions"`
+	CreatedAt                time.Time  `json:"createdAt"`
+}
+
+func fromDomainCouponToResponse(c domain.Coupon) Coupon {
+	var currencies []string
+	for _, currency := range c.Currencies {
+		currencies = append(currencies, string(currency))
+	}
+
+	return Coupon{
+		Code:                     c.Code,
+		Description:              c.Description,
+		Type:                     string(c.DiscountType),
+		Value:                    c.DiscountValue,
+		Currencies:               currencies,
+		ValidFrom:                c.ValidFrom,
+		ValidUntil:               c.ValidUntil,
+		MaxRedemptions:           c.MaxRedemptions,
+		MaxRedemptionsPerAccount: c.MaxRedemptionsPerAccount,
+		Redemptions:              c.Redemptions,
+		CreatedAt:                c.CreatedAt,
+	}
+}
+
+// UsageEvent represents a usage of a metered feature. EventID is unique
+// within the bill, a retried event with the same eventId is recorded once.
+// Timestamp defaults to the time the event is recorded.
+type UsageEvent struct {
+	EventID   string    `json:"eventId"`
+	Meter     string    `json:"meter"`
+	Quantity  int64     `json:"quantity"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// UsageTotal represents the usage recorded for a meter of a bill.
+type UsageTotal struct {
+	Meter          string    `json:"meter"`
+	Quantity       int64     `json:"quantity"`
+	Events         int64     `json:"events"`
+	LastRecordedAt time.Time `json:"lastRecordedAt"`
+}
+
+// AuditEntry represents a single entry of the audit trail of a bill.
+type AuditEntry struct {
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func fromDomainAuditEntryToResponse(e domain.AuditEntry) AuditEntry {
+	return AuditEntry{
+		Action:    string(e.Action),
+		Reason:    e.Reason,
+		CreatedAt: e.CreatedAt,
 	}
 }
 
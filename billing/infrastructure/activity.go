@@ -2,41 +2,66 @@ package infrastructure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"encore.app/billing/domain"
 	"encore.dev/rlog"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 )
 
+// creditNoteErrorType is the Temporal application error type of credit notes
+// rejected by the bill; such errors are not retried.
+const creditNoteErrorType = "CreditNoteRejected"
+
+// usageErrorType is the Temporal application error type of usage that cannot
+// be priced, e.g. because its amount overflows; such errors are not retried.
+const usageErrorType = "UsageRejected"
+
 // BillingActivities defines the set of Temporal activities related to billing.
 // Each method on this struct represents an activity that can be executed
 // asynchronously by a Temporal workflow. Activities should be **idempotent**
 // and short-running, as recommended by Temporal best practices.
 type BillingActivities struct {
-	repository domain.Repository
+	repository       domain.Repository
+	taxEngine        domain.TaxEngine
+	invoiceNumbering domain.InvoiceNumbering
+	meters           domain.MeterCatalog
 }
 
-// NewBillingActivity creates a new BillingActivities instance with the given repository.
+// NewBillingActivity creates a new BillingActivities instance with the given repository,
+// the tax engine used for bills closed by the workflow itself, the numbering of
+// the invoice numbers assigned to closed bills and the meters pricing their usage.
 // This is used to register the activities with the Temporal worker.
-func NewBillingActivity(repository domain.Repository) domain.BillingActivities {
+func NewBillingActivity(
+	repository domain.Repository,
+	taxEngine domain.TaxEngine,
+	invoiceNumbering domain.InvoiceNumbering,
+	meters domain.MeterCatalog,
+) domain.BillingActivities {
 	return &BillingActivities{
-		repository: repository,
+		repository:       repository,
+		taxEngine:        taxEngine,
+		invoiceNumbering: invoiceNumbering,
+		meters:           meters,
 	}
 }
 
-// SetBillingToCloseActivity calculates the total for a Bill and closes it by
-// calling CloseBill in the database context.
-func (a *BillingActivities) SetBillingToCloseActivity(ctx context.Context, bill domain.Bill) error {
+// SetBillingToCloseActivity calculates the total for a Bill, including its
+// discounts, and closes it by calling CloseBill in the database context.
+// It returns the invoice number assigned to the bill.
+func (a *BillingActivities) SetBillingToCloseActivity(ctx context.Context, bill domain.Bill) (string, error) {
 	if bill.BillingID == "" {
-		return fmt.Errorf("close bill: missing billing id")
+		return "", fmt.Errorf("close bill: missing billing id")
 	}
 
 	bill.Total = bill.GetTotal()
-	if err := a.repository.CloseBilling(ctx, bill); err != nil {
-		return fmt.Errorf("close bill %s: %w", bill.BillingID, err)
+	if err := a.repository.CloseBilling(ctx, &bill, a.invoiceNumbering); err != nil {
+		return "", fmt.Errorf("close bill %s: %w", bill.BillingID, err)
 	}
 
-	return nil
+	return bill.InvoiceNumber, nil
 }
 
 // UpsertBillingToDBActivity inserts a new Bill or updates an existing one in the database.
@@ -53,23 +78,183 @@ func (a *BillingActivities) UpsertBillingToDBActivity(ctx context.Context, bill
 	return nil
 }
 
-// InsertLineItemActivity inserts or updates a single Item in the database.
-func (a *BillingActivities) InsertLineItemActivity(ctx context.Context, item domain.Item) error {
+// InsertLineItemActivity inserts or updates a single Item in the database
+// and returns the persisted Item, including its database ID. Only proration
+// credits may have a negative price.
+func (a *BillingActivities) InsertLineItemActivity(ctx context.Context, item domain.Item) (domain.Item, error) {
 	if item.BillingID == "" {
-		return fmt.Errorf("upsert item: missing billing id")
+		return domain.Item{}, fmt.Errorf("upsert item: missing billing id")
 	}
 	if item.Name == "" {
-		return fmt.Errorf("upsert item: missing name")
+		return domain.Item{}, fmt.Errorf("upsert item: missing name")
+	}
+	if item.Price <= 0 && !item.IsProrationCredit() {
+		return domain.Item{}, fmt.Errorf("upsert item: invalid price %d", item.Price)
 	}
-	if item.Price <= 0 {
-		return fmt.Errorf("upsert item: invalid price %d", item.Price)
+	if item.Quantity < 0 {
+		return domain.Item{}, fmt.Errorf("upsert item: invalid quantity %d", item.Quantity)
 	}
 	if err := a.repository.SaveItem(ctx, &item); err != nil {
-		return fmt.Errorf("upsert item for bill %s: %w", item.BillingID, err)
+		return domain.Item{}, fmt.Errorf("upsert item for bill %s: %w", item.BillingID, err)
+	}
+	return item, nil
+}
+
+// GetUsageTotalsActivity returns the usage recorded for a Bill, aggregated per meter.
+func (a *BillingActivities) GetUsageTotalsActivity(ctx context.Context, billingID string) ([]domain.UsageTotal, error) {
+	if billingID == "" {
+		return nil, fmt.Errorf("get usage: missing billing id")
+	}
+
+	totals, err := a.repository.GetUsageTotalsByBillID(ctx, billingID)
+	if err != nil {
+		return nil, fmt.Errorf("get usage of bill %s: %w", billingID, err)
+	}
+	return totals, nil
+}
+
+// AggregateUsageActivity turns the usage recorded for a Bill into a single
+// line item per meter, priced in the bill currency, and returns the persisted
+// items. The bill is marked as closing first, so that no usage is recorded
+// past the aggregation, and the usage past the spending cap of the bill is
+// not billed. Usage items are upserted by their idempotency key, so
+// a bill closed again after being reopened is billed the usage recorded since
+// as well.
+func (a *BillingActivities) AggregateUsageActivity(ctx context.Context, bill domain.Bill) ([]domain.Item, error) {
+	if bill.BillingID == "" {
+		return nil, fmt.Errorf("aggregate usage: missing billing id")
+	}
+	if err := a.repository.MarkBillingClosing(ctx, bill.BillingID); err != nil {
+		return nil, fmt.Errorf("mark bill %s closing: %w", bill.BillingID, err)
+	}
+
+	totals, err := a.GetUsageTotalsActivity(ctx, bill.BillingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []domain.Item
+	for _, total := range totals {
+		meter, found := a.meters.Find(total.Meter, bill.Currency)
+		if !found {
+			activity.GetLogger(ctx).Warn("skipping usage of unknown meter",
+				"billing_id", bill.BillingID,
+				"meter", total.Meter,
+				"currency", bill.Currency,
+			)
+			continue
+		}
+
+		item, capped, err := bill.CapUsage(meter, total)
+		if err != nil {
+			return nil, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("price usage of meter %s: %v", total.Meter, err), usageErrorType, err)
+		}
+		if capped {
+			activity.GetLogger(ctx).Warn("billing usage up to the spending cap",
+				"billing_id", bill.BillingID,
+				"meter", total.Meter,
+				"recorded", total.Quantity,
+				"billed", item.Quantity,
+			)
+		}
+		if err := a.repository.SaveItem(ctx, &item); err != nil {
+			return nil, fmt.Errorf("upsert usage item for bill %s: %w", bill.BillingID, err)
+		}
+		bill.SetItem(item)
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// VoidLineItemActivity marks a single Item as voided in the database.
+func (a *BillingActivities) VoidLineItemActivity(ctx context.Context, item domain.Item) error {
+	if item.BillingID == "" {
+		return fmt.Errorf("void item: missing billing id")
+	}
+	if item.ID == 0 {
+		return fmt.Errorf("void item: missing item id")
+	}
+	if item.VoidedAt == nil {
+		return fmt.Errorf("void item %d: missing voided at", item.ID)
+	}
+	if err := a.repository.VoidItem(ctx, item); err != nil {
+		return fmt.Errorf("void item %d for bill %s: %w", item.ID, item.BillingID, err)
 	}
 	return nil
 }
 
+// InsertDiscountActivity inserts a single Discount in the database
+// and returns the persisted Discount, including its database ID.
+func (a *BillingActivities) InsertDiscountActivity(ctx context.Context, discount domain.Discount) (domain.Discount, error) {
+	if discount.BillingID == "" {
+		return domain.Discount{}, fmt.Errorf("insert discount: missing billing id")
+	}
+	if discount.Type != domain.DiscountTypePercentage && discount.Type != domain.DiscountTypeFixed {
+		return domain.Discount{}, fmt.Errorf("insert discount: invalid type %q", discount.Type)
+	}
+	if discount.Value <= 0 {
+		return domain.Discount{}, fmt.Errorf("insert discount: invalid value %d", discount.Value)
+	}
+	if err := a.repository.SaveDiscount(ctx, &discount); err != nil {
+		return domain.Discount{}, fmt.Errorf("insert discount for bill %s: %w", discount.BillingID, err)
+	}
+	return discount, nil
+}
+
+// ReserveCouponsActivity reserves a redemption of the coupons applied to a
+// Bill that is closing and returns the codes of the coupons whose redemption
+// limit was reached in the meantime, which are removed from the bill.
+func (a *BillingActivities) ReserveCouponsActivity(ctx context.Context, reservation domain.CouponReservation) ([]string, error) {
+	if reservation.Bill.BillingID == "" {
+		return nil, fmt.Errorf("reserve coupons: missing billing id")
+	}
+	rejected, err := a.repository.ReserveCoupons(ctx, reservation.Bill, reservation.ReservedAt)
+	if err != nil {
+		return nil, fmt.Errorf("reserve coupons for bill %s: %w", reservation.Bill.BillingID, err)
+	}
+	return rejected, nil
+}
+
+// ApplyWalletCreditActivity applies the wallet credit of the account of a
+// Bill that is closing, in the bill currency, and returns the credit applied.
+// The credit is recorded in the wallet ledger at the time the bill closes.
+func (a *BillingActivities) ApplyWalletCreditActivity(ctx context.Context, application domain.WalletCreditApplication) (int64, error) {
+	if application.Bill.BillingID == "" {
+		return 0, fmt.Errorf("apply wallet credit: missing billing id")
+	}
+	credit, err := a.repository.ApplyWalletCredit(ctx, application.Bill, application.AppliedAt)
+	if err != nil {
+		return 0, fmt.Errorf("apply wallet credit to bill %s: %w", application.Bill.BillingID, err)
+	}
+	return credit, nil
+}
+
+// CalculateBillTaxesActivity calculates the tax lines of a Bill that is closed
+// by the workflow itself, e.g. at the end of its billing period, or whose usage
+// was billed when it was closed.
+func (a *BillingActivities) CalculateBillTaxesActivity(ctx context.Context, bill domain.Bill) ([]domain.TaxLine, error) {
+	if bill.BillingID == "" {
+		return nil, fmt.Errorf("calculate taxes: missing billing id")
+	}
+
+	return a.taxEngine.Calculate(bill), nil
+}
+
+// InsertBillTaxesActivity persists the tax lines calculated for a closed Bill.
+func (a *BillingActivities) InsertBillTaxesActivity(ctx context.Context, bill domain.Bill) error {
+	if bill.BillingID == "" {
+		return fmt.Errorf("insert taxes: missing billing id")
+	}
+
+	if err := a.repository.SaveTaxLines(ctx, &bill); err != nil {
+		return fmt.Errorf("persist taxes for bill %s: %w", bill.BillingID, err)
+	}
+
+	return nil
+}
+
 // InsertBillExchangeActivity is the Temporal activity wrapper
 func (a *BillingActivities) InsertBillExchangeActivity(ctx context.Context, bill domain.Bill) error {
 	if bill.BillingID == "" {
@@ -86,6 +271,145 @@ func (a *BillingActivities) InsertBillExchangeActivity(ctx context.Context, bill
 	return nil
 }
 
+// SetBillingToVoidActivity voids an open Bill and records the reason
+// in its audit trail.
+func (a *BillingActivities) SetBillingToVoidActivity(ctx context.Context, entry domain.AuditEntry) error {
+	if entry.BillingID == "" {
+		return fmt.Errorf("void bill: missing billing id")
+	}
+
+	if err := a.repository.VoidBilling(ctx, entry); err != nil {
+		return fmt.Errorf("void bill %s: %w", entry.BillingID, err)
+	}
+
+	return nil
+}
+
+// ExpireBillingActivity records the expiry of an idle Bill in its audit
+// trail. A Bill that is still open, because it has no items, is voided.
+func (a *BillingActivities) ExpireBillingActivity(ctx context.Context, entry domain.AuditEntry) error {
+	if entry.BillingID == "" {
+		return fmt.Errorf("expire bill: missing billing id")
+	}
+
+	if err := a.repository.ExpireBilling(ctx, entry); err != nil {
+		return fmt.Errorf("expire bill %s: %w", entry.BillingID, err)
+	}
+
+	return nil
+}
+
+// IssueCreditNoteActivity applies a credit note to its closed Bill and
+// persists it, returning the persisted credit note. A credit note that was
+// already issued under the same number is returned as is, so that retries
+// never credit a bill twice. Credit notes rejected by the bill fail without
+// retry.
+func (a *BillingActivities) IssueCreditNoteActivity(ctx context.Context, note domain.CreditNote) (domain.CreditNote, error) {
+	if note.BillingID == "" {
+		return domain.CreditNote{}, fmt.Errorf("issue credit note: missing billing id")
+	}
+
+	bill, err := a.repository.GetBill(ctx, note.BillingID)
+	if err != nil {
+		return domain.CreditNote{}, fmt.Errorf("issue credit note %s: %w", note.Number, err)
+	}
+
+	if issued, found := bill.FindCreditNote(note.Number); found {
+		return issued, nil
+	}
+
+	if err := bill.ApplyCreditNote(note); err != nil {
+		return domain.CreditNote{}, temporal.NewNonRetryableApplicationError(err.Error(), creditNoteErrorType, err)
+	}
+
+	if err := a.repository.SaveCreditNotes(ctx, &bill); err != nil {
+		return domain.CreditNote{}, fmt.Errorf("issue credit note %s: %w", note.Number, err)
+	}
+
+	issued, _ := bill.FindCreditNote(note.Number)
+	return issued, nil
+}
+
+// creditNoteError returns the domain error of a credit note rejected by
+// IssueCreditNoteActivity, or err itself for any other failure.
+func creditNoteError(err error) error {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) || appErr.Type() != creditNoteErrorType {
+		return err
+	}
+
+	for _, domainErr := range []error{
+		domain.ErrBillVoided,
+		domain.ErrBillNotClosed,
+		domain.ErrItemNotFound,
+		domain.ErrItemVoided,
+		domain.ErrInvalidPrice,
+		domain.ErrCreditExceedsBalance,
+	} {
+		if appErr.Message() == domainErr.Error() {
+			return domainErr
+		}
+	}
+	return err
+}
+
+// RemindUnpaidBillActivity records a payment reminder for a closed Bill that
+// is still unpaid. It reports whether the bill is still unpaid; the dunning
+// of settled, reopened or voided bills stops.
+func (a *BillingActivities) RemindUnpaidBillActivity(ctx context.Context, entry domain.AuditEntry) (bool, error) {
+	unpaid, err := a.isUnpaid(ctx, entry.BillingID)
+	if err != nil || !unpaid {
+		return false, err
+	}
+
+	if err := a.repository.RemindBilling(ctx, entry); err != nil {
+		return false, fmt.Errorf("remind bill %s: %w", entry.BillingID, err)
+	}
+
+	return true, nil
+}
+
+// SetBillingToOverdueActivity marks a closed Bill that is still unpaid as
+// OVERDUE. It reports whether the bill was still unpaid.
+func (a *BillingActivities) SetBillingToOverdueActivity(ctx context.Context, entry domain.AuditEntry) (bool, error) {
+	unpaid, err := a.isUnpaid(ctx, entry.BillingID)
+	if err != nil || !unpaid {
+		return false, err
+	}
+
+	if err := a.repository.MarkBillingOverdue(ctx, entry); err != nil {
+		return false, fmt.Errorf("mark bill %s overdue: %w", entry.BillingID, err)
+	}
+
+	return true, nil
+}
+
+// UpsertSubscriptionActivity inserts a new Subscription or updates the state
+// of an existing one in the database.
+func (a *BillingActivities) UpsertSubscriptionActivity(ctx context.Context, subscription domain.Subscription) error {
+	if subscription.SubscriptionID == "" {
+		return fmt.Errorf("upsert subscription: missing subscription id")
+	}
+	if err := a.repository.SaveSubscription(ctx, &subscription); err != nil {
+		return fmt.Errorf("upsert subscription %s: %w", subscription.SubscriptionID, err)
+	}
+	return nil
+}
+
+// isUnpaid reports whether the Bill is closed with an outstanding balance.
+func (a *BillingActivities) isUnpaid(ctx context.Context, billingID string) (bool, error) {
+	if billingID == "" {
+		return false, fmt.Errorf("dunning: missing billing id")
+	}
+
+	bill, err := a.repository.GetBill(ctx, billingID)
+	if err != nil {
+		return false, fmt.Errorf("dunning bill %s: %w", billingID, err)
+	}
+
+	return bill.IsClosed() && bill.GetOutstandingBalance() > 0, nil
+}
+
 // RevertBillCloseActivity is to handle revert bill closing
 func (a *BillingActivities) RevertBillCloseActivity(ctx context.Context, bill domain.Bill) error {
 	rlog.Info("BillingActivities.RevertBillCloseActivity", "billing-id", bill.BillingID)
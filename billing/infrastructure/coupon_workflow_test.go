@@ -0,0 +1,165 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/infrastructure"
+	"encore.app/billing/usecases"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+var couponBillStart = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+type couponWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	mockController *gomock.Controller
+	mockRepository *mock_domain.MockRepository
+	workflows      *infrastructure.Workflows
+	env            *testsuite.TestWorkflowEnvironment
+}
+
+func TestCouponWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(couponWorkflowTestSuite))
+}
+
+func (s *couponWorkflowTestSuite) SetupTest() {
+	s.mockController = gomock.NewController(s.T())
+	s.mockRepository = mock_domain.NewMockRepository(s.mockController)
+
+	taxEngine := domain.NewTaxEngine([]domain.TaxRate{
+		{Code: "SALES_TAX", Name: "Sales tax", Currency: domain.CurrencyUSD, Rate: 1000},
+	})
+	activities := infrastructure.NewBillingActivity(s.mockRepository, taxEngine, domain.InvoiceNumbering{}, domain.NewMeterCatalog(nil))
+	s.workflows = infrastructure.NewTemporalWorkflows(activities, nil, 24*time.Hour, 0, nil)
+
+	s.env = s.NewTestWorkflowEnvironment()
+	s.env.SetStartTime(couponBillStart)
+	s.env.RegisterWorkflow(s.workflows.BillingWorkflow)
+	s.env.RegisterActivity(activities)
+}
+
+func (s *couponWorkflowTestSuite) TearDownTest() {
+	s.mockController.Finish()
+}
+
+func couponDiscount() domain.Discount {
+	return domain.Discount{
+		BillingID:      "B-1",
+		Type:           domain.DiscountTypePercentage,
+		Value:          1000,
+		Description:    "Coupon WELCOME10",
+		CouponCode:     "WELCOME10",
+		IdempotencyKey: "coup-key",
+		CreatedAt:      couponBillStart,
+	}
+}
+
+func (s *couponWorkflowTestSuite) signalCoupon(delay time.Duration) {
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalApplyDiscount, couponDiscount())
+	}, delay)
+}
+
+func (s *couponWorkflowTestSuite) signalClose(taxes []domain.TaxLine) {
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "B-1",
+			ClosedAt:  couponBillStart.Add(time.Hour),
+			Taxes:     taxes,
+		})
+	}, time.Hour)
+}
+
+func openCouponBill() *domain.Bill {
+	return &domain.Bill{
+		BillingID: "B-1",
+		AccountID: "Acc-1",
+		Status:    domain.BillStatusOpen,
+		Currency:  domain.CurrencyUSD,
+		Total:     1000,
+		Items:     []domain.Item{{ID: 1, BillingID: "B-1", Name: "Espresso", Quantity: 1, UnitPrice: 1000, Price: 1000}},
+		CreatedAt: couponBillStart,
+	}
+}
+
+func (s *couponWorkflowTestSuite) TestCloseReservesCoupons() {
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().SaveDiscount(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, discount *domain.Discount) error {
+		discount.ID = 7
+		return nil
+	}).Times(1)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").Return(nil, nil).Times(1)
+	s.mockRepository.EXPECT().ReserveCoupons(gomock.Any(), gomock.Any(), couponBillStart.Add(time.Hour)).DoAndReturn(
+		func(_ any, bill domain.Bill, _ time.Time) ([]string, error) {
+			s.Equal([]string{"WELCOME10"}, bill.CouponCodes())
+			return nil, nil
+		},
+	).Times(1)
+
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().SaveTaxLines(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	// the same coupon applied twice is discounted once
+	s.signalCoupon(time.Minute)
+	s.signalCoupon(2 * time.Minute)
+	s.signalClose([]domain.TaxLine{{BillingID: "B-1", Code: "SALES_TAX", Rate: 1000, Base: 900, Amount: 90}})
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, openCouponBill())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Len(closed.Discounts, 1)
+	s.Equal(int64(900), closed.Total)
+	s.Equal(int64(90), closed.Taxes[0].Amount)
+}
+
+func (s *couponWorkflowTestSuite) TestCloseRemovesCouponsOverTheirLimit() {
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().SaveDiscount(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").Return(nil, nil).Times(1)
+	s.mockRepository.EXPECT().ReserveCoupons(gomock.Any(), gomock.Any(), gomock.Any()).Return([]string{"WELCOME10"}, nil).Times(1)
+
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().SaveTaxLines(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	// the taxes requested with the close include the coupon
+	s.signalCoupon(time.Minute)
+	s.signalClose([]domain.TaxLine{{BillingID: "B-1", Code: "SALES_TAX", Rate: 1000, Base: 900, Amount: 90}})
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, openCouponBill())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Empty(closed.Discounts)
+	s.Equal(int64(1000), closed.Total)
+	s.Len(closed.Taxes, 1)
+	s.Equal(int64(1000), closed.Taxes[0].Base)
+	s.Equal(int64(100), closed.Taxes[0].Amount)
+}
@@ -0,0 +1,116 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/infrastructure"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+var dunningClosedAt = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type dunningWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	mockController *gomock.Controller
+	mockRepository *mock_domain.MockRepository
+	env            *testsuite.TestWorkflowEnvironment
+}
+
+func TestDunningWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(dunningWorkflowTestSuite))
+}
+
+func (s *dunningWorkflowTestSuite) SetupTest() {
+	s.mockController = gomock.NewController(s.T())
+	s.mockRepository = mock_domain.NewMockRepository(s.mockController)
+
+	activities := infrastructure.NewBillingActivity(s.mockRepository, domain.NewTaxEngine(nil), domain.InvoiceNumbering{}, domain.NewMeterCatalog(nil))
+	workflows := infrastructure.NewTemporalWorkflows(activities, nil, 0, 0, []time.Duration{
+		3 * 24 * time.Hour,
+		7 * 24 * time.Hour,
+		14 * 24 * time.Hour,
+	})
+
+	s.env = s.NewTestWorkflowEnvironment()
+	s.env.SetStartTime(dunningClosedAt)
+	s.env.RegisterWorkflow(workflows.DunningWorkflow)
+	s.env.RegisterActivity(activities)
+}
+
+func (s *dunningWorkflowTestSuite) TearDownTest() {
+	s.mockController.Finish()
+}
+
+func unpaidBill() domain.Bill {
+	return domain.Bill{
+		BillingID: "mock-billing-id",
+		Status:    domain.BillStatusPartiallyPaid,
+		Currency:  domain.CurrencyUSD,
+		Items:     []domain.Item{{ID: 1, Price: 1000}},
+		Payments:  []domain.Payment{{ID: 1, Amount: 400, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 400}},
+		ClosedAt:  &dunningClosedAt,
+	}
+}
+
+func (s *dunningWorkflowTestSuite) TestUnpaidBillBecomesOverdue() {
+	s.mockRepository.EXPECT().GetBill(gomock.Any(), "mock-billing-id").Return(unpaidBill(), nil).Times(3)
+	gomock.InOrder(
+		s.mockRepository.EXPECT().RemindBilling(gomock.Any(), domain.AuditEntry{
+			BillingID: "mock-billing-id",
+			Action:    domain.AuditActionRemind,
+			Reason:    "payment reminder 1 of 3",
+			CreatedAt: dunningClosedAt.Add(3 * 24 * time.Hour),
+		}).Return(nil),
+		s.mockRepository.EXPECT().RemindBilling(gomock.Any(), domain.AuditEntry{
+			BillingID: "mock-billing-id",
+			Action:    domain.AuditActionRemind,
+			Reason:    "payment reminder 2 of 3",
+			CreatedAt: dunningClosedAt.Add(7 * 24 * time.Hour),
+		}).Return(nil),
+		s.mockRepository.EXPECT().MarkBillingOverdue(gomock.Any(), domain.AuditEntry{
+			BillingID: "mock-billing-id",
+			Action:    domain.AuditActionOverdue,
+			Reason:    "unpaid 336h0m0s after closing",
+			CreatedAt: dunningClosedAt.Add(14 * 24 * time.Hour),
+		}).Return(nil),
+	)
+
+	s.env.ExecuteWorkflow("DunningWorkflow", domain.DunningRequest{BillingID: "mock-billing-id", ClosedAt: dunningClosedAt})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *dunningWorkflowTestSuite) TestSettledSignalStopsDunning() {
+	s.mockRepository.EXPECT().GetBill(gomock.Any(), "mock-billing-id").Return(unpaidBill(), nil).Times(1)
+	s.mockRepository.EXPECT().RemindBilling(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalBillSettled, nil)
+	}, 4*24*time.Hour)
+
+	s.env.ExecuteWorkflow("DunningWorkflow", domain.DunningRequest{BillingID: "mock-billing-id", ClosedAt: dunningClosedAt})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal(dunningClosedAt.Add(4*24*time.Hour), s.env.Now().UTC())
+}
+
+func (s *dunningWorkflowTestSuite) TestPaidBillStopsDunning() {
+	bill := unpaidBill()
+	bill.Status = domain.BillStatusPaid
+	bill.Payments = append(bill.Payments, domain.Payment{ID: 2, Amount: 600, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 600})
+	s.mockRepository.EXPECT().GetBill(gomock.Any(), "mock-billing-id").Return(bill, nil).Times(1)
+
+	s.env.ExecuteWorkflow("DunningWorkflow", domain.DunningRequest{BillingID: "mock-billing-id", ClosedAt: dunningClosedAt})
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
@@ -0,0 +1,195 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"encore.app/billing/domain"
+)
+
+// FakePaymentProviderOptions configures the behaviour simulated by the
+// FakePaymentProvider.
+type FakePaymentProviderOptions struct {
+	// DeclineReason declines every authorization with the given reason.
+	DeclineReason string
+	// Timeouts is the number of calls failing with ErrProviderTimeout
+	// before the provider answers again.
+	Timeouts int
+	// ConfirmationDelay makes authorizations and captures PENDING; they are
+	// confirmed asynchronously once the delay has passed.
+	ConfirmationDelay time.Duration
+}
+
+// FakePaymentProvider is an in-process domain.PaymentProvider used to run
+// the payment flow offline. It approves every payment unless configured
+// otherwise by its FakePaymentProviderOptions.
+type FakePaymentProvider struct {
+	mu       sync.Mutex
+	options  FakePaymentProviderOptions
+	timeouts int
+	seq      int
+	payments map[string]domain.ProviderPayment
+	keys     map[string]string
+	notify   func(ctx context.Context, payment domain.ProviderPayment) error
+}
+
+// NewFakePaymentProvider creates a new FakePaymentProvider with the given options.
+func NewFakePaymentProvider(options FakePaymentProviderOptions) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		options:  options,
+		timeouts: options.Timeouts,
+		payments: make(map[string]domain.ProviderPayment),
+		keys:     make(map[string]string),
+	}
+}
+
+// OnConfirmation registers the callback notified of delayed confirmations,
+// as a real provider would call a webhook.
+func (p *FakePaymentProvider) OnConfirmation(notify func(ctx context.Context, payment domain.ProviderPayment) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notify = notify
+}
+
+// Authorize authorizes a payment, or returns the payment already authorized
+// with the same idempotency key.
+func (p *FakePaymentProvider) Authorize(ctx context.Context, req domain.PaymentRequest) (domain.ProviderPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.simulateTimeout(); err != nil {
+		return domain.ProviderPayment{}, err
+	}
+
+	if id, found := p.keys[req.IdempotencyKey]; found && req.IdempotencyKey != "" {
+		return p.payments[id], nil
+	}
+
+	p.seq++
+	payment := domain.ProviderPayment{
+		ID:        fmt.Sprintf("fake_%06d", p.seq),
+		BillingID: req.BillingID,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		UpdatedAt: time.Now(),
+	}
+	p.keys[req.IdempotencyKey] = payment.ID
+
+	status := domain.ProviderPaymentAuthorized
+	if p.options.DeclineReason != "" {
+		status = domain.ProviderPaymentDeclined
+		payment.DeclineReason = p.options.DeclineReason
+	}
+
+	return p.transition(payment, status), nil
+}
+
+// Capture captures an authorized payment.
+func (p *FakePaymentProvider) Capture(ctx context.Context, paymentID string) (domain.ProviderPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.simulateTimeout(); err != nil {
+		return domain.ProviderPayment{}, err
+	}
+
+	payment, found := p.payments[paymentID]
+	if !found {
+		return domain.ProviderPayment{}, domain.ErrProviderPaymentNotFound
+	}
+
+	switch payment.Status {
+	case domain.ProviderPaymentAuthorized:
+		return p.transition(payment, domain.ProviderPaymentCaptured), nil
+	case domain.ProviderPaymentPending, domain.ProviderPaymentCaptured:
+		return payment, nil
+	default:
+		return domain.ProviderPayment{}, fmt.Errorf("cannot capture %s payment %s", payment.Status, paymentID)
+	}
+}
+
+// Refund refunds a captured payment. Only full refunds are supported.
+func (p *FakePaymentProvider) Refund(ctx context.Context, paymentID string, amount int64) (domain.ProviderPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.simulateTimeout(); err != nil {
+		return domain.ProviderPayment{}, err
+	}
+
+	payment, found := p.payments[paymentID]
+	if !found {
+		return domain.ProviderPayment{}, domain.ErrProviderPaymentNotFound
+	}
+
+	switch {
+	case payment.Status == domain.ProviderPaymentRefunded:
+		return payment, nil
+	case payment.Status != domain.ProviderPaymentCaptured:
+		return domain.ProviderPayment{}, fmt.Errorf("cannot refund %s payment %s", payment.Status, paymentID)
+	case amount != payment.Amount:
+		return domain.ProviderPayment{}, fmt.Errorf("cannot refund %d of payment %s, only full refunds are supported", amount, paymentID)
+	}
+
+	payment.Status = domain.ProviderPaymentRefunded
+	payment.UpdatedAt = time.Now()
+	p.payments[paymentID] = payment
+	return payment, nil
+}
+
+// Status returns the current state of a payment.
+func (p *FakePaymentProvider) Status(ctx context.Context, paymentID string) (domain.ProviderPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.simulateTimeout(); err != nil {
+		return domain.ProviderPayment{}, err
+	}
+
+	payment, found := p.payments[paymentID]
+	if !found {
+		return domain.ProviderPayment{}, domain.ErrProviderPaymentNotFound
+	}
+	return payment, nil
+}
+
+// simulateTimeout fails the call while configured timeouts are left.
+// The caller must hold the lock.
+func (p *FakePaymentProvider) simulateTimeout() error {
+	if p.timeouts > 0 {
+		p.timeouts--
+		return domain.ErrProviderTimeout
+	}
+	return nil
+}
+
+// transition moves the payment to status, right away or, with a
+// confirmation delay, once the delay has passed. The caller must hold the lock.
+func (p *FakePaymentProvider) transition(payment domain.ProviderPayment, status domain.ProviderPaymentStatus) domain.ProviderPayment {
+	if p.options.ConfirmationDelay <= 0 {
+		payment.Status = status
+		p.payments[payment.ID] = payment
+		return payment
+	}
+
+	payment.Status = domain.ProviderPaymentPending
+	p.payments[payment.ID] = payment
+
+	time.AfterFunc(p.options.ConfirmationDelay, func() {
+		p.mu.Lock()
+		confirmed := p.payments[payment.ID]
+		confirmed.Status = status
+		confirmed.UpdatedAt = time.Now()
+		p.payments[payment.ID] = confirmed
+		notify := p.notify
+		p.mu.Unlock()
+
+		if notify != nil {
+			_ = notify(context.Background(), confirmed)
+		}
+	})
+
+	return payment
+}
@@ -0,0 +1,230 @@
+package infrastructure
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"encore.app/billing/domain"
+	"encore.app/pkg/currency"
+	"encore.app/pkg/pdf"
+)
+
+// invoice layout, in points.
+const (
+	invoiceMargin     = 50.0
+	invoiceLineHeight = 16.0
+	invoiceQtyX       = 330.0
+	invoiceUnitX      = 440.0
+	invoiceRight      = pdf.PageWidth - invoiceMargin
+)
+
+const invoiceDateFormat = "2006-01-02"
+
+// invoiceText replaces characters the standard PDF fonts cannot render,
+// such as the lari sign used by currency.FormatString.
+var invoiceText = strings.NewReplacer("₾", "GEL ")
+
+// RenderInvoicePDF renders a closed bill as a PDF invoice listing its items,
+// discounts, taxes, totals, wallet credit, payments and credit notes, and its
+// converted total when it was closed in another currency.
+func RenderInvoicePDF(bill domain.Bill) ([]byte, error) {
+	if bill.IsVoided() {
+		return nil, domain.ErrBillVoided
+	}
+	if !bill.IsClosed() {
+		return nil, domain.ErrBillNotClosed
+	}
+
+	r := newInvoiceRenderer()
+	amount := func(value int64) string {
+		return currency.FormatString(string(bill.Currency), value)
+	}
+
+	title := "Invoice"
+	if bill.InvoiceNumber != "" {
+		title = "Invoice " + bill.InvoiceNumber
+	}
+	r.page.SetFont(pdf.HelveticaBold, 18)
+	r.page.Text(invoiceMargin, r.y, title)
+	r.y -= 2 * invoiceLineHeight
+
+	r.field("Bill", bill.BillingID)
+	if bill.AccountID != "" {
+		r.field("Account", bill.AccountID)
+	}
+	r.field("Status", string(bill.Status))
+	r.field("Created", formatInvoiceDate(&bill.CreatedAt))
+	r.field("Issued", formatInvoiceDate(bill.ClosedAt))
+	if bill.PeriodEnd != nil {
+		r.field("Period end", formatInvoiceDate(bill.PeriodEnd))
+	}
+	r.y -= invoiceLineHeight
+
+	r.page.SetFont(pdf.HelveticaBold, 10)
+	r.page.Text(invoiceMargin, r.y, "Item")
+	r.page.TextRight(invoiceQtyX, r.y, "Quantity")
+	r.page.TextRight(invoiceUnitX, r.y, "Unit price")
+	r.page.TextRight(invoiceRight, r.y, "Amount")
+	r.rule()
+
+	r.page.SetFont(pdf.Helvetica, 10)
+	for _, item := range bill.Items {
+		if item.IsVoided() {
+			continue
+		}
+		quantity := fmt.Sprint(item.GetQuantity())
+		if item.Unit != "" {
+			quantity += " " + item.Unit
+		}
+		r.page.TextRight(invoiceQtyX, r.y, invoiceText.Replace(quantity))
+		if !item.IsTiered() {
+			r.page.TextRight(invoiceUnitX, r.y, invoiceText.Replace(amount(item.GetUnitPrice())))
+		}
+		r.row(item.Name, amount(item.Amount()))
+
+		// items priced by tiers list the units and amount of every tier below them
+		for _, tier := range item.Tiers {
+			r.page.TextRight(invoiceQtyX, r.y, fmt.Sprint(tier.Quantity))
+			unitPrice := amount(tier.UnitPrice)
+			if tier.PerUnits > 1 {
+				unitPrice += fmt.Sprintf(" / %d", tier.PerUnits)
+			}
+			r.page.TextRight(invoiceUnitX, r.y, invoiceText.Replace(unitPrice))
+			r.row(tierLabel(tier, amount), amount(tier.Amount))
+		}
+	}
+	r.rule()
+
+	r.row("Subtotal", amount(bill.GetSubtotal()))
+	for _, d := range bill.CalculateDiscounts() {
+		r.row(labelOr(d.Description, "Discount"), amount(-d.Amount))
+	}
+	for _, t := range bill.Taxes {
+		label := fmt.Sprintf("%s %s%%", t.Name, formatBasisPoints(t.Rate))
+		if t.Inclusive {
+			label += " (included)"
+		}
+		r.row(label, amount(t.Amount))
+	}
+
+	r.page.SetFont(pdf.HelveticaBold, 10)
+	r.row("Total due", amount(bill.GetGrandTotal()))
+	r.page.SetFont(pdf.Helvetica, 10)
+
+	if bill.CreditApplied > 0 {
+		r.row("Wallet credit", amount(-bill.CreditApplied))
+		r.page.SetFont(pdf.HelveticaBold, 10)
+		r.row("Amount due", amount(bill.GetAmountDue()))
+		r.page.SetFont(pdf.Helvetica, 10)
+	}
+
+	for _, p := range bill.Payments {
+		r.row("Payment "+formatInvoiceDate(&p.PaidAt)+referenceSuffix(p.Reference), amount(-p.BillAmount))
+	}
+	for _, n := range bill.CreditNotes {
+		r.row("Credit note "+n.Number, amount(-n.Amount))
+	}
+	if len(bill.Payments) > 0 || len(bill.CreditNotes) > 0 {
+		r.page.SetFont(pdf.HelveticaBold, 10)
+		r.row("Outstanding", amount(bill.GetOutstandingBalance()))
+		r.page.SetFont(pdf.Helvetica, 10)
+	}
+
+	if c := bill.Conversion; c.TargetCurrency != "" && c.TargetCurrency != bill.Currency {
+		r.y -= invoiceLineHeight
+		r.page.SetFont(pdf.HelveticaBold, 10)
+		r.row("Currency conversion", "")
+		r.page.SetFont(pdf.Helvetica, 10)
+		r.row("Rate", fmt.Sprintf("1 %s = %.4f %s", c.BaseCurrency, c.Rate, c.TargetCurrency))
+		r.row("Total in "+string(c.TargetCurrency), currency.FormatString(string(c.TargetCurrency), c.Total))
+	}
+
+	return r.doc.Bytes(), nil
+}
+
+// invoiceRenderer writes invoice lines from the top of the page downwards,
+// starting a new page when the current one is full.
+type invoiceRenderer struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+func newInvoiceRenderer() *invoiceRenderer {
+	r := &invoiceRenderer{doc: pdf.New()}
+	r.newPage()
+	return r
+}
+
+func (r *invoiceRenderer) newPage() {
+	r.page = r.doc.AddPage()
+	r.y = pdf.PageHeight - invoiceMargin - invoiceLineHeight
+}
+
+// next moves to the next line, on a new page keeping the current font
+// when the current page is full.
+func (r *invoiceRenderer) next() {
+	r.y -= invoiceLineHeight
+	if r.y < invoiceMargin {
+		r.newPage()
+	}
+}
+
+// field writes a label and its value on the current line.
+func (r *invoiceRenderer) field(label, value string) {
+	r.page.SetFont(pdf.HelveticaBold, 10)
+	r.page.Text(invoiceMargin, r.y, label)
+	r.page.SetFont(pdf.Helvetica, 10)
+	r.page.Text(invoiceMargin+80, r.y, invoiceText.Replace(value))
+	r.next()
+}
+
+// row writes a label and a right-aligned amount on the current line.
+func (r *invoiceRenderer) row(label, amount string) {
+	r.page.Text(invoiceMargin, r.y, invoiceText.Replace(label))
+	r.page.TextRight(invoiceRight, r.y, invoiceText.Replace(amount))
+	r.next()
+}
+
+// rule draws a horizontal line below the current line.
+func (r *invoiceRenderer) rule() {
+	r.page.Line(invoiceMargin, r.y-4, invoiceRight, r.y-4)
+	r.next()
+}
+
+func formatInvoiceDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(invoiceDateFormat)
+}
+
+// formatBasisPoints formats a rate in basis points as a percentage, e.g. 725 as "7.25".
+func formatBasisPoints(rate int64) string {
+	s := fmt.Sprintf("%d.%02d", rate/100, rate%100)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// tierLabel describes the units priced by a tier, e.g. "  Tier 2: 1001-5000 + $5.00 flat".
+func tierLabel(tier domain.ItemTier, amount func(int64) string) string {
+	label := fmt.Sprintf("  Tier %d: %d-%d", tier.Tier, tier.FirstUnit, tier.LastUnit)
+	if tier.FlatPrice > 0 {
+		label += " + " + amount(tier.FlatPrice) + " flat"
+	}
+	return label
+}
+
+func referenceSuffix(reference string) string {
+	if reference == "" {
+		return ""
+	}
+	return " (" + reference + ")"
+}
+
+func labelOr(label, fallback string) string {
+	if label == "" {
+		return fallback
+	}
+	return label
+}
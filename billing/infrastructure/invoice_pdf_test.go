@@ -0,0 +1,158 @@
+package infrastructure_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	"encore.app/billing/infrastructure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderInvoicePDF(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	closedAt := time.Date(2025, 3, 2, 18, 30, 0, 0, time.UTC)
+	voidedAt := closedAt
+
+	bill := domain.Bill{
+		BillingID:     "mock-billing-id",
+		InvoiceNumber: "INV-2025-000042",
+		AccountID:     "ACC-1",
+		Status:        domain.BillStatusPartiallyPaid,
+		Currency:      domain.CurrencyUSD,
+		Items: []domain.Item{
+			{ID: 1, Name: "Sparkling (0.5l)", Quantity: 3, Unit: "bottle", UnitPrice: 250, Price: 750},
+			{ID: 2, Name: "Still", Quantity: 1, UnitPrice: 250, Price: 250},
+			{ID: 3, Name: "Broken glass", Quantity: 1, UnitPrice: 999, Price: 999, VoidedAt: &voidedAt},
+		},
+		Taxes: []domain.TaxLine{
+			{Code: "SALES_TAX", Name: "Sales tax", Rate: 725, Base: 1000, Amount: 73},
+		},
+		Payments: []domain.Payment{
+			{ID: 1, Amount: 500, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 500, Reference: "R-1", PaidAt: closedAt},
+		},
+		Conversion: domain.BillExchange{
+			BaseCurrency:   domain.CurrencyUSD,
+			TargetCurrency: domain.CurrencyGEL,
+			Rate:           1 / 0.36,
+			Total:          2981,
+		},
+		CreatedAt: createdAt,
+		ClosedAt:  &closedAt,
+	}
+
+	out, err := infrastructure.RenderInvoicePDF(bill)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-")))
+
+	for _, text := range []string{
+		"(Invoice INV-2025-000042)",
+		"(mock-billing-id)",
+		"(2025-03-01)",
+		"(2025-03-02)",
+		"(Sparkling \\(0.5l\\))",
+		"(3 bottle)",
+		"($2.50)",
+		"($7.50)",
+		"($10.00)",
+		"(Sales tax 7.25%)",
+		"($0.73)",
+		"($10.73)",
+		"(Payment 2025-03-02 \\(R-1\\))",
+		"($-5.00)",
+		"($5.73)",
+		"(1 USD = 2.7778 GEL)",
+		"(GEL 29.81)",
+	} {
+		assert.Contains(t, string(out), text)
+	}
+	assert.NotContains(t, string(out), "Broken glass")
+}
+
+func TestRenderInvoicePDFListsItemTiers(t *testing.T) {
+	closedAt := time.Date(2025, 3, 2, 18, 30, 0, 0, time.UTC)
+	bill := domain.Bill{
+		BillingID: "mock-billing-id",
+		Status:    domain.BillStatusClosed,
+		Currency:  domain.CurrencyUSD,
+		Items: []domain.Item{
+			{ID: 1, Name: "API calls", Quantity: 7333, Unit: "call", Price: 833, Tiers: []domain.ItemTier{
+				{Tier: 1, FirstUnit: 1, LastUnit: 1000, Quantity: 1000, UnitPrice: 0, PerUnits: 100, Amount: 0},
+				{Tier: 2, FirstUnit: 1001, LastUnit: 5000, Quantity: 4000, UnitPrice: 15, PerUnits: 100, Amount: 600},
+				{Tier: 3, FirstUnit: 5001, LastUnit: 7333, Quantity: 2333, UnitPrice: 10, PerUnits: 100, Amount: 233},
+			}},
+			{ID: 2, Name: "Seats", Quantity: 1001, Price: 10510, Tiers: []domain.ItemTier{
+				{Tier: 3, FirstUnit: 1, LastUnit: 1001, Quantity: 1001, UnitPrice: 10, PerUnits: 1, FlatPrice: 500, Amount: 10510},
+			}},
+		},
+		ClosedAt: &closedAt,
+	}
+
+	out, err := infrastructure.RenderInvoicePDF(bill)
+	assert.NoError(t, err)
+
+	for _, text := range []string{
+		"(7333 call)",
+		"($8.33)",
+		"(  Tier 2: 1001-5000)",
+		"($0.15 / 100)",
+		"($6.00)",
+		"(  Tier 3: 5001-7333)",
+		"($2.33)",
+		"(  Tier 3: 1-1001 + $5.00 flat)",
+		"($105.10)",
+	} {
+		assert.Contains(t, string(out), text)
+	}
+}
+
+func TestRenderInvoicePDFShowsWalletCredit(t *testing.T) {
+	closedAt := time.Date(2025, 3, 2, 18, 30, 0, 0, time.UTC)
+	bill := domain.Bill{
+		BillingID:     "mock-billing-id",
+		Status:        domain.BillStatusClosed,
+		Currency:      domain.CurrencyUSD,
+		Items:         []domain.Item{{ID: 1, Name: "Still", Quantity: 4, UnitPrice: 250, Price: 1000}},
+		CreditApplied: 400,
+		ClosedAt:      &closedAt,
+	}
+
+	out, err := infrastructure.RenderInvoicePDF(bill)
+	assert.NoError(t, err)
+
+	for _, text := range []string{
+		"(Wallet credit)",
+		"($-4.00)",
+		"(Amount due)",
+		"($6.00)",
+	} {
+		assert.Contains(t, string(out), text)
+	}
+}
+
+func TestRenderInvoicePDFRejectsUnclosedBills(t *testing.T) {
+	for status, expectedErr := range map[domain.BillStatus]error{
+		domain.BillStatusOpen:   domain.ErrBillNotClosed,
+		domain.BillStatusVoided: domain.ErrBillVoided,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			_, err := infrastructure.RenderInvoicePDF(domain.Bill{BillingID: "mock-billing-id", Status: status})
+			assert.ErrorIs(t, err, expectedErr)
+		})
+	}
+}
+
+func TestRenderInvoicePDFSpansPages(t *testing.T) {
+	closedAt := time.Date(2025, 3, 2, 18, 30, 0, 0, time.UTC)
+	bill := domain.Bill{BillingID: "mock-billing-id", Status: domain.BillStatusClosed, Currency: domain.CurrencyUSD, ClosedAt: &closedAt}
+	for i := 1; i <= 100; i++ {
+		bill.Items = append(bill.Items, domain.Item{ID: int64(i), Name: fmt.Sprintf("Item %d", i), Price: 100})
+	}
+
+	out, err := infrastructure.RenderInvoicePDF(bill)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "/Count 3")
+	assert.Contains(t, string(out), "(Item 100)")
+}
@@ -0,0 +1,13 @@
+package infrastructure_test
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain lets the workflows log through rlog outside of the Encore runtime,
+// which otherwise panics on every call.
+func TestMain(m *testing.M) {
+	os.Setenv("ENCORERUNTIME_NOPANIC", "1")
+	os.Exit(m.Run())
+}
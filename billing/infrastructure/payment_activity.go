@@ -0,0 +1,120 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.app/billing/domain"
+	"encore.app/pkg/conversion"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// paymentRejectedErrorType is the Temporal application error type of
+// provider payments the bill no longer accepts; such errors are not retried.
+const paymentRejectedErrorType = "PaymentRejected"
+
+// PaymentActivities defines the set of Temporal activities that collect the
+// outstanding balance of closed bills through a domain.PaymentProvider.
+// Provider errors are returned as is, so that Temporal retries them.
+type PaymentActivities struct {
+	repository domain.Repository
+	provider   domain.PaymentProvider
+}
+
+// NewPaymentActivity creates a new PaymentActivities instance with the given
+// repository and payment provider.
+func NewPaymentActivity(repository domain.Repository, provider domain.PaymentProvider) domain.PaymentActivities {
+	return &PaymentActivities{
+		repository: repository,
+		provider:   provider,
+	}
+}
+
+// AuthorizePaymentActivity authorizes the payment of a bill with the provider.
+func (a *PaymentActivities) AuthorizePaymentActivity(ctx context.Context, req domain.PaymentRequest) (domain.ProviderPayment, error) {
+	payment, err := a.provider.Authorize(ctx, req)
+	if err != nil {
+		return domain.ProviderPayment{}, fmt.Errorf("authorize payment of bill %s: %w", req.BillingID, err)
+	}
+	return payment, nil
+}
+
+// CapturePaymentActivity captures an authorized payment with the provider.
+func (a *PaymentActivities) CapturePaymentActivity(ctx context.Context, payment domain.ProviderPayment) (domain.ProviderPayment, error) {
+	captured, err := a.provider.Capture(ctx, payment.ID)
+	if err != nil {
+		return domain.ProviderPayment{}, fmt.Errorf("capture payment %s: %w", payment.ID, err)
+	}
+	return captured, nil
+}
+
+// RefundPaymentActivity refunds a captured payment in full with the provider.
+func (a *PaymentActivities) RefundPaymentActivity(ctx context.Context, payment domain.ProviderPayment) (domain.ProviderPayment, error) {
+	refunded, err := a.provider.Refund(ctx, payment.ID, payment.Amount)
+	if err != nil {
+		return domain.ProviderPayment{}, fmt.Errorf("refund payment %s: %w", payment.ID, err)
+	}
+	return refunded, nil
+}
+
+// GetPaymentStatusActivity fetches the current state of a payment from the provider.
+func (a *PaymentActivities) GetPaymentStatusActivity(ctx context.Context, payment domain.ProviderPayment) (domain.ProviderPayment, error) {
+	current, err := a.provider.Status(ctx, payment.ID)
+	if err != nil {
+		return domain.ProviderPayment{}, fmt.Errorf("get status of payment %s: %w", payment.ID, err)
+	}
+	return current, nil
+}
+
+// RecordProviderPaymentActivity records a captured provider payment against
+// its Bill, referenced by the provider payment ID so that retries record it
+// once. Payments the bill no longer accepts, e.g. because it was paid in the
+// meantime, fail without retry.
+func (a *PaymentActivities) RecordProviderPaymentActivity(ctx context.Context, payment domain.ProviderPayment) error {
+	logger := activity.GetLogger(ctx)
+
+	bill, err := a.repository.GetBill(ctx, payment.BillingID)
+	if err != nil {
+		return fmt.Errorf("record payment %s: %w", payment.ID, err)
+	}
+
+	for _, p := range bill.Payments {
+		if p.Reference == payment.ID {
+			logger.Info("provider payment already recorded", "billing_id", bill.BillingID, "payment_id", payment.ID)
+			return nil
+		}
+	}
+
+	billAmount, rate, err := conversion.ConvertAmount(payment.Amount, string(payment.Currency), string(bill.Currency))
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError(err.Error(), paymentRejectedErrorType, err)
+	}
+
+	err = bill.ApplyPayment(domain.Payment{
+		BillingID:  bill.BillingID,
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+		Rate:       rate,
+		BillAmount: billAmount,
+		Reference:  payment.ID,
+		PaidAt:     payment.UpdatedAt,
+	})
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError(err.Error(), paymentRejectedErrorType, err)
+	}
+
+	if err := a.repository.SavePayments(ctx, &bill); err != nil {
+		return fmt.Errorf("record payment %s: %w", payment.ID, err)
+	}
+
+	return nil
+}
+
+// isPaymentRejected returns true if the error was returned by
+// RecordProviderPaymentActivity for a payment the bill does not accept.
+func isPaymentRejected(err error) bool {
+	var appErr *temporal.ApplicationError
+	return errors.As(err, &appErr) && appErr.Type() == paymentRejectedErrorType
+}
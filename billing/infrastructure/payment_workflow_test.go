@@ -0,0 +1,187 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/infrastructure"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+type paymentWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	mockController *gomock.Controller
+	mockRepository *mock_domain.MockRepository
+}
+
+func TestPaymentWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(paymentWorkflowTestSuite))
+}
+
+func (s *paymentWorkflowTestSuite) SetupTest() {
+	s.mockController = gomock.NewController(s.T())
+	s.mockRepository = mock_domain.NewMockRepository(s.mockController)
+}
+
+func (s *paymentWorkflowTestSuite) TearDownTest() {
+	s.mockController.Finish()
+}
+
+// newEnv creates a test environment running the payment workflow against a
+// fake provider configured with options.
+func (s *paymentWorkflowTestSuite) newEnv(options infrastructure.FakePaymentProviderOptions) *testsuite.TestWorkflowEnvironment {
+	provider := infrastructure.NewFakePaymentProvider(options)
+	activities := infrastructure.NewPaymentActivity(s.mockRepository, provider)
+	workflows := infrastructure.NewTemporalWorkflows(nil, activities, 0, 30*time.Minute, nil)
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(workflows.PaymentWorkflow)
+	env.RegisterActivity(activities)
+	env.OnSignalExternalWorkflow(mock.Anything, "dunning-mock-billing-id", "", domain.SignalBillSettled, mock.Anything).Return(nil)
+	return env
+}
+
+func closedBill() domain.Bill {
+	return domain.Bill{
+		BillingID: "mock-billing-id",
+		Status:    domain.BillStatusClosed,
+		Currency:  domain.CurrencyUSD,
+		Items:     []domain.Item{{ID: 1, Price: 1000}},
+	}
+}
+
+var paymentRequest = domain.PaymentRequest{
+	BillingID:      "mock-billing-id",
+	Amount:         1000,
+	Currency:       domain.CurrencyUSD,
+	IdempotencyKey: "mock-billing-id-run",
+}
+
+func (s *paymentWorkflowTestSuite) TestPaymentCapturedAndRecorded() {
+	env := s.newEnv(infrastructure.FakePaymentProviderOptions{})
+
+	s.mockRepository.EXPECT().GetBill(gomock.Any(), "mock-billing-id").Return(closedBill(), nil).Times(1)
+	s.mockRepository.EXPECT().SavePayments(gomock.Any(), gomock.Cond(func(b *domain.Bill) bool {
+		return b.Status == domain.BillStatusPaid && b.Payments[0].Reference == "fake_000001" && b.Payments[0].BillAmount == 1000
+	})).Return(nil).Times(1)
+
+	env.ExecuteWorkflow("PaymentWorkflow", paymentRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var payment domain.ProviderPayment
+	s.NoError(env.GetWorkflowResult(&payment))
+	s.Equal(domain.ProviderPaymentCaptured, payment.Status)
+	s.Equal(int64(1000), payment.Amount)
+	env.AssertExpectations(s.T())
+}
+
+func (s *paymentWorkflowTestSuite) TestPaymentDeclined() {
+	env := s.newEnv(infrastructure.FakePaymentProviderOptions{DeclineReason: "insufficient funds"})
+
+	env.ExecuteWorkflow("PaymentWorkflow", paymentRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var payment domain.ProviderPayment
+	s.NoError(env.GetWorkflowResult(&payment))
+	s.Equal(domain.ProviderPaymentDeclined, payment.Status)
+	s.Equal("insufficient funds", payment.DeclineReason)
+}
+
+func (s *paymentWorkflowTestSuite) TestProviderTimeoutsAreRetried() {
+	env := s.newEnv(infrastructure.FakePaymentProviderOptions{Timeouts: 3})
+
+	s.mockRepository.EXPECT().GetBill(gomock.Any(), "mock-billing-id").Return(closedBill(), nil).Times(1)
+	s.mockRepository.EXPECT().SavePayments(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	env.ExecuteWorkflow("PaymentWorkflow", paymentRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var payment domain.ProviderPayment
+	s.NoError(env.GetWorkflowResult(&payment))
+	s.Equal(domain.ProviderPaymentCaptured, payment.Status)
+}
+
+func (s *paymentWorkflowTestSuite) TestDelayedConfirmationsThroughSignal() {
+	env := s.newEnv(infrastructure.FakePaymentProviderOptions{ConfirmationDelay: time.Hour})
+
+	s.mockRepository.EXPECT().GetBill(gomock.Any(), "mock-billing-id").Return(closedBill(), nil).Times(1)
+	s.mockRepository.EXPECT().SavePayments(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(domain.SignalPaymentConfirmation, domain.ProviderPayment{ID: "fake_999999", Status: domain.ProviderPaymentCaptured})
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(domain.SignalPaymentConfirmation, domain.ProviderPayment{ID: "fake_000001", Status: domain.ProviderPaymentAuthorized})
+	}, 2*time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(domain.SignalPaymentConfirmation, domain.ProviderPayment{ID: "fake_000001", Status: domain.ProviderPaymentCaptured})
+	}, 3*time.Minute)
+
+	env.ExecuteWorkflow("PaymentWorkflow", paymentRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var payment domain.ProviderPayment
+	s.NoError(env.GetWorkflowResult(&payment))
+	s.Equal("fake_000001", payment.ID)
+	s.Equal(domain.ProviderPaymentCaptured, payment.Status)
+}
+
+func (s *paymentWorkflowTestSuite) TestUnconfirmedPaymentFails() {
+	env := s.newEnv(infrastructure.FakePaymentProviderOptions{ConfirmationDelay: time.Hour})
+
+	env.ExecuteWorkflow("PaymentWorkflow", paymentRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.ErrorContains(env.GetWorkflowError(), domain.ErrPaymentNotConfirmed.Error())
+}
+
+func (s *paymentWorkflowTestSuite) TestRejectedPaymentIsRefunded() {
+	env := s.newEnv(infrastructure.FakePaymentProviderOptions{})
+
+	bill := closedBill()
+	bill.Status = domain.BillStatusPaid
+	bill.Payments = []domain.Payment{{ID: 1, Amount: 1000, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 1000, Reference: "R-1"}}
+	s.mockRepository.EXPECT().GetBill(gomock.Any(), "mock-billing-id").Return(bill, nil).Times(1)
+
+	env.ExecuteWorkflow("PaymentWorkflow", paymentRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var payment domain.ProviderPayment
+	s.NoError(env.GetWorkflowResult(&payment))
+	s.Equal(domain.ProviderPaymentRefunded, payment.Status)
+}
+
+func (s *paymentWorkflowTestSuite) TestAlreadyRecordedPaymentIsNotRecordedTwice() {
+	env := s.newEnv(infrastructure.FakePaymentProviderOptions{})
+
+	bill := closedBill()
+	bill.Status = domain.BillStatusPaid
+	bill.Payments = []domain.Payment{{ID: 1, Amount: 1000, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 1000, Reference: "fake_000001"}}
+	s.mockRepository.EXPECT().GetBill(gomock.Any(), "mock-billing-id").Return(bill, nil).Times(1)
+
+	env.ExecuteWorkflow("PaymentWorkflow", paymentRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var payment domain.ProviderPayment
+	s.NoError(env.GetWorkflowResult(&payment))
+	s.Equal(domain.ProviderPaymentCaptured, payment.Status)
+}
@@ -2,7 +2,9 @@ package infrastructure
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"encore.app/billing/domain"
 	"encore.dev/storage/sqldb"
@@ -18,134 +20,2098 @@ func NewRepository(db *sqldb.Database) domain.Repository {
 	return &repository{db: db}
 }
 
+// Account operations
+
+func (r *repository) SaveAccount(ctx context.Context, account *domain.Account) error {
+	const q = `
+	INSERT INTO accounts (account_id, name, email, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.Exec(ctx, q,
+		account.ID,
+		account.Name,
+		account.Email,
+		account.CreatedAt,
+		account.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save account: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) UpdateAccount(ctx context.Context, account *domain.Account) error {
+	const q = `
+	UPDATE accounts
+		SET name = $2,
+			email = $3,
+			updated_at = $4
+	WHERE account_id = $1
+	RETURNING created_at
+	`
+
+	err := r.db.QueryRow(ctx, q, account.ID, account.Name, account.Email, account.UpdatedAt).Scan(&account.CreatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.ErrAccountNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) GetAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	const q = `
+	SELECT account_id, name, email, created_at, updated_at
+	FROM accounts
+	WHERE account_id = $1
+	`
+
+	var account domain.Account
+	err := r.db.QueryRow(ctx, q, accountID).Scan(
+		&account.ID,
+		&account.Name,
+		&account.Email,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.Account{}, domain.ErrAccountNotFound
+	}
+	if err != nil {
+		return domain.Account{}, fmt.Errorf("failed to get account: %w", err)
+	}
+	return account, nil
+}
+
+// DeleteAccount deletes an account. Accounts owning bills cannot be
+// deleted, their bills must stay attributable to a customer.
+func (r *repository) DeleteAccount(ctx context.Context, accountID string) error {
+	const lockQuery = `
+	SELECT id FROM accounts WHERE account_id = $1 FOR UPDATE
+	`
+
+	const billsQuery = `
+	SELECT EXISTS (SELECT 1 FROM bills WHERE account_id = $1)
+	`
+
+	const creditQuery = `
+	SELECT EXISTS (SELECT 1 FROM wallets WHERE account_id = $1 AND balance > 0)
+	`
+
+	const q = `
+	DELETE FROM accounts WHERE account_id = $1
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var id int64
+	err = tx.QueryRow(ctx, lockQuery, accountID).Scan(&id)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.ErrAccountNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	var hasBills bool
+	if err := tx.QueryRow(ctx, billsQuery, accountID).Scan(&hasBills); err != nil {
+		return fmt.Errorf("failed to check account bills: %w", err)
+	}
+	if hasBills {
+		return domain.ErrAccountHasBills
+	}
+
+	// wallets without credit left are deleted with the account
+	var hasCredit bool
+	if err := tx.QueryRow(ctx, creditQuery, accountID).Scan(&hasCredit); err != nil {
+		return fmt.Errorf("failed to check account credit: %w", err)
+	}
+	if hasCredit {
+		return domain.ErrAccountHasCredit
+	}
+
+	if _, err := tx.Exec(ctx, q, accountID); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Wallet operations
+
+// TopUpWallet adds the credit of a top-up entry to the wallet of the account
+// in its currency, creating the wallet on its first top-up, and assigns the
+// ID of the entry.
+func (r *repository) TopUpWallet(ctx context.Context, entry *domain.WalletEntry) error {
+	const walletQuery = `
+	INSERT INTO wallets (account_id, currency, balance, updated_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (account_id, currency) DO UPDATE
+	SET balance = wallets.balance + EXCLUDED.balance,
+	    updated_at = EXCLUDED.updated_at
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(ctx, walletQuery, entry.AccountID, entry.Currency, entry.Amount, entry.CreatedAt); err != nil {
+		return fmt.Errorf("failed to update wallet: %w", err)
+	}
+
+	if err := insertWalletEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetWallet returns the balances of the wallet of an account and its ledger,
+// oldest entry first.
+func (r *repository) GetWallet(ctx context.Context, accountID string) (domain.Wallet, error) {
+	const balancesQuery = `
+	SELECT currency, balance, updated_at
+	FROM wallets
+	WHERE account_id = $1
+	ORDER BY currency
+	`
+
+	const entriesQuery = `
+	SELECT id, account_id, currency, type, amount, COALESCE(bill_id, ''), reference, created_at
+	FROM wallet_entries
+	WHERE account_id = $1
+	ORDER BY id
+	`
+
+	wallet := domain.Wallet{AccountID: accountID}
+
+	rows, err := r.db.Query(ctx, balancesQuery, accountID)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to query wallet balances: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var balance domain.WalletBalance
+		if err := rows.Scan(&balance.Currency, &balance.Balance, &balance.UpdatedAt); err != nil {
+			return domain.Wallet{}, fmt.Errorf("failed to scan wallet balance: %w", err)
+		}
+		wallet.Balances = append(wallet.Balances, balance)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.Wallet{}, fmt.Errorf("rows error: %w", err)
+	}
+
+	entryRows, err := r.db.Query(ctx, entriesQuery, accountID)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to query wallet entries: %w", err)
+	}
+	defer entryRows.Close()
+
+	for entryRows.Next() {
+		var entry domain.WalletEntry
+		if err := entryRows.Scan(
+			&entry.ID,
+			&entry.AccountID,
+			&entry.Currency,
+			&entry.Type,
+			&entry.Amount,
+			&entry.BillingID,
+			&entry.Reference,
+			&entry.CreatedAt,
+		); err != nil {
+			return domain.Wallet{}, fmt.Errorf("failed to scan wallet entry: %w", err)
+		}
+		wallet.Entries = append(wallet.Entries, entry)
+	}
+	if err := entryRows.Err(); err != nil {
+		return domain.Wallet{}, fmt.Errorf("rows error: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// ApplyWalletCredit applies the credit of the wallet of the account of a
+// closing bill, in the bill currency, and returns the credit applied. The
+// wallet is locked while its balance is read and updated, so that bills
+// closing concurrently never take more credit than is available. The credit
+// already held by the bill, e.g. from a previous close attempt, is adjusted
+// rather than applied again, which keeps the operation idempotent. The credit
+// moved is recorded in the wallet ledger at appliedAt.
+func (r *repository) ApplyWalletCredit(ctx context.Context, bill domain.Bill, appliedAt time.Time) (int64, error) {
+	const lockQuery = `
+	SELECT balance
+	FROM wallets
+	WHERE account_id = $1
+	  AND currency = $2
+	FOR UPDATE
+	`
+
+	const billQuery = `
+	UPDATE bills SET credit_applied = $2 WHERE billing_id = $1
+	`
+
+	if bill.AccountID == "" {
+		return 0, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var balance int64
+	err = tx.QueryRow(ctx, lockQuery, bill.AccountID, bill.Currency).Scan(&balance)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock wallet: %w", err)
+	}
+
+	held, err := heldWalletCredit(ctx, tx, bill.BillingID)
+	if err != nil {
+		return 0, err
+	}
+
+	credit := bill.CreditFor(balance, held)
+	if err := moveWalletCredit(ctx, tx, bill, credit-held, appliedAt); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, billQuery, bill.BillingID, credit); err != nil {
+		return 0, fmt.Errorf("failed to update bill credit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit wallet credit: %w", err)
+	}
+
+	return credit, nil
+}
+
+// heldWalletCredit returns the wallet credit a bill holds.
+func heldWalletCredit(ctx context.Context, tx *sqldb.Tx, billingID string) (int64, error) {
+	const q = `
+	SELECT COALESCE(-SUM(amount), 0) FROM wallet_entries WHERE bill_id = $1
+	`
+
+	var held int64
+	if err := tx.QueryRow(ctx, q, billingID).Scan(&held); err != nil {
+		return 0, fmt.Errorf("failed to get bill credit: %w", err)
+	}
+	return held, nil
+}
+
+// moveWalletCredit records the consumption of amount of wallet credit by a
+// bill, or the release of -amount when it is negative, and updates the
+// balance of the wallet, which must be locked.
+func moveWalletCredit(ctx context.Context, tx *sqldb.Tx, bill domain.Bill, amount int64, at time.Time) error {
+	const walletQuery = `
+	UPDATE wallets
+		SET balance = balance - $3,
+			updated_at = $4
+	WHERE account_id = $1
+	  AND currency = $2
+	`
+
+	if amount == 0 {
+		return nil
+	}
+
+	entry := domain.WalletEntry{
+		AccountID: bill.AccountID,
+		Currency:  bill.Currency,
+		Type:      domain.WalletEntryTypeConsumption,
+		Amount:    -amount,
+		BillingID: bill.BillingID,
+		CreatedAt: at,
+	}
+	if amount < 0 {
+		entry.Type = domain.WalletEntryTypeRelease
+	}
+
+	if _, err := tx.Exec(ctx, walletQuery, bill.AccountID, bill.Currency, amount, at); err != nil {
+		return fmt.Errorf("failed to update wallet: %w", err)
+	}
+
+	return insertWalletEntry(ctx, tx, &entry)
+}
+
+// releaseWalletCredit gives the wallet credit held by a voided bill back to
+// the wallet of its account.
+func releaseWalletCredit(ctx context.Context, tx *sqldb.Tx, billingID string, releasedAt time.Time) error {
+	const billQuery = `
+	SELECT COALESCE(account_id, ''), currency FROM bills WHERE billing_id = $1
+	`
+
+	const lockQuery = `
+	SELECT balance FROM wallets WHERE account_id = $1 AND currency = $2 FOR UPDATE
+	`
+
+	const creditQuery = `
+	UPDATE bills SET credit_applied = 0 WHERE billing_id = $1
+	`
+
+	var bill domain.Bill
+	if err := tx.QueryRow(ctx, billQuery, billingID).Scan(&bill.AccountID, &bill.Currency); err != nil {
+		return fmt.Errorf("failed to get bill: %w", err)
+	}
+	bill.BillingID = billingID
+
+	var balance int64
+	err := tx.QueryRow(ctx, lockQuery, bill.AccountID, bill.Currency).Scan(&balance)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock wallet: %w", err)
+	}
+
+	held, err := heldWalletCredit(ctx, tx, billingID)
+	if err != nil {
+		return err
+	}
+
+	if err := moveWalletCredit(ctx, tx, bill, -held, releasedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, creditQuery, billingID); err != nil {
+		return fmt.Errorf("failed to update bill credit: %w", err)
+	}
+	return nil
+}
+
+func insertWalletEntry(ctx context.Context, tx *sqldb.Tx, entry *domain.WalletEntry) error {
+	const q = `
+	INSERT INTO wallet_entries (account_id, currency, type, amount, bill_id, reference, created_at)
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	RETURNING id
+	`
+
+	err := tx.QueryRow(ctx, q,
+		entry.AccountID,
+		entry.Currency,
+		entry.Type,
+		entry.Amount,
+		entry.BillingID,
+		entry.Reference,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save wallet entry: %w", err)
+	}
+	return nil
+}
+
+// Subscription operations
+
+// SaveSubscription inserts a subscription or updates its state, as the
+// subscription workflow persists every change of the subscription.
+func (r *repository) SaveSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	const q = `
+	INSERT INTO subscriptions (subscription_id, account_id, status, currency, region, recurrence, timezone, items,
+		cycle, current_billing_id, current_period_end, created_at, paused_at, cancelled_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $12, $13, $14)
+	ON CONFLICT (subscription_id) DO UPDATE
+	SET status = EXCLUDED.status,
+	    items = EXCLUDED.items,
+	    cycle = EXCLUDED.cycle,
+	    current_billing_id = EXCLUDED.current_billing_id,
+	    current_period_end = EXCLUDED.current_period_end,
+	    paused_at = EXCLUDED.paused_at,
+	    cancelled_at = EXCLUDED.cancelled_at
+	RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, q,
+		subscription.SubscriptionID,
+		subscription.AccountID,
+		subscription.Status,
+		subscription.Currency,
+		subscription.Region,
+		subscription.Recurrence,
+		subscription.Timezone,
+		planItemsValue(subscription.Items),
+		subscription.Cycle,
+		subscription.CurrentBillingID,
+		subscription.CurrentPeriodEnd,
+		subscription.CreatedAt,
+		subscription.PausedAt,
+		subscription.CancelledAt,
+	).Scan(&subscription.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) GetSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	const q = `
+	SELECT id, subscription_id, account_id, status, currency, region, recurrence, timezone, items,
+		cycle, COALESCE(current_billing_id, ''), current_period_end, created_at, paused_at, cancelled_at
+	FROM subscriptions
+	WHERE subscription_id = $1
+	`
+
+	var subscription domain.Subscription
+	err := r.db.QueryRow(ctx, q, subscriptionID).Scan(
+		&subscription.ID,
+		&subscription.SubscriptionID,
+		&subscription.AccountID,
+		&subscription.Status,
+		&subscription.Currency,
+		&subscription.Region,
+		&subscription.Recurrence,
+		&subscription.Timezone,
+		&subscription.Items,
+		&subscription.Cycle,
+		&subscription.CurrentBillingID,
+		&subscription.CurrentPeriodEnd,
+		&subscription.CreatedAt,
+		&subscription.PausedAt,
+		&subscription.CancelledAt,
+	)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.Subscription{}, domain.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// Catalog operations
+
+func (r *repository) SaveProduct(ctx context.Context, product *domain.Product) error {
+	const q = `
+	INSERT INTO catalog_products (product_id, name, description, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.Exec(ctx, q,
+		product.ID,
+		product.Name,
+		product.Description,
+		product.CreatedAt,
+		product.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save product: %w", err)
+	}
+	return nil
+}
+
+// UpdateProduct updates the name and description of a product. Archived
+// products cannot be updated.
+func (r *repository) UpdateProduct(ctx context.Context, product *domain.Product) error {
+	const q = `
+	UPDATE catalog_products
+		SET name = $2,
+			description = $3,
+			updated_at = $4
+	WHERE product_id = $1
+	  AND archived_at IS NULL
+	RETURNING created_at
+	`
+
+	err := r.db.QueryRow(ctx, q, product.ID, product.Name, product.Description, product.UpdatedAt).Scan(&product.CreatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		if _, err := r.GetProduct(ctx, product.ID); err != nil {
+			return err
+		}
+		return domain.ErrProductArchived
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+	return nil
+}
+
+// GetProduct returns a product with its SKUs.
+func (r *repository) GetProduct(ctx context.Context, productID string) (domain.Product, error) {
+	const q = `
+	SELECT product_id, name, description, created_at, updated_at, archived_at
+	FROM catalog_products
+	WHERE product_id = $1
+	`
+
+	var product domain.Product
+	err := r.db.QueryRow(ctx, q, productID).Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+		&product.ArchivedAt,
+	)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.Product{}, domain.ErrProductNotFound
+	}
+	if err != nil {
+		return domain.Product{}, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	skus, err := r.getSKUs(ctx, productID)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	product.SKUs = skus[productID]
+
+	return product, nil
+}
+
+// GetProducts returns all products of the catalog with their SKUs, by name.
+func (r *repository) GetProducts(ctx context.Context) ([]domain.Product, error) {
+	const q = `
+	SELECT product_id, name, description, created_at, updated_at, archived_at
+	FROM catalog_products
+	ORDER BY name, id
+	`
+
+	rows, err := r.db.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []domain.Product
+	for rows.Next() {
+		var product domain.Product
+		if err := rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.Description,
+			&product.CreatedAt,
+			&product.UpdatedAt,
+			&product.ArchivedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	skus, err := r.getSKUs(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		products[i].SKUs = skus[products[i].ID]
+	}
+
+	return products, nil
+}
+
+// getSKUs returns the SKUs of a product, or of all products when productID
+// is empty, by product ID.
+func (r *repository) getSKUs(ctx context.Context, productID string) (map[string][]domain.SKU, error) {
+	const q = `
+	SELECT sku, product_id, name, unit, currency, unit_price, prices, version, created_at, updated_at, archived_at
+	FROM catalog_skus
+	WHERE $1 = '' OR product_id = $1
+	ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, q, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query skus: %w", err)
+	}
+	defer rows.Close()
+
+	skus := make(map[string][]domain.SKU)
+	for rows.Next() {
+		var sku domain.SKU
+		if err := rows.Scan(
+			&sku.Code,
+			&sku.ProductID,
+			&sku.Name,
+			&sku.Unit,
+			&sku.Currency,
+			&sku.UnitPrice,
+			&sku.Prices,
+			&sku.Version,
+			&sku.CreatedAt,
+			&sku.UpdatedAt,
+			&sku.ArchivedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan sku: %w", err)
+		}
+		skus[sku.ProductID] = append(skus[sku.ProductID], sku)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return skus, nil
+}
+
+// ArchiveProduct archives a product and its SKUs, which can then no longer
+// be added to bills.
+func (r *repository) ArchiveProduct(ctx context.Context, productID string, archivedAt time.Time) error {
+	const productQuery = `
+	UPDATE catalog_products
+		SET archived_at = COALESCE(archived_at, $2)
+	WHERE product_id = $1
+	`
+
+	const skusQuery = `
+	UPDATE catalog_skus
+		SET archived_at = COALESCE(archived_at, $2)
+	WHERE product_id = $1
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.Exec(ctx, productQuery, productID, archivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to archive product: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrProductNotFound
+	}
+
+	if _, err := tx.Exec(ctx, skusQuery, productID, archivedAt); err != nil {
+		return fmt.Errorf("failed to archive skus: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SaveSKU creates the first version of a SKU of an active product.
+func (r *repository) SaveSKU(ctx context.Context, sku *domain.SKU) error {
+	const productQuery = `
+	SELECT archived_at IS NOT NULL FROM catalog_products WHERE product_id = $1 FOR SHARE
+	`
+
+	const q = `
+	INSERT INTO catalog_skus (sku, product_id, name, unit, currency, unit_price, prices, version, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, 1, $8, $9)
+	ON CONFLICT (sku) DO NOTHING
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var archived bool
+	err = tx.QueryRow(ctx, productQuery, sku.ProductID).Scan(&archived)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.ErrProductNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+	if archived {
+		return domain.ErrProductArchived
+	}
+
+	result, err := tx.Exec(ctx, q,
+		sku.Code,
+		sku.ProductID,
+		sku.Name,
+		sku.Unit,
+		sku.Currency,
+		sku.UnitPrice,
+		priceListValue(sku.Prices),
+		sku.CreatedAt,
+		sku.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save sku: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrSKUExists
+	}
+
+	sku.Version = 1
+	if err := saveSKUVersion(ctx, tx, sku); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateSKU updates the name, unit and price of a SKU as its next version.
+// Archived SKUs cannot be updated.
+func (r *repository) UpdateSKU(ctx context.Context, sku *domain.SKU) error {
+	const q = `
+	UPDATE catalog_skus
+		SET name = $2,
+			unit = $3,
+			currency = $4,
+			unit_price = $5,
+			prices = $6,
+			version = version + 1,
+			updated_at = $7
+	WHERE sku = $1
+	  AND archived_at IS NULL
+	RETURNING product_id, version, created_at
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = tx.QueryRow(ctx, q,
+		sku.Code,
+		sku.Name,
+		sku.Unit,
+		sku.Currency,
+		sku.UnitPrice,
+		priceListValue(sku.Prices),
+		sku.UpdatedAt,
+	).Scan(&sku.ProductID, &sku.Version, &sku.CreatedAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		if _, err := r.GetSKU(ctx, sku.Code); err != nil {
+			return err
+		}
+		return domain.ErrSKUArchived
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update sku: %w", err)
+	}
+
+	if err := saveSKUVersion(ctx, tx, sku); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// saveSKUVersion records the current version of a SKU.
+func saveSKUVersion(ctx context.Context, tx *sqldb.Tx, sku *domain.SKU) error {
+	const q = `
+	INSERT INTO catalog_sku_versions (sku, version, name, unit, currency, unit_price, prices, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := tx.Exec(ctx, q,
+		sku.Code,
+		sku.Version,
+		sku.Name,
+		sku.Unit,
+		sku.Currency,
+		sku.UnitPrice,
+		priceListValue(sku.Prices),
+		sku.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save sku version: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) GetSKU(ctx context.Context, code string) (domain.SKU, error) {
+	const q = `
+	SELECT sku, product_id, name, unit, currency, unit_price, prices, version, created_at, updated_at, archived_at
+	FROM catalog_skus
+	WHERE sku = $1
+	`
+
+	var sku domain.SKU
+	err := r.db.QueryRow(ctx, q, code).Scan(
+		&sku.Code,
+		&sku.ProductID,
+		&sku.Name,
+		&sku.Unit,
+		&sku.Currency,
+		&sku.UnitPrice,
+		&sku.Prices,
+		&sku.Version,
+		&sku.CreatedAt,
+		&sku.UpdatedAt,
+		&sku.ArchivedAt,
+	)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.SKU{}, domain.ErrSKUNotFound
+	}
+	if err != nil {
+		return domain.SKU{}, fmt.Errorf("failed to get sku: %w", err)
+	}
+	return sku, nil
+}
+
+// GetSKUVersion returns a SKU as it was at the given version.
+func (r *repository) GetSKUVersion(ctx context.Context, code string, version int64) (domain.SKU, error) {
+	const q = `
+	SELECT v.sku, s.product_id, v.name, v.unit, v.currency, v.unit_price, v.prices, v.version, s.created_at, v.created_at
+	FROM catalog_sku_versions v
+	JOIN catalog_skus s ON s.sku = v.sku
+	WHERE v.sku = $1
+	  AND v.version = $2
+	`
+
+	var sku domain.SKU
+	err := r.db.QueryRow(ctx, q, code, version).Scan(
+		&sku.Code,
+		&sku.ProductID,
+		&sku.Name,
+		&sku.Unit,
+		&sku.Currency,
+		&sku.UnitPrice,
+		&sku.Prices,
+		&sku.Version,
+		&sku.CreatedAt,
+		&sku.UpdatedAt,
+	)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.SKU{}, domain.ErrSKUNotFound
+	}
+	if err != nil {
+		return domain.SKU{}, fmt.Errorf("failed to get sku version: %w", err)
+	}
+	return sku, nil
+}
+
+// ArchiveSKU archives a SKU, which can then no longer be added to bills.
+func (r *repository) ArchiveSKU(ctx context.Context, code string, archivedAt time.Time) error {
+	const q = `
+	UPDATE catalog_skus
+		SET archived_at = COALESCE(archived_at, $2)
+	WHERE sku = $1
+	`
+
+	result, err := r.db.Exec(ctx, q, code, archivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to archive sku: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrSKUNotFound
+	}
+	return nil
+}
+
+// Coupon operations
+
+func (r *repository) SaveCoupon(ctx context.Context, coupon *domain.Coupon) error {
+	const q = `
+	INSERT INTO coupons (
+		code, description, discount_type, discount_value, currencies, valid_from, valid_until,
+		max_redemptions, max_redemptions_per_account, created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (code) DO NOTHING
+	`
+
+	result, err := r.db.Exec(ctx, q,
+		coupon.Code,
+		coupon.Description,
+		coupon.DiscountType,
+		coupon.DiscountValue,
+		couponCurrenciesValue(coupon.Currencies),
+		coupon.ValidFrom,
+		coupon.ValidUntil,
+		coupon.MaxRedemptions,
+		coupon.MaxRedemptionsPerAccount,
+		coupon.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save coupon: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrCouponExists
+	}
+	return nil
+}
+
+// GetCoupon returns a coupon with the number of bills it is redeemed by.
+func (r *repository) GetCoupon(ctx context.Context, code string) (domain.Coupon, error) {
+	const q = `
+	SELECT c.code, c.description, c.discount_type, c.discount_value, c.currencies, c.valid_from, c.valid_until,
+		c.max_redemptions, c.max_redemptions_per_account, c.created_at,
+		(SELECT COUNT(*) FROM coupon_redemptions cr WHERE cr.code = c.code AND cr.released_at IS NULL)
+	FROM coupons c
+	WHERE c.code = $1
+	`
+
+	var coupon domain.Coupon
+	err := r.db.QueryRow(ctx, q, code).Scan(
+		&coupon.Code,
+		&coupon.Description,
+		&coupon.DiscountType,
+		&coupon.DiscountValue,
+		&coupon.Currencies,
+		&coupon.ValidFrom,
+		&coupon.ValidUntil,
+		&coupon.MaxRedemptions,
+		&coupon.MaxRedemptionsPerAccount,
+		&coupon.CreatedAt,
+		&coupon.Redemptions,
+	)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return domain.Coupon{}, domain.ErrCouponNotFound
+	}
+	if err != nil {
+		return domain.Coupon{}, fmt.Errorf("failed to get coupon: %w", err)
+	}
+	return coupon, nil
+}
+
+// CountCouponRedemptions returns the number of bills a coupon is redeemed
+// by, overall and by the account.
+func (r *repository) CountCouponRedemptions(ctx context.Context, code string, accountID string) (int64, int64, error) {
+	var redemptions, accountRedemptions int64
+	if err := r.db.QueryRow(ctx, couponRedemptionsQuery, code, accountID).Scan(&redemptions, &accountRedemptions); err != nil {
+		return 0, 0, fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+	return redemptions, accountRedemptions, nil
+}
+
+// couponRedemptionsQuery counts the unreleased redemptions of a coupon,
+// overall and by an account.
+const couponRedemptionsQuery = `
+	SELECT COUNT(*), COUNT(*) FILTER (WHERE account_id = $2)
+	FROM coupon_redemptions
+	WHERE code = $1
+	  AND released_at IS NULL
+	`
+
+// ReserveCoupons reserves a redemption of every coupon applied to a bill
+// and returns the codes of the coupons whose redemption limit is reached,
+// which are removed from the bill and recorded in its audit trail. Coupons
+// are locked while their redemptions are counted, so that concurrent
+// reservations never exceed the limits, and in the order of their codes, so
+// that reservations of bills sharing coupons cannot deadlock. Coupons already
+// reserved for the bill, e.g. before it was reopened, are kept as is.
+func (r *repository) ReserveCoupons(ctx context.Context, bill domain.Bill, reservedAt time.Time) ([]string, error) {
+	const couponQuery = `
+	SELECT code, max_redemptions, max_redemptions_per_account
+	FROM coupons
+	WHERE code = $1
+	FOR UPDATE
+	`
+
+	const reservedQuery = `
+	SELECT EXISTS (
+		SELECT 1 FROM coupon_redemptions WHERE code = $1 AND bill_id = $2 AND released_at IS NULL
+	)
+	`
+
+	const reserveQuery = `
+	INSERT INTO coupon_redemptions (code, bill_id, account_id, reserved_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (code, bill_id)
+	DO UPDATE SET
+		account_id = EXCLUDED.account_id,
+		reserved_at = EXCLUDED.reserved_at,
+		released_at = NULL
+	`
+
+	const removeQuery = `
+	DELETE FROM bill_discounts WHERE bill_id = $1 AND coupon_code = $2
+	`
+
+	const auditQuery = `
+	INSERT INTO bill_audit_logs (bill_id, action, reason, created_at)
+	SELECT $1, $2, $3, $4
+	WHERE NOT EXISTS (
+		SELECT 1 FROM bill_audit_logs WHERE bill_id = $1 AND action = $2 AND reason = $3 AND created_at = $4
+	)
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var rejected []string
+	for _, code := range bill.CouponCodes() {
+		var coupon domain.Coupon
+		err := tx.QueryRow(ctx, couponQuery, code).Scan(&coupon.Code, &coupon.MaxRedemptions, &coupon.MaxRedemptionsPerAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock coupon %s: %w", code, err)
+		}
+
+		var reserved bool
+		if err := tx.QueryRow(ctx, reservedQuery, code, bill.BillingID).Scan(&reserved); err != nil {
+			return nil, fmt.Errorf("failed to get coupon redemption: %w", err)
+		}
+		if reserved {
+			continue
+		}
+
+		var redemptions, accountRedemptions int64
+		if err := tx.QueryRow(ctx, couponRedemptionsQuery, code, bill.AccountID).Scan(&redemptions, &accountRedemptions); err != nil {
+			return nil, fmt.Errorf("failed to count coupon redemptions: %w", err)
+		}
+
+		if !coupon.CanRedeem(redemptions, accountRedemptions) {
+			if _, err := tx.Exec(ctx, removeQuery, bill.BillingID, code); err != nil {
+				return nil, fmt.Errorf("failed to remove coupon %s: %w", code, err)
+			}
+
+			reason := fmt.Sprintf("coupon %s removed: %s", code, domain.ErrCouponExhausted)
+			if _, err := tx.Exec(ctx, auditQuery, bill.BillingID, domain.AuditActionCouponRejected, reason, reservedAt); err != nil {
+				return nil, fmt.Errorf("failed to save audit entry: %w", err)
+			}
+
+			rejected = append(rejected, code)
+			continue
+		}
+
+		if _, err := tx.Exec(ctx, reserveQuery, code, bill.BillingID, bill.AccountID, reservedAt); err != nil {
+			return nil, fmt.Errorf("failed to reserve coupon %s: %w", code, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit coupon reservations: %w", err)
+	}
+
+	return rejected, nil
+}
+
+// releaseCoupons releases the coupon redemptions of a voided bill.
+func releaseCoupons(ctx context.Context, tx *sqldb.Tx, billingID string, releasedAt time.Time) error {
+	const q = `
+	UPDATE coupon_redemptions
+		SET released_at = $2
+	WHERE bill_id = $1
+	  AND released_at IS NULL
+	`
+
+	if _, err := tx.Exec(ctx, q, billingID, releasedAt); err != nil {
+		return fmt.Errorf("failed to release coupons: %w", err)
+	}
+	return nil
+}
+
 // Bill operations
 func (r *repository) GetBill(ctx context.Context, billingID string) (domain.Bill, error) {
 	const q = `
-	SELECT id, billing_id, status, currency, total, created_at, closed_at
+	SELECT id, billing_id, COALESCE(invoice_number, ''), COALESCE(account_id, ''), COALESCE(subscription_id, ''), status, currency, region, total, max_total, period_end, metadata, created_at, closed_at, voided_at, credit_applied
+	FROM bills
+	WHERE billing_id = $1
+	`
+
+	var bill domain.Bill
+	err := r.db.QueryRow(ctx, q, billingID).Scan(
+		&bill.ID,
+		&bill.BillingID,
+		&bill.InvoiceNumber,
+		&bill.AccountID,
+		&bill.SubscriptionID,
+		&bill.Status,
+		&bill.Currency,
+		&bill.Region,
+		&bill.Total,
+		&bill.MaxTotal,
+		&bill.PeriodEnd,
+		&bill.Metadata,
+		&bill.CreatedAt,
+		&bill.ClosedAt,
+		&bill.VoidedAt,
+		&bill.CreditApplied,
+	)
+	if err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to get bill: %w", err)
+	}
+
+	if err := r.loadBillDetails(ctx, &bill); err != nil {
+		return domain.Bill{}, err
+	}
+
+	return bill, nil
+}
+
+// loadBillDetails loads the items, discounts, taxes, payments, credit notes
+// and currency conversion of a bill.
+func (r *repository) loadBillDetails(ctx context.Context, bill *domain.Bill) error {
+	items, err := r.GetItemsByBillID(ctx, bill.BillingID)
+	if err != nil {
+		return fmt.Errorf("failed to get items: %w", err)
+	}
+	bill.Items = items
+
+	discounts, err := r.GetDiscountsByBillID(ctx, bill.BillingID)
+	if err != nil {
+		return fmt.Errorf("failed to get discounts: %w", err)
+	}
+	bill.Discounts = discounts
+
+	taxes, err := r.GetTaxLinesByBillID(ctx, bill.BillingID)
+	if err != nil {
+		return fmt.Errorf("failed to get taxes: %w", err)
+	}
+	bill.Taxes = taxes
+
+	payments, err := r.GetPaymentsByBillID(ctx, bill.BillingID)
+	if err != nil {
+		return fmt.Errorf("failed to get payments: %w", err)
+	}
+	bill.Payments = payments
+
+	creditNotes, err := r.GetCreditNotesByBillID(ctx, bill.BillingID)
+	if err != nil {
+		return fmt.Errorf("failed to get credit notes: %w", err)
+	}
+	bill.CreditNotes = creditNotes
+
+	exchange, err := r.GetExchangeByBillID(ctx, bill.BillingID)
+	if err == nil {
+		bill.Conversion = exchange
+	}
+
+	return nil
+}
+
+// GetBillsByAccountID returns the bills owned by an account whose metadata
+// contains every key/value pair of metadata, most recent first.
+func (r *repository) GetBillsByAccountID(ctx context.Context, accountID string, metadata domain.Metadata) ([]domain.Bill, error) {
+	const q = `
+	SELECT id, billing_id, COALESCE(invoice_number, ''), account_id, COALESCE(subscription_id, ''), status, currency, region, total, max_total, period_end, metadata, created_at, closed_at, voided_at, credit_applied
 	FROM bills
+	WHERE account_id = $1
+	  AND metadata @> $2
+	ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.Query(ctx, q, accountID, metadataValue(metadata))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bills: %w", err)
+	}
+	defer rows.Close()
+
+	var bills []domain.Bill
+	for rows.Next() {
+		var bill domain.Bill
+		if err := rows.Scan(
+			&bill.ID,
+			&bill.BillingID,
+			&bill.InvoiceNumber,
+			&bill.AccountID,
+			&bill.SubscriptionID,
+			&bill.Status,
+			&bill.Currency,
+			&bill.Region,
+			&bill.Total,
+			&bill.MaxTotal,
+			&bill.PeriodEnd,
+			&bill.Metadata,
+			&bill.CreatedAt,
+			&bill.ClosedAt,
+			&bill.VoidedAt,
+			&bill.CreditApplied,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan bill: %w", err)
+		}
+		bills = append(bills, bill)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	for i := range bills {
+		if err := r.loadBillDetails(ctx, &bills[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return bills, nil
+}
+
+func (r *repository) SaveBill(ctx context.Context, bill *domain.Bill) error {
+	const q = `
+	INSERT INTO bills (billing_id, account_id, subscription_id, status, currency, region, period_end, metadata, created_at, max_total)
+	VALUES ($1, NULLIF($2, ''), NULLIF($9, ''), $3, $4, $5, $6, $7, $8, $10)
+	ON CONFLICT (billing_id) DO UPDATE
+	SET account_id = EXCLUDED.account_id,
+	    subscription_id = EXCLUDED.subscription_id,
+	    status = EXCLUDED.status,
+	    metadata = EXCLUDED.metadata,
+	    currency = EXCLUDED.currency,
+	    region = EXCLUDED.region,
+	    period_end = EXCLUDED.period_end,
+	    max_total = EXCLUDED.max_total,
+	    created_at = EXCLUDED.created_at
+	RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, q,
+		bill.BillingID,
+		bill.AccountID,
+		bill.Status,
+		bill.Currency,
+		bill.Region,
+		bill.PeriodEnd,
+		metadataValue(bill.Metadata),
+		bill.CreatedAt,
+		bill.SubscriptionID,
+		bill.MaxTotal,
+	).Scan(&bill.ID)
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevertBillClosing sets a bill whose close failed back to OPEN, whether it
+// failed once the usage of the bill was aggregated or once it was closed.
+func (r *repository) RevertBillClosing(ctx context.Context, billingID string) error {
+	const q = `
+	UPDATE bills
+		SET status = 'OPEN'
+	WHERE billing_id = $1
+	  AND status = 'CLOSING'
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(ctx, q, billingID); err != nil {
+		return fmt.Errorf("failed to update bill: %w", err)
+	}
+
+	if _, err := revertBillClosing(ctx, tx, billingID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) ReopenBill(ctx context.Context, entry domain.AuditEntry) error {
+	const q = `
+	INSERT INTO bill_audit_logs (bill_id, action, reason, created_at)
+	VALUES ($1, $2, $3, $4)
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	reverted, err := revertBillClosing(ctx, tx, entry.BillingID)
+	if err != nil {
+		return err
+	}
+	if !reverted {
+		return domain.ErrBillNotClosed
+	}
+
+	if _, err := tx.Exec(ctx, q, entry.BillingID, entry.Action, entry.Reason, entry.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) VoidBilling(ctx context.Context, entry domain.AuditEntry) error {
+	const q = `
+	UPDATE bills
+		SET status = 'VOIDED',
+			voided_at = $2
+	WHERE billing_id = $1
+	  AND status IN ('OPEN', 'CLOSING')
+	`
+
+	const auditQuery = `
+	INSERT INTO bill_audit_logs (bill_id, action, reason, created_at)
+	VALUES ($1, $2, $3, $4)
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.Exec(ctx, q, entry.BillingID, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update bill: %w", err)
+	}
+
+	// an already voided bill is not audited twice, keeping the activity idempotent
+	if result.RowsAffected() == 0 {
+		return nil
+	}
+
+	if err := releaseCoupons(ctx, tx, entry.BillingID, entry.CreatedAt); err != nil {
+		return err
+	}
+
+	if err := releaseWalletCredit(ctx, tx, entry.BillingID, entry.CreatedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, auditQuery, entry.BillingID, entry.Action, entry.Reason, entry.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ExpireBilling records the expiry of an idle bill in its audit trail and
+// voids the bill when it is still open, releasing its coupons and wallet
+// credit. A bill closed by the expiry keeps its status. The audit entry is
+// written once, keeping the activity idempotent.
+func (r *repository) ExpireBilling(ctx context.Context, entry domain.AuditEntry) error {
+	const q = `
+	UPDATE bills
+		SET status = 'VOIDED',
+			voided_at = $2
+	WHERE billing_id = $1
+	  AND status IN ('OPEN', 'CLOSING')
+	`
+
+	const auditQuery = `
+	INSERT INTO bill_audit_logs (bill_id, action, reason, created_at)
+	SELECT $1, $2, $3, $4
+	WHERE NOT EXISTS (
+		SELECT 1 FROM bill_audit_logs WHERE bill_id = $1 AND action = $2 AND created_at = $4
+	)
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.Exec(ctx, q, entry.BillingID, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update bill: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		if err := releaseCoupons(ctx, tx, entry.BillingID, entry.CreatedAt); err != nil {
+			return err
+		}
+		if err := releaseWalletCredit(ctx, tx, entry.BillingID, entry.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, auditQuery, entry.BillingID, entry.Action, entry.Reason, entry.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// RemindBilling records a payment reminder in the audit trail of a bill.
+// The audit entry is written once, keeping the activity idempotent.
+func (r *repository) RemindBilling(ctx context.Context, entry domain.AuditEntry) error {
+	const q = `
+	INSERT INTO bill_audit_logs (bill_id, action, reason, created_at)
+	SELECT $1, $2, $3, $4
+	WHERE NOT EXISTS (
+		SELECT 1 FROM bill_audit_logs WHERE bill_id = $1 AND action = $2 AND created_at = $4
+	)
+	`
+
+	if _, err := r.db.Exec(ctx, q, entry.BillingID, entry.Action, entry.Reason, entry.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// MarkBillingOverdue sets an unpaid closed bill to OVERDUE and records it in
+// its audit trail. Paid, open and voided bills keep their status. The audit
+// entry is written once, keeping the activity idempotent.
+func (r *repository) MarkBillingOverdue(ctx context.Context, entry domain.AuditEntry) error {
+	const q = `
+	UPDATE bills
+		SET status = 'OVERDUE'
+	WHERE billing_id = $1
+	  AND status IN ('CLOSED', 'PARTIALLY_PAID')
+	`
+
+	const auditQuery = `
+	INSERT INTO bill_audit_logs (bill_id, action, reason, created_at)
+	SELECT $1, $2, $3, $4
+	WHERE NOT EXISTS (
+		SELECT 1 FROM bill_audit_logs WHERE bill_id = $1 AND action = $2 AND created_at = $4
+	)
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(ctx, q, entry.BillingID); err != nil {
+		return fmt.Errorf("failed to update bill: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, auditQuery, entry.BillingID, entry.Action, entry.Reason, entry.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save audit entry: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// revertBillClosing sets a closed bill, or one paid by wallet credit alone,
+// back to OPEN and removes the taxes and the currency conversion calculated
+// when it was closed. The bill keeps its wallet credit until it closes again.
+// It reports whether the bill was closed.
+func revertBillClosing(ctx context.Context, tx *sqldb.Tx, billingID string) (bool, error) {
+	const q = `
+	UPDATE bills
+		SET status = 'OPEN',
+			closed_at = null,
+			tax_total = 0,
+			grand_total = 0
 	WHERE billing_id = $1
+	  AND status IN ('CLOSED', 'PAID')
+	  AND NOT EXISTS (SELECT 1 FROM payments WHERE bill_id = $1)
+	`
+
+	const deleteTaxesQuery = `
+	DELETE FROM bill_taxes
+	WHERE bill_id = $1
+	`
+
+	const deleteExchangesQuery = `
+	DELETE FROM bill_exchanges
+	WHERE bill_id = $1
+	`
+
+	result, err := tx.Exec(ctx, q, billingID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update bill: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, deleteTaxesQuery, billingID); err != nil {
+		return false, fmt.Errorf("failed to delete taxes: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, deleteExchangesQuery, billingID); err != nil {
+		return false, fmt.Errorf("failed to delete exchanges: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *repository) GetAuditEntriesByBillID(ctx context.Context, billID string) ([]domain.AuditEntry, error) {
+	const q = `
+	SELECT id, bill_id, action, reason, created_at
+	FROM bill_audit_logs
+	WHERE bill_id = $1
+	ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, q, billID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var entry domain.AuditEntry
+		if err := rows.Scan(&entry.ID, &entry.BillingID, &entry.Action, &entry.Reason, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *repository) SaveItem(ctx context.Context, item *domain.Item) error {
+	const q = `
+	INSERT INTO bill_items (bill_id, name, quantity, unit, unit_price, price, idemp_key, metadata, tiers, sku, catalog_version)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	ON CONFLICT (idemp_key)
+	DO UPDATE SET
+		name = EXCLUDED.name,
+		quantity = EXCLUDED.quantity,
+		unit = EXCLUDED.unit,
+		unit_price = EXCLUDED.unit_price,
+		price = EXCLUDED.price,
+		metadata = EXCLUDED.metadata,
+		tiers = EXCLUDED.tiers,
+		sku = EXCLUDED.sku,
+		catalog_version = EXCLUDED.catalog_version
+	RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, q,
+		item.BillingID,
+		item.Name,
+		item.GetQuantity(),
+		item.Unit,
+		item.GetUnitPrice(),
+		item.Amount(),
+		item.IdempotencyKey,
+		metadataValue(item.Metadata),
+		itemTiersValue(item.Tiers),
+		item.SKU,
+		item.CatalogVersion,
+	).Scan(&item.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to save item: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) VoidItem(ctx context.Context, item domain.Item) error {
+	const q = `
+	UPDATE bill_items
+		SET voided_at = COALESCE(voided_at, $3)
+	WHERE id = $1
+	  AND bill_id = $2
+	`
+
+	_, err := r.db.Exec(ctx, q, item.ID, item.BillingID, item.VoidedAt)
+	if err != nil {
+		return fmt.Errorf("failed to void item: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) GetItemsByBillID(ctx context.Context, billID string) ([]domain.Item, error) {
+	const q = `
+	SELECT id, bill_id, name, quantity, unit, unit_price, price, idemp_key, metadata, tiers, sku, catalog_version, voided_at
+	FROM bill_items
+	WHERE bill_id = $1
+	ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, q, billID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []domain.Item
+	for rows.Next() {
+		var item domain.Item
+		if err := rows.Scan(
+			&item.ID,
+			&item.BillingID,
+			&item.Name,
+			&item.Quantity,
+			&item.Unit,
+			&item.UnitPrice,
+			&item.Price,
+			&item.IdempotencyKey,
+			&item.Metadata,
+			&item.Tiers,
+			&item.SKU,
+			&item.CatalogVersion,
+			&item.VoidedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return items, nil
+}
+
+// Usage operations
+
+// SaveUsageEvents records the usage events of an open bill and returns how
+// many of them were new, events already recorded with the same event ID being
+// skipped. The bill row is share-locked, so that no event is recorded once the
+// bill is closed or voided.
+func (r *repository) SaveUsageEvents(ctx context.Context, billingID string, events []domain.UsageEvent) (int64, error) {
+	const lockQuery = `
+	SELECT status FROM bills WHERE billing_id = $1 FOR SHARE
 	`
 
-	var bill domain.Bill
-	err := r.db.QueryRow(ctx, q, billingID).Scan(
-		&bill.ID,
-		&bill.BillingID,
-		&bill.Status,
-		&bill.Currency,
-		&bill.Total,
-		&bill.CreatedAt,
-		&bill.ClosedAt,
-	)
+	const insertQuery = `
+	INSERT INTO usage_events (bill_id, event_id, meter, quantity, occurred_at, recorded_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (bill_id, event_id) DO NOTHING
+	`
+
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return domain.Bill{}, fmt.Errorf("failed to get bill: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status domain.BillStatus
+	if err := tx.QueryRow(ctx, lockQuery, billingID).Scan(&status); err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return 0, domain.ErrBillNotFound
+		}
+		return 0, fmt.Errorf("failed to lock bill: %w", err)
+	}
+
+	// the usage of a closing bill is already aggregated.
+	switch status {
+	case domain.BillStatusOpen:
+	case domain.BillStatusVoided:
+		return 0, domain.ErrBillVoided
+	default:
+		return 0, domain.ErrBillClosed
+	}
+
+	var recorded int64
+	for _, event := range events {
+		result, err := tx.Exec(ctx, insertQuery,
+			billingID,
+			event.EventID,
+			event.Meter,
+			event.Quantity,
+			event.Timestamp,
+			event.RecordedAt,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save usage event: %w", err)
+		}
+		recorded += result.RowsAffected()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit usage events: %w", err)
 	}
+	return recorded, nil
+}
+
+func (r *repository) GetUsageTotalsByBillID(ctx context.Context, billID string) ([]domain.UsageTotal, error) {
+	const q = `
+	SELECT meter, SUM(quantity), COUNT(*), MAX(recorded_at)
+	FROM usage_events
+	WHERE bill_id = $1
+	GROUP BY meter
+	ORDER BY meter
+	`
 
-	items, err := r.GetItemsByBillID(ctx, billingID)
+	rows, err := r.db.Query(ctx, q, billID)
 	if err != nil {
-		return domain.Bill{}, fmt.Errorf("failed to get items: %w", err)
+		return nil, fmt.Errorf("failed to query usage: %w", err)
 	}
-	bill.Items = items
+	defer rows.Close()
 
-	exchange, err := r.GetExchangeByBillID(ctx, billingID)
-	if err == nil {
-		bill.Conversion = exchange
+	var totals []domain.UsageTotal
+	for rows.Next() {
+		var total domain.UsageTotal
+		if err := rows.Scan(&total.Meter, &total.Quantity, &total.Events, &total.LastRecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		totals = append(totals, total)
 	}
 
-	return bill, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return totals, nil
 }
 
-func (r *repository) SaveBill(ctx context.Context, bill *domain.Bill) error {
+func (r *repository) SaveDiscount(ctx context.Context, discount *domain.Discount) error {
 	const q = `
-	INSERT INTO bills (billing_id, status, currency, created_at)
-	VALUES ($1, $2, $3, $4)
-	ON CONFLICT (billing_id) DO UPDATE
-	SET status = EXCLUDED.status,
-	    currency = EXCLUDED.currency,
-	    created_at = EXCLUDED.created_at
+	INSERT INTO bill_discounts (bill_id, item_id, type, value, description, coupon_code, idemp_key, created_at)
+	VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (idemp_key)
+	DO UPDATE SET
+		type = EXCLUDED.type,
+		value = EXCLUDED.value,
+		description = EXCLUDED.description
 	RETURNING id
 	`
 
 	err := r.db.QueryRow(ctx, q,
-		bill.BillingID,
-		bill.Status,
-		bill.Currency,
-		bill.CreatedAt,
-	).Scan(&bill.ID)
+		discount.BillingID,
+		discount.ItemID,
+		discount.Type,
+		discount.Value,
+		discount.Description,
+		discount.CouponCode,
+		discount.IdempotencyKey,
+		discount.CreatedAt,
+	).Scan(&discount.ID)
 
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to save discount: %w", err)
 	}
 	return nil
 }
 
-func (r *repository) RevertBillClosing(ctx context.Context, billingID string) error {
+func (r *repository) GetDiscountsByBillID(ctx context.Context, billID string) ([]domain.Discount, error) {
 	const q = `
-	UPDATE bills
-		SET status = 'OPEN',
-			closed_at = null
-	WHERE billing_id = $1
+	SELECT id, bill_id, COALESCE(item_id, 0), type, value, description, coupon_code, idemp_key, created_at
+	FROM bill_discounts
+	WHERE bill_id = $1
+	ORDER BY id
 	`
 
-	_, err := r.db.Exec(ctx, q, billingID)
-
+	rows, err := r.db.Query(ctx, q, billID)
 	if err != nil {
-		return fmt.Errorf("failed to update bill: %w", err)
+		return nil, fmt.Errorf("failed to query discounts: %w", err)
 	}
-	return nil
+	defer rows.Close()
+
+	var discounts []domain.Discount
+	for rows.Next() {
+		var discount domain.Discount
+		if err := rows.Scan(
+			&discount.ID,
+			&discount.BillingID,
+			&discount.ItemID,
+			&discount.Type,
+			&discount.Value,
+			&discount.Description,
+			&discount.CouponCode,
+			&discount.IdempotencyKey,
+			&discount.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan discount: %w", err)
+		}
+		discounts = append(discounts, discount)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return discounts, nil
 }
 
-func (r *repository) SaveItem(ctx context.Context, item *domain.Item) error {
+func (r *repository) SaveTaxLines(ctx context.Context, bill *domain.Bill) error {
 	const q = `
-	INSERT INTO bill_items (bill_id, name, price, idemp_key)
-	VALUES ($1, $2, $3, $4)
-	ON CONFLICT (idemp_key)
+	INSERT INTO bill_taxes (bill_id, code, name, rate, inclusive, base, amount)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (bill_id, code)
 	DO UPDATE SET
 		name = EXCLUDED.name,
-		price = EXCLUDED.price
+		rate = EXCLUDED.rate,
+		inclusive = EXCLUDED.inclusive,
+		base = EXCLUDED.base,
+		amount = EXCLUDED.amount
 	RETURNING id
 	`
 
-	err := r.db.QueryRow(ctx, q,
-		item.BillingID,
-		item.Name,
-		item.Price,
-		item.IdempotencyKey,
-	).Scan(&item.ID)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i := range bill.Taxes {
+		line := &bill.Taxes[i]
+		err := tx.QueryRow(ctx, q,
+			bill.BillingID,
+			line.Code,
+			line.Name,
+			line.Rate,
+			line.Inclusive,
+			line.Base,
+			line.Amount,
+		).Scan(&line.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save tax line %s: %w", line.Code, err)
+		}
+	}
 
+	return tx.Commit()
+}
+
+func (r *repository) GetTaxLinesByBillID(ctx context.Context, billID string) ([]domain.TaxLine, error) {
+	const q = `
+	SELECT id, bill_id, code, name, rate, inclusive, base, amount
+	FROM bill_taxes
+	WHERE bill_id = $1
+	ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, q, billID)
 	if err != nil {
-		return fmt.Errorf("failed to save item: %w", err)
+		return nil, fmt.Errorf("failed to query taxes: %w", err)
+	}
+	defer rows.Close()
+
+	var taxes []domain.TaxLine
+	for rows.Next() {
+		var line domain.TaxLine
+		if err := rows.Scan(
+			&line.ID,
+			&line.BillingID,
+			&line.Code,
+			&line.Name,
+			&line.Rate,
+			&line.Inclusive,
+			&line.Base,
+			&line.Amount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan tax line: %w", err)
+		}
+		taxes = append(taxes, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return taxes, nil
+}
+
+// lockBillBalance locks the bill row for the rest of the transaction and
+// returns ErrPaymentConflict when payments or credit notes were recorded
+// since the bill was read, so that its balance is never over-settled, and
+// ErrBillNotClosed when the bill was reopened since. A bill that became
+// overdue since stays OVERDUE until it is settled.
+func lockBillBalance(ctx context.Context, tx *sqldb.Tx, bill *domain.Bill) error {
+	const lockQuery = `
+	SELECT status FROM bills WHERE billing_id = $1 FOR UPDATE
+	`
+
+	const balanceQuery = `
+	SELECT
+		(SELECT COALESCE(SUM(bill_amount), 0) FROM payments WHERE bill_id = $1),
+		(SELECT COALESCE(SUM(amount), 0) FROM credit_notes WHERE bill_id = $1)
+	`
+
+	locked := domain.Bill{}
+	if err := tx.QueryRow(ctx, lockQuery, bill.BillingID).Scan(&locked.Status); err != nil {
+		return fmt.Errorf("failed to lock bill: %w", err)
+	}
+	if !locked.IsClosed() {
+		return domain.ErrBillNotClosed
+	}
+
+	var paid, credited int64
+	if err := tx.QueryRow(ctx, balanceQuery, bill.BillingID).Scan(&paid, &credited); err != nil {
+		return fmt.Errorf("failed to get bill balance: %w", err)
+	}
+
+	var persistedPaid, persistedCredited int64
+	for _, p := range bill.Payments {
+		if p.ID != 0 {
+			persistedPaid += p.BillAmount
+		}
 	}
+	for _, n := range bill.CreditNotes {
+		if n.ID != 0 {
+			persistedCredited += n.Amount
+		}
+	}
+	if paid != persistedPaid || credited != persistedCredited {
+		return domain.ErrPaymentConflict
+	}
+
+	if locked.Status == domain.BillStatusOverdue && !bill.IsPaid() {
+		bill.Status = domain.BillStatusOverdue
+	}
+
 	return nil
 }
 
-func (r *repository) GetItemsByBillID(ctx context.Context, billID string) ([]domain.Item, error) {
+// SavePayments persists the payments of the bill that have no ID yet,
+// assigning their IDs, together with the bill status. It returns
+// ErrPaymentConflict when other payments or credit notes were recorded since
+// the bill was read, so that the outstanding balance is never overpaid, and
+// ErrBillNotClosed when the bill was reopened since.
+func (r *repository) SavePayments(ctx context.Context, bill *domain.Bill) error {
+	const insertQuery = `
+	INSERT INTO payments (bill_id, amount, currency, rate, bill_amount, reference, paid_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id
+	`
+
+	const statusQuery = `
+	UPDATE bills SET status = $2 WHERE billing_id = $1
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockBillBalance(ctx, tx, bill); err != nil {
+		return err
+	}
+
+	for i := range bill.Payments {
+		p := &bill.Payments[i]
+		if p.ID != 0 {
+			continue
+		}
+
+		err := tx.QueryRow(ctx, insertQuery,
+			bill.BillingID,
+			p.Amount,
+			p.Currency,
+			p.Rate,
+			p.BillAmount,
+			p.Reference,
+			p.PaidAt,
+		).Scan(&p.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save payment: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, statusQuery, bill.BillingID, bill.Status); err != nil {
+		return fmt.Errorf("failed to update bill status: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) GetPaymentsByBillID(ctx context.Context, billID string) ([]domain.Payment, error) {
 	const q = `
-	SELECT id, bill_id, name, price, idemp_key
-	FROM bill_items
+	SELECT id, bill_id, amount, currency, rate, bill_amount, reference, paid_at
+	FROM payments
 	WHERE bill_id = $1
 	ORDER BY id
 	`
 
 	rows, err := r.db.Query(ctx, q, billID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query items: %w", err)
+		return nil, fmt.Errorf("failed to query payments: %w", err)
 	}
 	defer rows.Close()
 
-	var items []domain.Item
+	var payments []domain.Payment
 	for rows.Next() {
-		var item domain.Item
-		if err := rows.Scan(&item.ID, &item.BillingID, &item.Name, &item.Price, &item.IdempotencyKey); err != nil {
-			return nil, fmt.Errorf("failed to scan item: %w", err)
+		var p domain.Payment
+		if err := rows.Scan(&p.ID, &p.BillingID, &p.Amount, &p.Currency, &p.Rate, &p.BillAmount, &p.Reference, &p.PaidAt); err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
 		}
-		items = append(items, item)
+		payments = append(payments, p)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	return items, nil
+	return payments, nil
+}
+
+// SaveCreditNotes persists the credit notes of the bill that have no ID yet,
+// assigning their IDs, together with their lines and the bill status. Like
+// SavePayments, it returns ErrPaymentConflict when the balance of the bill
+// changed since it was read.
+func (r *repository) SaveCreditNotes(ctx context.Context, bill *domain.Bill) error {
+	const insertQuery = `
+	INSERT INTO credit_notes (number, bill_id, currency, amount, reason, issued_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id
+	`
+
+	const lineQuery = `
+	INSERT INTO credit_note_lines (credit_note_id, item_id, amount)
+	VALUES ($1, NULLIF($2, 0), $3)
+	`
+
+	const statusQuery = `
+	UPDATE bills SET status = $2 WHERE billing_id = $1
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockBillBalance(ctx, tx, bill); err != nil {
+		return err
+	}
+
+	for i := range bill.CreditNotes {
+		n := &bill.CreditNotes[i]
+		if n.ID != 0 {
+			continue
+		}
+
+		err := tx.QueryRow(ctx, insertQuery,
+			n.Number,
+			bill.BillingID,
+			n.Currency,
+			n.Amount,
+			n.Reason,
+			n.IssuedAt,
+		).Scan(&n.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save credit note: %w", err)
+		}
+
+		for _, line := range n.Lines {
+			if _, err := tx.Exec(ctx, lineQuery, n.ID, line.ItemID, line.Amount); err != nil {
+				return fmt.Errorf("failed to save credit note line: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, statusQuery, bill.BillingID, bill.Status); err != nil {
+		return fmt.Errorf("failed to update bill status: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) GetCreditNotesByBillID(ctx context.Context, billID string) ([]domain.CreditNote, error) {
+	const q = `
+	SELECT n.id, n.number, n.bill_id, n.currency, n.amount, n.reason, n.issued_at,
+		COALESCE(l.item_id, 0), l.amount
+	FROM credit_notes n
+	JOIN credit_note_lines l ON l.credit_note_id = n.id
+	WHERE n.bill_id = $1
+	ORDER BY n.id, l.id
+	`
+
+	rows, err := r.db.Query(ctx, q, billID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credit notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []domain.CreditNote
+	for rows.Next() {
+		var n domain.CreditNote
+		var line domain.CreditNoteLine
+		if err := rows.Scan(&n.ID, &n.Number, &n.BillingID, &n.Currency, &n.Amount, &n.Reason, &n.IssuedAt, &line.ItemID, &line.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan credit note: %w", err)
+		}
+		if len(notes) == 0 || notes[len(notes)-1].ID != n.ID {
+			notes = append(notes, n)
+		}
+		last := &notes[len(notes)-1]
+		last.Lines = append(last.Lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return notes, nil
 }
 
 func (r *repository) SaveExchange(ctx context.Context, bill *domain.Bill) error {
@@ -192,32 +2158,161 @@ func (r *repository) GetExchangeByBillID(ctx context.Context, billID string) (do
 	return exchange, nil
 }
 
-func (r *repository) CloseBilling(ctx context.Context, billing domain.Bill) error {
+// MarkBillingClosing moves an open bill to CLOSING before its usage is
+// aggregated. The update waits for the usage being recorded to be committed,
+// and usage recorded afterwards is rejected, so that every event recorded for
+// the bill is billed. Bills that are no longer open keep their status.
+func (r *repository) MarkBillingClosing(ctx context.Context, billingID string) error {
 	const q = `
 	UPDATE bills
-	SET status = 'CLOSED',
-	    closed_at = now(),
-	    total = (
-	      SELECT COALESCE(SUM(price), 0)
-	      FROM bill_items
-	      WHERE bill_items.bill_id = bills.billing_id
-	    )
+		SET status = 'CLOSING'
 	WHERE billing_id = $1
 	  AND status = 'OPEN'
-	RETURNING id, billing_id, status, currency, total, created_at, closed_at
 	`
 
-	err := r.db.QueryRow(ctx, q, billing.BillingID).Scan(
+	if _, err := r.db.Exec(ctx, q, billingID); err != nil {
+		return fmt.Errorf("failed to mark bill closing: %w", err)
+	}
+	return nil
+}
+
+// CloseBilling closes an open bill and assigns its invoice number, the next
+// number of the invoice sequence of the numbering series and closing year.
+// The sequence row stays locked until the bill is closed, so numbers are
+// never skipped nor reused. A bill that already has an invoice number, e.g.
+// because it was reopened, keeps it; a bill that is already closed is left
+// as is, keeping the close idempotent.
+func (r *repository) CloseBilling(ctx context.Context, billing *domain.Bill, numbering domain.InvoiceNumbering) error {
+	const lockQuery = `
+	SELECT status, COALESCE(invoice_number, '')
+	FROM bills
+	WHERE billing_id = $1
+	FOR UPDATE
+	`
+
+	const sequenceQuery = `
+	INSERT INTO invoice_sequences (series, year, last_number)
+	VALUES ($1, $2, 1)
+	ON CONFLICT (series, year) DO UPDATE
+	SET last_number = invoice_sequences.last_number + 1
+	RETURNING last_number
+	`
+
+	const q = `
+	UPDATE bills
+	SET status = $6,
+	    closed_at = now(),
+	    total = $2,
+	    tax_total = $3,
+	    grand_total = $4,
+	    invoice_number = $5
+	WHERE billing_id = $1
+	  AND status IN ('OPEN', 'CLOSING')
+	RETURNING id, billing_id, status, currency, region, total, created_at, closed_at
+	`
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status domain.BillStatus
+	if err := tx.QueryRow(ctx, lockQuery, billing.BillingID).Scan(&status, &billing.InvoiceNumber); err != nil {
+		if errors.Is(err, sqldb.ErrNoRows) {
+			return domain.ErrBillNotFound
+		}
+		return fmt.Errorf("failed to lock bill: %w", err)
+	}
+
+	if status != domain.BillStatusOpen && status != domain.BillStatusClosing {
+		return tx.Commit()
+	}
+
+	if billing.InvoiceNumber == "" {
+		closedAt := time.Now()
+		if billing.ClosedAt != nil {
+			closedAt = *billing.ClosedAt
+		}
+		year := closedAt.UTC().Year()
+
+		var number int64
+		if err := tx.QueryRow(ctx, sequenceQuery, numbering.Series, year).Scan(&number); err != nil {
+			return fmt.Errorf("failed to get next invoice number: %w", err)
+		}
+		billing.InvoiceNumber = numbering.InvoiceNumber(year, number)
+	}
+
+	err = tx.QueryRow(ctx, q,
+		billing.BillingID,
+		billing.Total,
+		billing.GetTaxTotal(),
+		billing.GetGrandTotal(),
+		billing.InvoiceNumber,
+		closedStatus(billing.Status),
+	).Scan(
 		&billing.ID,
 		&billing.BillingID,
 		&billing.Status,
 		&billing.Currency,
+		&billing.Region,
 		&billing.Total,
 		&billing.CreatedAt,
 		&billing.ClosedAt,
 	)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to close bill: %w", err)
 	}
-	return nil
+
+	return tx.Commit()
+}
+
+// closedStatus returns the status a bill is closed with: PAID when it is
+// fully covered by wallet credit, CLOSED otherwise.
+func closedStatus(status domain.BillStatus) domain.BillStatus {
+	if status == domain.BillStatusPaid {
+		return status
+	}
+	return domain.BillStatusClosed
+}
+
+// metadataValue returns the metadata as a JSONB parameter. Missing metadata
+// is stored as an empty object, which also matches every bill as a filter.
+func metadataValue(metadata domain.Metadata) domain.Metadata {
+	if metadata == nil {
+		return domain.Metadata{}
+	}
+	return metadata
+}
+
+// priceListValue stores SKUs sold in a single currency with an empty JSON object.
+func priceListValue(prices domain.PriceList) domain.PriceList {
+	if prices == nil {
+		return domain.PriceList{}
+	}
+	return prices
+}
+
+// couponCurrenciesValue stores coupons valid in every currency with an empty JSON array.
+func couponCurrenciesValue(currencies []domain.Currency) []domain.Currency {
+	if currencies == nil {
+		return []domain.Currency{}
+	}
+	return currencies
+}
+
+// itemTiersValue stores items not priced by tiers with an empty JSON array.
+func itemTiersValue(tiers []domain.ItemTier) []domain.ItemTier {
+	if tiers == nil {
+		return []domain.ItemTier{}
+	}
+	return tiers
+}
+
+// planItemsValue stores subscriptions without items as an empty JSON array.
+func planItemsValue(items []domain.PlanItem) []domain.PlanItem {
+	if items == nil {
+		return []domain.PlanItem{}
+	}
+	return items
 }
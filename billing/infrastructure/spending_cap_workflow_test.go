@@ -0,0 +1,158 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/infrastructure"
+	"encore.app/billing/usecases"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+var cappedBillStart = time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+
+var cappedMeters = domain.NewMeterCatalog([]domain.Meter{
+	{Code: "api_calls", Name: "API calls", Unit: "call", Currency: domain.CurrencyUSD, Price: domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 2}},
+})
+
+type spendingCapWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	mockController *gomock.Controller
+	mockRepository *mock_domain.MockRepository
+	workflows      *infrastructure.Workflows
+	env            *testsuite.TestWorkflowEnvironment
+}
+
+func TestSpendingCapWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(spendingCapWorkflowTestSuite))
+}
+
+func (s *spendingCapWorkflowTestSuite) SetupTest() {
+	s.mockController = gomock.NewController(s.T())
+	s.mockRepository = mock_domain.NewMockRepository(s.mockController)
+
+	activities := infrastructure.NewBillingActivity(s.mockRepository, domain.NewTaxEngine(nil), domain.InvoiceNumbering{}, cappedMeters)
+	s.workflows = infrastructure.NewTemporalWorkflows(activities, nil, 24*time.Hour, 0, nil)
+
+	s.env = s.NewTestWorkflowEnvironment()
+	s.env.SetStartTime(cappedBillStart)
+	s.env.RegisterWorkflow(s.workflows.BillingWorkflow)
+	s.env.RegisterActivity(activities)
+}
+
+func (s *spendingCapWorkflowTestSuite) TearDownTest() {
+	s.mockController.Finish()
+}
+
+func (s *spendingCapWorkflowTestSuite) TestItemsOverTheCapAreIgnored() {
+	bill := &domain.Bill{
+		BillingID: "B-1",
+		AccountID: "Acc-1",
+		Status:    domain.BillStatusOpen,
+		Currency:  domain.CurrencyUSD,
+		Total:     1000,
+		MaxTotal:  1500,
+		Items:     []domain.Item{{ID: 1, BillingID: "B-1", Name: "Espresso", Quantity: 1, UnitPrice: 1000, Price: 1000}},
+		CreatedAt: cappedBillStart,
+	}
+
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().SaveItem(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, item *domain.Item) error {
+			s.Equal("Croissant", item.Name)
+			item.ID = 2
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").Return(nil, nil).Times(1)
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().SaveTaxLines(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	// both items are signalled at once, as the use case checked each of them
+	// against the bill without the other.
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalAddLineItem, domain.Item{BillingID: "B-1", Name: "Croissant", Quantity: 1, UnitPrice: 400, Price: 400})
+		s.env.SignalWorkflow(domain.SignalAddLineItem, domain.Item{BillingID: "B-1", Name: "Cake", Quantity: 1, UnitPrice: 400, Price: 400})
+	}, time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "B-1",
+			ClosedAt:  cappedBillStart.Add(time.Hour),
+			Taxes:     []domain.TaxLine{{BillingID: "B-1", Code: "SALES_TAX", Rate: 1000, Base: 1400, Amount: 140}},
+		})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, bill)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal(domain.BillStatusClosed, closed.Status)
+	s.Len(closed.Items, 2)
+	s.Equal(int64(1400), closed.GetTotal())
+}
+
+func (s *spendingCapWorkflowTestSuite) TestUsageOverTheCapIsNotBilled() {
+	bill := &domain.Bill{
+		BillingID: "B-1",
+		AccountID: "Acc-1",
+		Status:    domain.BillStatusOpen,
+		Currency:  domain.CurrencyUSD,
+		Total:     1000,
+		MaxTotal:  1500,
+		Items:     []domain.Item{{ID: 1, BillingID: "B-1", Name: "Espresso", Quantity: 1, UnitPrice: 1000, Price: 1000}},
+		CreatedAt: cappedBillStart,
+	}
+
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").Return([]domain.UsageTotal{
+		{Meter: "api_calls", Quantity: 400, Events: 4},
+	}, nil).Times(1)
+	s.mockRepository.EXPECT().SaveItem(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, item *domain.Item) error {
+			s.Equal(int64(250), item.Quantity)
+			s.Equal("400", item.Metadata["usageRecorded"])
+			item.ID = 2
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			return nil
+		},
+	).Times(1)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "B-1",
+			ClosedAt:  cappedBillStart.Add(time.Hour),
+		})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, bill)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal(domain.BillStatusClosed, closed.Status)
+	s.Len(closed.Items, 2)
+	s.Equal(int64(1500), closed.GetTotal())
+}
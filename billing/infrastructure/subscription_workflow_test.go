@@ -0,0 +1,364 @@
+package infrastructure_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/infrastructure"
+	"encore.app/billing/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	subscriptionStart     = time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	subscriptionPeriodEnd = time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type subscriptionWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	mockController *gomock.Controller
+	mockRepository *mock_domain.MockRepository
+	workflows      *infrastructure.Workflows
+	env            *testsuite.TestWorkflowEnvironment
+}
+
+func TestSubscriptionWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(subscriptionWorkflowTestSuite))
+}
+
+func (s *subscriptionWorkflowTestSuite) SetupTest() {
+	s.mockController = gomock.NewController(s.T())
+	s.mockRepository = mock_domain.NewMockRepository(s.mockController)
+
+	activities := infrastructure.NewBillingActivity(s.mockRepository, domain.NewTaxEngine(nil), domain.InvoiceNumbering{}, domain.NewMeterCatalog(nil))
+	s.workflows = infrastructure.NewTemporalWorkflows(activities, nil, 0, 0, nil)
+
+	s.env = s.NewTestWorkflowEnvironment()
+	s.env.SetStartTime(subscriptionStart)
+	s.env.RegisterWorkflow(s.workflows.SubscriptionWorkflow)
+	s.env.RegisterWorkflow(s.workflows.BillingWorkflow)
+	s.env.RegisterActivity(activities)
+}
+
+func (s *subscriptionWorkflowTestSuite) TearDownTest() {
+	s.mockController.Finish()
+}
+
+func activeSubscription() *domain.Subscription {
+	return &domain.Subscription{
+		SubscriptionID: "Sub-1",
+		AccountID:      "Acc-1",
+		Status:         domain.SubscriptionStatusActive,
+		Currency:       domain.CurrencyUSD,
+		Recurrence:     domain.RecurrenceEndOfMonth,
+		Items:          []domain.PlanItem{{Name: "Pro plan", UnitPrice: 4900}},
+		CreatedAt:      subscriptionStart,
+	}
+}
+
+// onBillingWorkflow mocks the bill workflows started by the subscription,
+// completing them when their period ends.
+func (s *subscriptionWorkflowTestSuite) onBillingWorkflow(bills *[]domain.Bill) {
+	s.env.OnWorkflow(s.workflows.BillingWorkflow, mock.Anything, mock.Anything).Return(
+		func(ctx workflow.Context, bill *domain.Bill) error {
+			*bills = append(*bills, *bill)
+			return workflow.Sleep(ctx, bill.PeriodEnd.Sub(workflow.Now(ctx)))
+		},
+	)
+}
+
+func (s *subscriptionWorkflowTestSuite) TestCycleOpensBillAndContinuesAsNew() {
+	var saved []domain.Subscription
+	s.mockRepository.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, subscription *domain.Subscription) error {
+			saved = append(saved, *subscription)
+			return nil
+		},
+	).Times(2)
+
+	var bills []domain.Bill
+	s.onBillingWorkflow(&bills)
+
+	s.env.ExecuteWorkflow(s.workflows.SubscriptionWorkflow, activeSubscription())
+
+	s.True(s.env.IsWorkflowCompleted())
+	var continueAsNew *workflow.ContinueAsNewError
+	s.True(errors.As(s.env.GetWorkflowError(), &continueAsNew))
+	s.Equal(subscriptionPeriodEnd, s.env.Now().UTC())
+
+	s.Len(bills, 1)
+	s.Equal("Sub-1-1", bills[0].BillingID)
+	s.Equal("Sub-1", bills[0].SubscriptionID)
+	s.Equal("Acc-1", bills[0].AccountID)
+	s.Equal(subscriptionPeriodEnd, bills[0].PeriodEnd.UTC())
+	s.Len(bills[0].Items, 1)
+	s.Equal("Pro plan", bills[0].Items[0].Name)
+	s.Equal(int64(4900), bills[0].Items[0].Price)
+
+	s.Equal(int64(1), saved[1].Cycle)
+	s.Equal("Sub-1-1", saved[1].CurrentBillingID)
+	s.Equal(subscriptionPeriodEnd, saved[1].CurrentPeriodEnd.UTC())
+}
+
+func (s *subscriptionWorkflowTestSuite) TestCancelCompletesAfterCurrentCycle() {
+	var saved []domain.Subscription
+	s.mockRepository.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, subscription *domain.Subscription) error {
+			saved = append(saved, *subscription)
+			return nil
+		},
+	).Times(3)
+
+	var bills []domain.Bill
+	s.onBillingWorkflow(&bills)
+
+	cancelledAt := subscriptionStart.Add(24 * time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCancelSubscription, usecases.SubscriptionRequest{SubscriptionID: "Sub-1", RequestedAt: cancelledAt})
+	}, 24*time.Hour)
+
+	s.env.ExecuteWorkflow(s.workflows.SubscriptionWorkflow, activeSubscription())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal(subscriptionPeriodEnd, s.env.Now().UTC())
+	s.Len(bills, 1)
+	s.Equal(domain.SubscriptionStatusCancelled, saved[2].Status)
+	s.Equal(cancelledAt, saved[2].CancelledAt.UTC())
+}
+
+func (s *subscriptionWorkflowTestSuite) TestPausedSubscriptionWaitsForResume() {
+	s.mockRepository.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+
+	var bills []domain.Bill
+	s.onBillingWorkflow(&bills)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.Empty(bills)
+		s.env.SignalWorkflow(domain.SignalResumeSubscription, usecases.SubscriptionRequest{SubscriptionID: "Sub-1"})
+	}, 48*time.Hour)
+
+	subscription := activeSubscription()
+	subscription.Status = domain.SubscriptionStatusPaused
+	subscription.PausedAt = &subscriptionStart
+
+	s.env.ExecuteWorkflow(s.workflows.SubscriptionWorkflow, subscription)
+
+	var continueAsNew *workflow.ContinueAsNewError
+	s.True(errors.As(s.env.GetWorkflowError(), &continueAsNew))
+	s.Len(bills, 1)
+	s.Equal(subscriptionStart.Add(48*time.Hour), bills[0].CreatedAt.UTC())
+}
+
+func (s *subscriptionWorkflowTestSuite) TestNextCycleWaitsForPreviousPeriodEnd() {
+	s.mockRepository.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	var bills []domain.Bill
+	s.onBillingWorkflow(&bills)
+
+	// the bill of the first cycle was closed early, on the day it was opened
+	subscription := activeSubscription()
+	subscription.Cycle = 1
+	subscription.CurrentBillingID = "Sub-1-1"
+	subscription.CurrentPeriodEnd = &subscriptionPeriodEnd
+
+	s.env.ExecuteWorkflow(s.workflows.SubscriptionWorkflow, subscription)
+
+	var continueAsNew *workflow.ContinueAsNewError
+	s.True(errors.As(s.env.GetWorkflowError(), &continueAsNew))
+	s.Len(bills, 1)
+	s.Equal("Sub-1-2", bills[0].BillingID)
+	s.Equal(subscriptionPeriodEnd, bills[0].CreatedAt.UTC())
+	s.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), bills[0].PeriodEnd.UTC())
+}
+
+func (s *subscriptionWorkflowTestSuite) TestCancelledSubscriptionCompletes() {
+	s.mockRepository.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	subscription := activeSubscription()
+	subscription.Status = domain.SubscriptionStatusCancelled
+
+	s.env.ExecuteWorkflow(s.workflows.SubscriptionWorkflow, subscription)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *subscriptionWorkflowTestSuite) TestChangePlanProratesOpenBill() {
+	var saved []domain.Subscription
+	s.mockRepository.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, subscription *domain.Subscription) error {
+			saved = append(saved, *subscription)
+			return nil
+		},
+	).Times(3)
+
+	// the bill workflow collects the items added to the bill until its period
+	// ends; it replaces the registered one, as mocked workflows get no signals.
+	var added []domain.Item
+	s.env.RegisterWorkflowWithOptions(
+		func(ctx workflow.Context, bill *domain.Bill) error {
+			addItemCh := workflow.GetSignalChannel(ctx, domain.SignalAddLineItem)
+			periodEnd := workflow.NewTimer(ctx, bill.PeriodEnd.Sub(workflow.Now(ctx)))
+			for ended := false; !ended; {
+				selector := workflow.NewSelector(ctx)
+				selector.AddReceive(addItemCh, func(c workflow.ReceiveChannel, _ bool) {
+					var item domain.Item
+					c.Receive(ctx, &item)
+					added = append(added, item)
+				})
+				selector.AddFuture(periodEnd, func(workflow.Future) { ended = true })
+				selector.Select(ctx)
+			}
+			return nil
+		},
+		workflow.RegisterOptions{Name: "BillingWorkflow", DisableAlreadyRegisteredCheck: true},
+	)
+
+	changedAt := time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC)
+	newPlan := []domain.PlanItem{{Name: "Business plan", UnitPrice: 9900}}
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalChangeSubscriptionPlan, usecases.ChangePlanRequest{
+			SubscriptionID:     "Sub-1",
+			Items:              newPlan,
+			ProrationPrecision: domain.ProrationPrecisionDay,
+			RequestedAt:        changedAt,
+		})
+	}, changedAt.Sub(subscriptionStart))
+
+	s.env.ExecuteWorkflow(s.workflows.SubscriptionWorkflow, activeSubscription())
+
+	var continueAsNew *workflow.ContinueAsNewError
+	s.True(errors.As(s.env.GetWorkflowError(), &continueAsNew))
+	s.Equal(newPlan, saved[2].Items)
+
+	// 8 of the 17 days of the period remain on the day of the change
+	s.Len(added, 2)
+	s.Equal("Sub-1-1", added[0].BillingID)
+	s.Equal("Unused time on Pro plan", added[0].Name)
+	s.Equal(int64(-2306), added[0].Price)
+	s.Equal("Remaining time on Business plan", added[1].Name)
+	s.Equal(int64(4659), added[1].Price)
+	s.Equal("8/17", added[1].Metadata["prorationRemaining"])
+}
+
+func (s *subscriptionWorkflowTestSuite) TestChangePlanWithoutOpenBill() {
+	var saved []domain.Subscription
+	s.mockRepository.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, subscription *domain.Subscription) error {
+			saved = append(saved, *subscription)
+			return nil
+		},
+	).Times(4)
+
+	var bills []domain.Bill
+	s.onBillingWorkflow(&bills)
+
+	// the plan of a paused subscription changes without proration, the
+	// next cycle opening a bill with the new plan once it is resumed.
+	newPlan := []domain.PlanItem{{Name: "Business plan", UnitPrice: 9900}}
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalChangeSubscriptionPlan, usecases.ChangePlanRequest{
+			SubscriptionID:     "Sub-1",
+			Items:              newPlan,
+			ProrationPrecision: domain.ProrationPrecisionSecond,
+			RequestedAt:        subscriptionStart.Add(time.Hour),
+		})
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalResumeSubscription, usecases.SubscriptionRequest{SubscriptionID: "Sub-1"})
+	}, 2*time.Hour)
+
+	subscription := activeSubscription()
+	subscription.Status = domain.SubscriptionStatusPaused
+	subscription.PausedAt = &subscriptionStart
+
+	s.env.ExecuteWorkflow(s.workflows.SubscriptionWorkflow, subscription)
+
+	var continueAsNew *workflow.ContinueAsNewError
+	s.True(errors.As(s.env.GetWorkflowError(), &continueAsNew))
+	s.Equal(newPlan, saved[1].Items)
+	s.Len(bills, 1)
+	s.Len(bills[0].Items, 1)
+	s.Equal("Business plan", bills[0].Items[0].Name)
+	s.Equal(int64(9900), bills[0].Items[0].Price)
+}
+
+func (s *subscriptionWorkflowTestSuite) TestBillingWorkflowSavesProrationCredit() {
+	var saved []domain.Item
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().SaveItem(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, item *domain.Item) error {
+			item.ID = int64(len(saved) + 2)
+			saved = append(saved, *item)
+			return nil
+		},
+	).Times(2)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "Sub-1-1").Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "Sub-1-1").Return(nil, nil).Times(1)
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			return nil
+		},
+	).Times(1)
+
+	// the proration items of a downgrade go through the real activities.
+	bill := &domain.Bill{
+		BillingID:      "Sub-1-1",
+		AccountID:      "Acc-1",
+		SubscriptionID: "Sub-1",
+		Status:         domain.BillStatusOpen,
+		Currency:       domain.CurrencyUSD,
+		Total:          9900,
+		Items:          []domain.Item{{ID: 1, BillingID: "Sub-1-1", Name: "Business plan", Quantity: 1, UnitPrice: 9900, Price: 9900}},
+		CreatedAt:      subscriptionStart,
+	}
+	subscription := activeSubscription()
+	subscription.Items = []domain.PlanItem{{Name: "Business plan", UnitPrice: 9900}}
+	changedAt := time.Date(2025, 1, 24, 12, 0, 0, 0, time.UTC)
+	periodEnd := subscriptionPeriodEnd
+	adjustments, err := subscription.ChangePlan(
+		[]domain.PlanItem{{Name: "Pro plan", UnitPrice: 4900}},
+		&domain.Bill{BillingID: "Sub-1-1", PeriodEnd: &periodEnd, CreatedAt: subscriptionStart},
+		changedAt,
+		domain.ProrationPrecisionDay,
+	)
+	s.Require().NoError(err)
+	s.Require().Len(adjustments, 2)
+
+	s.env.RegisterDelayedCallback(func() {
+		for _, item := range adjustments {
+			s.env.SignalWorkflow(domain.SignalAddLineItem, item)
+		}
+	}, time.Hour)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "Sub-1-1",
+			ClosedAt:  subscriptionStart.Add(2 * time.Hour),
+		})
+	}, 2*time.Hour)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, bill)
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Len(saved, 2)
+	s.Equal("Unused time on Business plan", saved[0].Name)
+	s.Equal(int64(-4659), saved[0].Price)
+	s.Equal(int64(2306), saved[1].Price)
+	s.Len(closed.Items, 3)
+	s.Equal(int64(9900-4659+2306), closed.GetTotal())
+}
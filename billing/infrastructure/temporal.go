@@ -36,6 +36,61 @@ func (t *temporalWorkflowClient) StartWorkflow(ctx context.Context, workflowID s
 	return nil
 }
 
+// ExecuteCreditNoteWorkflow issues a credit note through the credit note
+// workflow and waits for the issued credit note.
+func (t *temporalWorkflowClient) ExecuteCreditNoteWorkflow(ctx context.Context, note domain.CreditNote) (domain.CreditNote, error) {
+	options := client.StartWorkflowOptions{
+		ID:        "credit-note-" + note.Number,
+		TaskQueue: domain.TemporalQueueName,
+	}
+
+	run, err := t.client.ExecuteWorkflow(ctx, options, t.workflows.CreditNoteWorkflow, note)
+	if err != nil {
+		return domain.CreditNote{}, fmt.Errorf("failed to start credit note workflow: %w", err)
+	}
+
+	var issued domain.CreditNote
+	if err := run.Get(ctx, &issued); err != nil {
+		return domain.CreditNote{}, creditNoteError(err)
+	}
+
+	return issued, nil
+}
+
+// StartSubscriptionWorkflow starts the workflow of a new subscription,
+// identified by the subscription ID.
+func (t *temporalWorkflowClient) StartSubscriptionWorkflow(ctx context.Context, subscription *domain.Subscription) error {
+	options := client.StartWorkflowOptions{
+		ID:        subscription.SubscriptionID,
+		TaskQueue: domain.TemporalQueueName,
+	}
+
+	_, err := t.client.ExecuteWorkflow(ctx, options, t.workflows.SubscriptionWorkflow, subscription)
+	if err != nil {
+		return fmt.Errorf("failed to start subscription workflow: %w", err)
+	}
+
+	return nil
+}
+
+// QuerySubscriptionWorkflow queries the current state of a subscription from its workflow
+func (t *temporalWorkflowClient) QuerySubscriptionWorkflow(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	resp, err := t.client.QueryWorkflow(ctx, subscriptionID, "", domain.QueryTypeGetSubscription)
+	if err != nil {
+		if strings.Contains(err.Error(), "workflow not found") {
+			return domain.Subscription{}, domain.ErrWorkflowNotFound
+		}
+		return domain.Subscription{}, fmt.Errorf("failed to query subscription workflow: %w", err)
+	}
+
+	var subscription domain.Subscription
+	if err := resp.Get(&subscription); err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to parse workflow result: %w", err)
+	}
+
+	return subscription, nil
+}
+
 // QueryWorkflow queries a workflow
 func (t *temporalWorkflowClient) QueryWorkflow(ctx context.Context, workflowID string) (domain.Bill, error) {
 	resp, err := t.client.QueryWorkflow(ctx, workflowID, "", domain.QueryTypeGetBilling)
@@ -63,6 +118,14 @@ func (t *temporalWorkflowClient) SignalWorkflow(ctx context.Context, workflowID
 	return nil
 }
 
+// WaitWorkflow waits until the latest run of a workflow completes
+func (t *temporalWorkflowClient) WaitWorkflow(ctx context.Context, workflowID string) error {
+	if err := t.client.GetWorkflow(ctx, workflowID, "").Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to wait for workflow: %w", err)
+	}
+	return nil
+}
+
 // IsWorkflowRunning checks if a workflow is running
 func (t *temporalWorkflowClient) IsWorkflowRunning(ctx context.Context, workflowID string) (bool, error) {
 	_, err := t.client.QueryWorkflow(ctx, workflowID, "", "getBill")
@@ -0,0 +1,359 @@
+package infrastructure_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/infrastructure"
+	"encore.app/billing/usecases"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+var usageBillStart = time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+type usageWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	mockController *gomock.Controller
+	mockRepository *mock_domain.MockRepository
+	workflows      *infrastructure.Workflows
+	env            *testsuite.TestWorkflowEnvironment
+}
+
+func TestUsageWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(usageWorkflowTestSuite))
+}
+
+func (s *usageWorkflowTestSuite) SetupTest() {
+	s.mockController = gomock.NewController(s.T())
+	s.mockRepository = mock_domain.NewMockRepository(s.mockController)
+
+	taxEngine := domain.NewTaxEngine([]domain.TaxRate{
+		{Code: "SALES_TAX", Name: "Sales tax", Currency: domain.CurrencyUSD, Rate: 1000},
+	})
+	meters := domain.NewMeterCatalog([]domain.Meter{
+		{Code: "api_calls", Name: "API calls", Unit: "call", Currency: domain.CurrencyUSD, Price: domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 2}},
+	})
+	activities := infrastructure.NewBillingActivity(s.mockRepository, taxEngine, domain.InvoiceNumbering{}, meters)
+	s.workflows = infrastructure.NewTemporalWorkflows(activities, nil, 24*time.Hour, 0, nil)
+
+	s.env = s.NewTestWorkflowEnvironment()
+	s.env.SetStartTime(usageBillStart)
+	s.env.RegisterWorkflow(s.workflows.BillingWorkflow)
+	s.env.RegisterActivity(activities)
+}
+
+func (s *usageWorkflowTestSuite) TearDownTest() {
+	s.mockController.Finish()
+}
+
+func openMeteredBill(items ...domain.Item) *domain.Bill {
+	return &domain.Bill{
+		BillingID: "B-1",
+		AccountID: "Acc-1",
+		Status:    domain.BillStatusOpen,
+		Currency:  domain.CurrencyUSD,
+		Items:     items,
+		CreatedAt: usageBillStart,
+	}
+}
+
+// expectUsageItem expects the usage of the api_calls meter to be saved as
+// the item with the given ID.
+func (s *usageWorkflowTestSuite) expectUsageItem(id int64, quantity int64) {
+	s.mockRepository.EXPECT().SaveItem(gomock.Any(), &domain.Item{
+		BillingID:      "B-1",
+		Name:           "API calls",
+		Quantity:       quantity,
+		Unit:           "call",
+		UnitPrice:      2,
+		Price:          2 * quantity,
+		IdempotencyKey: "B-1-usage-api_calls",
+	}).DoAndReturn(func(_ any, item *domain.Item) error {
+		item.ID = id
+		return nil
+	}).Times(1)
+}
+
+func (s *usageWorkflowTestSuite) TestCloseBillsUsagePerMeter() {
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").Return([]domain.UsageTotal{
+		{Meter: "api_calls", Quantity: 1500, Events: 3},
+		{Meter: "unknown_meter", Quantity: 10, Events: 1},
+	}, nil).Times(1)
+	s.expectUsageItem(2, 1500)
+
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			bill.InvoiceNumber = "INV-2025-000001"
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().SaveTaxLines(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().SaveExchange(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	// the taxes and conversion requested with the close only cover the setup fee
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "B-1",
+			Currency:  "GEL",
+			ClosedAt:  usageBillStart.Add(time.Hour),
+			Taxes:     []domain.TaxLine{{BillingID: "B-1", Code: "SALES_TAX", Rate: 1000, Base: 1000, Amount: 100}},
+			Exchange: domain.BillExchange{
+				BillID:         "B-1",
+				BaseCurrency:   domain.CurrencyUSD,
+				TargetCurrency: domain.CurrencyGEL,
+				Rate:           2.5,
+				Total:          2750,
+			},
+		})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, openMeteredBill(domain.Item{ID: 1, BillingID: "B-1", Name: "Setup fee", Price: 1000}))
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Len(closed.Items, 2)
+	s.Equal("API calls", closed.Items[1].Name)
+	s.Equal(int64(1000+3000), closed.Total)
+	s.Len(closed.Taxes, 1)
+	s.Equal(int64(4000), closed.Taxes[0].Base)
+	s.Equal(int64(400), closed.Taxes[0].Amount)
+	s.Equal(int64(4400), closed.GetGrandTotal())
+	s.Equal(domain.CurrencyGEL, closed.Conversion.TargetCurrency)
+	s.Equal(int64(11000), closed.Conversion.Total)
+}
+
+func (s *usageWorkflowTestSuite) TestIdleBillWithRecentUsageIsNotExpired() {
+	lastUsageAt := usageBillStart.Add(20 * time.Hour)
+	usage := []domain.UsageTotal{{Meter: "api_calls", Quantity: 40, Events: 40, LastRecordedAt: lastUsageAt}}
+
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").Return(usage, nil).Times(3)
+	s.expectUsageItem(1, 40)
+
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().SaveTaxLines(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().ExpireBilling(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, openMeteredBill())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	// the idle timeout runs from the last usage, not from the bill creation
+	s.Equal(lastUsageAt.Add(24*time.Hour), closed.ClosedAt.UTC())
+	s.Len(closed.Items, 1)
+	s.Equal(int64(80), closed.Total)
+}
+
+func (s *usageWorkflowTestSuite) TestIdleBillWithoutUsageIsVoided() {
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").Return(nil, nil).Times(1)
+	s.mockRepository.EXPECT().ExpireBilling(gomock.Any(), domain.AuditEntry{
+		BillingID: "B-1",
+		Action:    domain.AuditActionExpire,
+		Reason:    "no line item added within 24h0m0s",
+		CreatedAt: usageBillStart.Add(24 * time.Hour),
+	}).Return(nil).Times(1)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, openMeteredBill())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *usageWorkflowTestSuite) TestUsageRecordedAfterAggregationIsRejected() {
+	// the repository keeps the status of the bill and the usage recorded for
+	// it, rejecting usage once the bill is no longer open.
+	status := domain.BillStatusOpen
+	var recorded []domain.UsageEvent
+	recordUsage := func(event domain.UsageEvent) error {
+		_, err := s.mockRepository.SaveUsageEvents(context.Background(), "B-1", []domain.UsageEvent{event})
+		return err
+	}
+
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().SaveUsageEvents(gomock.Any(), "B-1", gomock.Any()).DoAndReturn(
+		func(_ any, _ string, events []domain.UsageEvent) (int64, error) {
+			if status != domain.BillStatusOpen {
+				return 0, domain.ErrBillClosed
+			}
+			recorded = append(recorded, events...)
+			return int64(len(events)), nil
+		},
+	).Times(2)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").DoAndReturn(
+		func(_ any, _ string) error {
+			status = domain.BillStatusClosing
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").DoAndReturn(
+		func(_ any, _ string) ([]domain.UsageTotal, error) {
+			s.Equal(domain.BillStatusClosing, status)
+			total := domain.UsageTotal{Meter: "api_calls"}
+			for _, event := range recorded {
+				total.Quantity += event.Quantity
+				total.Events++
+			}
+			return []domain.UsageTotal{total}, nil
+		},
+	).Times(1)
+	s.expectUsageItem(1, 40)
+
+	// an event recorded once the usage is aggregated, while the bill is
+	// still being closed, is rejected rather than left unbilled.
+	var lateErr error
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, _ domain.Bill, _ time.Time) (int64, error) {
+			lateErr = recordUsage(domain.UsageEvent{EventID: "e-2", Meter: "api_calls", Quantity: 10})
+			return 0, nil
+		},
+	).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			status = domain.BillStatusClosed
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().SaveTaxLines(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.NoError(recordUsage(domain.UsageEvent{EventID: "e-1", Meter: "api_calls", Quantity: 40}))
+	}, 30*time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "B-1",
+			ClosedAt:  usageBillStart.Add(time.Hour),
+		})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, openMeteredBill())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.ErrorIs(lateErr, domain.ErrBillClosed)
+	s.Len(recorded, 1)
+	s.Len(closed.Items, 1)
+	s.Equal(int64(80), closed.Total)
+}
+
+func (s *usageWorkflowTestSuite) TestFailedCloseReopensClosingBill() {
+	// the repository keeps the status of the bill and the usage recorded for
+	// it, rejecting usage once the bill is no longer open.
+	status := domain.BillStatusOpen
+	var recorded []domain.UsageEvent
+	recordUsage := func(event domain.UsageEvent) error {
+		_, err := s.mockRepository.SaveUsageEvents(context.Background(), "B-1", []domain.UsageEvent{event})
+		return err
+	}
+
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().SaveUsageEvents(gomock.Any(), "B-1", gomock.Any()).DoAndReturn(
+		func(_ any, _ string, events []domain.UsageEvent) (int64, error) {
+			if status != domain.BillStatusOpen {
+				return 0, domain.ErrBillClosed
+			}
+			recorded = append(recorded, events...)
+			return int64(len(events)), nil
+		},
+	).Times(2)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").DoAndReturn(
+		func(_ any, _ string) error {
+			status = domain.BillStatusClosing
+			return nil
+		},
+	).Times(2)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").DoAndReturn(
+		func(_ any, _ string) ([]domain.UsageTotal, error) {
+			total := domain.UsageTotal{Meter: "api_calls"}
+			for _, event := range recorded {
+				total.Quantity += event.Quantity
+				total.Events++
+			}
+			return []domain.UsageTotal{total}, nil
+		},
+	).Times(2)
+	s.expectUsageItem(1, 40)
+	s.expectUsageItem(1, 50)
+
+	// the first close fails once the usage is aggregated.
+	s.env.OnActivity("ApplyWalletCreditActivity", mock.Anything, mock.Anything).
+		Return(int64(0), temporal.NewNonRetryableApplicationError("wallet unavailable", "wallet", nil)).Once()
+	s.env.OnActivity("ApplyWalletCreditActivity", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+	s.mockRepository.EXPECT().RevertBillClosing(gomock.Any(), "B-1").DoAndReturn(
+		func(_ any, _ string) error {
+			if status == domain.BillStatusClosing {
+				status = domain.BillStatusOpen
+			}
+			return nil
+		},
+	).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			status = domain.BillStatusClosed
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().SaveTaxLines(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.NoError(recordUsage(domain.UsageEvent{EventID: "e-1", Meter: "api_calls", Quantity: 40}))
+	}, 30*time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "B-1",
+			ClosedAt:  usageBillStart.Add(time.Hour),
+		})
+	}, time.Hour)
+	// the bill is open again after the failed close, and records usage.
+	s.env.RegisterDelayedCallback(func() {
+		s.Equal(domain.BillStatusOpen, status)
+		s.NoError(recordUsage(domain.UsageEvent{EventID: "e-2", Meter: "api_calls", Quantity: 10}))
+	}, 90*time.Minute)
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "B-1",
+			ClosedAt:  usageBillStart.Add(2 * time.Hour),
+		})
+	}, 2*time.Hour)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, openMeteredBill())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal(domain.BillStatusClosed, status)
+	s.Len(recorded, 2)
+	s.Len(closed.Items, 1)
+	s.Equal(int64(100), closed.Total)
+}
@@ -0,0 +1,115 @@
+package infrastructure_test
+
+import (
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/infrastructure"
+	"encore.app/billing/usecases"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+var walletBillStart = time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+type walletWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	mockController *gomock.Controller
+	mockRepository *mock_domain.MockRepository
+	workflows      *infrastructure.Workflows
+	env            *testsuite.TestWorkflowEnvironment
+}
+
+func TestWalletWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(walletWorkflowTestSuite))
+}
+
+func (s *walletWorkflowTestSuite) SetupTest() {
+	s.mockController = gomock.NewController(s.T())
+	s.mockRepository = mock_domain.NewMockRepository(s.mockController)
+
+	activities := infrastructure.NewBillingActivity(s.mockRepository, domain.NewTaxEngine(nil), domain.InvoiceNumbering{}, domain.NewMeterCatalog(nil))
+	s.workflows = infrastructure.NewTemporalWorkflows(activities, nil, 24*time.Hour, 0, nil)
+
+	s.env = s.NewTestWorkflowEnvironment()
+	s.env.SetStartTime(walletBillStart)
+	s.env.RegisterWorkflow(s.workflows.BillingWorkflow)
+	s.env.RegisterActivity(activities)
+}
+
+func (s *walletWorkflowTestSuite) TearDownTest() {
+	s.mockController.Finish()
+}
+
+func openWalletBill() *domain.Bill {
+	return &domain.Bill{
+		BillingID: "B-1",
+		AccountID: "Acc-1",
+		Status:    domain.BillStatusOpen,
+		Currency:  domain.CurrencyUSD,
+		Total:     1000,
+		Items:     []domain.Item{{ID: 1, BillingID: "B-1", Name: "Espresso", Quantity: 1, UnitPrice: 1000, Price: 1000}},
+		CreatedAt: walletBillStart,
+	}
+}
+
+// closeWithCredit closes the bill with the given credit applied from the
+// wallet of its account and returns the bill as it was closed.
+func (s *walletWorkflowTestSuite) closeWithCredit(credit int64) domain.Bill {
+	s.mockRepository.EXPECT().SaveBill(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	s.mockRepository.EXPECT().MarkBillingClosing(gomock.Any(), "B-1").Return(nil).Times(1)
+	s.mockRepository.EXPECT().GetUsageTotalsByBillID(gomock.Any(), "B-1").Return(nil, nil).Times(1)
+	// the credit is recorded at the close time of the bill, whenever the
+	// activity runs.
+	s.mockRepository.EXPECT().ApplyWalletCredit(gomock.Any(), gomock.Any(), walletBillStart.Add(time.Hour)).DoAndReturn(
+		func(_ any, bill domain.Bill, _ time.Time) (int64, error) {
+			s.Equal(int64(1100), bill.GetGrandTotal())
+			return credit, nil
+		},
+	).Times(1)
+
+	var closed domain.Bill
+	s.mockRepository.EXPECT().CloseBilling(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, bill *domain.Bill, _ domain.InvoiceNumbering) error {
+			closed = *bill
+			return nil
+		},
+	).Times(1)
+	s.mockRepository.EXPECT().SaveTaxLines(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "B-1",
+			ClosedAt:  walletBillStart.Add(time.Hour),
+			Taxes:     []domain.TaxLine{{BillingID: "B-1", Code: "SALES_TAX", Rate: 1000, Base: 1000, Amount: 100}},
+		})
+	}, time.Hour)
+
+	s.env.ExecuteWorkflow(s.workflows.BillingWorkflow, openWalletBill())
+
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	return closed
+}
+
+func (s *walletWorkflowTestSuite) TestCloseAppliesPartialCredit() {
+	closed := s.closeWithCredit(400)
+
+	s.Equal(domain.BillStatusClosed, closed.Status)
+	s.Equal(int64(400), closed.CreditApplied)
+	s.Equal(int64(700), closed.GetAmountDue())
+	s.Equal(int64(700), closed.GetOutstandingBalance())
+}
+
+func (s *walletWorkflowTestSuite) TestCloseFullyCoveredByCreditIsPaid() {
+	closed := s.closeWithCredit(1100)
+
+	s.Equal(domain.BillStatusPaid, closed.Status)
+	s.Equal(int64(1100), closed.CreditApplied)
+	s.Equal(int64(0), closed.GetAmountDue())
+}
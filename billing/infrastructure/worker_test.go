@@ -0,0 +1,75 @@
+package infrastructure_test
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/mock/gomock"
+)
+
+// recordingRegistry records the names the worker would register workflows
+// and activities under.
+type recordingRegistry struct {
+	workflows  []string
+	activities []string
+}
+
+func (r *recordingRegistry) RegisterWorkflow(w interface{}) {
+	r.workflows = append(r.workflows, functionName(w))
+}
+
+func (r *recordingRegistry) RegisterWorkflowWithOptions(w interface{}, options workflow.RegisterOptions) {
+	r.workflows = append(r.workflows, options.Name)
+}
+
+func (r *recordingRegistry) RegisterActivity(a interface{}) {
+	r.activities = append(r.activities, functionName(a))
+}
+
+func (r *recordingRegistry) RegisterActivityWithOptions(a interface{}, options activity.RegisterOptions) {
+	r.activities = append(r.activities, options.Name)
+}
+
+// functionName returns the name Temporal registers a function under: the
+// name of the function or method, without its package and receiver.
+func functionName(fn interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func methodNames(iface interface{}) []string {
+	t := reflect.TypeOf(iface).Elem()
+	names := make([]string, 0, t.NumMethod())
+	for i := 0; i < t.NumMethod(); i++ {
+		names = append(names, t.Method(i).Name)
+	}
+	return names
+}
+
+func TestWorkflowsRegisterEveryActivity(t *testing.T) {
+	repository := mock_domain.NewMockRepository(gomock.NewController(t))
+	billingActivities := infrastructure.NewBillingActivity(repository, domain.NewTaxEngine(nil), domain.InvoiceNumbering{}, domain.NewMeterCatalog(nil))
+	paymentActivities := infrastructure.NewPaymentActivity(repository, nil)
+	workflows := infrastructure.NewTemporalWorkflows(billingActivities, paymentActivities, 24*time.Hour, time.Hour, nil)
+
+	registry := &recordingRegistry{}
+	workflows.Register(registry)
+
+	assert.ElementsMatch(t, append(
+		methodNames((*domain.BillingActivities)(nil)),
+		methodNames((*domain.PaymentActivities)(nil))...,
+	), registry.activities)
+	assert.ElementsMatch(t, []string{
+		"BillingWorkflow", "CreditNoteWorkflow", "PaymentWorkflow", "DunningWorkflow", "SubscriptionWorkflow",
+	}, registry.workflows)
+}
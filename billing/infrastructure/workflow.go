@@ -1,32 +1,109 @@
 package infrastructure
 
 import (
+	"fmt"
 	"time"
 
 	"encore.app/billing/domain"
 	"encore.app/billing/usecases"
 	"encore.dev/rlog"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
 	"go.temporal.io/sdk/workflow"
 )
 
 // Workflows defines a set of Temporal workflows that orchestrate
 // and coordinate billing-related activities.
 type Workflows struct {
-	billingActivities domain.BillingActivities
+	billingActivities          domain.BillingActivities
+	paymentActivities          domain.PaymentActivities
+	idleTimeout                time.Duration
+	paymentConfirmationTimeout time.Duration
+	dunningSchedule            []time.Duration
 }
 
 // NewTemporalWorkflows creates and returns a new Workflows instance
-// configured with the given BillingActivities.
-func NewTemporalWorkflows(billingActivities domain.BillingActivities) *Workflows {
+// configured with the given BillingActivities and PaymentActivities.
+// Bills that receive no line item within idleTimeout expire; a zero
+// idleTimeout disables the expiry. Closed bills are charged through the
+// payment activities, unless they are nil, and pending provider payments
+// are given up on when they are not confirmed within paymentConfirmationTimeout.
+// Unpaid bills are reminded at every offset of dunningSchedule after closing
+// and become overdue at its last offset; an empty schedule disables dunning.
+func NewTemporalWorkflows(
+	billingActivities domain.BillingActivities,
+	paymentActivities domain.PaymentActivities,
+	idleTimeout time.Duration,
+	paymentConfirmationTimeout time.Duration,
+	dunningSchedule []time.Duration,
+) *Workflows {
 	return &Workflows{
-		billingActivities: billingActivities,
+		billingActivities:          billingActivities,
+		paymentActivities:          paymentActivities,
+		idleTimeout:                idleTimeout,
+		paymentConfirmationTimeout: paymentConfirmationTimeout,
+		dunningSchedule:            dunningSchedule,
 	}
 }
 
+// Register registers the workflows and every activity they execute with the
+// worker. The payment activities are registered unless they are nil.
+func (w *Workflows) Register(registry worker.Registry) {
+	registry.RegisterWorkflow(w.BillingWorkflow)
+	registry.RegisterWorkflow(w.CreditNoteWorkflow)
+	registry.RegisterWorkflow(w.PaymentWorkflow)
+	registry.RegisterWorkflow(w.DunningWorkflow)
+	registry.RegisterWorkflow(w.SubscriptionWorkflow)
+	registry.RegisterActivity(w.billingActivities.UpsertBillingToDBActivity)
+	registry.RegisterActivity(w.billingActivities.SetBillingToCloseActivity)
+	registry.RegisterActivity(w.billingActivities.InsertLineItemActivity)
+	registry.RegisterActivity(w.billingActivities.VoidLineItemActivity)
+	registry.RegisterActivity(w.billingActivities.GetUsageTotalsActivity)
+	registry.RegisterActivity(w.billingActivities.AggregateUsageActivity)
+	registry.RegisterActivity(w.billingActivities.InsertDiscountActivity)
+	registry.RegisterActivity(w.billingActivities.ReserveCouponsActivity)
+	registry.RegisterActivity(w.billingActivities.ApplyWalletCreditActivity)
+	registry.RegisterActivity(w.billingActivities.CalculateBillTaxesActivity)
+	registry.RegisterActivity(w.billingActivities.InsertBillTaxesActivity)
+	registry.RegisterActivity(w.billingActivities.InsertBillExchangeActivity)
+	registry.RegisterActivity(w.billingActivities.RevertBillCloseActivity)
+	registry.RegisterActivity(w.billingActivities.SetBillingToVoidActivity)
+	registry.RegisterActivity(w.billingActivities.ExpireBillingActivity)
+	registry.RegisterActivity(w.billingActivities.IssueCreditNoteActivity)
+	registry.RegisterActivity(w.billingActivities.RemindUnpaidBillActivity)
+	registry.RegisterActivity(w.billingActivities.SetBillingToOverdueActivity)
+	registry.RegisterActivity(w.billingActivities.UpsertSubscriptionActivity)
+
+	if w.paymentActivities == nil {
+		return
+	}
+	registry.RegisterActivity(w.paymentActivities.AuthorizePaymentActivity)
+	registry.RegisterActivity(w.paymentActivities.CapturePaymentActivity)
+	registry.RegisterActivity(w.paymentActivities.RefundPaymentActivity)
+	registry.RegisterActivity(w.paymentActivities.GetPaymentStatusActivity)
+	registry.RegisterActivity(w.paymentActivities.RecordProviderPaymentActivity)
+}
+
 // BillingWorkflow is a Temporal workflow that manages the lifecycle of a Bill.
-// It handles incoming signals to add line items or close the bill, updates the
-// database via activities, calculates totals, and performs currency conversion
-// when the bill is closed.
+// It handles incoming signals to add or void line items, apply discounts,
+// close or void the bill, updates the database via activities, calculates
+// totals, and persists taxes and currency conversion when the bill is closed.
+// Bills with a period end are closed automatically when the period ends.
+// Line items that would push the total past the spending cap of the bill are
+// ignored.
+// The usage recorded for the bill is billed by one line item per meter when
+// the bill is closed, and a redemption of the coupons applied to it is
+// reserved, removing those over their redemption limit. The wallet credit of
+// the account is applied to the bill before it is closed. Bills that receive
+// neither line item nor usage within the idle timeout expire: they are closed
+// when they have items or usage and voided otherwise. Subscription bills,
+// opened with their plan items, do not expire and are closed at their period
+// end.
+// Once closed, unpaid bills are followed up by a DunningWorkflow and their
+// outstanding balance is charged by a PaymentWorkflow, both started as
+// abandoned children, so that they outlive the bill workflow.
+// The workflow completes once the bill is closed or voided.
 func (w *Workflows) BillingWorkflow(ctx workflow.Context, state *domain.Bill) error {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("starting billing workflows",
@@ -46,6 +123,21 @@ func (w *Workflows) BillingWorkflow(ctx workflow.Context, state *domain.Bill) er
 		return err
 	}
 
+	// items the bill is started with, such as the plan items of a
+	// subscription bill, are persisted before any signal is handled.
+	for i, item := range state.Items {
+		if item.ID != 0 {
+			continue
+		}
+
+		var savedItem domain.Item
+		if err := workflow.ExecuteActivity(ctx, w.billingActivities.InsertLineItemActivity, item).Get(ctx, &savedItem); err != nil {
+			logger.Error("failed to persist initial item", "workflow_id", state.BillingID, "err", err)
+			return err
+		}
+		state.Items[i] = savedItem
+	}
+
 	if err := workflow.SetQueryHandler(ctx, domain.QueryTypeGetBilling, func() (domain.Bill, error) {
 		stateClone := *state
 		stateClone.Total = stateClone.GetTotal()
@@ -59,15 +151,48 @@ func (w *Workflows) BillingWorkflow(ctx workflow.Context, state *domain.Bill) er
 	}
 
 	addItemLineCh := workflow.GetSignalChannel(ctx, domain.SignalAddLineItem)
+	voidItemLineCh := workflow.GetSignalChannel(ctx, domain.SignalVoidLineItem)
+	applyDiscountCh := workflow.GetSignalChannel(ctx, domain.SignalApplyDiscount)
 	closeBillCh := workflow.GetSignalChannel(ctx, domain.SignalCloseBill)
+	voidBillCh := workflow.GetSignalChannel(ctx, domain.SignalVoidBill)
 
 	closeRequested := false
+	voidRequested := false
 	var itemQueue []domain.Item
+	var voidQueue []usecases.VoidItemRequest
+	var discountQueue []domain.Discount
 	var closeBillingRequest usecases.CloseBillRequest
+	var voidBillRequest usecases.VoidBillRequest
+
+	// bills with a billing period are closed automatically in their own
+	// currency by a durable timer, unless they are closed manually before.
+	var periodEndTimer workflow.Future
+	autoCloseRequested := false
+	if state.PeriodEnd != nil && state.PeriodEnd.After(workflow.Now(ctx)) {
+		periodEndTimer = workflow.NewTimer(ctx, state.PeriodEnd.Sub(workflow.Now(ctx)))
+	}
+
+	// the idle timer is not reset on every line item, instead it is re-armed
+	// for the remaining time when it fires after a recent line item.
+	var idleTimer workflow.Future
+	lastItemAt := workflow.Now(ctx)
+	expireRequested := false
+	expired := false
+	if w.idleTimeout > 0 && state.SubscriptionID == "" {
+		idleTimer = workflow.NewTimer(ctx, w.idleTimeout)
+	}
+
+	// a close failing once the usage is aggregated sets the bill back to
+	// OPEN before the next close, so that its usage is recorded again.
+	revertClosing := func() {
+		if err := workflow.ExecuteActivity(ctx, w.billingActivities.RevertBillCloseActivity, state).Get(ctx, nil); err != nil {
+			rlog.Error("failed to revert bill closing", "workflow_id", state.BillingID, "err", err)
+		}
+	}
 
 	for {
-		if state.IsClosed() {
-			rlog.Info("bill is already closed, ignoring all signals", "workflow_id", state.BillingID)
+		if state.IsClosed() || state.IsVoided() {
+			rlog.Info("bill is no longer open, ignoring all signals", "workflow_id", state.BillingID, "status", state.Status)
 			break
 		}
 
@@ -87,6 +212,36 @@ func (w *Workflows) BillingWorkflow(ctx workflow.Context, state *domain.Bill) er
 
 			rlog.Info("received line item signal", "workflow_id", state.BillingID)
 			itemQueue = append(itemQueue, toBeAddedItem)
+			lastItemAt = workflow.Now(ctx)
+		})
+
+		selector.AddReceive(voidItemLineCh, func(c workflow.ReceiveChannel, _ bool) {
+			var message usecases.VoidItemRequest
+			c.Receive(ctx, &message)
+
+			if state.IsClosed() {
+				rlog.Warn("attempted to void item of closed bill",
+					"workflow_id", state.BillingID,
+					"item_id", message.ItemID,
+				)
+				return
+			}
+
+			rlog.Info("received void line item signal", "workflow_id", state.BillingID, "item_id", message.ItemID)
+			voidQueue = append(voidQueue, message)
+		})
+
+		selector.AddReceive(applyDiscountCh, func(c workflow.ReceiveChannel, _ bool) {
+			var discount domain.Discount
+			c.Receive(ctx, &discount)
+
+			if state.IsClosed() {
+				rlog.Warn("attempted to apply discount to closed bill", "workflow_id", state.BillingID)
+				return
+			}
+
+			rlog.Info("received apply discount signal", "workflow_id", state.BillingID)
+			discountQueue = append(discountQueue, discount)
 		})
 
 		selector.AddReceive(closeBillCh, func(c workflow.ReceiveChannel, _ bool) {
@@ -102,10 +257,71 @@ func (w *Workflows) BillingWorkflow(ctx workflow.Context, state *domain.Bill) er
 			closeBillingRequest = message
 		})
 
+		selector.AddReceive(voidBillCh, func(c workflow.ReceiveChannel, _ bool) {
+			var message usecases.VoidBillRequest
+			c.Receive(ctx, &message)
+
+			if state.IsClosed() {
+				rlog.Warn("attempted to void closed bill", "workflow_id", state.BillingID)
+				return
+			}
+
+			voidRequested = true
+			voidBillRequest = message
+		})
+
+		if periodEndTimer != nil {
+			selector.AddFuture(periodEndTimer, func(f workflow.Future) {
+				periodEndTimer = nil
+				if err := f.Get(ctx, nil); err != nil {
+					rlog.Warn("billing period timer failed", "workflow_id", state.BillingID, "err", err)
+					return
+				}
+
+				rlog.Info("billing period ended, closing bill", "workflow_id", state.BillingID)
+				closeRequested = true
+				autoCloseRequested = true
+				closeBillingRequest = usecases.CloseBillRequest{
+					BillingID: state.BillingID,
+					ClosedAt:  workflow.Now(ctx),
+				}
+			})
+		}
+
+		if idleTimer != nil {
+			selector.AddFuture(idleTimer, func(f workflow.Future) {
+				idleTimer = nil
+				if err := f.Get(ctx, nil); err != nil {
+					rlog.Warn("idle timer failed", "workflow_id", state.BillingID, "err", err)
+					return
+				}
+
+				if idle := workflow.Now(ctx).Sub(lastItemAt); idle < w.idleTimeout {
+					idleTimer = workflow.NewTimer(ctx, w.idleTimeout-idle)
+					return
+				}
+
+				rlog.Info("bill is idle, expiring bill", "workflow_id", state.BillingID)
+				expireRequested = true
+			})
+		}
+
 		selector.Select(ctx)
 
 		for _, item := range itemQueue {
-			err := workflow.ExecuteActivity(ctx, w.billingActivities.InsertLineItemActivity, item).Get(ctx, nil)
+			// the cap is checked again against the items added since the
+			// signal was sent, so that no signal can bypass it.
+			if state.ExceedsSpendingCap(item) {
+				rlog.Warn("ignoring item over the spending cap",
+					"workflow_id", state.BillingID,
+					"item", item.Name,
+					"max_total", state.MaxTotal,
+				)
+				continue
+			}
+
+			var savedItem domain.Item
+			err := workflow.ExecuteActivity(ctx, w.billingActivities.InsertLineItemActivity, item).Get(ctx, &savedItem)
 			if err != nil {
 				rlog.Error("failed to persist item to db",
 					"workflow_id", state.BillingID,
@@ -113,21 +329,227 @@ func (w *Workflows) BillingWorkflow(ctx workflow.Context, state *domain.Bill) er
 				)
 				continue
 			}
-			state.AddItem(item)
+			state.AddItem(savedItem)
 		}
 		itemQueue = itemQueue[:0]
 
+		for _, message := range voidQueue {
+			item, found := state.FindItem(message.ItemID)
+			if !found || item.IsVoided() {
+				rlog.Warn("ignoring void of unknown or voided item",
+					"workflow_id", state.BillingID,
+					"item_id", message.ItemID,
+				)
+				continue
+			}
+
+			item.VoidedAt = &message.VoidedAt
+			err := workflow.ExecuteActivity(ctx, w.billingActivities.VoidLineItemActivity, item).Get(ctx, nil)
+			if err != nil {
+				rlog.Error("failed to void item in db",
+					"workflow_id", state.BillingID,
+					"item_id", message.ItemID,
+					"err", err,
+				)
+				continue
+			}
+			_ = state.VoidItem(message.ItemID, message.VoidedAt)
+		}
+		voidQueue = voidQueue[:0]
+
+		for _, discount := range discountQueue {
+			if discount.CouponCode != "" && state.HasCoupon(discount.CouponCode) {
+				rlog.Warn("ignoring coupon already applied",
+					"workflow_id", state.BillingID,
+					"coupon_code", discount.CouponCode,
+				)
+				continue
+			}
+			if !discount.IsBillLevel() {
+				item, found := state.FindItem(discount.ItemID)
+				if !found || item.IsVoided() {
+					rlog.Warn("ignoring discount of unknown or voided item",
+						"workflow_id", state.BillingID,
+						"item_id", discount.ItemID,
+					)
+					continue
+				}
+			}
+
+			var savedDiscount domain.Discount
+			err := workflow.ExecuteActivity(ctx, w.billingActivities.InsertDiscountActivity, discount).Get(ctx, &savedDiscount)
+			if err != nil {
+				rlog.Error("failed to persist discount to db",
+					"workflow_id", state.BillingID,
+					"err", err,
+				)
+				continue
+			}
+			_ = state.ApplyDiscount(savedDiscount)
+		}
+		discountQueue = discountQueue[:0]
+
+		if voidRequested {
+			entry := domain.AuditEntry{
+				BillingID: state.BillingID,
+				Action:    domain.AuditActionVoid,
+				Reason:    voidBillRequest.Reason,
+				CreatedAt: voidBillRequest.VoidedAt,
+			}
+
+			err := workflow.ExecuteActivity(ctx, w.billingActivities.SetBillingToVoidActivity, entry).Get(ctx, nil)
+			if err != nil {
+				rlog.Error("failed to set billing to void", "workflow_id", state.BillingID, "err", err)
+				voidRequested = false
+				continue
+			}
+
+			state.Void(voidBillRequest.VoidedAt)
+			break
+		}
+
+		if expireRequested && !closeRequested {
+			expireRequested = false
+			now := workflow.Now(ctx)
+
+			// usage is recorded without signalling the workflow, so a bill
+			// still receiving usage is not idle.
+			var usage []domain.UsageTotal
+			if err := workflow.ExecuteActivity(ctx, w.billingActivities.GetUsageTotalsActivity, state.BillingID).Get(ctx, &usage); err != nil {
+				rlog.Error("failed to get usage", "workflow_id", state.BillingID, "err", err)
+				idleTimer = workflow.NewTimer(ctx, w.idleTimeout)
+				continue
+			}
+
+			var lastUsageAt time.Time
+			for _, total := range usage {
+				if total.LastRecordedAt.After(lastUsageAt) {
+					lastUsageAt = total.LastRecordedAt
+				}
+			}
+			if idle := now.Sub(lastUsageAt); idle < w.idleTimeout {
+				idleTimer = workflow.NewTimer(ctx, w.idleTimeout-idle)
+				continue
+			}
+
+			if state.HasActiveItems() || len(usage) > 0 {
+				closeRequested = true
+				autoCloseRequested = true
+				expired = true
+				closeBillingRequest = usecases.CloseBillRequest{
+					BillingID: state.BillingID,
+					ClosedAt:  now,
+				}
+			} else {
+				entry := domain.AuditEntry{
+					BillingID: state.BillingID,
+					Action:    domain.AuditActionExpire,
+					Reason:    "no line item added within " + w.idleTimeout.String(),
+					CreatedAt: now,
+				}
+
+				err := workflow.ExecuteActivity(ctx, w.billingActivities.ExpireBillingActivity, entry).Get(ctx, nil)
+				if err != nil {
+					rlog.Error("failed to expire billing", "workflow_id", state.BillingID, "err", err)
+					idleTimer = workflow.NewTimer(ctx, w.idleTimeout)
+					continue
+				}
+
+				state.Void(now)
+				break
+			}
+		}
+
 		if closeRequested {
+			// the usage recorded for the bill is billed by one item per meter.
+			var usageItems []domain.Item
+			if err := workflow.ExecuteActivity(ctx, w.billingActivities.AggregateUsageActivity, state).Get(ctx, &usageItems); err != nil {
+				rlog.Error("failed to aggregate usage", "workflow_id", state.BillingID, "err", err)
+				revertClosing()
+				continue
+			}
+			for _, item := range usageItems {
+				state.SetItem(item)
+			}
+
+			// coupons whose redemption limit was reached by other bills since
+			// they were applied are removed from the bill.
+			var rejectedCoupons []string
+			if len(state.CouponCodes()) > 0 {
+				reservation := domain.CouponReservation{Bill: *state, ReservedAt: closeBillingRequest.ClosedAt}
+				if err := workflow.ExecuteActivity(ctx, w.billingActivities.ReserveCouponsActivity, reservation).Get(ctx, &rejectedCoupons); err != nil {
+					rlog.Error("failed to reserve coupons", "workflow_id", state.BillingID, "err", err)
+					revertClosing()
+					continue
+				}
+				for _, code := range rejectedCoupons {
+					rlog.Warn("removed coupon over its redemption limit",
+						"workflow_id", state.BillingID,
+						"coupon_code", code,
+					)
+					state.RemoveCoupon(code)
+				}
+			}
+			recalculate := len(usageItems) > 0 || len(rejectedCoupons) > 0
+
+			// taxes requested with a manual close do not include the usage
+			// nor the removal of coupons.
+			if autoCloseRequested || recalculate {
+				var taxes []domain.TaxLine
+				if err := workflow.ExecuteActivity(ctx, w.billingActivities.CalculateBillTaxesActivity, state).Get(ctx, &taxes); err != nil {
+					rlog.Error("failed to calculate taxes", "workflow_id", state.BillingID, "err", err)
+					revertClosing()
+					continue
+				}
+				closeBillingRequest.Taxes = taxes
+			}
+
 			state.Conversion = closeBillingRequest.Exchange
+			state.Taxes = closeBillingRequest.Taxes
+			if recalculate && state.Conversion.TargetCurrency != "" {
+				state.Conversion.Total = state.Conversion.Convert(state.GetGrandTotal())
+			}
+
+			// the prepaid credit of the account covers the grand total first.
+			if state.AccountID != "" {
+				var creditApplied int64
+				application := domain.WalletCreditApplication{Bill: *state, AppliedAt: closeBillingRequest.ClosedAt}
+				if err := workflow.ExecuteActivity(ctx, w.billingActivities.ApplyWalletCreditActivity, application).Get(ctx, &creditApplied); err != nil {
+					rlog.Error("failed to apply wallet credit", "workflow_id", state.BillingID, "err", err)
+					state.Conversion = domain.BillExchange{}
+					state.Taxes = nil
+					revertClosing()
+					continue
+				}
+				state.CreditApplied = creditApplied
+			}
 			state.Close(closeBillingRequest.ClosedAt)
 
-			err := workflow.ExecuteActivity(ctx, w.billingActivities.SetBillingToCloseActivity, state).Get(ctx, nil)
+			var invoiceNumber string
+			err := workflow.ExecuteActivity(ctx, w.billingActivities.SetBillingToCloseActivity, state).Get(ctx, &invoiceNumber)
 			if err != nil {
 				rlog.Error("failed to set billing to close", "workflow_id", state.BillingID, "err", err)
 				state.Conversion = domain.BillExchange{}
+				state.Taxes = nil
 				state.Status = domain.BillStatusOpen
+				revertClosing()
 				continue
 			}
+			state.InvoiceNumber = invoiceNumber
+
+			if len(state.Taxes) > 0 {
+				if err := workflow.ExecuteActivity(ctx, w.billingActivities.InsertBillTaxesActivity, state).Get(ctx, nil); err != nil {
+					rlog.Error("failed to set taxes",
+						"workflow_id", state.BillingID,
+						"err", err,
+					)
+					state.Conversion = domain.BillExchange{}
+					state.Taxes = nil
+					state.Status = domain.BillStatusOpen
+					_ = workflow.ExecuteActivity(ctx, w.billingActivities.RevertBillCloseActivity, state)
+					continue
+				}
+			}
 
 			if state.Conversion.TargetCurrency != "" {
 				if err := workflow.ExecuteActivity(ctx, w.billingActivities.InsertBillExchangeActivity, state).Get(ctx, nil); err != nil {
@@ -136,16 +558,469 @@ func (w *Workflows) BillingWorkflow(ctx workflow.Context, state *domain.Bill) er
 						"err", err,
 					)
 					state.Conversion = domain.BillExchange{}
+					state.Taxes = nil
 					state.Status = domain.BillStatusOpen
 					_ = workflow.ExecuteActivity(ctx, w.billingActivities.RevertBillCloseActivity, state)
 					continue
 				}
 			}
 
+			if expired {
+				entry := domain.AuditEntry{
+					BillingID: state.BillingID,
+					Action:    domain.AuditActionExpire,
+					Reason:    "no line item added within " + w.idleTimeout.String(),
+					CreatedAt: closeBillingRequest.ClosedAt,
+				}
+				if err := workflow.ExecuteActivity(ctx, w.billingActivities.ExpireBillingActivity, entry).Get(ctx, nil); err != nil {
+					rlog.Error("failed to record billing expiry", "workflow_id", state.BillingID, "err", err)
+				}
+			}
+
 			break
 		}
 	}
 
+	if state.IsClosed() && state.GetOutstandingBalance() > 0 {
+		if len(w.dunningSchedule) > 0 {
+			w.startDunningWorkflow(ctx, state)
+		}
+		if w.paymentActivities != nil {
+			w.startPaymentWorkflow(ctx, state)
+		}
+	}
+
 	rlog.Info("billing workflow completed", "workflow_id", state.BillingID, "status", state.Status)
 	return nil
 }
+
+// SubscriptionWorkflow is a long-lived Temporal workflow that manages a
+// Subscription. Every run handles one cycle: it starts a BillingWorkflow
+// child for a bill with the plan items, which the child closes at the end of
+// the period, and continues as new once the bill is closed, so that the
+// history of the subscription stays bounded. A cycle starts at the end of
+// the previous one at the earliest. Paused subscriptions start no cycle until
+// they are resumed, and cancelled subscriptions complete once the bill of
+// their current cycle is closed. Plan changes apply from the next cycle on
+// and are prorated on the open bill of the current cycle, if any.
+func (w *Workflows) SubscriptionWorkflow(ctx workflow.Context, state *domain.Subscription) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("starting subscription workflow", "subscription_id", state.SubscriptionID, "cycle", state.Cycle)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	if err := workflow.ExecuteActivity(ctx, w.billingActivities.UpsertSubscriptionActivity, state).Get(ctx, nil); err != nil {
+		logger.Error("failed to persist subscription", "subscription_id", state.SubscriptionID, "err", err)
+		return err
+	}
+
+	if err := workflow.SetQueryHandler(ctx, domain.QueryTypeGetSubscription, func() (domain.Subscription, error) {
+		return *state, nil
+	}); err != nil {
+		logger.Error("SetQueryHandler failed", "subscription_id", state.SubscriptionID, "err", err)
+		return err
+	}
+
+	pauseCh := workflow.GetSignalChannel(ctx, domain.SignalPauseSubscription)
+	resumeCh := workflow.GetSignalChannel(ctx, domain.SignalResumeSubscription)
+	cancelCh := workflow.GetSignalChannel(ctx, domain.SignalCancelSubscription)
+	changePlanCh := workflow.GetSignalChannel(ctx, domain.SignalChangeSubscriptionPlan)
+
+	// the bill of the current cycle and its workflow, while the bill is open.
+	var openBill *domain.Bill
+	var openBillWorkflow workflow.ChildWorkflowFuture
+
+	// change applies a change requested by a signal and persists it; changes
+	// the subscription rejects, e.g. pausing it twice, are ignored.
+	change := func(signal string, apply func() error) {
+		if err := apply(); err != nil {
+			logger.Warn("ignoring subscription signal", "subscription_id", state.SubscriptionID, "signal", signal, "err", err)
+			return
+		}
+		if err := workflow.ExecuteActivity(ctx, w.billingActivities.UpsertSubscriptionActivity, state).Get(ctx, nil); err != nil {
+			logger.Error("failed to persist subscription", "subscription_id", state.SubscriptionID, "signal", signal, "err", err)
+		}
+	}
+
+	addSignals := func(selector workflow.Selector) {
+		selector.AddReceive(pauseCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req usecases.SubscriptionRequest
+			c.Receive(ctx, &req)
+			change(domain.SignalPauseSubscription, func() error { return state.Pause(req.RequestedAt) })
+		})
+		selector.AddReceive(resumeCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req usecases.SubscriptionRequest
+			c.Receive(ctx, &req)
+			change(domain.SignalResumeSubscription, state.Resume)
+		})
+		selector.AddReceive(cancelCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req usecases.SubscriptionRequest
+			c.Receive(ctx, &req)
+			change(domain.SignalCancelSubscription, func() error { return state.Cancel(req.RequestedAt) })
+		})
+		selector.AddReceive(changePlanCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req usecases.ChangePlanRequest
+			c.Receive(ctx, &req)
+
+			var adjustments []domain.Item
+			change(domain.SignalChangeSubscriptionPlan, func() (err error) {
+				adjustments, err = state.ChangePlan(req.Items, openBill, req.RequestedAt, req.ProrationPrecision)
+				return err
+			})
+
+			// the proration is added to the open bill as line items, which
+			// its workflow ignores if the bill has been closed in the meantime.
+			for _, item := range adjustments {
+				if err := openBillWorkflow.SignalChildWorkflow(ctx, domain.SignalAddLineItem, item).Get(ctx, nil); err != nil {
+					logger.Error("failed to add proration item", "subscription_id", state.SubscriptionID, "billing_id", openBill.BillingID, "item", item.Name, "err", err)
+				}
+			}
+		})
+	}
+
+	// wait until the subscription is active and the previous period, whose
+	// bill may have been closed early, has ended.
+	for !state.IsCancelled() {
+		now := workflow.Now(ctx)
+		if state.IsActive() && (state.CurrentPeriodEnd == nil || !now.Before(*state.CurrentPeriodEnd)) {
+			break
+		}
+
+		selector := workflow.NewSelector(ctx)
+		addSignals(selector)
+
+		timerCtx, cancelTimer := workflow.WithCancel(ctx)
+		if state.IsActive() {
+			selector.AddFuture(workflow.NewTimer(timerCtx, state.CurrentPeriodEnd.Sub(now)), func(workflow.Future) {})
+		}
+		selector.Select(ctx)
+		cancelTimer()
+	}
+
+	if state.IsCancelled() {
+		logger.Info("subscription cancelled", "subscription_id", state.SubscriptionID)
+		return nil
+	}
+
+	bill, err := state.StartCycle(workflow.Now(ctx))
+	if err != nil {
+		logger.Error("failed to start subscription cycle", "subscription_id", state.SubscriptionID, "err", err)
+		return err
+	}
+
+	if err := workflow.ExecuteActivity(ctx, w.billingActivities.UpsertSubscriptionActivity, state).Get(ctx, nil); err != nil {
+		logger.Error("failed to persist subscription", "subscription_id", state.SubscriptionID, "err", err)
+		return err
+	}
+
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        bill.BillingID,
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+	})
+	child := workflow.ExecuteChildWorkflow(childCtx, w.BillingWorkflow, &bill)
+	if err := child.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
+		logger.Error("failed to start subscription bill", "subscription_id", state.SubscriptionID, "billing_id", bill.BillingID, "err", err)
+		return err
+	}
+	logger.Info("subscription cycle started", "subscription_id", state.SubscriptionID, "billing_id", bill.BillingID, "period_end", bill.PeriodEnd)
+
+	openBill, openBillWorkflow = &bill, child
+	for billCompleted := false; !billCompleted; {
+		selector := workflow.NewSelector(ctx)
+		addSignals(selector)
+		selector.AddFuture(child, func(f workflow.Future) {
+			billCompleted = true
+			if err := f.Get(ctx, nil); err != nil {
+				logger.Error("subscription bill failed", "subscription_id", state.SubscriptionID, "billing_id", bill.BillingID, "err", err)
+			}
+		})
+		selector.Select(ctx)
+	}
+	openBill, openBillWorkflow = nil, nil
+
+	// signals received since the last selection are applied before
+	// continuing as new, as they would be lost otherwise.
+	for drained := false; !drained; {
+		selector := workflow.NewSelector(ctx)
+		addSignals(selector)
+		selector.AddDefault(func() { drained = true })
+		selector.Select(ctx)
+	}
+
+	if state.IsCancelled() {
+		logger.Info("subscription cancelled, last cycle completed", "subscription_id", state.SubscriptionID, "billing_id", bill.BillingID)
+		return nil
+	}
+
+	return workflow.NewContinueAsNewError(ctx, w.SubscriptionWorkflow, state)
+}
+
+// startDunningWorkflow starts the DunningWorkflow following up a closed bill
+// until it is paid.
+func (w *Workflows) startDunningWorkflow(ctx workflow.Context, state *domain.Bill) {
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        domain.DunningWorkflowID(state.BillingID),
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+	})
+
+	req := domain.DunningRequest{
+		BillingID: state.BillingID,
+		ClosedAt:  *state.ClosedAt,
+	}
+
+	child := workflow.ExecuteChildWorkflow(childCtx, w.DunningWorkflow, req)
+	if err := child.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
+		rlog.Error("failed to start dunning workflow", "workflow_id", state.BillingID, "err", err)
+	}
+}
+
+// startPaymentWorkflow starts the PaymentWorkflow charging the outstanding
+// balance of a closed bill. A failure to start it leaves the bill to be paid
+// manually.
+func (w *Workflows) startPaymentWorkflow(ctx workflow.Context, state *domain.Bill) {
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        domain.PaymentWorkflowID(state.BillingID),
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+	})
+
+	req := domain.PaymentRequest{
+		BillingID:      state.BillingID,
+		AccountID:      state.AccountID,
+		Amount:         state.GetOutstandingBalance(),
+		Currency:       state.Currency,
+		IdempotencyKey: fmt.Sprintf("%s-%s", state.BillingID, workflow.GetInfo(ctx).WorkflowExecution.RunID),
+	}
+
+	child := workflow.ExecuteChildWorkflow(childCtx, w.PaymentWorkflow, req)
+	if err := child.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
+		rlog.Error("failed to start payment workflow", "workflow_id", state.BillingID, "err", err)
+	}
+}
+
+// PaymentWorkflow is a Temporal workflow that charges the outstanding balance
+// of a closed Bill through the payment provider. The payment is authorized,
+// captured and recorded against the bill; provider calls are retried with
+// backoff. Operations the provider confirms asynchronously are awaited through
+// the SignalPaymentConfirmation signal, falling back to polling the provider
+// when no confirmation arrives in time. A captured payment the bill no longer
+// accepts is refunded. The workflow returns the final provider payment.
+func (w *Workflows) PaymentWorkflow(ctx workflow.Context, req domain.PaymentRequest) (domain.ProviderPayment, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("starting payment workflow", "billing_id", req.BillingID, "amount", req.Amount)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    10,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	confirmationCh := workflow.GetSignalChannel(ctx, domain.SignalPaymentConfirmation)
+
+	var payment domain.ProviderPayment
+	if err := workflow.ExecuteActivity(ctx, w.paymentActivities.AuthorizePaymentActivity, req).Get(ctx, &payment); err != nil {
+		logger.Error("failed to authorize payment", "billing_id", req.BillingID, "err", err)
+		return domain.ProviderPayment{}, err
+	}
+
+	payment, err := w.awaitPaymentConfirmation(ctx, confirmationCh, payment)
+	if err != nil {
+		return payment, err
+	}
+
+	if payment.Status == domain.ProviderPaymentDeclined {
+		logger.Info("payment declined", "billing_id", req.BillingID, "payment_id", payment.ID, "reason", payment.DeclineReason)
+		return payment, nil
+	}
+
+	if payment.Status == domain.ProviderPaymentAuthorized {
+		if err := workflow.ExecuteActivity(ctx, w.paymentActivities.CapturePaymentActivity, payment).Get(ctx, &payment); err != nil {
+			logger.Error("failed to capture payment", "billing_id", req.BillingID, "payment_id", payment.ID, "err", err)
+			return payment, err
+		}
+
+		payment, err = w.awaitPaymentConfirmation(ctx, confirmationCh, payment)
+		if err != nil {
+			return payment, err
+		}
+	}
+
+	if payment.Status != domain.ProviderPaymentCaptured {
+		return payment, fmt.Errorf("unexpected status %s of payment %s", payment.Status, payment.ID)
+	}
+
+	err = workflow.ExecuteActivity(ctx, w.paymentActivities.RecordProviderPaymentActivity, payment).Get(ctx, nil)
+	if err != nil && isPaymentRejected(err) {
+		logger.Warn("bill rejected the payment, refunding", "billing_id", req.BillingID, "payment_id", payment.ID, "err", err)
+		if err := workflow.ExecuteActivity(ctx, w.paymentActivities.RefundPaymentActivity, payment).Get(ctx, &payment); err != nil {
+			logger.Error("failed to refund payment", "billing_id", req.BillingID, "payment_id", payment.ID, "err", err)
+			return payment, err
+		}
+		return payment, nil
+	}
+	if err != nil {
+		logger.Error("failed to record payment", "billing_id", req.BillingID, "payment_id", payment.ID, "err", err)
+		return payment, err
+	}
+
+	logger.Info("payment recorded", "billing_id", req.BillingID, "payment_id", payment.ID)
+
+	// the payment settles the outstanding balance charged, the dunning
+	// workflow may have completed already, in which case it is not signalled.
+	settled := workflow.SignalExternalWorkflow(ctx, domain.DunningWorkflowID(req.BillingID), "", domain.SignalBillSettled, nil)
+	if err := settled.Get(ctx, nil); err != nil {
+		logger.Info("dunning workflow not signalled", "billing_id", req.BillingID, "err", err)
+	}
+
+	return payment, nil
+}
+
+// DunningWorkflow is a Temporal workflow that follows up a closed Bill that
+// stays unpaid. It waits on durable timers for every offset of the dunning
+// schedule, counted from the closing of the bill, and records a payment
+// reminder at each of them; at the last offset the bill is marked OVERDUE.
+// The workflow stops as soon as the SignalBillSettled signal is received or
+// an activity finds the bill settled, reopened or voided.
+func (w *Workflows) DunningWorkflow(ctx workflow.Context, req domain.DunningRequest) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("starting dunning workflow", "billing_id", req.BillingID)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	settledCh := workflow.GetSignalChannel(ctx, domain.SignalBillSettled)
+	settled := false
+
+	for i, offset := range w.dunningSchedule {
+		if wait := req.ClosedAt.Add(offset).Sub(workflow.Now(ctx)); wait > 0 {
+			timerCtx, cancelTimer := workflow.WithCancel(ctx)
+			timer := workflow.NewTimer(timerCtx, wait)
+
+			selector := workflow.NewSelector(ctx)
+			selector.AddReceive(settledCh, func(c workflow.ReceiveChannel, _ bool) {
+				c.Receive(ctx, nil)
+				settled = true
+			})
+			selector.AddFuture(timer, func(workflow.Future) {})
+			selector.Select(ctx)
+			cancelTimer()
+		}
+
+		if settled {
+			logger.Info("bill settled, stopping dunning", "billing_id", req.BillingID)
+			return nil
+		}
+
+		entry := domain.AuditEntry{
+			BillingID: req.BillingID,
+			Action:    domain.AuditActionRemind,
+			Reason:    fmt.Sprintf("payment reminder %d of %d", i+1, len(w.dunningSchedule)),
+			CreatedAt: workflow.Now(ctx),
+		}
+		step := w.billingActivities.RemindUnpaidBillActivity
+		if i == len(w.dunningSchedule)-1 {
+			entry.Action = domain.AuditActionOverdue
+			entry.Reason = fmt.Sprintf("unpaid %s after closing", offset)
+			step = w.billingActivities.SetBillingToOverdueActivity
+		}
+
+		var unpaid bool
+		if err := workflow.ExecuteActivity(ctx, step, entry).Get(ctx, &unpaid); err != nil {
+			logger.Error("failed to execute dunning step", "billing_id", req.BillingID, "action", entry.Action, "err", err)
+			return err
+		}
+		if !unpaid {
+			logger.Info("bill no longer unpaid, stopping dunning", "billing_id", req.BillingID)
+			return nil
+		}
+	}
+
+	logger.Info("dunning workflow completed, bill is overdue", "billing_id", req.BillingID)
+	return nil
+}
+
+// awaitPaymentConfirmation waits until a pending payment is confirmed through
+// the confirmation signal. When no confirmation arrives within the
+// confirmation timeout, the provider is asked for the payment status once.
+func (w *Workflows) awaitPaymentConfirmation(ctx workflow.Context, confirmationCh workflow.ReceiveChannel, payment domain.ProviderPayment) (domain.ProviderPayment, error) {
+	if !payment.IsPending() {
+		return payment, nil
+	}
+
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer cancelTimer()
+	timer := workflow.NewTimer(timerCtx, w.paymentConfirmationTimeout)
+	timedOut := false
+
+	for payment.IsPending() && !timedOut {
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(confirmationCh, func(c workflow.ReceiveChannel, _ bool) {
+			var confirmation domain.ProviderPayment
+			c.Receive(ctx, &confirmation)
+
+			if confirmation.ID != payment.ID {
+				workflow.GetLogger(ctx).Warn("ignoring confirmation of another payment", "payment_id", payment.ID, "confirmed_id", confirmation.ID)
+				return
+			}
+			payment.Status = confirmation.Status
+			payment.DeclineReason = confirmation.DeclineReason
+			if !confirmation.UpdatedAt.IsZero() {
+				payment.UpdatedAt = confirmation.UpdatedAt
+			}
+		})
+		selector.AddFuture(timer, func(workflow.Future) {
+			timedOut = true
+		})
+		selector.Select(ctx)
+	}
+
+	if !payment.IsPending() {
+		return payment, nil
+	}
+
+	if err := workflow.ExecuteActivity(ctx, w.paymentActivities.GetPaymentStatusActivity, payment).Get(ctx, &payment); err != nil {
+		return payment, err
+	}
+	if payment.IsPending() {
+		return payment, domain.ErrPaymentNotConfirmed
+	}
+	return payment, nil
+}
+
+// CreditNoteWorkflow is a Temporal workflow that issues a credit note against
+// a closed Bill. The credit note is applied and persisted by an activity that
+// is retried until it succeeds, unless the bill rejects the credit note.
+// The workflow returns the issued credit note.
+func (w *Workflows) CreditNoteWorkflow(ctx workflow.Context, note domain.CreditNote) (domain.CreditNote, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("starting credit note workflow",
+		"billing_id", note.BillingID,
+		"credit_note", note.Number,
+	)
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var issued domain.CreditNote
+	if err := workflow.ExecuteActivity(ctx, w.billingActivities.IssueCreditNoteActivity, note).Get(ctx, &issued); err != nil {
+		logger.Error("failed to issue credit note",
+			"billing_id", note.BillingID,
+			"credit_note", note.Number,
+			"err", err,
+		)
+		return domain.CreditNote{}, err
+	}
+
+	return issued, nil
+}
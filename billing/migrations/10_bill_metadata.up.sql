@@ -0,0 +1,4 @@
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE bill_items ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS bills_metadata_idx ON bills USING GIN (metadata jsonb_path_ops);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS payments (
+  id          SERIAL PRIMARY KEY,
+  bill_id     TEXT NOT NULL REFERENCES bills(billing_id) ON DELETE CASCADE,
+  amount      BIGINT NOT NULL, -- in the smallest unit of `currency`
+  currency    currency NOT NULL,
+  rate        DECIMAL(10,6) NOT NULL,
+  bill_amount BIGINT NOT NULL, -- `amount` converted to the smallest unit of the bill currency
+  reference   TEXT NOT NULL DEFAULT '',
+  paid_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS payments_bill_id_idx ON payments (bill_id);
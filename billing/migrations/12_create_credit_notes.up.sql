@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS credit_notes (
+  id        SERIAL PRIMARY KEY,
+  number    TEXT NOT NULL UNIQUE,
+  bill_id   TEXT NOT NULL REFERENCES bills(billing_id) ON DELETE CASCADE,
+  currency  currency NOT NULL,
+  amount    BIGINT NOT NULL, -- in the smallest unit of `currency`, the bill currency
+  reason    TEXT NOT NULL,
+  issued_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS credit_notes_bill_id_idx ON credit_notes (bill_id);
+
+CREATE TABLE IF NOT EXISTS credit_note_lines (
+  id             SERIAL PRIMARY KEY,
+  credit_note_id INTEGER NOT NULL REFERENCES credit_notes(id) ON DELETE CASCADE,
+  item_id        INTEGER REFERENCES bill_items(id) ON DELETE CASCADE, -- NULL for bill-level credit
+  amount         BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS credit_note_lines_credit_note_id_idx ON credit_note_lines (credit_note_id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+  series      TEXT NOT NULL,
+  year        INTEGER NOT NULL,
+  last_number BIGINT NOT NULL, -- last invoice number assigned in `series` for `year`
+  PRIMARY KEY (series, year)
+);
+
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS invoice_number TEXT UNIQUE;
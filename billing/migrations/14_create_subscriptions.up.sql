@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS subscriptions (
+  id                 SERIAL PRIMARY KEY,
+  subscription_id    TEXT NOT NULL UNIQUE,
+  account_id         TEXT NOT NULL REFERENCES accounts(account_id),
+  status             TEXT NOT NULL,
+  currency           TEXT NOT NULL,
+  region             TEXT NOT NULL DEFAULT '',
+  recurrence         TEXT NOT NULL,
+  timezone           TEXT NOT NULL DEFAULT '',
+  items              JSONB NOT NULL DEFAULT '[]'::jsonb,
+  cycle              BIGINT NOT NULL DEFAULT 0,
+  current_billing_id TEXT,
+  current_period_end TIMESTAMPTZ,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  paused_at          TIMESTAMPTZ,
+  cancelled_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_account_id_idx ON subscriptions (account_id);
+
+-- bills opened by a subscription cycle reference their subscription
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS subscription_id TEXT REFERENCES subscriptions(subscription_id);
+
+CREATE INDEX IF NOT EXISTS bills_subscription_id_idx ON bills (subscription_id);
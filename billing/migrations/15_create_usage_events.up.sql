@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS usage_events (
+  id          BIGSERIAL PRIMARY KEY,
+  bill_id     TEXT NOT NULL REFERENCES bills(billing_id) ON DELETE CASCADE,
+  event_id    TEXT NOT NULL,
+  meter       TEXT NOT NULL,
+  quantity    BIGINT NOT NULL,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  recorded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  -- events are deduplicated by their client-defined ID within a bill
+  CONSTRAINT usage_event_unique UNIQUE (bill_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS usage_events_bill_id_meter_idx ON usage_events (bill_id, meter);
@@ -0,0 +1,2 @@
+-- breakdown of the price of items priced by tiers
+ALTER TABLE bill_items ADD COLUMN IF NOT EXISTS tiers JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS catalog_products (
+  id          SERIAL PRIMARY KEY,
+  product_id  TEXT NOT NULL UNIQUE,
+  name        TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  archived_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS catalog_skus (
+  id          SERIAL PRIMARY KEY,
+  sku         TEXT NOT NULL UNIQUE,
+  product_id  TEXT NOT NULL REFERENCES catalog_products(product_id),
+  name        TEXT NOT NULL,
+  unit        TEXT NOT NULL DEFAULT '',
+  currency    TEXT NOT NULL,
+  unit_price  BIGINT NOT NULL,
+  version     BIGINT NOT NULL DEFAULT 1,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  archived_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS catalog_skus_product_id_idx ON catalog_skus (product_id);
+
+-- every version of a SKU is kept, so that the items added by SKU can be
+-- traced back to the name and price they were billed with
+CREATE TABLE IF NOT EXISTS catalog_sku_versions (
+  sku        TEXT NOT NULL REFERENCES catalog_skus(sku),
+  version    BIGINT NOT NULL,
+  name       TEXT NOT NULL,
+  unit       TEXT NOT NULL DEFAULT '',
+  currency   TEXT NOT NULL,
+  unit_price BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (sku, version)
+);
+
+-- items added by SKU keep the SKU and the catalog version they were priced with
+ALTER TABLE bill_items ADD COLUMN IF NOT EXISTS sku TEXT NOT NULL DEFAULT '';
+ALTER TABLE bill_items ADD COLUMN IF NOT EXISTS catalog_version BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- unit prices of SKUs in other currencies than their own, keyed by currency
+ALTER TABLE catalog_skus ADD COLUMN IF NOT EXISTS prices JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE catalog_sku_versions ADD COLUMN IF NOT EXISTS prices JSONB NOT NULL DEFAULT '{}';
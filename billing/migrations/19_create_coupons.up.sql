@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS coupons (
+  id                          SERIAL PRIMARY KEY,
+  code                        TEXT NOT NULL UNIQUE,
+  description                 TEXT NOT NULL DEFAULT '',
+  discount_type               TEXT NOT NULL,  -- PERCENTAGE or FIXED
+  discount_value              BIGINT NOT NULL, -- basis points for PERCENTAGE, smallest currency unit for FIXED
+  currencies                  JSONB NOT NULL DEFAULT '[]', -- empty for coupons valid in every currency
+  valid_from                  TIMESTAMPTZ,
+  valid_until                 TIMESTAMPTZ,
+  max_redemptions             BIGINT NOT NULL DEFAULT 0, -- 0 for unlimited
+  max_redemptions_per_account BIGINT NOT NULL DEFAULT 0, -- 0 for unlimited
+  created_at                  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- a redemption is reserved when a bill with the coupon closes and released
+-- when the bill is voided; only unreleased redemptions count towards the limits
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+  id          SERIAL PRIMARY KEY,
+  code        TEXT NOT NULL REFERENCES coupons(code),
+  bill_id     TEXT NOT NULL REFERENCES bills(billing_id) ON DELETE CASCADE,
+  account_id  TEXT NOT NULL DEFAULT '',
+  reserved_at TIMESTAMPTZ NOT NULL,
+  released_at TIMESTAMPTZ,
+  CONSTRAINT coupon_redemption_unique UNIQUE (code, bill_id)
+);
+
+CREATE INDEX IF NOT EXISTS coupon_redemptions_code_account_id_idx ON coupon_redemptions (code, account_id);
+
+ALTER TABLE bill_discounts ADD COLUMN IF NOT EXISTS coupon_code TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,26 @@
+-- the prepaid credit of an account in a currency; balance is the sum of the
+-- amounts of its ledger entries and the row is locked while credit is applied
+CREATE TABLE IF NOT EXISTS wallets (
+  account_id TEXT NOT NULL REFERENCES accounts(account_id) ON DELETE CASCADE,
+  currency   currency NOT NULL,
+  balance    BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0), -- in the smallest unit of `currency`
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (account_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS wallet_entries (
+  id         SERIAL PRIMARY KEY,
+  account_id TEXT NOT NULL,
+  currency   currency NOT NULL,
+  type       TEXT NOT NULL,   -- TOP_UP, CONSUMPTION or RELEASE
+  amount     BIGINT NOT NULL, -- positive for credit added to the wallet, negative for credit taken from it
+  bill_id    TEXT REFERENCES bills(billing_id) ON DELETE CASCADE, -- bill the credit is applied to, NULL for top-ups
+  reference  TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  FOREIGN KEY (account_id, currency) REFERENCES wallets(account_id, currency) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS wallet_entries_account_id_idx ON wallet_entries (account_id, currency);
+CREATE INDEX IF NOT EXISTS wallet_entries_bill_id_idx ON wallet_entries (bill_id);
+
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS credit_applied BIGINT NOT NULL DEFAULT 0;
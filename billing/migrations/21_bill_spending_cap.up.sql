@@ -0,0 +1,2 @@
+ALTER TABLE bills
+  ADD COLUMN IF NOT EXISTS max_total BIGINT NOT NULL DEFAULT 0 CHECK (max_total >= 0);
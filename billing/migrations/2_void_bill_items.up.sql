@@ -0,0 +1,2 @@
+ALTER TABLE bill_items
+  ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ;
@@ -0,0 +1,9 @@
+ALTER TABLE bill_items
+  ADD COLUMN IF NOT EXISTS quantity   BIGINT NOT NULL DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS unit       TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS unit_price BIGINT;
+
+-- existing single-price items are a single unit of their price
+UPDATE bill_items SET unit_price = price WHERE unit_price IS NULL;
+
+ALTER TABLE bill_items ALTER COLUMN unit_price SET NOT NULL;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS bill_discounts (
+  id          SERIAL PRIMARY KEY,
+  bill_id     TEXT NOT NULL REFERENCES bills(billing_id) ON DELETE CASCADE,
+  item_id     INTEGER REFERENCES bill_items(id) ON DELETE CASCADE, -- NULL for bill-level discounts
+  type        TEXT NOT NULL,  -- PERCENTAGE or FIXED
+  value       BIGINT NOT NULL, -- basis points for PERCENTAGE, smallest currency unit for FIXED
+  description TEXT NOT NULL DEFAULT '',
+  idemp_key   TEXT NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bill_discount_unique UNIQUE (idemp_key)
+);
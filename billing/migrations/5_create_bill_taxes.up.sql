@@ -0,0 +1,17 @@
+ALTER TABLE bills
+  ADD COLUMN IF NOT EXISTS region      TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS tax_total   BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS grand_total BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS bill_taxes (
+  id         SERIAL PRIMARY KEY,
+  bill_id    TEXT NOT NULL REFERENCES bills(billing_id) ON DELETE CASCADE,
+  code       TEXT NOT NULL,
+  name       TEXT NOT NULL,
+  rate       BIGINT NOT NULL,  -- basis points, 1800 = 18%
+  inclusive  BOOLEAN NOT NULL, -- true when the tax is contained in the item prices
+  base       BIGINT NOT NULL,  -- taxable amount in the smallest unit of the bill currency
+  amount     BIGINT NOT NULL,  -- tax amount in the smallest unit of the bill currency
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bill_tax_unique UNIQUE (bill_id, code)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS bill_audit_logs (
+  id         SERIAL PRIMARY KEY,
+  bill_id    TEXT NOT NULL REFERENCES bills(billing_id) ON DELETE CASCADE,
+  action     TEXT NOT NULL,
+  reason     TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS bill_audit_logs_bill_id_idx ON bill_audit_logs (bill_id);
@@ -0,0 +1,2 @@
+ALTER TABLE bills
+  ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS accounts (
+  id         SERIAL PRIMARY KEY,
+  account_id TEXT NOT NULL UNIQUE,
+  name       TEXT NOT NULL,
+  email      TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- bills opened before accounts existed have no owner
+ALTER TABLE bills ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES accounts(account_id);
+
+CREATE INDEX IF NOT EXISTS bills_account_id_idx ON bills (account_id);
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"encore.app/billing/domain"
@@ -13,6 +14,7 @@ import (
 	"encore.app/pkg/currency"
 	"encore.app/pkg/generator"
 	"encore.app/pkg/temporalclient"
+	"encore.dev"
 	"encore.dev/beta/errs"
 	"encore.dev/rlog"
 	"go.temporal.io/sdk/client"
@@ -38,20 +40,40 @@ func initService() (*Service, error) {
 	idGenerator := generator.NewIDGenerator(time.Now().UnixNano())
 	clock := clock.RealClock{}
 
+	taxEngine := domain.NewTaxEngine(taxRates)
+	meters := domain.NewMeterCatalog(usageMeters)
+	if err := meters.Validate(); err != nil {
+		return nil, err
+	}
+
 	repository := infrastructure.NewRepository(billingdb)
-	billingActivities := infrastructure.NewBillingActivity(repository)
-	workflows := infrastructure.NewTemporalWorkflows(billingActivities)
+	billingActivities := infrastructure.NewBillingActivity(repository, taxEngine, invoiceNumbering, meters)
+	paymentProvider := infrastructure.NewFakePaymentProvider(paymentProviderOptions)
+	paymentActivities := infrastructure.NewPaymentActivity(repository, paymentProvider)
+	workflows := infrastructure.NewTemporalWorkflows(billingActivities, paymentActivities, billIdleTimeout, paymentConfirmationTimeout, dunningSchedule)
 
 	temporalClient := infrastructure.NewTemporalWorkflowClient(c, workflows)
-	billingUseCase := usecases.NewBillingUseCase(repository, temporalClient, idGenerator, clock)
+	billingUseCase := usecases.NewBillingUseCase(repository, temporalClient, idGenerator, clock,
+		usecases.WithTaxEngine(taxEngine),
+		usecases.WithMeters(meters),
+		usecases.WithProrationPrecision(prorationPrecision),
+		usecases.WithCatalogPriceConversion(catalogPriceConversion),
+		usecases.WithReopenGracePeriod(reopenGracePeriod),
+		usecases.WithCloseWaitTimeout(billCloseWaitTimeout),
+	)
+
+	paymentProvider.OnConfirmation(func(ctx context.Context, payment domain.ProviderPayment) error {
+		return billingUseCase.ConfirmPayment(ctx, usecases.ConfirmPaymentRequest{
+			BillingID:     payment.BillingID,
+			PaymentID:     payment.ID,
+			Status:        string(payment.Status),
+			DeclineReason: payment.DeclineReason,
+		})
+	})
 
 	rlog.Info("starting temporal worker")
 	w := worker.New(c, domain.TemporalQueueName, worker.Options{})
-	w.RegisterWorkflow(workflows.BillingWorkflow)
-	w.RegisterActivity(billingActivities.UpsertBillingToDBActivity)
-	w.RegisterActivity(billingActivities.SetBillingToCloseActivity)
-	w.RegisterActivity(billingActivities.InsertLineItemActivity)
-	w.RegisterActivity(billingActivities.InsertBillExchangeActivity)
+	workflows.Register(w)
 
 	if err := w.Start(); err != nil {
 		c.Close()
@@ -94,8 +116,13 @@ func (s *Service) GetBill(ctx context.Context, id string) (*GetBillResponse, err
 func (s *Service) AddItem(ctx context.Context, id string, req *AddItemRequest) (*AddItemResponse, error) {
 	bill, err := s.useCase.AddItem(ctx, usecases.AddItemRequest{
 		BillingID: id,
+		SKU:       req.SKU,
 		Name:      req.Name,
 		Price:     req.Price,
+		Quantity:  req.Quantity,
+		Unit:      req.Unit,
+		UnitPrice: req.UnitPrice,
+		Metadata:  req.Metadata,
 	})
 
 	if err != nil {
@@ -104,6 +131,10 @@ func (s *Service) AddItem(ctx context.Context, id string, req *AddItemRequest) (
 			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
 		}
 
+		if errors.Is(err, domain.ErrSpendingCapExceeded) {
+			return nil, errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
 		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
 	}
 
@@ -112,6 +143,139 @@ func (s *Service) AddItem(ctx context.Context, id string, req *AddItemRequest) (
 	}, nil
 }
 
+// VoidItem voids a line item of a running bill workflow.
+// The item is kept in the bill's item history but no longer counts towards the total.
+//
+//encore:api public method=DELETE path=/api/v1/bills/:id/items/:itemId
+func (s *Service) VoidItem(ctx context.Context, id string, itemId int64) (*VoidItemResponse, error) {
+	bill, err := s.useCase.VoidItem(ctx, usecases.VoidItemRequest{
+		BillingID: id,
+		ItemID:    itemId,
+	})
+
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotFound) || errors.Is(err, domain.ErrItemNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillClosed) || errors.Is(err, domain.ErrBillVoided) || errors.Is(err, domain.ErrItemVoided) {
+			return nil, errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &VoidItemResponse{
+		CurrentBill: fromDomainBillToBillReponse(bill),
+	}, nil
+}
+
+// ApplyDiscount applies a percentage or fixed-amount discount to a running bill workflow,
+// either to a single item or to the whole bill.
+//
+//encore:api public method=POST path=/api/v1/bills/:id/discounts
+func (s *Service) ApplyDiscount(ctx context.Context, id string, req *ApplyDiscountRequest) (*ApplyDiscountResponse, error) {
+	bill, err := s.useCase.ApplyDiscount(ctx, usecases.ApplyDiscountRequest{
+		BillingID:   id,
+		ItemID:      req.ItemID,
+		Type:        req.Type,
+		Value:       req.Value,
+		Description: req.Description,
+	})
+
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotFound) || errors.Is(err, domain.ErrItemNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillClosed) || errors.Is(err, domain.ErrBillVoided) || errors.Is(err, domain.ErrItemVoided) {
+			return nil, errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &ApplyDiscountResponse{
+		CurrentBill: fromDomainBillToBillReponse(bill),
+	}, nil
+}
+
+// RecordUsage records usage events of metered features for an open bill.
+// Events are deduplicated by their eventId, and the usage of each meter is
+// billed as a single line item when the bill closes.
+//
+//encore:api public method=POST path=/api/v1/bills/:id/usage
+func (s *Service) RecordUsage(ctx context.Context, id string, req *RecordUsageRequest) (*RecordUsageResponse, error) {
+	var events []domain.UsageEvent
+	for _, e := range req.Events {
+		events = append(events, domain.UsageEvent{
+			EventID:   e.EventID,
+			Meter:     e.Meter,
+			Quantity:  e.Quantity,
+			Timestamp: e.Timestamp,
+		})
+	}
+
+	recorded, err := s.useCase.RecordUsage(ctx, usecases.RecordUsageRequest{
+		BillingID: id,
+		Events:    events,
+	})
+	if err != nil {
+		return nil, usageError(err)
+	}
+
+	return &RecordUsageResponse{
+		Recorded:   recorded,
+		Duplicates: int64(len(events)) - recorded,
+	}, nil
+}
+
+// GetUsage returns the usage recorded for a bill so far, aggregated per meter.
+//
+//encore:api public method=GET path=/api/v1/bills/:id/usage
+func (s *Service) GetUsage(ctx context.Context, id string) (*GetUsageResponse, error) {
+	totals, err := s.useCase.GetUsage(ctx, id)
+	if err != nil {
+		return nil, usageError(err)
+	}
+
+	usage := []UsageTotal{}
+	for _, t := range totals {
+		usage = append(usage, UsageTotal(t))
+	}
+
+	return &GetUsageResponse{Usage: usage}, nil
+}
+
+// usageError maps the errors of the usage use cases to API errors.
+func usageError(err error) error {
+	var domainValidationErr domain.ValidationError
+	if errors.As(err, &domainValidationErr) {
+		return errs.WrapCode(err, errs.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrBillNotFound) {
+		return errs.WrapCode(err, errs.NotFound, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrBillClosed) || errors.Is(err, domain.ErrBillVoided) ||
+		errors.Is(err, domain.ErrSpendingCapExceeded) {
+		return errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+	}
+
+	return errs.WrapCode(err, errs.Internal, "internal server error")
+}
+
 // CloseBillingByID close an running bill workflow and return its total
 // Assumes the workflow is still running for active operations
 //
@@ -124,14 +288,29 @@ func (s *Service) CloseBillingByID(ctx context.Context, id string, req *CloseBil
 			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
 		}
 
+		if errors.Is(err, domain.ErrBillNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillClosed) || errors.Is(err, domain.ErrBillVoided) {
+			return nil, errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
 		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
 	}
 
 	return &CloseBillingResponse{
+		InvoiceNumber: finalBill.InvoiceNumber,
+		Subtotal:      newAmount(finalBill.Currency, finalBill.GetSubtotal()),
+		Discount:      newAmount(finalBill.Currency, finalBill.GetDiscountTotal()),
+		Tax:           newAmount(finalBill.Currency, finalBill.GetTaxTotal()),
+		GrandTotal:    newAmount(finalBill.Currency, finalBill.GetGrandTotal()),
+		CreditApplied: newAmount(finalBill.Currency, finalBill.CreditApplied),
+		AmountDue:     newAmount(finalBill.Currency, finalBill.GetAmountDue()),
 		OriginalCurrencyTotal: Amount{
 			Currency:        string(finalBill.Currency),
-			Amount:          finalBill.Total,
-			FormattedAmount: currency.FormatString(string(finalBill.Currency), finalBill.Total),
+			Amount:          finalBill.GetGrandTotal(),
+			FormattedAmount: currency.FormatString(string(finalBill.Currency), finalBill.GetGrandTotal()),
 		},
 		ConvertedCurrencyTotal: Amount{
 			Currency:        string(finalBill.Conversion.TargetCurrency),
@@ -141,12 +320,261 @@ func (s *Service) CloseBillingByID(ctx context.Context, id string, req *CloseBil
 	}, nil
 }
 
+// ReopenBill reopens a bill closed within the configured grace period.
+// The reason is recorded in the bill's audit trail and the bill workflow
+// is restarted from the database state.
+//
+//encore:api public method=POST path=/api/v1/bills/:id/reopen
+func (s *Service) ReopenBill(ctx context.Context, id string, req *ReopenBillRequest) (*ReopenBillResponse, error) {
+	bill, err := s.useCase.ReopenBill(ctx, usecases.ReopenBillRequest{
+		BillingID: id,
+		Reason:    req.Reason,
+	})
+
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotClosed) || errors.Is(err, domain.ErrReopenWindowExpired) || errors.Is(err, domain.ErrBillHasPayments) ||
+			errors.Is(err, domain.ErrBillHasCreditNotes) {
+			return nil, errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &ReopenBillResponse{
+		CurrentBill: fromDomainBillToBillReponse(bill),
+	}, nil
+}
+
+// CancelBill voids an open bill, for example one opened by mistake.
+// The bill stays retrievable through GetBill but is excluded from totals and reports.
+//
+//encore:api public method=POST path=/api/v1/bills/:id/cancel
+func (s *Service) CancelBill(ctx context.Context, id string, req *CancelBillRequest) (*CancelBillResponse, error) {
+	bill, err := s.useCase.VoidBill(ctx, usecases.VoidBillRequest{
+		BillingID: id,
+		Reason:    req.Reason,
+	})
+
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillClosed) || errors.Is(err, domain.ErrBillVoided) {
+			return nil, errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &CancelBillResponse{
+		CurrentBill: fromDomainBillToBillReponse(bill),
+	}, nil
+}
+
+// RecordPayment records a full or partial payment against a closed bill.
+// Payments in another currency are converted to the bill currency, and the
+// bill becomes PARTIALLY_PAID or PAID depending on its outstanding balance.
+//
+//encore:api public method=POST path=/api/v1/bills/:id/payments
+func (s *Service) RecordPayment(ctx context.Context, id string, req *RecordPaymentRequest) (*RecordPaymentResponse, error) {
+	bill, err := s.useCase.RecordPayment(ctx, usecases.RecordPaymentRequest{
+		BillingID: id,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Reference: req.Reference,
+	})
+
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotClosed) || errors.Is(err, domain.ErrBillVoided) ||
+			errors.Is(err, domain.ErrBillPaid) || errors.Is(err, domain.ErrPaymentExceedsBalance) {
+			return nil, errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrPaymentConflict) {
+			return nil, errs.WrapCode(err, errs.Aborted, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &RecordPaymentResponse{
+		CurrentBill: fromDomainBillToBillReponse(bill),
+	}, nil
+}
+
+// IssueCreditNote issues a credit note against a closed bill, crediting some
+// of its items or the bill as a whole. The credit note is issued by a Temporal
+// workflow and reduces the outstanding balance of the bill.
+//
+//encore:api public method=POST path=/api/v1/bills/:id/credit-notes
+func (s *Service) IssueCreditNote(ctx context.Context, id string, req *IssueCreditNoteRequest) (*IssueCreditNoteResponse, error) {
+	var lines []domain.CreditNoteLine
+	for _, l := range req.Lines {
+		lines = append(lines, domain.CreditNoteLine{ItemID: l.ItemID, Amount: l.Amount})
+	}
+
+	bill, err := s.useCase.IssueCreditNote(ctx, usecases.IssueCreditNoteRequest{
+		BillingID: id,
+		Reason:    req.Reason,
+		Lines:     lines,
+	})
+
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotFound) || errors.Is(err, domain.ErrItemNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrBillNotClosed) || errors.Is(err, domain.ErrBillVoided) ||
+			errors.Is(err, domain.ErrItemVoided) || errors.Is(err, domain.ErrCreditExceedsBalance) {
+			return nil, errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &IssueCreditNoteResponse{
+		CurrentBill: fromDomainBillToBillReponse(bill),
+	}, nil
+}
+
+// ConfirmPayment receives the asynchronous confirmation of a pending payment
+// from the payment provider and forwards it to the payment workflow of the bill.
+//
+//encore:api public method=POST path=/api/v1/bills/:id/payments/confirmations
+func (s *Service) ConfirmPayment(ctx context.Context, id string, req *ConfirmPaymentRequest) error {
+	err := s.useCase.ConfirmPayment(ctx, usecases.ConfirmPaymentRequest{
+		BillingID:     id,
+		PaymentID:     req.PaymentID,
+		Status:        req.Status,
+		DeclineReason: req.DeclineReason,
+	})
+
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		return errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return nil
+}
+
+// GetInvoicePDF renders the invoice of a closed bill as a PDF document.
+// Open and voided bills have no invoice and are rejected.
+//
+//encore:api public raw method=GET path=/api/v1/bills/:id/invoice.pdf
+func (s *Service) GetInvoicePDF(w http.ResponseWriter, req *http.Request) {
+	id := encore.CurrentRequest().PathParams.Get("id")
+
+	bill, err := s.useCase.GetInvoice(req.Context(), id)
+	if err != nil {
+		errs.HTTPError(w, invoiceError(err))
+		return
+	}
+
+	document, err := infrastructure.RenderInvoicePDF(bill)
+	if err != nil {
+		errs.HTTPError(w, invoiceError(err))
+		return
+	}
+
+	fileName := bill.InvoiceNumber
+	if fileName == "" {
+		fileName = bill.BillingID
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", fileName+".pdf"))
+	_, _ = w.Write(document)
+}
+
+// invoiceError maps the errors of GetInvoicePDF to API errors, as raw
+// endpoints write their errors themselves.
+func invoiceError(err error) error {
+	var domainValidationErr domain.ValidationError
+	if errors.As(err, &domainValidationErr) {
+		return errs.WrapCode(err, errs.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrBillNotFound) {
+		return errs.WrapCode(err, errs.NotFound, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrBillNotClosed) || errors.Is(err, domain.ErrBillVoided) {
+		return errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+	}
+
+	return errs.WrapCode(err, errs.Internal, "internal server error")
+}
+
+// GetAuditTrail returns the audit trail of a bill, such as reopenings and their reasons.
+//
+//encore:api public method=GET path=/api/v1/bills/:id/audit
+func (s *Service) GetAuditTrail(ctx context.Context, id string) (*GetAuditTrailResponse, error) {
+	entries, err := s.useCase.GetAuditTrail(ctx, id)
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	var auditEntries []AuditEntry
+	for _, e := range entries {
+		auditEntries = append(auditEntries, fromDomainAuditEntryToResponse(e))
+	}
+
+	return &GetAuditTrailResponse{
+		Entries: auditEntries,
+	}, nil
+}
+
 // OpenBilling handle open new biling by executing new workflows
 //
 //encore:api public method=POST path=/api/v1/bills
 func (s *Service) OpenBilling(ctx context.Context, req *OpenBillingRequest) (*OpenBillingResponse, error) {
 	billindID, err := s.useCase.CreateBill(ctx, usecases.CreateBillRequest{
-		Currency: req.Currency,
+		AccountID:  req.AccountID,
+		Currency:   req.Currency,
+		Region:     req.Region,
+		PeriodEnd:  req.PeriodEnd,
+		Recurrence: req.Recurrence,
+		Timezone:   req.Timezone,
+		MaxTotal:   req.MaxTotal,
+		Metadata:   req.Metadata,
 	})
 	if err != nil {
 		var domainValidationErr domain.ValidationError
@@ -154,14 +582,586 @@ func (s *Service) OpenBilling(ctx context.Context, req *OpenBillingRequest) (*Op
 			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
 		}
 
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
 		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
 	}
 
 	return &OpenBillingResponse{
 		BillingID: billindID,
 		Currency:  req.Currency,
+		AccountID: req.AccountID,
+	}, nil
+
+}
+
+// CreateAccount creates a new customer account that can own bills.
+//
+//encore:api public method=POST path=/api/v1/accounts
+func (s *Service) CreateAccount(ctx context.Context, req *CreateAccountRequest) (*AccountResponse, error) {
+	account, err := s.useCase.CreateAccount(ctx, usecases.CreateAccountRequest{
+		Name:  req.Name,
+		Email: req.Email,
+	})
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &AccountResponse{
+		Account: fromDomainAccountToResponse(account),
+	}, nil
+}
+
+// GetAccount fetches a customer account by its ID.
+//
+//encore:api public method=GET path=/api/v1/accounts/:id
+func (s *Service) GetAccount(ctx context.Context, id string) (*AccountResponse, error) {
+	account, err := s.useCase.GetAccount(ctx, id)
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &AccountResponse{
+		Account: fromDomainAccountToResponse(account),
+	}, nil
+}
+
+// UpdateAccount updates the name and email of a customer account.
+//
+//encore:api public method=PUT path=/api/v1/accounts/:id
+func (s *Service) UpdateAccount(ctx context.Context, id string, req *UpdateAccountRequest) (*AccountResponse, error) {
+	account, err := s.useCase.UpdateAccount(ctx, usecases.UpdateAccountRequest{
+		AccountID: id,
+		Name:      req.Name,
+		Email:     req.Email,
+	})
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return &AccountResponse{
+		Account: fromDomainAccountToResponse(account),
+	}, nil
+}
+
+// DeleteAccount deletes a customer account. Accounts that own bills or have
+// wallet credit left cannot be deleted.
+//
+//encore:api public method=DELETE path=/api/v1/accounts/:id
+func (s *Service) DeleteAccount(ctx context.Context, id string) error {
+	err := s.useCase.DeleteAccount(ctx, id)
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrAccountHasBills) || errors.Is(err, domain.ErrAccountHasCredit) {
+			return errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+		}
+
+		return errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	return nil
+}
+
+// TopUpWallet adds prepaid credit to the wallet of a customer account. The
+// credit is applied to the bills of the account in the same currency when
+// they close.
+//
+//encore:api public method=POST path=/api/v1/accounts/:id/wallet/top-ups
+func (s *Service) TopUpWallet(ctx context.Context, id string, req *TopUpWalletRequest) (*WalletResponse, error) {
+	wallet, err := s.useCase.TopUpWallet(ctx, usecases.TopUpWalletRequest{
+		AccountID: id,
+		Currency:  req.Currency,
+		Amount:    req.Amount,
+		Reference: req.Reference,
+	})
+	if err != nil {
+		return nil, walletError(err)
+	}
+
+	return &WalletResponse{
+		Wallet: fromDomainWalletToResponse(wallet),
+	}, nil
+}
+
+// GetWallet returns the credit balances of a customer account and the ledger
+// of its top-ups and of the credit applied to its bills.
+//
+//encore:api public method=GET path=/api/v1/accounts/:id/wallet
+func (s *Service) GetWallet(ctx context.Context, id string) (*WalletResponse, error) {
+	wallet, err := s.useCase.GetWallet(ctx, id)
+	if err != nil {
+		return nil, walletError(err)
+	}
+
+	return &WalletResponse{
+		Wallet: fromDomainWalletToResponse(wallet),
+	}, nil
+}
+
+// walletError maps the errors of the wallet use cases to API errors.
+func walletError(err error) error {
+	var domainValidationErr domain.ValidationError
+	if errors.As(err, &domainValidationErr) {
+		return errs.WrapCode(err, errs.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrAccountNotFound) {
+		return errs.WrapCode(err, errs.NotFound, err.Error())
+	}
+
+	return errs.WrapCode(err, errs.Internal, "internal server error")
+}
+
+// GetAccountBills returns the bills owned by a customer account, most recent first,
+// optionally filtered by metadata, e.g. ?metadata=orderId:1234&metadata=table:7.
+//
+//encore:api public method=GET path=/api/v1/accounts/:id/bills
+func (s *Service) GetAccountBills(ctx context.Context, id string, req *GetAccountBillsRequest) (*GetAccountBillsResponse, error) {
+	bills, err := s.useCase.GetAccountBills(ctx, usecases.GetAccountBillsRequest{
+		AccountID: id,
+		Metadata:  req.Metadata,
+	})
+	if err != nil {
+		var domainValidationErr domain.ValidationError
+		if errors.As(err, &domainValidationErr) {
+			return nil, errs.WrapCode(err, errs.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return nil, errs.WrapCode(err, errs.NotFound, err.Error())
+		}
+
+		return nil, errs.WrapCode(err, errs.Internal, "internal server error")
+	}
+
+	var accountBills []Bill
+	for _, b := range bills {
+		accountBills = append(accountBills, fromDomainBillToBillReponse(b))
+	}
+
+	return &GetAccountBillsResponse{
+		Bills: accountBills,
+	}, nil
+}
+
+// CreateSubscription subscribes an account to a recurring plan. A bill with the
+// plan items is opened right away and, every cycle, when the previous one is
+// closed at the end of its period.
+//
+//encore:api public method=POST path=/api/v1/subscriptions
+func (s *Service) CreateSubscription(ctx context.Context, req *CreateSubscriptionRequest) (*SubscriptionResponse, error) {
+	var items []domain.PlanItem
+	for _, i := range req.Items {
+		items = append(items, domain.PlanItem(i))
+	}
+
+	subscription, err := s.useCase.CreateSubscription(ctx, usecases.CreateSubscriptionRequest{
+		AccountID:  req.AccountID,
+		Currency:   req.Currency,
+		Region:     req.Region,
+		Recurrence: req.Recurrence,
+		Timezone:   req.Timezone,
+		Items:      items,
+	})
+	if err != nil {
+		return nil, subscriptionError(err)
+	}
+
+	return &SubscriptionResponse{
+		Subscription: fromDomainSubscriptionToResponse(subscription),
+	}, nil
+}
+
+// GetSubscription fetches the current state of a subscription by its ID.
+//
+//encore:api public method=GET path=/api/v1/subscriptions/:id
+func (s *Service) GetSubscription(ctx context.Context, id string) (*SubscriptionResponse, error) {
+	subscription, err := s.useCase.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, subscriptionError(err)
+	}
+
+	return &SubscriptionResponse{
+		Subscription: fromDomainSubscriptionToResponse(subscription),
 	}, nil
+}
+
+// PauseSubscription pauses an active subscription: the bill of the current
+// cycle is still closed at the end of its period, but no new bill is opened
+// until the subscription is resumed.
+//
+//encore:api public method=POST path=/api/v1/subscriptions/:id/pause
+func (s *Service) PauseSubscription(ctx context.Context, id string) (*SubscriptionResponse, error) {
+	subscription, err := s.useCase.PauseSubscription(ctx, id)
+	if err != nil {
+		return nil, subscriptionError(err)
+	}
+
+	return &SubscriptionResponse{
+		Subscription: fromDomainSubscriptionToResponse(subscription),
+	}, nil
+}
+
+// ResumeSubscription resumes a paused subscription, opening a new bill once
+// the period of the current cycle has ended.
+//
+//encore:api public method=POST path=/api/v1/subscriptions/:id/resume
+func (s *Service) ResumeSubscription(ctx context.Context, id string) (*SubscriptionResponse, error) {
+	subscription, err := s.useCase.ResumeSubscription(ctx, id)
+	if err != nil {
+		return nil, subscriptionError(err)
+	}
+
+	return &SubscriptionResponse{
+		Subscription: fromDomainSubscriptionToResponse(subscription),
+	}, nil
+}
+
+// CancelSubscription cancels a subscription: the bill of the current cycle
+// is still closed at the end of its period, and no new bill is opened.
+//
+//encore:api public method=POST path=/api/v1/subscriptions/:id/cancel
+func (s *Service) CancelSubscription(ctx context.Context, id string) (*SubscriptionResponse, error) {
+	subscription, err := s.useCase.CancelSubscription(ctx, id)
+	if err != nil {
+		return nil, subscriptionError(err)
+	}
+
+	return &SubscriptionResponse{
+		Subscription: fromDomainSubscriptionToResponse(subscription),
+	}, nil
+}
+
+// ChangeSubscriptionPlan replaces the plan of a subscription from the next
+// cycle on. The open bill of the current cycle is credited for the unused
+// part of its period on the current plan and charged for the remaining part
+// on the new plan.
+//
+//encore:api public method=POST path=/api/v1/subscriptions/:id/plan
+func (s *Service) ChangeSubscriptionPlan(ctx context.Context, id string, req *ChangeSubscriptionPlanRequest) (*SubscriptionResponse, error) {
+	var items []domain.PlanItem
+	for _, i := range req.Items {
+		items = append(items, domain.PlanItem(i))
+	}
+
+	subscription, err := s.useCase.ChangeSubscriptionPlan(ctx, usecases.ChangePlanRequest{
+		SubscriptionID:     id,
+		Items:              items,
+		ProrationPrecision: domain.ProrationPrecision(req.ProrationPrecision),
+	})
+	if err != nil {
+		return nil, subscriptionError(err)
+	}
+
+	return &SubscriptionResponse{
+		Subscription: fromDomainSubscriptionToResponse(subscription),
+	}, nil
+}
+
+// subscriptionError maps the errors of the subscription use cases to API errors.
+func subscriptionError(err error) error {
+	var domainValidationErr domain.ValidationError
+	if errors.As(err, &domainValidationErr) {
+		return errs.WrapCode(err, errs.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrAccountNotFound) || errors.Is(err, domain.ErrSubscriptionNotFound) {
+		return errs.WrapCode(err, errs.NotFound, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrSubscriptionPaused) || errors.Is(err, domain.ErrSubscriptionNotPaused) ||
+		errors.Is(err, domain.ErrSubscriptionCancelled) {
+		return errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+	}
+
+	return errs.WrapCode(err, errs.Internal, "internal server error")
+}
+
+// CreateProduct adds a product to the catalog.
+//
+//encore:api public method=POST path=/api/v1/catalog/products
+func (s *Service) CreateProduct(ctx context.Context, req *ProductRequest) (*ProductResponse, error) {
+	product, err := s.useCase.CreateProduct(ctx, usecases.CreateProductRequest{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, catalogError(err)
+	}
+
+	return &ProductResponse{
+		Product: fromDomainProductToResponse(product),
+	}, nil
+}
+
+// ListProducts returns the products of the catalog with their SKUs.
+//
+//encore:api public method=GET path=/api/v1/catalog/products
+func (s *Service) ListProducts(ctx context.Context) (*ListProductsResponse, error) {
+	products, err := s.useCase.ListProducts(ctx)
+	if err != nil {
+		return nil, catalogError(err)
+	}
+
+	var result []Product
+	for _, p := range products {
+		result = append(result, fromDomainProductToResponse(p))
+	}
+
+	return &ListProductsResponse{
+		Products: result,
+	}, nil
+}
+
+// GetProduct fetches a product of the catalog and its SKUs by its ID.
+//
+//encore:api public method=GET path=/api/v1/catalog/products/:id
+func (s *Service) GetProduct(ctx context.Context, id string) (*ProductResponse, error) {
+	product, err := s.useCase.GetProduct(ctx, id)
+	if err != nil {
+		return nil, catalogError(err)
+	}
+
+	return &ProductResponse{
+		Product: fromDomainProductToResponse(product),
+	}, nil
+}
+
+// UpdateProduct updates the name and description of a product.
+//
+//encore:api public method=PUT path=/api/v1/catalog/products/:id
+func (s *Service) UpdateProduct(ctx context.Context, id string, req *ProductRequest) (*ProductResponse, error) {
+	product, err := s.useCase.UpdateProduct(ctx, usecases.UpdateProductRequest{
+		ProductID:   id,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, catalogError(err)
+	}
+
+	return &ProductResponse{
+		Product: fromDomainProductToResponse(product),
+	}, nil
+}
+
+// ArchiveProduct archives a product and its SKUs. Items already added by SKU
+// are kept on their bills, but the SKUs can no longer be added.
+//
+//encore:api public method=DELETE path=/api/v1/catalog/products/:id
+func (s *Service) ArchiveProduct(ctx context.Context, id string) error {
+	if err := s.useCase.ArchiveProduct(ctx, id); err != nil {
+		return catalogError(err)
+	}
+	return nil
+}
+
+// CreateSKU adds a SKU to a product of the catalog.
+//
+//encore:api public method=POST path=/api/v1/catalog/products/:id/skus
+func (s *Service) CreateSKU(ctx context.Context, id string, req *CreateSKURequest) (*SKUResponse, error) {
+	sku, err := s.useCase.CreateSKU(ctx, usecases.CreateSKURequest{
+		ProductID: id,
+		Code:      req.Code,
+		Name:      req.Name,
+		Unit:      req.Unit,
+		Currency:  req.Currency,
+		UnitPrice: req.UnitPrice,
+		Prices:    fromRequestPriceList(req.Prices),
+	})
+	if err != nil {
+		return nil, catalogError(err)
+	}
+
+	return &SKUResponse{
+		SKU: fromDomainSKUToResponse(sku),
+	}, nil
+}
+
+// GetSKU fetches a SKU by its code, optionally at a past version,
+// e.g. ?version=2.
+//
+//encore:api public method=GET path=/api/v1/catalog/skus/:sku
+func (s *Service) GetSKU(ctx context.Context, sku string, req *GetSKURequest) (*SKUResponse, error) {
+	result, err := s.useCase.GetSKU(ctx, usecases.GetSKURequest{
+		Code:    sku,
+		Version: req.Version,
+	})
+	if err != nil {
+		return nil, catalogError(err)
+	}
+
+	return &SKUResponse{
+		SKU: fromDomainSKUToResponse(result),
+	}, nil
+}
+
+// UpdateSKU updates the name, unit and price of a SKU as its next version.
+// Items already added by SKU keep the price they were added with.
+//
+//encore:api public method=PUT path=/api/v1/catalog/skus/:sku
+func (s *Service) UpdateSKU(ctx context.Context, sku string, req *UpdateSKURequest) (*SKUResponse, error) {
+	result, err := s.useCase.UpdateSKU(ctx, usecases.UpdateSKURequest{
+		Code:      sku,
+		Name:      req.Name,
+		Unit:      req.Unit,
+		Currency:  req.Currency,
+		UnitPrice: req.UnitPrice,
+		Prices:    fromRequestPriceList(req.Prices),
+	})
+	if err != nil {
+		return nil, catalogError(err)
+	}
+
+	return &SKUResponse{
+		SKU: fromDomainSKUToResponse(result),
+	}, nil
+}
+
+// ArchiveSKU archives a SKU, which can then no longer be added to bills.
+//
+//encore:api public method=DELETE path=/api/v1/catalog/skus/:sku
+func (s *Service) ArchiveSKU(ctx context.Context, sku string) error {
+	if err := s.useCase.ArchiveSKU(ctx, sku); err != nil {
+		return catalogError(err)
+	}
+	return nil
+}
+
+// catalogError maps the errors of the catalog use cases to API errors.
+func catalogError(err error) error {
+	var domainValidationErr domain.ValidationError
+	if errors.As(err, &domainValidationErr) {
+		return errs.WrapCode(err, errs.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrProductNotFound) || errors.Is(err, domain.ErrSKUNotFound) {
+		return errs.WrapCode(err, errs.NotFound, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrSKUExists) {
+		return errs.WrapCode(err, errs.AlreadyExists, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrProductArchived) || errors.Is(err, domain.ErrSKUArchived) {
+		return errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+	}
+
+	return errs.WrapCode(err, errs.Internal, "internal server error")
+}
+
+// CreateCoupon creates a coupon that can be applied to bills by its code.
+//
+//encore:api public method=POST path=/api/v1/coupons
+func (s *Service) CreateCoupon(ctx context.Context, req *CreateCouponRequest) (*CouponResponse, error) {
+	coupon, err := s.useCase.CreateCoupon(ctx, usecases.CreateCouponRequest{
+		Code:                     req.Code,
+		Description:              req.Description,
+		Type:                     req.Type,
+		Value:                    req.Value,
+		Currencies:               req.Currencies,
+		ValidFrom:                req.ValidFrom,
+		ValidUntil:               req.ValidUntil,
+		MaxRedemptions:           req.MaxRedemptions,
+		MaxRedemptionsPerAccount: req.MaxRedemptionsPerAccount,
+	})
+	if err != nil {
+		return nil, couponError(err)
+	}
+
+	return &CouponResponse{
+		Coupon: fromDomainCouponToResponse(coupon),
+	}, nil
+}
+
+// GetCoupon fetches a coupon by its code, with the number of bills it is
+// redeemed by.
+//
+//encore:api public method=GET path=/api/v1/coupons/:code
+func (s *Service) GetCoupon(ctx context.Context, code string) (*CouponResponse, error) {
+	coupon, err := s.useCase.GetCoupon(ctx, code)
+	if err != nil {
+		return nil, couponError(err)
+	}
+
+	return &CouponResponse{
+		Coupon: fromDomainCouponToResponse(coupon),
+	}, nil
+}
+
+// ApplyCoupon applies the discount of a coupon to a running bill workflow.
+// The redemption of the coupon is reserved when the bill closes.
+//
+//encore:api public method=POST path=/api/v1/bills/:id/coupons
+func (s *Service) ApplyCoupon(ctx context.Context, id string, req *ApplyCouponRequest) (*ApplyCouponResponse, error) {
+	bill, err := s.useCase.ApplyCoupon(ctx, usecases.ApplyCouponRequest{
+		BillingID: id,
+		Code:      req.Code,
+	})
+	if err != nil {
+		return nil, couponError(err)
+	}
+
+	return &ApplyCouponResponse{
+		CurrentBill: fromDomainBillToBillReponse(bill),
+	}, nil
+}
+
+// couponError maps the errors of the coupon use cases to API errors.
+func couponError(err error) error {
+	var domainValidationErr domain.ValidationError
+	if errors.As(err, &domainValidationErr) {
+		return errs.WrapCode(err, errs.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrCouponNotFound) || errors.Is(err, domain.ErrBillNotFound) {
+		return errs.WrapCode(err, errs.NotFound, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrCouponExists) {
+		return errs.WrapCode(err, errs.AlreadyExists, err.Error())
+	}
+
+	if errors.Is(err, domain.ErrCouponNotActive) || errors.Is(err, domain.ErrCouponCurrency) ||
+		errors.Is(err, domain.ErrCouponExhausted) || errors.Is(err, domain.ErrCouponApplied) ||
+		errors.Is(err, domain.ErrBillClosed) || errors.Is(err, domain.ErrBillVoided) {
+		return errs.WrapCode(err, errs.FailedPrecondition, err.Error())
+	}
 
+	return errs.WrapCode(err, errs.Internal, "internal server error")
 }
 
 // Shutdown hanlde graceful shutdown.
@@ -0,0 +1,70 @@
+//go:build encore_app
+
+// The billing package declares its database at init, which requires the
+// Encore runtime: these tests run with `encore test ./billing/...`.
+
+package billing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.app/billing/domain"
+	"encore.app/billing/usecases"
+	mock_usecases "encore.app/billing/usecases/mock"
+	"encore.dev/beta/errs"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_CloseBillingByID(t *testing.T) {
+	mockUseCase := mock_usecases.NewMockBillingUseCase(gomock.NewController(t))
+	s := &Service{useCase: mockUseCase}
+	ctx := context.Background()
+
+	mockUseCase.EXPECT().CloseBill(ctx, usecases.CloseBillRequest{BillingID: "B-1"}).Return(domain.Bill{
+		BillingID:     "B-1",
+		Status:        domain.BillStatusClosed,
+		Currency:      domain.CurrencyUSD,
+		InvoiceNumber: "INV-2025-000001",
+		Items:         []domain.Item{{ID: 1, Name: "Espresso", Quantity: 1, UnitPrice: 1000, Price: 1000}},
+		Total:         1000,
+		CreditApplied: 300,
+	}, nil).Times(1)
+
+	resp, err := s.CloseBillingByID(ctx, "B-1", &CloseBillingRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INV-2025-000001", resp.InvoiceNumber)
+	assert.Equal(t, newAmount(domain.CurrencyUSD, 1000), resp.GrandTotal)
+	assert.Equal(t, newAmount(domain.CurrencyUSD, 300), resp.CreditApplied)
+	assert.Equal(t, newAmount(domain.CurrencyUSD, 700), resp.AmountDue)
+}
+
+func TestService_CloseBillingByIDErrors(t *testing.T) {
+	testCases := []struct {
+		condition    string
+		err          error
+		expectedCode errs.ErrCode
+	}{
+		{condition: "bill not found", err: domain.ErrBillNotFound, expectedCode: errs.NotFound},
+		{condition: "bill already closed", err: domain.ErrBillClosed, expectedCode: errs.FailedPrecondition},
+		{condition: "bill voided", err: domain.ErrBillVoided, expectedCode: errs.FailedPrecondition},
+		{condition: "unexpected error", err: errors.New("unexpected error"), expectedCode: errs.Internal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.condition, func(t *testing.T) {
+			mockUseCase := mock_usecases.NewMockBillingUseCase(gomock.NewController(t))
+			s := &Service{useCase: mockUseCase}
+			ctx := context.Background()
+
+			mockUseCase.EXPECT().CloseBill(ctx, usecases.CloseBillRequest{BillingID: "B-1"}).Return(domain.Bill{}, tc.err).Times(1)
+
+			_, err := s.CloseBillingByID(ctx, "B-1", &CloseBillingRequest{})
+
+			assert.Equal(t, tc.expectedCode, errs.Code(err))
+		})
+	}
+}
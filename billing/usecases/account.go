@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"encore.app/billing/domain"
+)
+
+func (u *billingUseCase) CreateAccount(ctx context.Context, req CreateAccountRequest) (domain.Account, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Email = strings.TrimSpace(req.Email)
+	if err := validateAccount(req.Name, req.Email); err != nil {
+		return domain.Account{}, err
+	}
+
+	now := u.clock.Now()
+	account := domain.Account{
+		ID:        u.idGenerator.GenerateBillingID("Acc"),
+		Name:      req.Name,
+		Email:     req.Email,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.repo.SaveAccount(ctx, &account); err != nil {
+		return domain.Account{}, fmt.Errorf("failed to save account: %w", err)
+	}
+
+	return account, nil
+}
+
+func (u *billingUseCase) GetAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	if accountID == "" {
+		return domain.Account{}, domain.ValidationError{Field: "accountId", Message: "account ID is required"}
+	}
+
+	account, err := u.repo.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return domain.Account{}, err
+		}
+		return domain.Account{}, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	return account, nil
+}
+
+func (u *billingUseCase) UpdateAccount(ctx context.Context, req UpdateAccountRequest) (domain.Account, error) {
+	if req.AccountID == "" {
+		return domain.Account{}, domain.ValidationError{Field: "accountId", Message: "account ID is required"}
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Email = strings.TrimSpace(req.Email)
+	if err := validateAccount(req.Name, req.Email); err != nil {
+		return domain.Account{}, err
+	}
+
+	account := domain.Account{
+		ID:        req.AccountID,
+		Name:      req.Name,
+		Email:     req.Email,
+		UpdatedAt: u.clock.Now(),
+	}
+
+	if err := u.repo.UpdateAccount(ctx, &account); err != nil {
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return domain.Account{}, err
+		}
+		return domain.Account{}, fmt.Errorf("failed to update account: %w", err)
+	}
+
+	return account, nil
+}
+
+func (u *billingUseCase) DeleteAccount(ctx context.Context, accountID string) error {
+	if accountID == "" {
+		return domain.ValidationError{Field: "accountId", Message: "account ID is required"}
+	}
+
+	if err := u.repo.DeleteAccount(ctx, accountID); err != nil {
+		if errors.Is(err, domain.ErrAccountNotFound) || errors.Is(err, domain.ErrAccountHasBills) ||
+			errors.Is(err, domain.ErrAccountHasCredit) {
+			return err
+		}
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	return nil
+}
+
+// GetAccountBills returns the bills of an account matching the metadata
+// filters, most recent first. Open bills are read from their running
+// workflow so that their totals are up to date, the others come from the database.
+func (u *billingUseCase) GetAccountBills(ctx context.Context, req GetAccountBillsRequest) ([]domain.Bill, error) {
+	filter, err := parseMetadataFilter(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.GetAccount(ctx, req.AccountID); err != nil {
+		return nil, err
+	}
+
+	bills, err := u.repo.GetBillsByAccountID(ctx, req.AccountID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account bills: %w", err)
+	}
+
+	for i, bill := range bills {
+		if !bill.IsOpen() {
+			continue
+		}
+
+		current, err := u.workflowClient.QueryWorkflow(ctx, bill.BillingID)
+		if err != nil {
+			continue
+		}
+		bills[i] = current
+	}
+
+	return bills, nil
+}
+
+// parseMetadataFilter parses "key:value" filters into the metadata a bill
+// must contain.
+func parseMetadataFilter(filters []string) (domain.Metadata, error) {
+	metadata := domain.Metadata{}
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, ":")
+		if !ok || key == "" {
+			return nil, domain.ValidationError{Field: "metadata", Message: "metadata filter must be formatted as key:value"}
+		}
+		metadata[key] = value
+	}
+	return metadata, validateMetadata(metadata)
+}
+
+func validateAccount(name, email string) error {
+	if name == "" {
+		return domain.ValidationError{Field: "name", Message: "name is required"}
+	}
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return domain.ValidationError{Field: "email", Message: "email is invalid"}
+		}
+	}
+	return nil
+}
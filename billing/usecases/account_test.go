@@ -0,0 +1,291 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	mock_usecases "encore.app/billing/usecases/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *billingUseCaseTestSuite) TestCreateAccount() {
+	mockAccountID := "Acc-account-id"
+
+	testCases := []struct {
+		condition       string
+		argument        usecases.CreateAccountRequest
+		expectedAccount domain.Account
+		expectedErr     error
+		doMock          func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: name is empty",
+			argument:    usecases.CreateAccountRequest{Name: "  "},
+			expectedErr: domain.ValidationError{Field: "name", Message: "name is required"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: email is invalid",
+			argument:    usecases.CreateAccountRequest{Name: "Acme", Email: "not-an-email"},
+			expectedErr: domain.ValidationError{Field: "email", Message: "email is invalid"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition: "success",
+			argument:  usecases.CreateAccountRequest{Name: " Acme ", Email: "billing@acme.test"},
+			expectedAccount: domain.Account{
+				ID:        mockAccountID,
+				Name:      "Acme",
+				Email:     "billing@acme.test",
+				CreatedAt: mockTime,
+				UpdatedAt: mockTime,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("Acc").Return(mockAccountID).Times(1)
+				mockRepo.EXPECT().SaveAccount(ctx, &domain.Account{
+					ID:        mockAccountID,
+					Name:      "Acme",
+					Email:     "billing@acme.test",
+					CreatedAt: mockTime,
+					UpdatedAt: mockTime,
+				}).Return(nil).Times(1)
+			},
+		},
+		{
+			condition:   "fail to save account",
+			argument:    usecases.CreateAccountRequest{Name: "Acme"},
+			expectedErr: fmt.Errorf("failed to save account: %w", errors.New("unexpected error")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("Acc").Return(mockAccountID).Times(1)
+				mockRepo.EXPECT().SaveAccount(ctx, gomock.Any()).Return(errors.New("unexpected error")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			account, err := uc.CreateAccount(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedAccount, account)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestUpdateAccount() {
+	mockAccountID := "Acc-account-id"
+
+	testCases := []struct {
+		condition       string
+		argument        usecases.UpdateAccountRequest
+		expectedAccount domain.Account
+		expectedErr     error
+		doMock          func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: account id is empty",
+			argument:    usecases.UpdateAccountRequest{Name: "Acme"},
+			expectedErr: domain.ValidationError{Field: "accountId", Message: "account ID is required"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "account not found",
+			argument:    usecases.UpdateAccountRequest{AccountID: mockAccountID, Name: "Acme"},
+			expectedErr: domain.ErrAccountNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().UpdateAccount(ctx, gomock.Any()).Return(domain.ErrAccountNotFound).Times(1)
+			},
+		},
+		{
+			condition: "success",
+			argument:  usecases.UpdateAccountRequest{AccountID: mockAccountID, Name: "Acme Inc", Email: "ap@acme.test"},
+			expectedAccount: domain.Account{
+				ID:        mockAccountID,
+				Name:      "Acme Inc",
+				Email:     "ap@acme.test",
+				UpdatedAt: mockTime,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().UpdateAccount(ctx, &domain.Account{
+					ID:        mockAccountID,
+					Name:      "Acme Inc",
+					Email:     "ap@acme.test",
+					UpdatedAt: mockTime,
+				}).Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			account, err := uc.UpdateAccount(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedAccount, account)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestDeleteAccount() {
+	mockAccountID := "Acc-account-id"
+
+	testCases := []struct {
+		condition   string
+		accountID   string
+		expectedErr error
+		doMock      func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: account id is empty",
+			accountID:   "",
+			expectedErr: domain.ValidationError{Field: "accountId", Message: "account ID is required"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "account has bills",
+			accountID:   mockAccountID,
+			expectedErr: domain.ErrAccountHasBills,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().DeleteAccount(ctx, mockAccountID).Return(domain.ErrAccountHasBills).Times(1)
+			},
+		},
+		{
+			condition:   "account has wallet credit",
+			accountID:   mockAccountID,
+			expectedErr: domain.ErrAccountHasCredit,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().DeleteAccount(ctx, mockAccountID).Return(domain.ErrAccountHasCredit).Times(1)
+			},
+		},
+		{
+			condition:   "success",
+			accountID:   mockAccountID,
+			expectedErr: nil,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().DeleteAccount(ctx, mockAccountID).Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			err := uc.DeleteAccount(ctx, tc.accountID)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestGetAccountBills() {
+	mockAccountID := "Acc-account-id"
+	openBill := domain.Bill{BillingID: "Bill-open", AccountID: mockAccountID, Status: domain.BillStatusOpen}
+	runningBill := domain.Bill{
+		BillingID: "Bill-open",
+		AccountID: mockAccountID,
+		Status:    domain.BillStatusOpen,
+		Items:     []domain.Item{{ID: 1, Price: 1000}},
+		Total:     1000,
+	}
+	closedBill := domain.Bill{BillingID: "Bill-closed", AccountID: mockAccountID, Status: domain.BillStatusClosed, Total: 2500}
+
+	testCases := []struct {
+		condition     string
+		argument      usecases.GetAccountBillsRequest
+		expectedBills []domain.Bill
+		expectedErr   error
+		doMock        func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "validation failed: account id is empty",
+			argument:    usecases.GetAccountBillsRequest{},
+			expectedErr: domain.ValidationError{Field: "accountId", Message: "account ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "account not found",
+			argument:    usecases.GetAccountBillsRequest{AccountID: mockAccountID},
+			expectedErr: domain.ErrAccountNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{}, domain.ErrAccountNotFound).Times(1)
+			},
+		},
+		{
+			condition:   "validation failed: malformed metadata filter",
+			argument:    usecases.GetAccountBillsRequest{AccountID: mockAccountID, Metadata: []string{"table"}},
+			expectedErr: domain.ValidationError{Field: "metadata", Message: "metadata filter must be formatted as key:value"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:     "filter by metadata",
+			argument:      usecases.GetAccountBillsRequest{AccountID: mockAccountID, Metadata: []string{"orderId:A-12", "table:7"}},
+			expectedBills: []domain.Bill{closedBill},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
+				mockRepo.EXPECT().
+					GetBillsByAccountID(ctx, mockAccountID, domain.Metadata{"orderId": "A-12", "table": "7"}).
+					Return([]domain.Bill{closedBill}, nil).
+					Times(1)
+			},
+		},
+		{
+			condition:     "open bills are read from their workflow",
+			argument:      usecases.GetAccountBillsRequest{AccountID: mockAccountID},
+			expectedBills: []domain.Bill{runningBill, closedBill},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
+				mockRepo.EXPECT().GetBillsByAccountID(ctx, mockAccountID, domain.Metadata{}).Return([]domain.Bill{openBill, closedBill}, nil).Times(1)
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "Bill-open").Return(runningBill, nil).Times(1)
+			},
+		},
+		{
+			condition:     "open bill falls back to the database when its workflow cannot be queried",
+			argument:      usecases.GetAccountBillsRequest{AccountID: mockAccountID},
+			expectedBills: []domain.Bill{openBill},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
+				mockRepo.EXPECT().GetBillsByAccountID(ctx, mockAccountID, domain.Metadata{}).Return([]domain.Bill{openBill}, nil).Times(1)
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "Bill-open").Return(domain.Bill{}, errors.New("workflow not running")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			bills, err := uc.GetAccountBills(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedBills, bills)
+		})
+	}
+}
@@ -7,17 +7,90 @@ import (
 	"encore.app/billing/domain"
 )
 
+// CreateAccountRequest represents the payload for creating a new account.
+// Name is required, Email is optional.
+type CreateAccountRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// UpdateAccountRequest represents the payload for updating an account.
+type UpdateAccountRequest struct {
+	AccountID string `json:"accountId"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+}
+
+// GetAccountBillsRequest represents the payload to list the bills of an account.
+// Metadata filters are "key:value" pairs, only bills whose metadata contains
+// every pair are returned.
+type GetAccountBillsRequest struct {
+	AccountID string   `json:"accountId"`
+	Metadata  []string `json:"metadata"`
+}
+
+// TopUpWalletRequest represents the payload to add prepaid credit to the
+// wallet of an account. Currency must be either "USD" or "GEL" and Amount is
+// expressed in its smallest unit. Reference is optional, e.g. a receipt number.
+type TopUpWalletRequest struct {
+	AccountID string `json:"accountId"`
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+}
+
 // CreateBillRequest represents the payload for creating a new bill.
+// AccountID is required and must reference an existing account.
 // Currency must be either "USD" or "GEL".
+// Region is optional and selects region-specific tax rates.
+// PeriodEnd or Recurrence, evaluated in Timezone, optionally set when the
+// bill is closed automatically. MaxTotal optionally caps the total of the
+// bill; zero means no cap. Metadata is optional.
 type CreateBillRequest struct {
-	Currency string `json:"currency"`
+	AccountID  string          `json:"accountId"`
+	Currency   string          `json:"currency"`
+	Region     string          `json:"region"`
+	PeriodEnd  *time.Time      `json:"periodEnd"`
+	Recurrence string          `json:"recurrence"`
+	Timezone   string          `json:"timezone"`
+	MaxTotal   int64           `json:"maxTotal"`
+	Metadata   domain.Metadata `json:"metadata"`
 }
 
 // AddItemRequest represents the payload to add a new item to an existing bill.
+// Price is kept for single-price items: when UnitPrice is empty, Price is used
+// as the unit price. Quantity defaults to 1. Metadata is optional.
+// Items can instead be added by SKU, in which case their name, unit and unit
+// price come from the catalog and Name, Unit, Price and UnitPrice must be empty.
 type AddItemRequest struct {
-	BillingID string `json:"billingId"`
-	Name      string `json:"name"`
-	Price     int64  `json:"price"`
+	BillingID string          `json:"billingId"`
+	SKU       string          `json:"sku"`
+	Name      string          `json:"name"`
+	Price     int64           `json:"price"`
+	Quantity  int64           `json:"quantity"`
+	Unit      string          `json:"unit"`
+	UnitPrice int64           `json:"unitPrice"`
+	Metadata  domain.Metadata `json:"metadata"`
+}
+
+// VoidItemRequest represents the payload to void an item of an existing bill.
+// VoidedAt is set by the use case and carried to the workflow.
+type VoidItemRequest struct {
+	BillingID string    `json:"billingId"`
+	ItemID    int64     `json:"itemId"`
+	VoidedAt  time.Time `json:"voidedAt"`
+}
+
+// ApplyDiscountRequest represents the payload to apply a discount to an existing bill.
+// ItemID is optional, a zero ItemID applies the discount to the whole bill.
+// Value is expressed in basis points for PERCENTAGE discounts and in the
+// smallest currency unit for FIXED discounts.
+type ApplyDiscountRequest struct {
+	BillingID   string `json:"billingId"`
+	ItemID      int64  `json:"itemId"`
+	Type        string `json:"type"`
+	Value       int64  `json:"value"`
+	Description string `json:"description"`
 }
 
 // CloseBillRequest represents the payload to close an existing bill.
@@ -26,12 +99,172 @@ type CloseBillRequest struct {
 	BillingID string              `json:"billingId"`
 	Currency  string              `json:"currency"`
 	ClosedAt  time.Time           `json:"closedAt"`
+	Taxes     []domain.TaxLine    `json:"taxes"`
 	Exchange  domain.BillExchange `json:"exchange"`
 }
 
+// ReopenBillRequest represents the payload to reopen a closed bill.
+// Reason is required and recorded in the bill's audit trail.
+type ReopenBillRequest struct {
+	BillingID string `json:"billingId"`
+	Reason    string `json:"reason"`
+}
+
+// VoidBillRequest represents the payload to void an open bill.
+// Reason is required and recorded in the bill's audit trail,
+// VoidedAt is set by the use case and carried to the workflow.
+type VoidBillRequest struct {
+	BillingID string    `json:"billingId"`
+	Reason    string    `json:"reason"`
+	VoidedAt  time.Time `json:"voidedAt"`
+}
+
+// RecordPaymentRequest represents the payload to record a payment against a closed bill.
+// Amount is expressed in the smallest unit of Currency, which defaults to the
+// bill currency. Reference is optional, e.g. a receipt number.
+type RecordPaymentRequest struct {
+	BillingID string `json:"billingId"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+}
+
+// ConfirmPaymentRequest represents the asynchronous confirmation of a pending
+// provider payment, as notified by the payment provider.
+type ConfirmPaymentRequest struct {
+	BillingID     string `json:"billingId"`
+	PaymentID     string `json:"paymentId"`
+	Status        string `json:"status"`
+	DeclineReason string `json:"declineReason"`
+}
+
+// IssueCreditNoteRequest represents the payload to issue a credit note against
+// a closed bill. Each line credits an amount, in the smallest unit of the bill
+// currency, for a single item or, when ItemID is zero, for the whole bill.
+type IssueCreditNoteRequest struct {
+	BillingID string                  `json:"billingId"`
+	Reason    string                  `json:"reason"`
+	Lines     []domain.CreditNoteLine `json:"lines"`
+}
+
+// RecordUsageRequest represents the payload to record usage events of metered
+// features for an open bill. Each event needs an EventID, unique within the
+// bill, a Meter and a positive Quantity. Timestamp defaults to the time the
+// event is recorded.
+type RecordUsageRequest struct {
+	BillingID string              `json:"billingId"`
+	Events    []domain.UsageEvent `json:"events"`
+}
+
 // PayloadToBytes convert request argument `r` to []byte
 // to generate idempotency key.
 func PayloadToBytes(r any) []byte {
 	b, _ := json.Marshal(r)
 	return b
 }
+
+// CreateSubscriptionRequest represents the payload for creating a new subscription.
+// AccountID is required and must reference an existing account.
+// Currency must be either "USD" or "GEL". Region is optional.
+// Recurrence, evaluated in Timezone, sets the period of every cycle, and
+// Items are the plan items billed every cycle.
+type CreateSubscriptionRequest struct {
+	AccountID  string            `json:"accountId"`
+	Currency   string            `json:"currency"`
+	Region     string            `json:"region"`
+	Recurrence string            `json:"recurrence"`
+	Timezone   string            `json:"timezone"`
+	Items      []domain.PlanItem `json:"items"`
+}
+
+// SubscriptionRequest represents the payload to pause, resume or cancel a
+// subscription. RequestedAt is set by the use case and carried to the workflow.
+type SubscriptionRequest struct {
+	SubscriptionID string    `json:"subscriptionId"`
+	RequestedAt    time.Time `json:"requestedAt"`
+}
+
+// ChangePlanRequest represents the payload to change the plan of a
+// subscription. Items replace the plan items, and ProrationPrecision, DAY or
+// SECOND, overrides the default precision of the proration of the change on
+// the open bill. RequestedAt is set by the use case and carried to the workflow.
+type ChangePlanRequest struct {
+	SubscriptionID     string                    `json:"subscriptionId"`
+	Items              []domain.PlanItem         `json:"items"`
+	ProrationPrecision domain.ProrationPrecision `json:"prorationPrecision"`
+	RequestedAt        time.Time                 `json:"requestedAt"`
+}
+
+// CreateProductRequest represents the payload for creating a product of the
+// catalog. Name is required, Description is optional.
+type CreateProductRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateProductRequest represents the payload for updating a product of the catalog.
+type UpdateProductRequest struct {
+	ProductID   string `json:"productId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CreateSKURequest represents the payload for creating a SKU of a product.
+// Code identifies the SKU in the catalog and is made of letters, digits,
+// dots, dashes and underscores. Currency must be either "USD" or "GEL" and
+// UnitPrice is expressed in its smallest unit. Prices optionally lists the
+// unit prices in the other currencies the SKU is sold in. Name defaults to
+// the name of the product, Unit is optional.
+type CreateSKURequest struct {
+	ProductID string           `json:"productId"`
+	Code      string           `json:"code"`
+	Name      string           `json:"name"`
+	Unit      string           `json:"unit"`
+	Currency  string           `json:"currency"`
+	UnitPrice int64            `json:"unitPrice"`
+	Prices    domain.PriceList `json:"prices"`
+}
+
+// UpdateSKURequest represents the payload for updating a SKU, which creates
+// its next version.
+type UpdateSKURequest struct {
+	Code      string           `json:"code"`
+	Name      string           `json:"name"`
+	Unit      string           `json:"unit"`
+	Currency  string           `json:"currency"`
+	UnitPrice int64            `json:"unitPrice"`
+	Prices    domain.PriceList `json:"prices"`
+}
+
+// GetSKURequest represents the payload to fetch a SKU. A zero Version
+// returns the current version of the SKU.
+type GetSKURequest struct {
+	Code    string `json:"code"`
+	Version int64  `json:"version"`
+}
+
+// CreateCouponRequest represents the payload for creating a coupon. Code is
+// made of letters, digits, dashes and underscores and is stored uppercase.
+// Value is expressed in basis points for PERCENTAGE coupons and in the
+// smallest unit of their only currency for FIXED coupons. Currencies
+// optionally restricts the bills the coupon can be applied to. ValidFrom and
+// ValidUntil optionally bound the validity window of the coupon, and
+// MaxRedemptions and MaxRedemptionsPerAccount optionally limit the number of
+// bills it can be redeemed by.
+type CreateCouponRequest struct {
+	Code                     string     `json:"code"`
+	Description              string     `json:"description"`
+	Type                     string     `json:"type"`
+	Value                    int64      `json:"value"`
+	Currencies               []string   `json:"currencies"`
+	ValidFrom                *time.Time `json:"validFrom"`
+	ValidUntil               *time.Time `json:"validUntil"`
+	MaxRedemptions           int64      `json:"maxRedemptions"`
+	MaxRedemptionsPerAccount int64      `json:"maxRedemptionsPerAccount"`
+}
+
+// ApplyCouponRequest represents the payload to apply a coupon to an existing bill.
+type ApplyCouponRequest struct {
+	BillingID string `json:"billingId"`
+	Code      string `json:"code"`
+}
@@ -2,7 +2,9 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"encore.app/billing/domain"
 	"encore.app/pkg/clock"
@@ -16,6 +18,66 @@ type billingUseCase struct {
 	workflowClient WorkflowClient
 	idGenerator    generator.IDProvider
 	clock          clock.Clock
+	taxEngine      domain.TaxEngine
+	meters         domain.MeterCatalog
+
+	prorationPrecision     domain.ProrationPrecision
+	catalogPriceConversion bool
+	reopenGracePeriod      time.Duration
+	closeWaitTimeout       time.Duration
+}
+
+// Option configures optional behaviour of the billing use case
+type Option func(*billingUseCase)
+
+// WithTaxEngine sets the tax engine used to calculate taxes when a bill is closed.
+// Without it, bills are closed without taxes.
+func WithTaxEngine(taxEngine domain.TaxEngine) Option {
+	return func(u *billingUseCase) {
+		u.taxEngine = taxEngine
+	}
+}
+
+// WithMeters sets the meters usage can be recorded for.
+// Without it, no usage can be recorded.
+func WithMeters(meters domain.MeterCatalog) Option {
+	return func(u *billingUseCase) {
+		u.meters = meters
+	}
+}
+
+// WithProrationPrecision sets the default precision of the proration of
+// subscription plan changes. Without it, plan changes are prorated by day.
+func WithProrationPrecision(precision domain.ProrationPrecision) Option {
+	return func(u *billingUseCase) {
+		u.prorationPrecision = precision
+	}
+}
+
+// WithCatalogPriceConversion sets whether SKUs without a price in the
+// currency of a bill are added to it at their price converted with the
+// exchange rates. Without it, such SKUs cannot be added.
+func WithCatalogPriceConversion(enabled bool) Option {
+	return func(u *billingUseCase) {
+		u.catalogPriceConversion = enabled
+	}
+}
+
+// WithReopenGracePeriod sets how long after closing a bill can be reopened.
+// Without it, closed bills cannot be reopened.
+func WithReopenGracePeriod(gracePeriod time.Duration) Option {
+	return func(u *billingUseCase) {
+		u.reopenGracePeriod = gracePeriod
+	}
+}
+
+// WithCloseWaitTimeout sets how long CloseBill waits for the bill workflow to
+// close the bill, so that the bill it returns has its invoice number.
+// Without it, CloseBill returns as soon as the close is requested.
+func WithCloseWaitTimeout(timeout time.Duration) Option {
+	return func(u *billingUseCase) {
+		u.closeWaitTimeout = timeout
+	}
 }
 
 // NewBillingUseCase creates a new billing use case
@@ -24,13 +86,22 @@ func NewBillingUseCase(
 	workflowClient WorkflowClient,
 	idGenerator generator.IDProvider,
 	clock clock.Clock,
+	opts ...Option,
 ) BillingUseCase {
-	return &billingUseCase{
+	u := &billingUseCase{
 		repo:           repo,
 		workflowClient: workflowClient,
 		idGenerator:    idGenerator,
 		clock:          clock,
+
+		prorationPrecision: domain.ProrationPrecisionDay,
 	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
 }
 
 // CreateBill creates a new bill and starts the workflow
@@ -39,14 +110,32 @@ func (u *billingUseCase) CreateBill(ctx context.Context, req CreateBillRequest)
 		return "", err
 	}
 
+	if _, err := u.repo.GetAccount(ctx, req.AccountID); err != nil {
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to get account: %w", err)
+	}
+
+	now := u.clock.Now()
+	periodEnd, err := u.resolvePeriodEnd(req, now)
+	if err != nil {
+		return "", err
+	}
+
 	billingID := u.idGenerator.GenerateBillingID("Bill")
 	bill := &domain.Bill{
 		BillingID: billingID,
+		AccountID: req.AccountID,
 		Status:    domain.BillStatusOpen,
 		Currency:  domain.Currency(req.Currency),
+		Region:    req.Region,
 		Total:     0,
+		MaxTotal:  req.MaxTotal,
 		Items:     []domain.Item{},
-		CreatedAt: u.clock.Now(),
+		PeriodEnd: periodEnd,
+		Metadata:  req.Metadata,
+		CreatedAt: now,
 	}
 
 	if err := u.workflowClient.StartWorkflow(ctx, bill.BillingID, bill); err != nil {
@@ -62,8 +151,10 @@ func (u *billingUseCase) GetBill(ctx context.Context, billingID string) (domain.
 		return domain.Bill{}, domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
 	}
 
+	// closed workflows can still be queried, but payments are only recorded
+	// in the database, so only open bills are read from their workflow.
 	bill, err := u.workflowClient.QueryWorkflow(ctx, billingID)
-	if err == nil {
+	if err == nil && bill.IsOpen() {
 		return bill, nil
 	}
 
@@ -75,12 +166,30 @@ func (u *billingUseCase) GetBill(ctx context.Context, billingID string) (domain.
 	return bill, nil
 }
 
-// AddItem adds an item to a bill
+// AddItem adds an item to a bill. Items added by SKU are priced from the
+// catalog in the currency of the bill.
 func (u *billingUseCase) AddItem(ctx context.Context, req AddItemRequest) (domain.Bill, error) {
 	if err := u.validateAddItemRequest(req); err != nil {
 		return domain.Bill{}, err
 	}
 
+	quantity, unitPrice := req.Quantity, req.UnitPrice
+	if quantity == 0 {
+		quantity = 1
+	}
+	if unitPrice == 0 {
+		unitPrice = req.Price
+	}
+
+	var price int64
+	if req.SKU == "" {
+		var err error
+		price, err = domain.LineAmount(quantity, unitPrice)
+		if err != nil {
+			return domain.Bill{}, domain.ValidationError{Field: "quantity", Message: "quantity multiplied by unit price is too large"}
+		}
+	}
+
 	bill, err := u.GetBill(ctx, req.BillingID)
 	if err != nil {
 		return domain.Bill{}, err
@@ -90,13 +199,30 @@ func (u *billingUseCase) AddItem(ctx context.Context, req AddItemRequest) (domai
 		return domain.Bill{}, domain.ErrBillClosed
 	}
 
-	idempotencyKey := u.idGenerator.GenerateIdempotencyKey("idem", PayloadToBytes(req))
+	if bill.IsVoided() {
+		return domain.Bill{}, domain.ErrBillVoided
+	}
+
 	item := domain.Item{
-		BillingID:      req.BillingID,
-		Name:           req.Name,
-		Price:          req.Price,
-		IdempotencyKey: idempotencyKey,
+		BillingID: req.BillingID,
+		Name:      req.Name,
+		Quantity:  quantity,
+		Unit:      req.Unit,
+		UnitPrice: unitPrice,
+		Price:     price,
 	}
+	if req.SKU != "" {
+		item, err = u.catalogItem(ctx, bill, req.SKU, quantity)
+		if err != nil {
+			return domain.Bill{}, err
+		}
+	}
+	if bill.ExceedsSpendingCap(item) {
+		return domain.Bill{}, domain.ErrSpendingCapExceeded
+	}
+	item.IdempotencyKey = u.idGenerator.GenerateIdempotencyKey("idem", PayloadToBytes(req))
+	item.Metadata = req.Metadata
+
 	bill.Items = append(bill.Items, item)
 	bill.Total = bill.GetTotal()
 
@@ -107,6 +233,77 @@ func (u *billingUseCase) AddItem(ctx context.Context, req AddItemRequest) (domai
 	return bill, nil
 }
 
+// VoidItem voids an item of an open bill
+func (u *billingUseCase) VoidItem(ctx context.Context, req VoidItemRequest) (domain.Bill, error) {
+	if err := u.validateVoidItemRequest(req); err != nil {
+		return domain.Bill{}, err
+	}
+
+	bill, err := u.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	if bill.IsClosed() {
+		return domain.Bill{}, domain.ErrBillClosed
+	}
+
+	if bill.IsVoided() {
+		return domain.Bill{}, domain.ErrBillVoided
+	}
+
+	req.VoidedAt = u.clock.Now()
+	if err := bill.VoidItem(req.ItemID, req.VoidedAt); err != nil {
+		return domain.Bill{}, err
+	}
+
+	if err := u.workflowClient.SignalWorkflow(ctx, req.BillingID, domain.SignalVoidLineItem, req); err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to void item: %w", err)
+	}
+
+	return bill, nil
+}
+
+// ApplyDiscount applies an item-level or bill-level discount to an open bill
+func (u *billingUseCase) ApplyDiscount(ctx context.Context, req ApplyDiscountRequest) (domain.Bill, error) {
+	if err := u.validateApplyDiscountRequest(req); err != nil {
+		return domain.Bill{}, err
+	}
+
+	bill, err := u.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	if bill.IsClosed() {
+		return domain.Bill{}, domain.ErrBillClosed
+	}
+
+	if bill.IsVoided() {
+		return domain.Bill{}, domain.ErrBillVoided
+	}
+
+	idempotencyKey := u.idGenerator.GenerateIdempotencyKey("disc", PayloadToBytes(req))
+	discount := domain.Discount{
+		BillingID:      req.BillingID,
+		ItemID:         req.ItemID,
+		Type:           domain.DiscountType(req.Type),
+		Value:          req.Value,
+		Description:    req.Description,
+		IdempotencyKey: idempotencyKey,
+		CreatedAt:      u.clock.Now(),
+	}
+	if err := bill.ApplyDiscount(discount); err != nil {
+		return domain.Bill{}, err
+	}
+
+	if err := u.workflowClient.SignalWorkflow(ctx, req.BillingID, domain.SignalApplyDiscount, discount); err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to apply discount: %w", err)
+	}
+
+	return bill, nil
+}
+
 // CloseBill closes a bill
 func (u *billingUseCase) CloseBill(ctx context.Context, req CloseBillRequest) (domain.Bill, error) {
 	if err := u.validateCloseBillRequest(req); err != nil {
@@ -122,11 +319,18 @@ func (u *billingUseCase) CloseBill(ctx context.Context, req CloseBillRequest) (d
 		return domain.Bill{}, domain.ErrBillClosed
 	}
 
+	if bill.IsVoided() {
+		return domain.Bill{}, domain.ErrBillVoided
+	}
+
 	closedAt := u.clock.Now()
 	req.ClosedAt = closedAt
 
+	bill.Taxes = u.taxEngine.Calculate(bill)
+	req.Taxes = bill.Taxes
+
 	if req.Currency != "" {
-		converted, rate, _ := conversion.ConvertAmount(bill.Total, string(bill.Currency), req.Currency)
+		converted, rate, _ := conversion.ConvertAmount(bill.GetGrandTotal(), string(bill.Currency), req.Currency)
 		bill.Conversion = domain.BillExchange{
 			BillID:         bill.BillingID,
 			BaseCurrency:   bill.Currency,
@@ -143,30 +347,278 @@ func (u *billingUseCase) CloseBill(ctx context.Context, req CloseBillRequest) (d
 	}
 
 	bill.Close(closedAt)
+
+	if u.closeWaitTimeout > 0 {
+		return u.waitForClosedBill(ctx, bill), nil
+	}
+
 	return bill, nil
 }
 
+// waitForClosedBill waits for the bill workflow to complete the close and
+// returns the closed bill as persisted, with its invoice number. When the
+// close does not complete within the close wait timeout, the requested
+// bill is returned without invoice number.
+func (u *billingUseCase) waitForClosedBill(ctx context.Context, bill domain.Bill) domain.Bill {
+	waitCtx, cancel := context.WithTimeout(ctx, u.closeWaitTimeout)
+	defer cancel()
+
+	if err := u.workflowClient.WaitWorkflow(waitCtx, bill.BillingID); err != nil {
+		return bill
+	}
+
+	closed, err := u.repo.GetBill(ctx, bill.BillingID)
+	if err != nil || !closed.IsClosed() {
+		return bill
+	}
+
+	return closed
+}
+
+// ReopenBill reopens a bill closed less than the grace period ago.
+// The closing is reverted in the database, the reason is recorded in the
+// audit trail and the bill workflow is restarted from the database state.
+func (u *billingUseCase) ReopenBill(ctx context.Context, req ReopenBillRequest) (domain.Bill, error) {
+	if err := u.validateReopenBillRequest(req); err != nil {
+		return domain.Bill{}, err
+	}
+
+	bill, err := u.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	if !bill.IsClosed() {
+		return domain.Bill{}, domain.ErrBillNotClosed
+	}
+
+	if len(bill.Payments) > 0 {
+		return domain.Bill{}, domain.ErrBillHasPayments
+	}
+
+	if len(bill.CreditNotes) > 0 {
+		return domain.Bill{}, domain.ErrBillHasCreditNotes
+	}
+
+	now := u.clock.Now()
+	if bill.ClosedAt == nil || now.Sub(*bill.ClosedAt) > u.reopenGracePeriod {
+		return domain.Bill{}, domain.ErrReopenWindowExpired
+	}
+
+	entry := domain.AuditEntry{
+		BillingID: req.BillingID,
+		Action:    domain.AuditActionReopen,
+		Reason:    req.Reason,
+		CreatedAt: now,
+	}
+	if err := u.repo.ReopenBill(ctx, entry); err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to reopen bill: %w", err)
+	}
+
+	bill, err = u.repo.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to get reopened bill: %w", err)
+	}
+
+	if err := u.workflowClient.StartWorkflow(ctx, bill.BillingID, &bill); err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to start workflow: %w", err)
+	}
+
+	return bill, nil
+}
+
+// VoidBill voids an open bill, for example when it was opened by mistake.
+// The workflow persists the void, records the reason in the audit trail and completes.
+func (u *billingUseCase) VoidBill(ctx context.Context, req VoidBillRequest) (domain.Bill, error) {
+	if err := u.validateVoidBillRequest(req); err != nil {
+		return domain.Bill{}, err
+	}
+
+	bill, err := u.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	if bill.IsClosed() {
+		return domain.Bill{}, domain.ErrBillClosed
+	}
+
+	if bill.IsVoided() {
+		return domain.Bill{}, domain.ErrBillVoided
+	}
+
+	req.VoidedAt = u.clock.Now()
+	if err := u.workflowClient.SignalWorkflow(ctx, req.BillingID, domain.SignalVoidBill, req); err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to void bill: %w", err)
+	}
+
+	bill.Void(req.VoidedAt)
+	return bill, nil
+}
+
+// GetAuditTrail retrieves the audit trail of a bill
+func (u *billingUseCase) GetAuditTrail(ctx context.Context, billingID string) ([]domain.AuditEntry, error) {
+	if billingID == "" {
+		return nil, domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+
+	entries, err := u.repo.GetAuditEntriesByBillID(ctx, billingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit trail: %w", err)
+	}
+
+	return entries, nil
+}
+
 // Validation methods
 func (u *billingUseCase) validateCreateBillRequest(req CreateBillRequest) error {
+	if req.AccountID == "" {
+		return domain.ValidationError{Field: "accountId", Message: "account ID is required"}
+	}
 	if req.Currency == "" {
 		return domain.ValidationError{Field: "currency", Message: "currency is required"}
 	}
 	if req.Currency != "USD" && req.Currency != "GEL" {
 		return domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"}
 	}
+	if req.PeriodEnd != nil && req.Recurrence != "" {
+		return domain.ValidationError{Field: "periodEnd", Message: "periodEnd and recurrence cannot both be set"}
+	}
+	if req.MaxTotal < 0 {
+		return domain.ValidationError{Field: "maxTotal", Message: "max total must not be negative"}
+	}
+	return validateMetadata(req.Metadata)
+}
+
+// validateMetadata enforces the metadata limits, keeping bills and items
+// small enough to be carried in the workflow state.
+func validateMetadata(metadata domain.Metadata) error {
+	if len(metadata) > domain.MaxMetadataKeys {
+		return domain.ValidationError{Field: "metadata", Message: fmt.Sprintf("metadata cannot have more than %d keys", domain.MaxMetadataKeys)}
+	}
+	for key, value := range metadata {
+		if key == "" {
+			return domain.ValidationError{Field: "metadata", Message: "metadata keys cannot be empty"}
+		}
+		if len(key) > domain.MaxMetadataKeyLength {
+			return domain.ValidationError{Field: "metadata", Message: fmt.Sprintf("metadata keys cannot be longer than %d characters", domain.MaxMetadataKeyLength)}
+		}
+		if len(value) > domain.MaxMetadataValueLength {
+			return domain.ValidationError{Field: "metadata", Message: fmt.Sprintf("metadata values cannot be longer than %d characters", domain.MaxMetadataValueLength)}
+		}
+	}
 	return nil
 }
 
+// resolvePeriodEnd returns the moment the bill should be closed
+// automatically, or nil when the bill has no billing period.
+func (u *billingUseCase) resolvePeriodEnd(req CreateBillRequest, now time.Time) (*time.Time, error) {
+	if req.PeriodEnd != nil {
+		if !req.PeriodEnd.After(now) {
+			return nil, domain.ValidationError{Field: "periodEnd", Message: "periodEnd must be in the future"}
+		}
+		periodEnd := *req.PeriodEnd
+		return &periodEnd, nil
+	}
+	if req.Recurrence == "" {
+		return nil, nil
+	}
+
+	loc := time.UTC
+	if req.Timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(req.Timezone)
+		if err != nil {
+			return nil, domain.ValidationError{Field: "timezone", Message: "timezone must be a valid IANA time zone"}
+		}
+	}
+
+	periodEnd, err := domain.PeriodEnd(domain.Recurrence(req.Recurrence), now, loc)
+	if err != nil {
+		return nil, domain.ValidationError{Field: "recurrence", Message: "recurrence must be END_OF_DAY, END_OF_WEEK or END_OF_MONTH"}
+	}
+	return &periodEnd, nil
+}
+
 func (u *billingUseCase) validateAddItemRequest(req AddItemRequest) error {
 	if req.BillingID == "" {
 		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
 	}
+	if req.SKU != "" {
+		if req.Name != "" || req.Unit != "" || req.Price != 0 || req.UnitPrice != 0 {
+			return domain.ValidationError{Field: "sku", Message: "name, unit and price cannot be set for items added by sku"}
+		}
+		if req.Quantity < 0 {
+			return domain.ValidationError{Field: "quantity", Message: "quantity must be greater than 0"}
+		}
+		return validateMetadata(req.Metadata)
+	}
 	if req.Name == "" {
 		return domain.ValidationError{Field: "name", Message: "item name is required"}
 	}
-	if req.Price <= 0 {
+	if req.Price < 0 || (req.Price == 0 && req.UnitPrice <= 0) {
 		return domain.ValidationError{Field: "price", Message: "price must be greater than 0"}
 	}
+	if req.UnitPrice < 0 {
+		return domain.ValidationError{Field: "unitPrice", Message: "unit price must be greater than 0"}
+	}
+	if req.Quantity < 0 {
+		return domain.ValidationError{Field: "quantity", Message: "quantity must be greater than 0"}
+	}
+	return validateMetadata(req.Metadata)
+}
+
+func (u *billingUseCase) validateVoidItemRequest(req VoidItemRequest) error {
+	if req.BillingID == "" {
+		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if req.ItemID <= 0 {
+		return domain.ValidationError{Field: "itemID", Message: "item ID is required"}
+	}
+	return nil
+}
+
+func (u *billingUseCase) validateApplyDiscountRequest(req ApplyDiscountRequest) error {
+	if req.BillingID == "" {
+		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if req.ItemID < 0 {
+		return domain.ValidationError{Field: "itemID", Message: "item ID must not be negative"}
+	}
+	if req.Value <= 0 {
+		return domain.ValidationError{Field: "value", Message: "value must be greater than 0"}
+	}
+
+	switch domain.DiscountType(req.Type) {
+	case domain.DiscountTypePercentage:
+		if req.Value > domain.MaxPercentageBasisPoints {
+			return domain.ValidationError{Field: "value", Message: "percentage must not exceed 10000 basis points"}
+		}
+	case domain.DiscountTypeFixed:
+	default:
+		return domain.ValidationError{Field: "type", Message: "type must be PERCENTAGE or FIXED"}
+	}
+
+	return nil
+}
+
+func (u *billingUseCase) validateReopenBillRequest(req ReopenBillRequest) error {
+	if req.BillingID == "" {
+		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if req.Reason == "" {
+		return domain.ValidationError{Field: "reason", Message: "reason is required"}
+	}
+	return nil
+}
+
+func (u *billingUseCase) validateVoidBillRequest(req VoidBillRequest) error {
+	if req.BillingID == "" {
+		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if req.Reason == "" {
+		return domain.ValidationError{Field: "reason", Message: "reason is required"}
+	}
 	return nil
 }
 
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,41 +50,85 @@ func TestBillingUseCases(t *testing.T) {
 
 func (suite *billingUseCaseTestSuite) TestCreateBill() {
 	mockBillingID := "Bill-billing-id"
+	mockAccountID := "Acc-account-id"
+	mockPeriodEnd := mockTime.Add(72 * time.Hour)
+	mockPastPeriodEnd := mockTime.Add(-time.Hour)
 
 	testCases := []struct {
 		condition   string
 		argument    usecases.CreateBillRequest
 		expectedErr error
 		expectedID  string
-		doMock      func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock)
+		doMock      func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock)
 	}{
 		{
 			condition:   "validation failed: currency is empty",
-			argument:    usecases.CreateBillRequest{Currency: ""},
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: ""},
 			expectedErr: domain.ValidationError{Field: "currency", Message: "currency is required"},
 			expectedID:  "",
-			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
 			},
 		},
 		{
 			condition:   "validation failed: currency is invalid",
-			argument:    usecases.CreateBillRequest{Currency: "IDR"},
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "IDR"},
 			expectedErr: domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"},
 			expectedID:  "",
-			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
 			},
 		},
 		{
-			condition:   "success",
+			condition:   "validation failed: account id is empty",
 			argument:    usecases.CreateBillRequest{Currency: "USD"},
+			expectedErr: domain.ValidationError{Field: "accountId", Message: "account ID is required"},
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition: "validation failed: too many metadata keys",
+			argument: func() usecases.CreateBillRequest {
+				metadata := domain.Metadata{}
+				for i := 0; i <= domain.MaxMetadataKeys; i++ {
+					metadata[fmt.Sprintf("key-%d", i)] = "value"
+				}
+				return usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD", Metadata: metadata}
+			}(),
+			expectedErr: domain.ValidationError{Field: "metadata", Message: "metadata cannot have more than 20 keys"},
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "validation failed: metadata key too long",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD", Metadata: domain.Metadata{strings.Repeat("k", domain.MaxMetadataKeyLength+1): "value"}},
+			expectedErr: domain.ValidationError{Field: "metadata", Message: "metadata keys cannot be longer than 40 characters"},
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "account not found",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD"},
+			expectedErr: domain.ErrAccountNotFound,
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{}, domain.ErrAccountNotFound).Times(1)
+			},
+		},
+		{
+			condition:   "success",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD"},
 			expectedErr: nil,
 			expectedID:  mockBillingID,
-			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
 				mockGenerator.EXPECT().GenerateBillingID("Bill").Return(mockBillingID).Times(1)
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
 				mockClock.EXPECT().Now().Return(mockTime).Times(1)
 				mockWorkflow.EXPECT().
 					StartWorkflow(ctx, gomock.Any(), gomock.AssignableToTypeOf(&domain.Bill{
 						BillingID: mockBillingID,
+						AccountID: mockAccountID,
 						Status:    domain.BillStatusOpen,
 						Currency:  domain.CurrencyUSD,
 						Total:     0,
@@ -92,13 +138,111 @@ func (suite *billingUseCaseTestSuite) TestCreateBill() {
 					Return(nil)
 			},
 		},
+		{
+			condition: "validation failed: both period end and recurrence are set",
+			argument: usecases.CreateBillRequest{
+				AccountID:  mockAccountID,
+				Currency:   "USD",
+				PeriodEnd:  &mockPeriodEnd,
+				Recurrence: string(domain.RecurrenceEndOfMonth),
+			},
+			expectedErr: domain.ValidationError{Field: "periodEnd", Message: "periodEnd and recurrence cannot both be set"},
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "validation failed: max total is negative",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD", MaxTotal: -1},
+			expectedErr: domain.ValidationError{Field: "maxTotal", Message: "max total must not be negative"},
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "validation failed: period end is in the past",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD", PeriodEnd: &mockPastPeriodEnd},
+			expectedErr: domain.ValidationError{Field: "periodEnd", Message: "periodEnd must be in the future"},
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "validation failed: invalid recurrence",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD", Recurrence: "EVERY_FORTNIGHT"},
+			expectedErr: domain.ValidationError{Field: "recurrence", Message: "recurrence must be END_OF_DAY, END_OF_WEEK or END_OF_MONTH"},
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "validation failed: invalid timezone",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD", Recurrence: string(domain.RecurrenceEndOfDay), Timezone: "Mars/Olympus"},
+			expectedErr: domain.ValidationError{Field: "timezone", Message: "timezone must be a valid IANA time zone"},
+			expectedID:  "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "success with explicit period end",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD", PeriodEnd: &mockPeriodEnd},
+			expectedErr: nil,
+			expectedID:  mockBillingID,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				mockGenerator.EXPECT().GenerateBillingID("Bill").Return(mockBillingID).Times(1)
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().
+					StartWorkflow(ctx, mockBillingID, &domain.Bill{
+						BillingID: mockBillingID,
+						AccountID: mockAccountID,
+						Status:    domain.BillStatusOpen,
+						Currency:  domain.CurrencyUSD,
+						Items:     []domain.Item{},
+						PeriodEnd: &mockPeriodEnd,
+						CreatedAt: mockTime,
+					}).
+					Return(nil)
+			},
+		},
+		{
+			condition:   "success with monthly recurrence",
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD", Recurrence: string(domain.RecurrenceEndOfMonth)},
+			expectedErr: nil,
+			expectedID:  mockBillingID,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				endOfMonth := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+				mockGenerator.EXPECT().GenerateBillingID("Bill").Return(mockBillingID).Times(1)
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().
+					StartWorkflow(ctx, mockBillingID, &domain.Bill{
+						BillingID: mockBillingID,
+						AccountID: mockAccountID,
+						Status:    domain.BillStatusOpen,
+						Currency:  domain.CurrencyUSD,
+						Items:     []domain.Item{},
+						PeriodEnd: &endOfMonth,
+						CreatedAt: mockTime,
+					}).
+					Return(nil)
+			},
+		},
 		{
 			condition:   "fail start workflow",
-			argument:    usecases.CreateBillRequest{Currency: "USD"},
+			argument:    usecases.CreateBillRequest{AccountID: mockAccountID, Currency: "USD"},
 			expectedErr: fmt.Errorf("failed to start workflow: %w", errors.New("unexpected error")),
 			expectedID:  "",
-			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
 				mockGenerator.EXPECT().GenerateBillingID("Bill").Return(mockBillingID).Times(1)
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{ID: mockAccountID}, nil).Times(1)
 				mockClock.EXPECT().Now().Return(mockTime).Times(1)
 				mockWorkflow.EXPECT().
 					StartWorkflow(ctx, gomock.Any(), gomock.AssignableToTypeOf(&domain.Bill{})).
@@ -110,11 +254,11 @@ func (suite *billingUseCaseTestSuite) TestCreateBill() {
 
 	for _, tc := range testCases {
 		suite.T().Run(tc.condition, func(t *testing.T) {
-			uc := usecases.NewBillingUseCase(nil, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
 			ctx := context.Background()
 			assertion := assert.New(t)
 
-			tc.doMock(ctx, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
 
 			id, err := uc.CreateBill(ctx, tc.argument)
 			assertion.Equal(tc.expectedID, id)
@@ -204,10 +348,72 @@ func (suite *billingUseCaseTestSuite) TestAddItem() {
 	testCases := []struct {
 		condition    string
 		req          usecases.AddItemRequest
+		opts         []usecases.Option
 		expectedBill domain.Bill
 		expectedErr  error
 		doMock       func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider)
 	}{
+		{
+			condition:   "validation failed: metadata value too long",
+			req:         usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000, Metadata: domain.Metadata{"note": strings.Repeat("v", domain.MaxMetadataValueLength+1)}},
+			expectedErr: domain.ValidationError{Field: "metadata", Message: "metadata values cannot be longer than 500 characters"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+			},
+		},
+		{
+			condition:   "item would exceed the spending cap",
+			req:         usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Quantity: 2, UnitPrice: 300},
+			expectedErr: domain.ErrSpendingCapExceeded,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusOpen,
+					Currency:  domain.CurrencyUSD,
+					Total:     1000,
+					MaxTotal:  1500,
+					Items: []domain.Item{
+						{ID: 1, BillingID: "mock-billing-id", Name: "Sparkling", Quantity: 1, UnitPrice: 1000, Price: 1000},
+					},
+					CreatedAt: mockCreatedAt,
+				}, nil).Times(1)
+			},
+		},
+		{
+			condition: "success with metadata",
+			req:       usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000, Metadata: domain.Metadata{"costCenter": "bar"}},
+			expectedBill: domain.Bill{
+				BillingID: "mock-billing-id",
+				Status:    domain.BillStatusOpen,
+				Currency:  domain.CurrencyUSD,
+				Total:     1000,
+				Items: []domain.Item{
+					{BillingID: "mock-billing-id", Name: "Sparkling", Quantity: 1, UnitPrice: 1000, Price: 1000, IdempotencyKey: mockIdempotencyKey, Metadata: domain.Metadata{"costCenter": "bar"}},
+				},
+				CreatedAt: mockCreatedAt,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusOpen,
+					Currency:  domain.CurrencyUSD,
+					CreatedAt: mockCreatedAt,
+				}, nil).Times(1)
+				mockGenerator.EXPECT().GenerateIdempotencyKey("idem", gomock.Any()).Return(mockIdempotencyKey).Times(1)
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalAddLineItem, domain.Item{
+						BillingID:      "mock-billing-id",
+						Name:           "Sparkling",
+						Quantity:       1,
+						UnitPrice:      1000,
+						Price:          1000,
+						IdempotencyKey: mockIdempotencyKey,
+						Metadata:       domain.Metadata{"costCenter": "bar"},
+					}).
+					Return(nil).
+					Times(1)
+			},
+		},
 		{
 			condition: "success",
 			req:       usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
@@ -219,7 +425,7 @@ func (suite *billingUseCaseTestSuite) TestAddItem() {
 				Total:     2000,
 				Items: []domain.Item{
 					{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000, IdempotencyKey: mockIdempotencyKey},
-					{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000, IdempotencyKey: mockIdempotencyKey},
+					{BillingID: "mock-billing-id", Name: "Sparkling", Quantity: 1, UnitPrice: 1000, Price: 1000, IdempotencyKey: mockIdempotencyKey},
 				},
 				Conversion: domain.BillExchange{},
 				CreatedAt:  mockCreatedAt,
@@ -250,6 +456,8 @@ func (suite *billingUseCaseTestSuite) TestAddItem() {
 					SignalWorkflow(ctx, "mock-billing-id", domain.SignalAddLineItem, domain.Item{
 						BillingID:      "mock-billing-id",
 						Name:           "Sparkling",
+						Quantity:       1,
+						UnitPrice:      1000,
 						Price:          1000,
 						IdempotencyKey: mockIdempotencyKey,
 					}).
@@ -258,10 +466,22 @@ func (suite *billingUseCaseTestSuite) TestAddItem() {
 			},
 		},
 		{
-			condition:    "failed to signal workflow",
-			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
-			expectedBill: domain.Bill{},
-			expectedErr:  fmt.Errorf("failed to add item: %w", errors.New("some-err")),
+			condition: "success with quantity and unit price",
+			req:       usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Coffee", Quantity: 3, Unit: "cup", UnitPrice: 450},
+			expectedBill: domain.Bill{
+				ID:        1,
+				BillingID: "mock-billing-id",
+				Status:    domain.BillStatusOpen,
+				Currency:  domain.CurrencyUSD,
+				Total:     2350,
+				Items: []domain.Item{
+					{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000, IdempotencyKey: mockIdempotencyKey},
+					{BillingID: "mock-billing-id", Name: "Coffee", Quantity: 3, Unit: "cup", UnitPrice: 450, Price: 1350, IdempotencyKey: mockIdempotencyKey},
+				},
+				Conversion: domain.BillExchange{},
+				CreatedAt:  mockCreatedAt,
+			},
+			expectedErr: nil,
 			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
 				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
 					ID:        1,
@@ -278,7 +498,7 @@ func (suite *billingUseCaseTestSuite) TestAddItem() {
 
 				mockGenerator.
 					EXPECT().
-					GenerateIdempotencyKey("idem", usecases.PayloadToBytes(usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000})).
+					GenerateIdempotencyKey("idem", usecases.PayloadToBytes(usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Coffee", Quantity: 3, Unit: "cup", UnitPrice: 450})).
 					Return(mockIdempotencyKey).
 					Times(1)
 
@@ -286,87 +506,615 @@ func (suite *billingUseCaseTestSuite) TestAddItem() {
 					EXPECT().
 					SignalWorkflow(ctx, "mock-billing-id", domain.SignalAddLineItem, domain.Item{
 						BillingID:      "mock-billing-id",
-						Name:           "Sparkling",
-						Price:          1000,
+						Name:           "Coffee",
+						Quantity:       3,
+						Unit:           "cup",
+						UnitPrice:      450,
+						Price:          1350,
 						IdempotencyKey: mockIdempotencyKey,
 					}).
-					Return(errors.New("some-err")).
+					Return(nil).
 					Times(1)
 			},
 		},
 		{
-			condition:    "bill is closed",
-			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+			condition: "success by sku priced in the currency of the bill",
+			req:       usecases.AddItemRequest{BillingID: "mock-billing-id", SKU: "ESP-DBL", Quantity: 2},
+			expectedBill: domain.Bill{
+				BillingID: "mock-billing-id",
+				Status:    domain.BillStatusOpen,
+				Currency:  domain.CurrencyGEL,
+				Total:     2500,
+				Items: []domain.Item{
+					{BillingID: "mock-billing-id", Name: "Double espresso", Quantity: 2, Unit: "cup", UnitPrice: 1250, Price: 2500, SKU: "ESP-DBL", CatalogVersion: 3, IdempotencyKey: mockIdempotencyKey},
+				},
+				CreatedAt: mockCreatedAt,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusOpen,
+					Currency:  domain.CurrencyGEL,
+					CreatedAt: mockCreatedAt,
+				}, nil).Times(1)
+				mockRepo.EXPECT().GetSKU(ctx, "ESP-DBL").Return(domain.SKU{
+					Code:      "ESP-DBL",
+					ProductID: "Prod-espresso",
+					Name:      "Double espresso",
+					Unit:      "cup",
+					Currency:  domain.CurrencyUSD,
+					UnitPrice: 1000,
+					Prices:    domain.PriceList{domain.CurrencyGEL: 1250},
+					Version:   3,
+				}, nil).Times(1)
+				mockGenerator.
+					EXPECT().
+					GenerateIdempotencyKey("idem", usecases.PayloadToBytes(usecases.AddItemRequest{BillingID: "mock-billing-id", SKU: "ESP-DBL", Quantity: 2})).
+					Return(mockIdempotencyKey).
+					Times(1)
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalAddLineItem, domain.Item{
+						BillingID:      "mock-billing-id",
+						Name:           "Double espresso",
+						Quantity:       2,
+						Unit:           "cup",
+						UnitPrice:      1250,
+						Price:          2500,
+						SKU:            "ESP-DBL",
+						CatalogVersion: 3,
+						IdempotencyKey: mockIdempotencyKey,
+					}).
+					Return(nil).
+					Times(1)
+			},
+		},
+		{
+			condition: "success by sku with its price converted to the currency of the bill",
+			req:       usecases.AddItemRequest{BillingID: "mock-billing-id", SKU: "ESP-DBL", Quantity: 2},
+			opts:      []usecases.Option{usecases.WithCatalogPriceConversion(true)},
+			expectedBill: domain.Bill{
+				BillingID: "mock-billing-id",
+				Status:    domain.BillStatusOpen,
+				Currency:  domain.CurrencyGEL,
+				Total:     5556,
+				Items: []domain.Item{
+					{BillingID: "mock-billing-id", Name: "Double espresso", Quantity: 2, Unit: "cup", UnitPrice: 2778, Price: 5556, SKU: "ESP-DBL", CatalogVersion: 3, IdempotencyKey: mockIdempotencyKey},
+				},
+				CreatedAt: mockCreatedAt,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusOpen,
+					Currency:  domain.CurrencyGEL,
+					CreatedAt: mockCreatedAt,
+				}, nil).Times(1)
+				mockRepo.EXPECT().GetSKU(ctx, "ESP-DBL").Return(domain.SKU{
+					Code:      "ESP-DBL",
+					ProductID: "Prod-espresso",
+					Name:      "Double espresso",
+					Unit:      "cup",
+					Currency:  domain.CurrencyUSD,
+					UnitPrice: 1000,
+					Version:   3,
+				}, nil).Times(1)
+				mockGenerator.
+					EXPECT().
+					GenerateIdempotencyKey("idem", usecases.PayloadToBytes(usecases.AddItemRequest{BillingID: "mock-billing-id", SKU: "ESP-DBL", Quantity: 2})).
+					Return(mockIdempotencyKey).
+					Times(1)
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalAddLineItem, domain.Item{
+						BillingID:      "mock-billing-id",
+						Name:           "Double espresso",
+						Quantity:       2,
+						Unit:           "cup",
+						UnitPrice:      2778,
+						Price:          5556,
+						SKU:            "ESP-DBL",
+						CatalogVersion: 3,
+						IdempotencyKey: mockIdempotencyKey,
+					}).
+					Return(nil).
+					Times(1)
+			},
+		},
+		{
+			condition:    "sku has no price in the currency of the bill",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", SKU: "ESP-DBL"},
 			expectedBill: domain.Bill{},
-			expectedErr:  domain.ErrBillClosed,
+			expectedErr:  domain.ValidationError{Field: "sku", Message: `sku "ESP-DBL" has no price in GEL`},
 			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
 				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
-					ID:        1,
 					BillingID: "mock-billing-id",
-					Status:    domain.BillStatusClosed,
+					Status:    domain.BillStatusOpen,
+					Currency:  domain.CurrencyGEL,
+				}, nil).Times(1)
+				mockRepo.EXPECT().GetSKU(ctx, "ESP-DBL").Return(domain.SKU{
+					Code:      "ESP-DBL",
+					Name:      "Double espresso",
 					Currency:  domain.CurrencyUSD,
-					Total:     1000,
-					Items: []domain.Item{
-						{Name: "Sparkling", Price: 1000},
-					},
-					Conversion: domain.BillExchange{},
-					CreatedAt:  mockCreatedAt,
-					ClosedAt:   &mockClosedAt,
+					UnitPrice: 1000,
+					Version:   3,
 				}, nil).Times(1)
 			},
 		},
 		{
-			condition:    "bill not found",
-			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+			condition:    "validation failed: price set for an item added by sku",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", SKU: "ESP-DBL", UnitPrice: 450},
 			expectedBill: domain.Bill{},
-			expectedErr:  domain.ErrBillNotFound,
+			expectedErr:  domain.ValidationError{Field: "sku", Message: "name, unit and price cannot be set for items added by sku"},
 			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
-				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrBillNotFound).Times(1)
-				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrBillNotFound).Times(1)
 			},
 		},
 		{
-			condition:    "billing id is empty",
-			req:          usecases.AddItemRequest{Name: "Sparkling", Price: 1000},
+			condition:    "unknown sku",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", SKU: "ESP-TRP"},
 			expectedBill: domain.Bill{},
-			expectedErr:  domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			expectedErr:  domain.ValidationError{Field: "sku", Message: `unknown sku "ESP-TRP"`},
 			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusOpen,
+					Currency:  domain.CurrencyUSD,
+				}, nil).Times(1)
+				mockRepo.EXPECT().GetSKU(ctx, "ESP-TRP").Return(domain.SKU{}, domain.ErrSKUNotFound).Times(1)
 			},
 		},
 		{
-			condition:    "item name is empty",
-			req:          usecases.AddItemRequest{BillingID: "Sparkling", Price: 1000},
+			condition:    "archived sku",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", SKU: "ESP-DBL"},
 			expectedBill: domain.Bill{},
-			expectedErr:  domain.ValidationError{Field: "name", Message: "item name is required"},
+			expectedErr:  domain.ValidationError{Field: "sku", Message: `sku "ESP-DBL" is archived`},
 			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusOpen,
+					Currency:  domain.CurrencyUSD,
+				}, nil).Times(1)
+				mockRepo.EXPECT().GetSKU(ctx, "ESP-DBL").Return(domain.SKU{
+					Code:       "ESP-DBL",
+					Name:       "Double espresso",
+					Currency:   domain.CurrencyUSD,
+					UnitPrice:  450,
+					Version:    1,
+					ArchivedAt: &mockClosedAt,
+				}, nil).Times(1)
 			},
 		},
 		{
-			condition:    "item price is empty",
-			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling"},
+			condition:    "quantity multiplied by unit price overflows",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Coffee", Quantity: math.MaxInt64 / 2, UnitPrice: 3},
 			expectedBill: domain.Bill{},
-			expectedErr:  domain.ValidationError{Field: "price", Message: "price must be greater than 0"},
+			expectedErr:  domain.ValidationError{Field: "quantity", Message: "quantity multiplied by unit price is too large"},
 			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		suite.T().Run(tc.condition, func(t *testing.T) {
-			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, nil)
-			ctx := context.Background()
-			assertion := assert.New(t)
-
-			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator)
-			bill, err := uc.AddItem(ctx, tc.req)
-			assertion.Equal(tc.expectedBill, bill)
-			assertion.Equal(tc.expectedErr, err)
-		})
-	}
-}
-
-func (suite *billingUseCaseTestSuite) TestCloseBilling() {
-	testCases := []struct {
+		{
+			condition:    "negative quantity",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Coffee", Quantity: -1, UnitPrice: 450},
+			expectedBill: domain.Bill{},
+			expectedErr:  domain.ValidationError{Field: "quantity", Message: "quantity must be greater than 0"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+			},
+		},
+		{
+			condition:    "failed to signal workflow",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+			expectedBill: domain.Bill{},
+			expectedErr:  fmt.Errorf("failed to add item: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{
+					ID:        1,
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusOpen,
+					Currency:  domain.CurrencyUSD,
+					Total:     1000,
+					Items: []domain.Item{
+						{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000, IdempotencyKey: mockIdempotencyKey},
+					},
+					Conversion: domain.BillExchange{},
+					CreatedAt:  mockCreatedAt,
+				}, nil).Times(1)
+
+				mockGenerator.
+					EXPECT().
+					GenerateIdempotencyKey("idem", usecases.PayloadToBytes(usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000})).
+					Return(mockIdempotencyKey).
+					Times(1)
+
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalAddLineItem, domain.Item{
+						BillingID:      "mock-billing-id",
+						Name:           "Sparkling",
+						Quantity:       1,
+						UnitPrice:      1000,
+						Price:          1000,
+						IdempotencyKey: mockIdempotencyKey,
+					}).
+					Return(errors.New("some-err")).
+					Times(1)
+			},
+		},
+		{
+			condition:    "bill is closed",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+			expectedBill: domain.Bill{},
+			expectedErr:  domain.ErrBillClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(domain.Bill{
+					ID:        1,
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusClosed,
+					Currency:  domain.CurrencyUSD,
+					Total:     1000,
+					Items: []domain.Item{
+						{Name: "Sparkling", Price: 1000},
+					},
+					Conversion: domain.BillExchange{},
+					CreatedAt:  mockCreatedAt,
+					ClosedAt:   &mockClosedAt,
+				}, nil).Times(1)
+			},
+		},
+		{
+			condition:    "bill is voided",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+			expectedBill: domain.Bill{},
+			expectedErr:  domain.ErrBillVoided,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(domain.Bill{
+					ID:        1,
+					BillingID: "mock-billing-id",
+					Status:    domain.BillStatusVoided,
+					Currency:  domain.CurrencyUSD,
+					CreatedAt: mockCreatedAt,
+					VoidedAt:  &mockClosedAt,
+				}, nil).Times(1)
+			},
+		},
+		{
+			condition:    "bill not found",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+			expectedBill: domain.Bill{},
+			expectedErr:  domain.ErrBillNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrBillNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrBillNotFound).Times(1)
+			},
+		},
+		{
+			condition:    "billing id is empty",
+			req:          usecases.AddItemRequest{Name: "Sparkling", Price: 1000},
+			expectedBill: domain.Bill{},
+			expectedErr:  domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+			},
+		},
+		{
+			condition:    "item name is empty",
+			req:          usecases.AddItemRequest{BillingID: "Sparkling", Price: 1000},
+			expectedBill: domain.Bill{},
+			expectedErr:  domain.ValidationError{Field: "name", Message: "item name is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+			},
+		},
+		{
+			condition:    "item price is empty",
+			req:          usecases.AddItemRequest{BillingID: "mock-billing-id", Name: "Sparkling"},
+			expectedBill: domain.Bill{},
+			expectedErr:  domain.ValidationError{Field: "price", Message: "price must be greater than 0"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider) {
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, nil, tc.opts...)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator)
+			bill, err := uc.AddItem(ctx, tc.req)
+			assertion.Equal(tc.expectedBill, bill)
+			assertion.Equal(tc.expectedErr, err)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestVoidItem() {
+	mockCreatedAt := time.Now().AddDate(0, 0, -1)
+	mockClosedAt := mockCreatedAt.Add(1 * time.Hour)
+
+	openBill := func() domain.Bill {
+		return domain.Bill{
+			ID:        1,
+			BillingID: "mock-billing-id",
+			Status:    domain.BillStatusOpen,
+			Currency:  domain.CurrencyUSD,
+			Total:     1500,
+			Items: []domain.Item{
+				{ID: 10, BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+				{ID: 11, BillingID: "mock-billing-id", Name: "Still", Price: 500},
+			},
+			CreatedAt: mockCreatedAt,
+		}
+	}
+
+	testCases := []struct {
+		condition    string
+		req          usecases.VoidItemRequest
+		expectedBill domain.Bill
+		expectedErr  error
+		doMock       func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock)
+	}{
+		{
+			condition:   "billing id is empty",
+			req:         usecases.VoidItemRequest{ItemID: 10},
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "item id is empty",
+			req:         usecases.VoidItemRequest{BillingID: "mock-billing-id"},
+			expectedErr: domain.ValidationError{Field: "itemID", Message: "item ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "bill not found",
+			req:         usecases.VoidItemRequest{BillingID: "mock-billing-id", ItemID: 10},
+			expectedErr: domain.ErrBillNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, errors.New("some-err")).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(domain.Bill{}, sql.ErrNoRows).Times(1)
+			},
+		},
+		{
+			condition:   "bill is closed",
+			req:         usecases.VoidItemRequest{BillingID: "mock-billing-id", ItemID: 10},
+			expectedErr: domain.ErrBillClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				bill := openBill()
+				bill.Status = domain.BillStatusClosed
+				bill.ClosedAt = &mockClosedAt
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+			},
+		},
+		{
+			condition:   "item not found",
+			req:         usecases.VoidItemRequest{BillingID: "mock-billing-id", ItemID: 99},
+			expectedErr: domain.ErrItemNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "item already voided",
+			req:         usecases.VoidItemRequest{BillingID: "mock-billing-id", ItemID: 10},
+			expectedErr: domain.ErrItemVoided,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				bill := openBill()
+				bill.Items[0].VoidedAt = &mockCreatedAt
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "failed to signal workflow",
+			req:         usecases.VoidItemRequest{BillingID: "mock-billing-id", ItemID: 10},
+			expectedErr: fmt.Errorf("failed to void item: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalVoidLineItem, usecases.VoidItemRequest{BillingID: "mock-billing-id", ItemID: 10, VoidedAt: mockTime}).
+					Return(errors.New("some-err")).
+					Times(1)
+			},
+		},
+		{
+			condition: "success",
+			req:       usecases.VoidItemRequest{BillingID: "mock-billing-id", ItemID: 10},
+			expectedBill: domain.Bill{
+				ID:        1,
+				BillingID: "mock-billing-id",
+				Status:    domain.BillStatusOpen,
+				Currency:  domain.CurrencyUSD,
+				Total:     500,
+				Items: []domain.Item{
+					{ID: 10, BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000, VoidedAt: &mockTime},
+					{ID: 11, BillingID: "mock-billing-id", Name: "Still", Price: 500},
+				},
+				CreatedAt: mockCreatedAt,
+			},
+			expectedErr: nil,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalVoidLineItem, usecases.VoidItemRequest{BillingID: "mock-billing-id", ItemID: 10, VoidedAt: mockTime}).
+					Return(nil).
+					Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, nil, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient, suite.mockClock)
+			bill, err := uc.VoidItem(ctx, tc.req)
+			assertion.Equal(tc.expectedBill, bill)
+			assertion.Equal(tc.expectedErr, err)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestApplyDiscount() {
+	mockCreatedAt := time.Now().AddDate(0, 0, -1)
+	mockIdempotencyKey := "mock-idempotency"
+
+	openBill := func() domain.Bill {
+		return domain.Bill{
+			ID:        1,
+			BillingID: "mock-billing-id",
+			Status:    domain.BillStatusOpen,
+			Currency:  domain.CurrencyUSD,
+			Total:     1000,
+			Items: []domain.Item{
+				{ID: 10, BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+			},
+			CreatedAt: mockCreatedAt,
+		}
+	}
+
+	testCases := []struct {
+		condition    string
+		req          usecases.ApplyDiscountRequest
+		expectedBill domain.Bill
+		expectedErr  error
+		doMock       func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock)
+	}{
+		{
+			condition:   "billing id is empty",
+			req:         usecases.ApplyDiscountRequest{Type: "FIXED", Value: 100},
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "value is empty",
+			req:         usecases.ApplyDiscountRequest{BillingID: "mock-billing-id", Type: "FIXED"},
+			expectedErr: domain.ValidationError{Field: "value", Message: "value must be greater than 0"},
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "invalid type",
+			req:         usecases.ApplyDiscountRequest{BillingID: "mock-billing-id", Type: "BOGO", Value: 100},
+			expectedErr: domain.ValidationError{Field: "type", Message: "type must be PERCENTAGE or FIXED"},
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "percentage above 100%",
+			req:         usecases.ApplyDiscountRequest{BillingID: "mock-billing-id", Type: "PERCENTAGE", Value: 10001},
+			expectedErr: domain.ValidationError{Field: "value", Message: "percentage must not exceed 10000 basis points"},
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "item not found",
+			req:         usecases.ApplyDiscountRequest{BillingID: "mock-billing-id", ItemID: 99, Type: "FIXED", Value: 100},
+			expectedErr: domain.ErrItemNotFound,
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockGenerator.EXPECT().GenerateIdempotencyKey("disc", gomock.Any()).Return(mockIdempotencyKey).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "bill is closed",
+			req:         usecases.ApplyDiscountRequest{BillingID: "mock-billing-id", Type: "FIXED", Value: 100},
+			expectedErr: domain.ErrBillClosed,
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				bill := openBill()
+				bill.Status = domain.BillStatusClosed
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+				suite.mockRepository.EXPECT().GetBill(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+			},
+		},
+		{
+			condition:   "failed to signal workflow",
+			req:         usecases.ApplyDiscountRequest{BillingID: "mock-billing-id", Type: "FIXED", Value: 100},
+			expectedErr: fmt.Errorf("failed to apply discount: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockGenerator.EXPECT().GenerateIdempotencyKey("disc", gomock.Any()).Return(mockIdempotencyKey).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().SignalWorkflow(ctx, "mock-billing-id", domain.SignalApplyDiscount, gomock.Any()).Return(errors.New("some-err")).Times(1)
+			},
+		},
+		{
+			condition: "success item discount",
+			req:       usecases.ApplyDiscountRequest{BillingID: "mock-billing-id", ItemID: 10, Type: "PERCENTAGE", Value: 1000, Description: "happy hour"},
+			expectedBill: domain.Bill{
+				ID:        1,
+				BillingID: "mock-billing-id",
+				Status:    domain.BillStatusOpen,
+				Currency:  domain.CurrencyUSD,
+				Total:     900,
+				Items: []domain.Item{
+					{ID: 10, BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+				},
+				Discounts: []domain.Discount{
+					{
+						BillingID:      "mock-billing-id",
+						ItemID:         10,
+						Type:           domain.DiscountTypePercentage,
+						Value:          1000,
+						Description:    "happy hour",
+						IdempotencyKey: mockIdempotencyKey,
+						CreatedAt:      mockTime,
+					},
+				},
+				CreatedAt: mockCreatedAt,
+			},
+			expectedErr: nil,
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, mockGenerator *mock_generator.MockIDProvider, mockClock *mock_clock.MockClock) {
+				req := usecases.ApplyDiscountRequest{BillingID: "mock-billing-id", ItemID: 10, Type: "PERCENTAGE", Value: 1000, Description: "happy hour"}
+
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockGenerator.EXPECT().GenerateIdempotencyKey("disc", usecases.PayloadToBytes(req)).Return(mockIdempotencyKey).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalApplyDiscount, domain.Discount{
+						BillingID:      "mock-billing-id",
+						ItemID:         10,
+						Type:           domain.DiscountTypePercentage,
+						Value:          1000,
+						Description:    "happy hour",
+						IdempotencyKey: mockIdempotencyKey,
+						CreatedAt:      mockTime,
+					}).
+					Return(nil).
+					Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			bill, err := uc.ApplyDiscount(ctx, tc.req)
+			assertion.Equal(tc.expectedBill, bill)
+			assertion.Equal(tc.expectedErr, err)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestCloseBilling() {
+	testCases := []struct {
 		condition       string
 		req             usecases.CloseBillRequest
 		expectedErr     error
@@ -425,7 +1173,8 @@ func (suite *billingUseCaseTestSuite) TestCloseBilling() {
 			req:         usecases.CloseBillRequest{BillingID: "mock-billing-id", Currency: ""},
 			expectedErr: domain.ErrBillClosed,
 			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
-				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(
 					domain.Bill{
 						ID:        1,
 						BillingID: "mock-billing-id",
@@ -465,6 +1214,53 @@ func (suite *billingUseCaseTestSuite) TestCloseBilling() {
 				mockWorkflow.EXPECT().SignalWorkflow(ctx, "mock-billing-id", domain.SignalCloseBill, usecases.CloseBillRequest{BillingID: "mock-billing-id", Exchange: domain.BillExchange{}, Currency: "", ClosedAt: mockTime}).Return(errors.New("some-err")).Times(1)
 			},
 		},
+		{
+			condition:   "success with convertion of discounted total",
+			req:         usecases.CloseBillRequest{BillingID: "mock-billing-id", Currency: "GEL"},
+			expectedErr: nil,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				converted, rate, _ := conversion.ConvertAmount(800, string(domain.CurrencyUSD), string(domain.CurrencyGEL))
+
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(
+					domain.Bill{
+						ID:        1,
+						BillingID: "mock-billing-id",
+						Status:    domain.BillStatusOpen,
+						Currency:  domain.CurrencyUSD,
+						Total:     800,
+						Items: []domain.Item{
+							{ID: 10, Name: "Sparkling", Price: 1000},
+						},
+						Discounts: []domain.Discount{
+							{Type: domain.DiscountTypeFixed, Value: 200},
+						},
+					},
+					nil,
+				).Times(1)
+
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(
+						ctx,
+						"mock-billing-id",
+						domain.SignalCloseBill,
+						usecases.CloseBillRequest{
+							BillingID: "mock-billing-id",
+							Exchange: domain.BillExchange{
+								BillID:         "mock-billing-id",
+								BaseCurrency:   domain.CurrencyUSD,
+								TargetCurrency: domain.CurrencyGEL,
+								Rate:           rate,
+								Total:          converted,
+							},
+							Currency: "GEL",
+							ClosedAt: mockTime,
+						}).
+					Return(nil).
+					Times(1)
+			},
+		},
 		{
 			condition:   "success with convertion",
 			req:         usecases.CloseBillRequest{BillingID: "mock-billing-id", Currency: "GEL"},
@@ -531,6 +1327,366 @@ func (suite *billingUseCaseTestSuite) TestCloseBilling() {
 	}
 }
 
+func (suite *billingUseCaseTestSuite) TestCloseBillingWithTaxes() {
+	ctx := context.Background()
+	assertion := assert.New(suite.T())
+	taxEngine := domain.NewTaxEngine([]domain.TaxRate{
+		{Code: "SALES_TAX", Name: "Sales tax", Currency: domain.CurrencyUSD, Rate: 1000},
+	})
+	uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, nil, suite.mockClock, usecases.WithTaxEngine(taxEngine))
+
+	expectedTaxes := []domain.TaxLine{
+		{BillingID: "mock-billing-id", Code: "SALES_TAX", Name: "Sales tax", Rate: 1000, Base: 1000, Amount: 100},
+	}
+	converted, rate, _ := conversion.ConvertAmount(1100, string(domain.CurrencyUSD), string(domain.CurrencyGEL))
+
+	suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+	suite.mockWorkflowClient.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(
+		domain.Bill{
+			ID:        1,
+			BillingID: "mock-billing-id",
+			Status:    domain.BillStatusOpen,
+			Currency:  domain.CurrencyUSD,
+			Total:     1000,
+			Items: []domain.Item{
+				{Name: "Sparkling", Price: 1000},
+			},
+		},
+		nil,
+	).Times(1)
+	suite.mockWorkflowClient.
+		EXPECT().
+		SignalWorkflow(ctx, "mock-billing-id", domain.SignalCloseBill, usecases.CloseBillRequest{
+			BillingID: "mock-billing-id",
+			Currency:  "GEL",
+			ClosedAt:  mockTime,
+			Taxes:     expectedTaxes,
+			Exchange: domain.BillExchange{
+				BillID:         "mock-billing-id",
+				BaseCurrency:   domain.CurrencyUSD,
+				TargetCurrency: domain.CurrencyGEL,
+				Rate:           rate,
+				Total:          converted,
+			},
+		}).
+		Return(nil).
+		Times(1)
+
+	bill, err := uc.CloseBill(ctx, usecases.CloseBillRequest{BillingID: "mock-billing-id", Currency: "GEL"})
+	assertion.NoError(err)
+	assertion.Equal(expectedTaxes, bill.Taxes)
+	assertion.Equal(int64(100), bill.GetTaxTotal())
+	assertion.Equal(int64(1100), bill.GetGrandTotal())
+}
+
+func (suite *billingUseCaseTestSuite) TestCloseBillingWaitsForInvoiceNumber() {
+	openBill := domain.Bill{
+		ID:        1,
+		BillingID: "mock-billing-id",
+		Status:    domain.BillStatusOpen,
+		Currency:  domain.CurrencyUSD,
+		Items:     []domain.Item{{ID: 10, Name: "Sparkling", Price: 1000}},
+	}
+	closedBill := openBill
+	closedBill.Status = domain.BillStatusClosed
+	closedBill.Total = 1000
+	closedBill.InvoiceNumber = "INV-2025-000042"
+	closedBill.ClosedAt = &mockTime
+	closeReq := usecases.CloseBillRequest{BillingID: "mock-billing-id", ClosedAt: mockTime}
+
+	testCases := []struct {
+		condition             string
+		expectedInvoiceNumber string
+		doMock                func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:             "close completed",
+			expectedInvoiceNumber: "INV-2025-000042",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().WaitWorkflow(gomock.Any(), "mock-billing-id").Return(nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill, nil).Times(1)
+			},
+		},
+		{
+			condition:             "close not completed in time",
+			expectedInvoiceNumber: "",
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().WaitWorkflow(gomock.Any(), "mock-billing-id").Return(context.DeadlineExceeded).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, nil, suite.mockClock,
+				usecases.WithCloseWaitTimeout(time.Second),
+			)
+			ctx := context.Background()
+
+			suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			suite.mockWorkflowClient.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill, nil).Times(1)
+			suite.mockWorkflowClient.EXPECT().SignalWorkflow(ctx, "mock-billing-id", domain.SignalCloseBill, closeReq).Return(nil).Times(1)
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			bill, err := uc.CloseBill(ctx, usecases.CloseBillRequest{BillingID: "mock-billing-id"})
+			assert.NoError(t, err)
+			assert.True(t, bill.IsClosed())
+			assert.Equal(t, tc.expectedInvoiceNumber, bill.InvoiceNumber)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestReopenBill() {
+	closedAt := mockTime.Add(-10 * time.Minute)
+	expiredClosedAt := mockTime.Add(-20 * time.Minute)
+
+	closedBill := func(closedAt time.Time) domain.Bill {
+		return domain.Bill{
+			ID:        1,
+			BillingID: "mock-billing-id",
+			Status:    domain.BillStatusClosed,
+			Currency:  domain.CurrencyUSD,
+			Total:     1000,
+			Items:     []domain.Item{{ID: 10, Name: "Sparkling", Price: 1000}},
+			ClosedAt:  &closedAt,
+		}
+	}
+	reopenedBill := domain.Bill{
+		ID:        1,
+		BillingID: "mock-billing-id",
+		Status:    domain.BillStatusOpen,
+		Currency:  domain.CurrencyUSD,
+		Items:     []domain.Item{{ID: 10, Name: "Sparkling", Price: 1000}},
+	}
+	expectedEntry := domain.AuditEntry{
+		BillingID: "mock-billing-id",
+		Action:    domain.AuditActionReopen,
+		Reason:    "wrong table",
+		CreatedAt: mockTime,
+	}
+
+	testCases := []struct {
+		condition    string
+		req          usecases.ReopenBillRequest
+		expectedBill domain.Bill
+		expectedErr  error
+		doMock       func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock)
+	}{
+		{
+			condition:   "billing id is empty",
+			req:         usecases.ReopenBillRequest{Reason: "wrong table"},
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "reason is empty",
+			req:         usecases.ReopenBillRequest{BillingID: "mock-billing-id"},
+			expectedErr: domain.ValidationError{Field: "reason", Message: "reason is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "bill is open",
+			req:         usecases.ReopenBillRequest{BillingID: "mock-billing-id", Reason: "wrong table"},
+			expectedErr: domain.ErrBillNotClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(reopenedBill, nil).Times(1)
+			},
+		},
+		{
+			condition:   "bill has payments",
+			req:         usecases.ReopenBillRequest{BillingID: "mock-billing-id", Reason: "wrong table"},
+			expectedErr: domain.ErrBillHasPayments,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				bill := closedBill(closedAt)
+				bill.Status = domain.BillStatusPartiallyPaid
+				bill.Payments = []domain.Payment{{ID: 1, Amount: 500, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 500}}
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+			},
+		},
+		{
+			condition:   "bill has credit notes",
+			req:         usecases.ReopenBillRequest{BillingID: "mock-billing-id", Reason: "wrong table"},
+			expectedErr: domain.ErrBillHasCreditNotes,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				bill := closedBill(closedAt)
+				bill.CreditNotes = []domain.CreditNote{{ID: 1, Number: "CN-1", Amount: 100, Lines: []domain.CreditNoteLine{{Amount: 100}}}}
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+			},
+		},
+		{
+			condition:   "grace period expired",
+			req:         usecases.ReopenBillRequest{BillingID: "mock-billing-id", Reason: "wrong table"},
+			expectedErr: domain.ErrReopenWindowExpired,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(closedBill(expiredClosedAt), nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(expiredClosedAt), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "failed to reopen bill in database",
+			req:         usecases.ReopenBillRequest{BillingID: "mock-billing-id", Reason: "wrong table"},
+			expectedErr: fmt.Errorf("failed to reopen bill: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(closedBill(closedAt), nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(closedAt), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().ReopenBill(ctx, expectedEntry).Return(errors.New("some-err")).Times(1)
+			},
+		},
+		{
+			condition:   "failed to restart workflow",
+			req:         usecases.ReopenBillRequest{BillingID: "mock-billing-id", Reason: "wrong table"},
+			expectedErr: fmt.Errorf("failed to start workflow: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(closedBill(closedAt), nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(closedAt), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().ReopenBill(ctx, expectedEntry).Return(nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(reopenedBill, nil).Times(1)
+				mockWorkflow.EXPECT().StartWorkflow(ctx, "mock-billing-id", &reopenedBill).Return(errors.New("some-err")).Times(1)
+			},
+		},
+		{
+			condition:    "success",
+			req:          usecases.ReopenBillRequest{BillingID: "mock-billing-id", Reason: "wrong table"},
+			expectedBill: reopenedBill,
+			expectedErr:  nil,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(closedBill(closedAt), nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(closedAt), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().ReopenBill(ctx, expectedEntry).Return(nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(reopenedBill, nil).Times(1)
+				mockWorkflow.EXPECT().StartWorkflow(ctx, "mock-billing-id", &reopenedBill).Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, nil, suite.mockClock, usecases.WithReopenGracePeriod(15*time.Minute))
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient, suite.mockClock)
+			bill, err := uc.ReopenBill(ctx, tc.req)
+			assertion.Equal(tc.expectedBill, bill)
+			assertion.Equal(tc.expectedErr, err)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestVoidBill() {
+	openBill := func() domain.Bill {
+		return domain.Bill{
+			ID:        1,
+			BillingID: "mock-billing-id",
+			Status:    domain.BillStatusOpen,
+			Currency:  domain.CurrencyUSD,
+			Items:     []domain.Item{},
+		}
+	}
+
+	testCases := []struct {
+		condition    string
+		req          usecases.VoidBillRequest
+		expectedBill domain.Bill
+		expectedErr  error
+		doMock       func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock)
+	}{
+		{
+			condition:   "billing id is empty",
+			req:         usecases.VoidBillRequest{Reason: "opened by mistake"},
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "reason is empty",
+			req:         usecases.VoidBillRequest{BillingID: "mock-billing-id"},
+			expectedErr: domain.ValidationError{Field: "reason", Message: "reason is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+			},
+		},
+		{
+			condition:   "bill is closed",
+			req:         usecases.VoidBillRequest{BillingID: "mock-billing-id", Reason: "opened by mistake"},
+			expectedErr: domain.ErrBillClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				bill := openBill()
+				bill.Status = domain.BillStatusClosed
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+			},
+		},
+		{
+			condition:   "bill is already voided",
+			req:         usecases.VoidBillRequest{BillingID: "mock-billing-id", Reason: "opened by mistake"},
+			expectedErr: domain.ErrBillVoided,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				bill := openBill()
+				bill.Status = domain.BillStatusVoided
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+			},
+		},
+		{
+			condition:   "failed to signal workflow",
+			req:         usecases.VoidBillRequest{BillingID: "mock-billing-id", Reason: "opened by mistake"},
+			expectedErr: fmt.Errorf("failed to void bill: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalVoidBill, usecases.VoidBillRequest{BillingID: "mock-billing-id", Reason: "opened by mistake", VoidedAt: mockTime}).
+					Return(errors.New("some-err")).
+					Times(1)
+			},
+		},
+		{
+			condition: "success",
+			req:       usecases.VoidBillRequest{BillingID: "mock-billing-id", Reason: "opened by mistake"},
+			expectedBill: domain.Bill{
+				ID:        1,
+				BillingID: "mock-billing-id",
+				Status:    domain.BillStatusVoided,
+				Currency:  domain.CurrencyUSD,
+				Items:     []domain.Item{},
+				VoidedAt:  &mockTime,
+			},
+			expectedErr: nil,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient, mockClock *mock_clock.MockClock) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.
+					EXPECT().
+					SignalWorkflow(ctx, "mock-billing-id", domain.SignalVoidBill, usecases.VoidBillRequest{BillingID: "mock-billing-id", Reason: "opened by mistake", VoidedAt: mockTime}).
+					Return(nil).
+					Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, nil, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient, suite.mockClock)
+			bill, err := uc.VoidBill(ctx, tc.req)
+			assertion.Equal(tc.expectedBill, bill)
+			assertion.Equal(tc.expectedErr, err)
+		})
+	}
+}
+
 func (suite *billingUseCaseTestSuite) TearDownTest() {
 	suite.mockController.Finish()
 }
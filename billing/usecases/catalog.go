@@ -0,0 +1,306 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"encore.app/billing/domain"
+	"encore.app/pkg/conversion"
+)
+
+// maxSKULength is the maximum length of a SKU code.
+const maxSKULength = 64
+
+// skuPattern matches the characters SKU codes are made of.
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+func (u *billingUseCase) CreateProduct(ctx context.Context, req CreateProductRequest) (domain.Product, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Name == "" {
+		return domain.Product{}, domain.ValidationError{Field: "name", Message: "name is required"}
+	}
+
+	now := u.clock.Now()
+	product := domain.Product{
+		ID:          u.idGenerator.GenerateBillingID("Prod"),
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := u.repo.SaveProduct(ctx, &product); err != nil {
+		return domain.Product{}, fmt.Errorf("failed to save product: %w", err)
+	}
+
+	return product, nil
+}
+
+func (u *billingUseCase) GetProduct(ctx context.Context, productID string) (domain.Product, error) {
+	if productID == "" {
+		return domain.Product{}, domain.ValidationError{Field: "productId", Message: "product ID is required"}
+	}
+
+	product, err := u.repo.GetProduct(ctx, productID)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return domain.Product{}, err
+		}
+		return domain.Product{}, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return product, nil
+}
+
+// ListProducts returns the products of the catalog with their SKUs, including
+// the archived ones.
+func (u *billingUseCase) ListProducts(ctx context.Context) ([]domain.Product, error) {
+	products, err := u.repo.GetProducts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+
+	return products, nil
+}
+
+func (u *billingUseCase) UpdateProduct(ctx context.Context, req UpdateProductRequest) (domain.Product, error) {
+	if req.ProductID == "" {
+		return domain.Product{}, domain.ValidationError{Field: "productId", Message: "product ID is required"}
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Name == "" {
+		return domain.Product{}, domain.ValidationError{Field: "name", Message: "name is required"}
+	}
+
+	product := domain.Product{
+		ID:          req.ProductID,
+		Name:        req.Name,
+		Description: req.Description,
+		UpdatedAt:   u.clock.Now(),
+	}
+
+	if err := u.repo.UpdateProduct(ctx, &product); err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) || errors.Is(err, domain.ErrProductArchived) {
+			return domain.Product{}, err
+		}
+		return domain.Product{}, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	return u.GetProduct(ctx, req.ProductID)
+}
+
+// ArchiveProduct archives a product and its SKUs. Items already added by SKU
+// are kept, but the SKUs of the product can no longer be added to bills.
+func (u *billingUseCase) ArchiveProduct(ctx context.Context, productID string) error {
+	if productID == "" {
+		return domain.ValidationError{Field: "productId", Message: "product ID is required"}
+	}
+
+	if err := u.repo.ArchiveProduct(ctx, productID, u.clock.Now()); err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to archive product: %w", err)
+	}
+
+	return nil
+}
+
+func (u *billingUseCase) CreateSKU(ctx context.Context, req CreateSKURequest) (domain.SKU, error) {
+	req.Code = strings.TrimSpace(req.Code)
+	req.Name = strings.TrimSpace(req.Name)
+	req.Unit = strings.TrimSpace(req.Unit)
+	if err := validateSKU(req.Code, req.Currency, req.UnitPrice, req.Prices); err != nil {
+		return domain.SKU{}, err
+	}
+
+	product, err := u.GetProduct(ctx, req.ProductID)
+	if err != nil {
+		return domain.SKU{}, err
+	}
+
+	if product.IsArchived() {
+		return domain.SKU{}, domain.ErrProductArchived
+	}
+
+	if req.Name == "" {
+		req.Name = product.Name
+	}
+
+	now := u.clock.Now()
+	sku := domain.SKU{
+		Code:      req.Code,
+		ProductID: product.ID,
+		Name:      req.Name,
+		Unit:      req.Unit,
+		Currency:  domain.Currency(req.Currency),
+		UnitPrice: req.UnitPrice,
+		Prices:    req.Prices,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.repo.SaveSKU(ctx, &sku); err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) || errors.Is(err, domain.ErrProductArchived) ||
+			errors.Is(err, domain.ErrSKUExists) {
+			return domain.SKU{}, err
+		}
+		return domain.SKU{}, fmt.Errorf("failed to save sku: %w", err)
+	}
+
+	return sku, nil
+}
+
+// GetSKU returns the current version of a SKU, or the requested version,
+// e.g. the one an item was priced with.
+func (u *billingUseCase) GetSKU(ctx context.Context, req GetSKURequest) (domain.SKU, error) {
+	if req.Code == "" {
+		return domain.SKU{}, domain.ValidationError{Field: "sku", Message: "sku is required"}
+	}
+	if req.Version < 0 {
+		return domain.SKU{}, domain.ValidationError{Field: "version", Message: "version must not be negative"}
+	}
+
+	var sku domain.SKU
+	var err error
+	if req.Version == 0 {
+		sku, err = u.repo.GetSKU(ctx, req.Code)
+	} else {
+		sku, err = u.repo.GetSKUVersion(ctx, req.Code, req.Version)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrSKUNotFound) {
+			return domain.SKU{}, err
+		}
+		return domain.SKU{}, fmt.Errorf("failed to get sku: %w", err)
+	}
+
+	return sku, nil
+}
+
+// UpdateSKU updates the name, unit and price of a SKU as its next version.
+// Items already added by SKU keep the version they were priced with.
+func (u *billingUseCase) UpdateSKU(ctx context.Context, req UpdateSKURequest) (domain.SKU, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Unit = strings.TrimSpace(req.Unit)
+	if err := validateSKU(req.Code, req.Currency, req.UnitPrice, req.Prices); err != nil {
+		return domain.SKU{}, err
+	}
+	if req.Name == "" {
+		return domain.SKU{}, domain.ValidationError{Field: "name", Message: "name is required"}
+	}
+
+	sku := domain.SKU{
+		Code:      req.Code,
+		Name:      req.Name,
+		Unit:      req.Unit,
+		Currency:  domain.Currency(req.Currency),
+		UnitPrice: req.UnitPrice,
+		Prices:    req.Prices,
+		UpdatedAt: u.clock.Now(),
+	}
+
+	if err := u.repo.UpdateSKU(ctx, &sku); err != nil {
+		if errors.Is(err, domain.ErrSKUNotFound) || errors.Is(err, domain.ErrSKUArchived) {
+			return domain.SKU{}, err
+		}
+		return domain.SKU{}, fmt.Errorf("failed to update sku: %w", err)
+	}
+
+	return sku, nil
+}
+
+// ArchiveSKU archives a SKU, which can then no longer be added to bills.
+func (u *billingUseCase) ArchiveSKU(ctx context.Context, code string) error {
+	if code == "" {
+		return domain.ValidationError{Field: "sku", Message: "sku is required"}
+	}
+
+	if err := u.repo.ArchiveSKU(ctx, code, u.clock.Now()); err != nil {
+		if errors.Is(err, domain.ErrSKUNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to archive sku: %w", err)
+	}
+
+	return nil
+}
+
+// catalogItem returns the item billing quantity units of a SKU on bill, at
+// the current price of the SKU in the currency of the bill. SKUs without a
+// price in that currency cannot be added, unless catalog price conversion is
+// enabled, in which case their price is converted to it.
+func (u *billingUseCase) catalogItem(ctx context.Context, bill domain.Bill, code string, quantity int64) (domain.Item, error) {
+	sku, err := u.repo.GetSKU(ctx, code)
+	if err != nil {
+		if errors.Is(err, domain.ErrSKUNotFound) {
+			return domain.Item{}, domain.ValidationError{Field: "sku", Message: fmt.Sprintf("unknown sku %q", code)}
+		}
+		return domain.Item{}, fmt.Errorf("failed to get sku: %w", err)
+	}
+
+	unitPrice, found := sku.UnitPriceIn(bill.Currency)
+	if !found {
+		if !u.catalogPriceConversion {
+			return domain.Item{}, domain.ValidationError{
+				Field:   "sku",
+				Message: fmt.Sprintf("sku %q has no price in %s", code, bill.Currency),
+			}
+		}
+
+		unitPrice, _, err = conversion.ConvertAmount(sku.UnitPrice, string(sku.Currency), string(bill.Currency))
+		if err != nil {
+			return domain.Item{}, fmt.Errorf("failed to convert sku price: %w", err)
+		}
+	}
+
+	item, err := sku.Item(bill.BillingID, quantity, unitPrice)
+	if err != nil {
+		if errors.Is(err, domain.ErrSKUArchived) {
+			return domain.Item{}, domain.ValidationError{Field: "sku", Message: fmt.Sprintf("sku %q is archived", code)}
+		}
+		return domain.Item{}, domain.ValidationError{Field: "quantity", Message: "quantity multiplied by unit price is too large"}
+	}
+
+	return item, nil
+}
+
+func validateSKU(code, currency string, unitPrice int64, prices domain.PriceList) error {
+	if code == "" {
+		return domain.ValidationError{Field: "code", Message: "sku code is required"}
+	}
+	if len(code) > maxSKULength || !skuPattern.MatchString(code) {
+		return domain.ValidationError{
+			Field:   "code",
+			Message: fmt.Sprintf("sku code must be at most %d letters, digits, dots, dashes or underscores", maxSKULength),
+		}
+	}
+	if currency != string(domain.CurrencyUSD) && currency != string(domain.CurrencyGEL) {
+		return domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"}
+	}
+	if unitPrice <= 0 {
+		return domain.ValidationError{Field: "unitPrice", Message: "unit price must be greater than 0"}
+	}
+	for priceCurrency, price := range prices {
+		if priceCurrency != domain.CurrencyUSD && priceCurrency != domain.CurrencyGEL {
+			return domain.ValidationError{Field: "prices", Message: "price currencies must be USD or GEL"}
+		}
+		if string(priceCurrency) == currency {
+			return domain.ValidationError{
+				Field:   "prices",
+				Message: fmt.Sprintf("the price in %s is set by unitPrice", currency),
+			}
+		}
+		if price <= 0 {
+			return domain.ValidationError{Field: "prices", Message: "prices must be greater than 0"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *billingUseCaseTestSuite) TestCreateProduct() {
+	mockProductID := "Prod-product-id"
+
+	testCases := []struct {
+		condition       string
+		argument        usecases.CreateProductRequest
+		expectedProduct domain.Product
+		expectedErr     error
+		doMock          func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: name is empty",
+			argument:    usecases.CreateProductRequest{Name: "  ", Description: "Coffee"},
+			expectedErr: domain.ValidationError{Field: "name", Message: "name is required"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition: "success",
+			argument:  usecases.CreateProductRequest{Name: " Espresso ", Description: "Single origin"},
+			expectedProduct: domain.Product{
+				ID:          mockProductID,
+				Name:        "Espresso",
+				Description: "Single origin",
+				CreatedAt:   mockTime,
+				UpdatedAt:   mockTime,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("Prod").Return(mockProductID).Times(1)
+				mockRepo.EXPECT().SaveProduct(ctx, &domain.Product{
+					ID:          mockProductID,
+					Name:        "Espresso",
+					Description: "Single origin",
+					CreatedAt:   mockTime,
+					UpdatedAt:   mockTime,
+				}).Return(nil).Times(1)
+			},
+		},
+		{
+			condition:   "fail to save product",
+			argument:    usecases.CreateProductRequest{Name: "Espresso"},
+			expectedErr: fmt.Errorf("failed to save product: %w", errors.New("unexpected error")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("Prod").Return(mockProductID).Times(1)
+				mockRepo.EXPECT().SaveProduct(ctx, gomock.Any()).Return(errors.New("unexpected error")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			product, err := uc.CreateProduct(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedProduct, product)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestUpdateProduct() {
+	mockProductID := "Prod-product-id"
+
+	testCases := []struct {
+		condition       string
+		argument        usecases.UpdateProductRequest
+		expectedProduct domain.Product
+		expectedErr     error
+		doMock          func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: product id is empty",
+			argument:    usecases.UpdateProductRequest{Name: "Espresso"},
+			expectedErr: domain.ValidationError{Field: "productId", Message: "product ID is required"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "product is archived",
+			argument:    usecases.UpdateProductRequest{ProductID: mockProductID, Name: "Espresso"},
+			expectedErr: domain.ErrProductArchived,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().UpdateProduct(ctx, gomock.Any()).Return(domain.ErrProductArchived).Times(1)
+			},
+		},
+		{
+			condition: "success",
+			argument:  usecases.UpdateProductRequest{ProductID: mockProductID, Name: "Espresso", Description: "Blend"},
+			expectedProduct: domain.Product{
+				ID:          mockProductID,
+				Name:        "Espresso",
+				Description: "Blend",
+				SKUs:        []domain.SKU{{Code: "ESP-SGL", ProductID: mockProductID, Version: 1}},
+				UpdatedAt:   mockTime,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().UpdateProduct(ctx, &domain.Product{
+					ID:          mockProductID,
+					Name:        "Espresso",
+					Description: "Blend",
+					UpdatedAt:   mockTime,
+				}).Return(nil).Times(1)
+				mockRepo.EXPECT().GetProduct(ctx, mockProductID).Return(domain.Product{
+					ID:          mockProductID,
+					Name:        "Espresso",
+					Description: "Blend",
+					SKUs:        []domain.SKU{{Code: "ESP-SGL", ProductID: mockProductID, Version: 1}},
+					UpdatedAt:   mockTime,
+				}, nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			product, err := uc.UpdateProduct(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedProduct, product)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestCreateSKU() {
+	mockProductID := "Prod-product-id"
+	archivedAt := mockTime.AddDate(0, 0, -1)
+
+	testCases := []struct {
+		condition   string
+		argument    usecases.CreateSKURequest
+		expectedSKU domain.SKU
+		expectedErr error
+		doMock      func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: code has invalid characters",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP SGL", Currency: "USD", UnitPrice: 450},
+			expectedErr: domain.ValidationError{Field: "code", Message: "sku code must be at most 64 letters, digits, dots, dashes or underscores"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: code is too long",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: strings.Repeat("E", 65), Currency: "USD", UnitPrice: 450},
+			expectedErr: domain.ValidationError{Field: "code", Message: "sku code must be at most 64 letters, digits, dots, dashes or underscores"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: currency is not supported",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP-SGL", Currency: "EUR", UnitPrice: 450},
+			expectedErr: domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: unit price is empty",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP-SGL", Currency: "USD"},
+			expectedErr: domain.ValidationError{Field: "unitPrice", Message: "unit price must be greater than 0"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: price in an unsupported currency",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP-SGL", Currency: "USD", UnitPrice: 450, Prices: domain.PriceList{"EUR": 420}},
+			expectedErr: domain.ValidationError{Field: "prices", Message: "price currencies must be USD or GEL"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: price in the currency of the sku",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP-SGL", Currency: "USD", UnitPrice: 450, Prices: domain.PriceList{domain.CurrencyUSD: 500}},
+			expectedErr: domain.ValidationError{Field: "prices", Message: "the price in USD is set by unitPrice"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: price is empty",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP-SGL", Currency: "USD", UnitPrice: 450, Prices: domain.PriceList{domain.CurrencyGEL: 0}},
+			expectedErr: domain.ValidationError{Field: "prices", Message: "prices must be greater than 0"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "product not found",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP-SGL", Currency: "USD", UnitPrice: 450},
+			expectedErr: domain.ErrProductNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetProduct(ctx, mockProductID).Return(domain.Product{}, domain.ErrProductNotFound).Times(1)
+			},
+		},
+		{
+			condition:   "product is archived",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP-SGL", Currency: "USD", UnitPrice: 450},
+			expectedErr: domain.ErrProductArchived,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetProduct(ctx, mockProductID).Return(domain.Product{ID: mockProductID, Name: "Espresso", ArchivedAt: &archivedAt}, nil).Times(1)
+			},
+		},
+		{
+			condition:   "sku already exists",
+			argument:    usecases.CreateSKURequest{ProductID: mockProductID, Code: "ESP-SGL", Currency: "USD", UnitPrice: 450},
+			expectedErr: domain.ErrSKUExists,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetProduct(ctx, mockProductID).Return(domain.Product{ID: mockProductID, Name: "Espresso"}, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SaveSKU(ctx, gomock.Any()).Return(domain.ErrSKUExists).Times(1)
+			},
+		},
+		{
+			condition: "success, named after the product",
+			argument:  usecases.CreateSKURequest{ProductID: mockProductID, Code: " ESP-SGL ", Unit: "cup", Currency: "USD", UnitPrice: 450, Prices: domain.PriceList{domain.CurrencyGEL: 1250}},
+			expectedSKU: domain.SKU{
+				Code:      "ESP-SGL",
+				ProductID: mockProductID,
+				Name:      "Espresso",
+				Unit:      "cup",
+				Currency:  domain.CurrencyUSD,
+				UnitPrice: 450,
+				Prices:    domain.PriceList{domain.CurrencyGEL: 1250},
+				Version:   1,
+				CreatedAt: mockTime,
+				UpdatedAt: mockTime,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetProduct(ctx, mockProductID).Return(domain.Product{ID: mockProductID, Name: "Espresso"}, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SaveSKU(ctx, &domain.SKU{
+					Code:      "ESP-SGL",
+					ProductID: mockProductID,
+					Name:      "Espresso",
+					Unit:      "cup",
+					Currency:  domain.CurrencyUSD,
+					UnitPrice: 450,
+					Prices:    domain.PriceList{domain.CurrencyGEL: 1250},
+					Version:   1,
+					CreatedAt: mockTime,
+					UpdatedAt: mockTime,
+				}).Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			sku, err := uc.CreateSKU(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedSKU, sku)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestGetSKU() {
+	current := domain.SKU{Code: "ESP-SGL", Name: "Espresso", Currency: domain.CurrencyUSD, UnitPrice: 500, Version: 2}
+	first := domain.SKU{Code: "ESP-SGL", Name: "Espresso", Currency: domain.CurrencyUSD, UnitPrice: 450, Version: 1}
+
+	testCases := []struct {
+		condition   string
+		argument    usecases.GetSKURequest
+		expectedSKU domain.SKU
+		expectedErr error
+		doMock      func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: negative version",
+			argument:    usecases.GetSKURequest{Code: "ESP-SGL", Version: -1},
+			expectedErr: domain.ValidationError{Field: "version", Message: "version must not be negative"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "current version",
+			argument:    usecases.GetSKURequest{Code: "ESP-SGL"},
+			expectedSKU: current,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetSKU(ctx, "ESP-SGL").Return(current, nil).Times(1)
+			},
+		},
+		{
+			condition:   "past version",
+			argument:    usecases.GetSKURequest{Code: "ESP-SGL", Version: 1},
+			expectedSKU: first,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetSKUVersion(ctx, "ESP-SGL", int64(1)).Return(first, nil).Times(1)
+			},
+		},
+		{
+			condition:   "sku not found",
+			argument:    usecases.GetSKURequest{Code: "ESP-SGL", Version: 3},
+			expectedErr: domain.ErrSKUNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetSKUVersion(ctx, "ESP-SGL", int64(3)).Return(domain.SKU{}, domain.ErrSKUNotFound).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			sku, err := uc.GetSKU(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedSKU, sku)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestUpdateSKU() {
+	testCases := []struct {
+		condition   string
+		argument    usecases.UpdateSKURequest
+		expectedSKU domain.SKU
+		expectedErr error
+		doMock      func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: name is empty",
+			argument:    usecases.UpdateSKURequest{Code: "ESP-SGL", Currency: "USD", UnitPrice: 500},
+			expectedErr: domain.ValidationError{Field: "name", Message: "name is required"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "sku is archived",
+			argument:    usecases.UpdateSKURequest{Code: "ESP-SGL", Name: "Espresso", Currency: "USD", UnitPrice: 500},
+			expectedErr: domain.ErrSKUArchived,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().UpdateSKU(ctx, gomock.Any()).Return(domain.ErrSKUArchived).Times(1)
+			},
+		},
+		{
+			condition: "success",
+			argument:  usecases.UpdateSKURequest{Code: "ESP-SGL", Name: "Espresso", Unit: "cup", Currency: "USD", UnitPrice: 500},
+			expectedSKU: domain.SKU{
+				Code:      "ESP-SGL",
+				ProductID: "Prod-product-id",
+				Name:      "Espresso",
+				Unit:      "cup",
+				Currency:  domain.CurrencyUSD,
+				UnitPrice: 500,
+				Version:   2,
+				UpdatedAt: mockTime,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().UpdateSKU(ctx, &domain.SKU{
+					Code:      "ESP-SGL",
+					Name:      "Espresso",
+					Unit:      "cup",
+					Currency:  domain.CurrencyUSD,
+					UnitPrice: 500,
+					UpdatedAt: mockTime,
+				}).DoAndReturn(func(ctx context.Context, sku *domain.SKU) error {
+					sku.ProductID = "Prod-product-id"
+					sku.Version = 2
+					return nil
+				}).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			sku, err := uc.UpdateSKU(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedSKU, sku)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"encore.app/billing/domain"
+)
+
+// maxCouponCodeLength is the maximum length of a coupon code.
+const maxCouponCodeLength = 32
+
+// couponCodePattern matches the characters coupon codes are made of.
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]+$`)
+
+func (u *billingUseCase) CreateCoupon(ctx context.Context, req CreateCouponRequest) (domain.Coupon, error) {
+	req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	req.Description = strings.TrimSpace(req.Description)
+	if err := validateCreateCouponRequest(req); err != nil {
+		return domain.Coupon{}, err
+	}
+
+	var currencies []domain.Currency
+	for _, currency := range req.Currencies {
+		currencies = append(currencies, domain.Currency(currency))
+	}
+
+	coupon := domain.Coupon{
+		Code:                     req.Code,
+		Description:              req.Description,
+		DiscountType:             domain.DiscountType(req.Type),
+		DiscountValue:            req.Value,
+		Currencies:               currencies,
+		ValidFrom:                req.ValidFrom,
+		ValidUntil:               req.ValidUntil,
+		MaxRedemptions:           req.MaxRedemptions,
+		MaxRedemptionsPerAccount: req.MaxRedemptionsPerAccount,
+		CreatedAt:                u.clock.Now(),
+	}
+
+	if err := u.repo.SaveCoupon(ctx, &coupon); err != nil {
+		if errors.Is(err, domain.ErrCouponExists) {
+			return domain.Coupon{}, err
+		}
+		return domain.Coupon{}, fmt.Errorf("failed to save coupon: %w", err)
+	}
+
+	return coupon, nil
+}
+
+// GetCoupon returns a coupon with the number of bills it is redeemed by.
+func (u *billingUseCase) GetCoupon(ctx context.Context, code string) (domain.Coupon, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return domain.Coupon{}, domain.ValidationError{Field: "code", Message: "coupon code is required"}
+	}
+
+	coupon, err := u.repo.GetCoupon(ctx, code)
+	if err != nil {
+		if errors.Is(err, domain.ErrCouponNotFound) {
+			return domain.Coupon{}, err
+		}
+		return domain.Coupon{}, fmt.Errorf("failed to get coupon: %w", err)
+	}
+
+	return coupon, nil
+}
+
+// ApplyCoupon applies the discount of a coupon to an open bill. The coupon
+// must be active, valid for the bill currency and within its redemption
+// limits, which are checked again when the bill closes: a coupon whose limit
+// is reached by other bills in the meantime is then removed from the bill.
+func (u *billingUseCase) ApplyCoupon(ctx context.Context, req ApplyCouponRequest) (domain.Bill, error) {
+	req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	if req.BillingID == "" {
+		return domain.Bill{}, domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if req.Code == "" {
+		return domain.Bill{}, domain.ValidationError{Field: "code", Message: "coupon code is required"}
+	}
+
+	bill, err := u.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	if bill.IsClosed() {
+		return domain.Bill{}, domain.ErrBillClosed
+	}
+
+	if bill.IsVoided() {
+		return domain.Bill{}, domain.ErrBillVoided
+	}
+
+	if bill.HasCoupon(req.Code) {
+		return domain.Bill{}, domain.ErrCouponApplied
+	}
+
+	coupon, err := u.GetCoupon(ctx, req.Code)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	now := u.clock.Now()
+	if !coupon.IsValidAt(now) {
+		return domain.Bill{}, domain.ErrCouponNotActive
+	}
+
+	if !coupon.AcceptsCurrency(bill.Currency) {
+		return domain.Bill{}, domain.ErrCouponCurrency
+	}
+
+	redemptions, accountRedemptions, err := u.repo.CountCouponRedemptions(ctx, coupon.Code, bill.AccountID)
+	if err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+	if !coupon.CanRedeem(redemptions, accountRedemptions) {
+		return domain.Bill{}, domain.ErrCouponExhausted
+	}
+
+	discount := coupon.Discount(bill.BillingID)
+	discount.IdempotencyKey = u.idGenerator.GenerateIdempotencyKey("coup", PayloadToBytes(req))
+	discount.CreatedAt = now
+	if err := bill.ApplyDiscount(discount); err != nil {
+		return domain.Bill{}, err
+	}
+
+	if err := u.workflowClient.SignalWorkflow(ctx, req.BillingID, domain.SignalApplyDiscount, discount); err != nil {
+		return domain.Bill{}, fmt.Errorf("failed to apply coupon: %w", err)
+	}
+
+	return bill, nil
+}
+
+func validateCreateCouponRequest(req CreateCouponRequest) error {
+	if req.Code == "" {
+		return domain.ValidationError{Field: "code", Message: "coupon code is required"}
+	}
+	if len(req.Code) > maxCouponCodeLength || !couponCodePattern.MatchString(req.Code) {
+		return domain.ValidationError{
+			Field:   "code",
+			Message: fmt.Sprintf("coupon code must be at most %d letters, digits, dashes or underscores", maxCouponCodeLength),
+		}
+	}
+	if req.Value <= 0 {
+		return domain.ValidationError{Field: "value", Message: "value must be greater than 0"}
+	}
+	for _, currency := range req.Currencies {
+		if currency != string(domain.CurrencyUSD) && currency != string(domain.CurrencyGEL) {
+			return domain.ValidationError{Field: "currencies", Message: "currencies must be USD or GEL"}
+		}
+	}
+
+	switch domain.DiscountType(req.Type) {
+	case domain.DiscountTypePercentage:
+		if req.Value > domain.MaxPercentageBasisPoints {
+			return domain.ValidationError{Field: "value", Message: "percentage must not exceed 10000 basis points"}
+		}
+	case domain.DiscountTypeFixed:
+		if len(req.Currencies) != 1 {
+			return domain.ValidationError{Field: "currencies", Message: "fixed coupons must have exactly one currency"}
+		}
+	default:
+		return domain.ValidationError{Field: "type", Message: "type must be PERCENTAGE or FIXED"}
+	}
+
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		return domain.ValidationError{Field: "validUntil", Message: "validUntil must be after validFrom"}
+	}
+	if req.MaxRedemptions < 0 {
+		return domain.ValidationError{Field: "maxRedemptions", Message: "max redemptions must not be negative"}
+	}
+	if req.MaxRedemptionsPerAccount < 0 {
+		return domain.ValidationError{Field: "maxRedemptionsPerAccount", Message: "max redemptions per account must not be negative"}
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	mock_usecases "encore.app/billing/usecases/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *billingUseCaseTestSuite) TestCreateCoupon() {
+	validUntil := mockTime.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		condition      string
+		argument       usecases.CreateCouponRequest
+		expectedCoupon domain.Coupon
+		expectedErr    error
+		doMock         func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: code has invalid characters",
+			argument:    usecases.CreateCouponRequest{Code: "WELCOME 10", Type: "PERCENTAGE", Value: 1000},
+			expectedErr: domain.ValidationError{Field: "code", Message: "coupon code must be at most 32 letters, digits, dashes or underscores"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: percentage above 100%",
+			argument:    usecases.CreateCouponRequest{Code: "WELCOME10", Type: "PERCENTAGE", Value: 10001},
+			expectedErr: domain.ValidationError{Field: "value", Message: "percentage must not exceed 10000 basis points"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: fixed coupon without currency",
+			argument:    usecases.CreateCouponRequest{Code: "FIVE", Type: "FIXED", Value: 500},
+			expectedErr: domain.ValidationError{Field: "currencies", Message: "fixed coupons must have exactly one currency"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: currency is not supported",
+			argument:    usecases.CreateCouponRequest{Code: "FIVE", Type: "FIXED", Value: 500, Currencies: []string{"EUR"}},
+			expectedErr: domain.ValidationError{Field: "currencies", Message: "currencies must be USD or GEL"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: validity window is empty",
+			argument:    usecases.CreateCouponRequest{Code: "WELCOME10", Type: "PERCENTAGE", Value: 1000, ValidFrom: &validUntil, ValidUntil: &validUntil},
+			expectedErr: domain.ValidationError{Field: "validUntil", Message: "validUntil must be after validFrom"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: negative redemption limit",
+			argument:    usecases.CreateCouponRequest{Code: "WELCOME10", Type: "PERCENTAGE", Value: 1000, MaxRedemptions: -1},
+			expectedErr: domain.ValidationError{Field: "maxRedemptions", Message: "max redemptions must not be negative"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "coupon already exists",
+			argument:    usecases.CreateCouponRequest{Code: "WELCOME10", Type: "PERCENTAGE", Value: 1000},
+			expectedErr: domain.ErrCouponExists,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SaveCoupon(ctx, gomock.Any()).Return(domain.ErrCouponExists).Times(1)
+			},
+		},
+		{
+			condition: "success, code is uppercased",
+			argument: usecases.CreateCouponRequest{
+				Code:                     " welcome10 ",
+				Description:              "Welcome offer",
+				Type:                     "PERCENTAGE",
+				Value:                    1000,
+				Currencies:               []string{"USD"},
+				ValidUntil:               &validUntil,
+				MaxRedemptions:           100,
+				MaxRedemptionsPerAccount: 1,
+			},
+			expectedCoupon: domain.Coupon{
+				Code:                     "WELCOME10",
+				Description:              "Welcome offer",
+				DiscountType:             domain.DiscountTypePercentage,
+				DiscountValue:            1000,
+				Currencies:               []domain.Currency{domain.CurrencyUSD},
+				ValidUntil:               &validUntil,
+				MaxRedemptions:           100,
+				MaxRedemptionsPerAccount: 1,
+				CreatedAt:                mockTime,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SaveCoupon(ctx, &domain.Coupon{
+					Code:                     "WELCOME10",
+					Description:              "Welcome offer",
+					DiscountType:             domain.DiscountTypePercentage,
+					DiscountValue:            1000,
+					Currencies:               []domain.Currency{domain.CurrencyUSD},
+					ValidUntil:               &validUntil,
+					MaxRedemptions:           100,
+					MaxRedemptionsPerAccount: 1,
+					CreatedAt:                mockTime,
+				}).Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			coupon, err := uc.CreateCoupon(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedCoupon, coupon)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestApplyCoupon() {
+	mockIdempotencyKey := "mock-idempotency"
+	expiredAt := mockTime.AddDate(0, 0, -1)
+
+	openBill := func() domain.Bill {
+		return domain.Bill{
+			ID:        1,
+			BillingID: "mock-billing-id",
+			AccountID: "mock-account-id",
+			Status:    domain.BillStatusOpen,
+			Currency:  domain.CurrencyUSD,
+			Total:     1000,
+			Items: []domain.Item{
+				{ID: 10, BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+			},
+		}
+	}
+
+	coupon := domain.Coupon{
+		Code:                     "WELCOME10",
+		DiscountType:             domain.DiscountTypePercentage,
+		DiscountValue:            1000,
+		MaxRedemptions:           10,
+		MaxRedemptionsPerAccount: 1,
+	}
+
+	couponDiscount := domain.Discount{
+		BillingID:      "mock-billing-id",
+		Type:           domain.DiscountTypePercentage,
+		Value:          1000,
+		Description:    "Coupon WELCOME10",
+		CouponCode:     "WELCOME10",
+		IdempotencyKey: mockIdempotencyKey,
+		CreatedAt:      mockTime,
+	}
+
+	testCases := []struct {
+		condition    string
+		req          usecases.ApplyCouponRequest
+		expectedBill domain.Bill
+		expectedErr  error
+		doMock       func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "code is empty",
+			req:         usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: " "},
+			expectedErr: domain.ValidationError{Field: "code", Message: "coupon code is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "coupon already applied",
+			req:         usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: "welcome10"},
+			expectedErr: domain.ErrCouponApplied,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				bill := openBill()
+				bill.Discounts = []domain.Discount{couponDiscount}
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+			},
+		},
+		{
+			condition:   "coupon not found",
+			req:         usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: "WELCOME10"},
+			expectedErr: domain.ErrCouponNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockRepo.EXPECT().GetCoupon(ctx, "WELCOME10").Return(domain.Coupon{}, domain.ErrCouponNotFound).Times(1)
+			},
+		},
+		{
+			condition:   "coupon expired",
+			req:         usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: "WELCOME10"},
+			expectedErr: domain.ErrCouponNotActive,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				expired := coupon
+				expired.ValidUntil = &expiredAt
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockRepo.EXPECT().GetCoupon(ctx, "WELCOME10").Return(expired, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "coupon not valid for the bill currency",
+			req:         usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: "WELCOME10"},
+			expectedErr: domain.ErrCouponCurrency,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				gelOnly := coupon
+				gelOnly.Currencies = []domain.Currency{domain.CurrencyGEL}
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockRepo.EXPECT().GetCoupon(ctx, "WELCOME10").Return(gelOnly, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "account redemption limit reached",
+			req:         usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: "WELCOME10"},
+			expectedErr: domain.ErrCouponExhausted,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockRepo.EXPECT().GetCoupon(ctx, "WELCOME10").Return(coupon, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().CountCouponRedemptions(ctx, "WELCOME10", "mock-account-id").Return(int64(3), int64(1), nil).Times(1)
+			},
+		},
+		{
+			condition:   "failed to signal workflow",
+			req:         usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: "WELCOME10"},
+			expectedErr: fmt.Errorf("failed to apply coupon: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockRepo.EXPECT().GetCoupon(ctx, "WELCOME10").Return(coupon, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().CountCouponRedemptions(ctx, "WELCOME10", "mock-account-id").Return(int64(3), int64(0), nil).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateIdempotencyKey("coup", gomock.Any()).Return(mockIdempotencyKey).Times(1)
+				mockWorkflow.EXPECT().SignalWorkflow(ctx, "mock-billing-id", domain.SignalApplyDiscount, gomock.Any()).Return(errors.New("some-err")).Times(1)
+			},
+		},
+		{
+			condition: "success",
+			req:       usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: "welcome10"},
+			expectedBill: domain.Bill{
+				ID:        1,
+				BillingID: "mock-billing-id",
+				AccountID: "mock-account-id",
+				Status:    domain.BillStatusOpen,
+				Currency:  domain.CurrencyUSD,
+				Total:     900,
+				Items: []domain.Item{
+					{ID: 10, BillingID: "mock-billing-id", Name: "Sparkling", Price: 1000},
+				},
+				Discounts: []domain.Discount{couponDiscount},
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				req := usecases.ApplyCouponRequest{BillingID: "mock-billing-id", Code: "WELCOME10"}
+
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(openBill(), nil).Times(1)
+				mockRepo.EXPECT().GetCoupon(ctx, "WELCOME10").Return(coupon, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().CountCouponRedemptions(ctx, "WELCOME10", "mock-account-id").Return(int64(3), int64(0), nil).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateIdempotencyKey("coup", usecases.PayloadToBytes(req)).Return(mockIdempotencyKey).Times(1)
+				mockWorkflow.EXPECT().SignalWorkflow(ctx, "mock-billing-id", domain.SignalApplyDiscount, couponDiscount).Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			bill, err := uc.ApplyCoupon(ctx, tc.req)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedBill, bill)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.app/billing/domain"
+)
+
+// IssueCreditNote issues a credit note against a closed bill. The credit note
+// is checked against the bill first, so that invalid credit notes are rejected
+// right away, and is then issued by the credit note workflow.
+func (u *billingUseCase) IssueCreditNote(ctx context.Context, req IssueCreditNoteRequest) (domain.Bill, error) {
+	if err := u.validateIssueCreditNoteRequest(req); err != nil {
+		return domain.Bill{}, err
+	}
+
+	bill, err := u.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	note := domain.CreditNote{
+		Number:    u.idGenerator.GenerateBillingID("CN"),
+		BillingID: bill.BillingID,
+		Currency:  bill.Currency,
+		Reason:    req.Reason,
+		Lines:     req.Lines,
+		IssuedAt:  u.clock.Now(),
+	}
+
+	if err := bill.ApplyCreditNote(note); err != nil {
+		return domain.Bill{}, err
+	}
+
+	issued, err := u.workflowClient.ExecuteCreditNoteWorkflow(ctx, note)
+	if err != nil {
+		if errors.Is(err, domain.ErrCreditExceedsBalance) ||
+			errors.Is(err, domain.ErrBillNotClosed) ||
+			errors.Is(err, domain.ErrBillVoided) ||
+			errors.Is(err, domain.ErrItemNotFound) ||
+			errors.Is(err, domain.ErrItemVoided) {
+			return domain.Bill{}, err
+		}
+		return domain.Bill{}, fmt.Errorf("failed to issue credit note: %w", err)
+	}
+
+	bill.CreditNotes[len(bill.CreditNotes)-1] = issued
+	if bill.IsPaid() {
+		u.stopDunning(ctx, bill.BillingID)
+	}
+
+	return bill, nil
+}
+
+func (u *billingUseCase) validateIssueCreditNoteRequest(req IssueCreditNoteRequest) error {
+	if req.BillingID == "" {
+		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if req.Reason == "" {
+		return domain.ValidationError{Field: "reason", Message: "reason is required"}
+	}
+	if len(req.Lines) == 0 {
+		return domain.ValidationError{Field: "lines", Message: "at least one line is required"}
+	}
+	for _, line := range req.Lines {
+		if line.ItemID < 0 {
+			return domain.ValidationError{Field: "lines", Message: "item ID must not be negative"}
+		}
+		if line.Amount <= 0 {
+			return domain.ValidationError{Field: "lines", Message: "amount must be greater than 0"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	mock_usecases "encore.app/billing/usecases/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *billingUseCaseTestSuite) TestIssueCreditNote() {
+	closedBill := func() domain.Bill {
+		return domain.Bill{
+			ID:        1,
+			BillingID: "mock-billing-id",
+			Status:    domain.BillStatusClosed,
+			Currency:  domain.CurrencyUSD,
+			Total:     1000,
+			Items: []domain.Item{
+				{ID: 10, Name: "Sparkling", Price: 600},
+				{ID: 11, Name: "Still", Price: 400},
+			},
+			ClosedAt: &mockTime,
+		}
+	}
+	note := domain.CreditNote{
+		Number:    "CN-1",
+		BillingID: "mock-billing-id",
+		Currency:  domain.CurrencyUSD,
+		Reason:    "broken bottle",
+		Lines:     []domain.CreditNoteLine{{ItemID: 10, Amount: 300}},
+		IssuedAt:  mockTime,
+	}
+	issued := note
+	issued.ID = 1
+	issued.Amount = 300
+
+	testCases := []struct {
+		condition    string
+		req          usecases.IssueCreditNoteRequest
+		expectedBill domain.Bill
+		expectedErr  error
+		doMock       func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "billing id is empty",
+			req:         usecases.IssueCreditNoteRequest{Reason: "broken bottle", Lines: note.Lines},
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "reason is empty",
+			req:         usecases.IssueCreditNoteRequest{BillingID: "mock-billing-id", Lines: note.Lines},
+			expectedErr: domain.ValidationError{Field: "reason", Message: "reason is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "no lines",
+			req:         usecases.IssueCreditNoteRequest{BillingID: "mock-billing-id", Reason: "broken bottle"},
+			expectedErr: domain.ValidationError{Field: "lines", Message: "at least one line is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "line amount is not positive",
+			req:         usecases.IssueCreditNoteRequest{BillingID: "mock-billing-id", Reason: "broken bottle", Lines: []domain.CreditNoteLine{{ItemID: 10}}},
+			expectedErr: domain.ValidationError{Field: "lines", Message: "amount must be greater than 0"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "credit exceeds the item amount",
+			req:         usecases.IssueCreditNoteRequest{BillingID: "mock-billing-id", Reason: "broken bottle", Lines: []domain.CreditNoteLine{{ItemID: 11, Amount: 401}}},
+			expectedErr: domain.ErrCreditExceedsBalance,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(), nil).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("CN").Return("CN-1").Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition: "credit note issued",
+			req:       usecases.IssueCreditNoteRequest{BillingID: "mock-billing-id", Reason: "broken bottle", Lines: note.Lines},
+			expectedBill: func() domain.Bill {
+				bill := closedBill()
+				bill.CreditNotes = []domain.CreditNote{issued}
+				return bill
+			}(),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(), nil).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("CN").Return("CN-1").Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().ExecuteCreditNoteWorkflow(ctx, note).Return(issued, nil).Times(1)
+			},
+		},
+		{
+			condition: "credit note settles the bill",
+			req:       usecases.IssueCreditNoteRequest{BillingID: "mock-billing-id", Reason: "broken bottle", Lines: note.Lines},
+			expectedBill: func() domain.Bill {
+				bill := closedBill()
+				bill.Status = domain.BillStatusPaid
+				bill.Payments = []domain.Payment{{ID: 1, Amount: 700, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 700}}
+				bill.CreditNotes = []domain.CreditNote{issued}
+				return bill
+			}(),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				bill := closedBill()
+				bill.Status = domain.BillStatusPartiallyPaid
+				bill.Payments = []domain.Payment{{ID: 1, Amount: 700, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 700}}
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("CN").Return("CN-1").Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().ExecuteCreditNoteWorkflow(ctx, note).Return(issued, nil).Times(1)
+				mockWorkflow.EXPECT().SignalWorkflow(ctx, "dunning-mock-billing-id", domain.SignalBillSettled, nil).Return(nil).Times(1)
+			},
+		},
+		{
+			condition:   "credit note rejected by the workflow",
+			req:         usecases.IssueCreditNoteRequest{BillingID: "mock-billing-id", Reason: "broken bottle", Lines: note.Lines},
+			expectedErr: domain.ErrCreditExceedsBalance,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(), nil).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("CN").Return("CN-1").Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().ExecuteCreditNoteWorkflow(ctx, note).Return(domain.CreditNote{}, domain.ErrCreditExceedsBalance).Times(1)
+			},
+		},
+		{
+			condition:   "failed to execute the workflow",
+			req:         usecases.IssueCreditNoteRequest{BillingID: "mock-billing-id", Reason: "broken bottle", Lines: note.Lines},
+			expectedErr: fmt.Errorf("failed to issue credit note: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(), nil).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("CN").Return("CN-1").Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().ExecuteCreditNoteWorkflow(ctx, note).Return(domain.CreditNote{}, errors.New("some-err")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			bill, err := uc.IssueCreditNote(ctx, tc.req)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedBill, bill)
+		})
+	}
+}
@@ -8,10 +8,46 @@ import (
 
 // BillingUseCase defines the interface for billing business operations
 type BillingUseCase interface {
+	CreateAccount(ctx context.Context, req CreateAccountRequest) (domain.Account, error)
+	GetAccount(ctx context.Context, accountID string) (domain.Account, error)
+	UpdateAccount(ctx context.Context, req UpdateAccountRequest) (domain.Account, error)
+	DeleteAccount(ctx context.Context, accountID string) error
+	GetAccountBills(ctx context.Context, req GetAccountBillsRequest) ([]domain.Bill, error)
+	TopUpWallet(ctx context.Context, req TopUpWalletRequest) (domain.Wallet, error)
+	GetWallet(ctx context.Context, accountID string) (domain.Wallet, error)
 	CreateBill(ctx context.Context, req CreateBillRequest) (string, error)
 	GetBill(ctx context.Context, billingID string) (domain.Bill, error)
 	AddItem(ctx context.Context, req AddItemRequest) (domain.Bill, error)
+	VoidItem(ctx context.Context, req VoidItemRequest) (domain.Bill, error)
+	ApplyDiscount(ctx context.Context, req ApplyDiscountRequest) (domain.Bill, error)
+	RecordUsage(ctx context.Context, req RecordUsageRequest) (int64, error)
+	GetUsage(ctx context.Context, billingID string) ([]domain.UsageTotal, error)
 	CloseBill(ctx context.Context, req CloseBillRequest) (domain.Bill, error)
+	ReopenBill(ctx context.Context, req ReopenBillRequest) (domain.Bill, error)
+	VoidBill(ctx context.Context, req VoidBillRequest) (domain.Bill, error)
+	GetAuditTrail(ctx context.Context, billingID string) ([]domain.AuditEntry, error)
+	RecordPayment(ctx context.Context, req RecordPaymentRequest) (domain.Bill, error)
+	IssueCreditNote(ctx context.Context, req IssueCreditNoteRequest) (domain.Bill, error)
+	ConfirmPayment(ctx context.Context, req ConfirmPaymentRequest) error
+	GetInvoice(ctx context.Context, billingID string) (domain.Bill, error)
+	CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (domain.Subscription, error)
+	GetSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error)
+	PauseSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error)
+	ResumeSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error)
+	CancelSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error)
+	ChangeSubscriptionPlan(ctx context.Context, req ChangePlanRequest) (domain.Subscription, error)
+	CreateProduct(ctx context.Context, req CreateProductRequest) (domain.Product, error)
+	GetProduct(ctx context.Context, productID string) (domain.Product, error)
+	ListProducts(ctx context.Context) ([]domain.Product, error)
+	UpdateProduct(ctx context.Context, req UpdateProductRequest) (domain.Product, error)
+	ArchiveProduct(ctx context.Context, productID string) error
+	CreateSKU(ctx context.Context, req CreateSKURequest) (domain.SKU, error)
+	GetSKU(ctx context.Context, req GetSKURequest) (domain.SKU, error)
+	UpdateSKU(ctx context.Context, req UpdateSKURequest) (domain.SKU, error)
+	ArchiveSKU(ctx context.Context, code string) error
+	CreateCoupon(ctx context.Context, req CreateCouponRequest) (domain.Coupon, error)
+	GetCoupon(ctx context.Context, code string) (domain.Coupon, error)
+	ApplyCoupon(ctx context.Context, req ApplyCouponRequest) (domain.Bill, error)
 }
 
 // WorkflowClient defines the interface for workflow operations
@@ -20,4 +56,8 @@ type WorkflowClient interface {
 	QueryWorkflow(ctx context.Context, workflowID string) (domain.Bill, error)
 	SignalWorkflow(ctx context.Context, workflowID string, signal string, data interface{}) error
 	IsWorkflowRunning(ctx context.Context, workflowID string) (bool, error)
+	WaitWorkflow(ctx context.Context, workflowID string) error
+	ExecuteCreditNoteWorkflow(ctx context.Context, note domain.CreditNote) (domain.CreditNote, error)
+	StartSubscriptionWorkflow(ctx context.Context, subscription *domain.Subscription) error
+	QuerySubscriptionWorkflow(ctx context.Context, subscriptionID string) (domain.Subscription, error)
 }
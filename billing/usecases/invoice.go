@@ -0,0 +1,25 @@
+package usecases
+
+import (
+	"context"
+
+	"encore.app/billing/domain"
+)
+
+// GetInvoice retrieves the bill an invoice is rendered from. Invoices only
+// exist once a bill is closed, so open and voided bills are rejected.
+func (u *billingUseCase) GetInvoice(ctx context.Context, billingID string) (domain.Bill, error) {
+	bill, err := u.GetBill(ctx, billingID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	if bill.IsVoided() {
+		return domain.Bill{}, domain.ErrBillVoided
+	}
+	if !bill.IsClosed() {
+		return domain.Bill{}, domain.ErrBillNotClosed
+	}
+
+	return bill, nil
+}
@@ -0,0 +1,94 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	mock_usecases "encore.app/billing/usecases/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *billingUseCaseTestSuite) TestGetInvoice() {
+	closedBill := domain.Bill{
+		ID:            1,
+		BillingID:     "mock-billing-id",
+		InvoiceNumber: "INV-2025-000001",
+		Status:        domain.BillStatusPaid,
+		Currency:      domain.CurrencyUSD,
+		Total:         1000,
+		Items:         []domain.Item{{ID: 10, Name: "Sparkling", Price: 1000}},
+		ClosedAt:      &mockTime,
+	}
+
+	testCases := []struct {
+		condition    string
+		billingID    string
+		expectedBill domain.Bill
+		expectedErr  error
+		doMock       func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "billing id is empty",
+			billingID:   "",
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "bill not found",
+			billingID:   "mock-billing-id",
+			expectedErr: domain.ErrBillNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, errors.New("not found")).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(domain.Bill{}, errors.New("no rows")).Times(1)
+			},
+		},
+		{
+			condition:   "bill is open",
+			billingID:   "mock-billing-id",
+			expectedErr: domain.ErrBillNotClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{BillingID: "mock-billing-id", Status: domain.BillStatusOpen}, nil).Times(1)
+			},
+		},
+		{
+			condition:   "bill is voided",
+			billingID:   "mock-billing-id",
+			expectedErr: domain.ErrBillVoided,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, errors.New("not running")).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(domain.Bill{BillingID: "mock-billing-id", Status: domain.BillStatusVoided}, nil).Times(1)
+			},
+		},
+		{
+			condition:    "bill is closed",
+			billingID:    "mock-billing-id",
+			expectedBill: closedBill,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, errors.New("not running")).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill, nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			bill, err := uc.GetInvoice(ctx, tc.billingID)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBill, bill)
+		})
+	}
+}
@@ -57,6 +57,94 @@ func (mr *MockBillingUseCaseMockRecorder) AddItem(ctx, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddItem", reflect.TypeOf((*MockBillingUseCase)(nil).AddItem), ctx, req)
 }
 
+// ApplyCoupon mocks base method.
+func (m *MockBillingUseCase) ApplyCoupon(ctx context.Context, req usecases.ApplyCouponRequest) (domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyCoupon", ctx, req)
+	ret0, _ := ret[0].(domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyCoupon indicates an expected call of ApplyCoupon.
+func (mr *MockBillingUseCaseMockRecorder) ApplyCoupon(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyCoupon", reflect.TypeOf((*MockBillingUseCase)(nil).ApplyCoupon), ctx, req)
+}
+
+// ApplyDiscount mocks base method.
+func (m *MockBillingUseCase) ApplyDiscount(ctx context.Context, req usecases.ApplyDiscountRequest) (domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyDiscount", ctx, req)
+	ret0, _ := ret[0].(domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyDiscount indicates an expected call of ApplyDiscount.
+func (mr *MockBillingUseCaseMockRecorder) ApplyDiscount(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyDiscount", reflect.TypeOf((*MockBillingUseCase)(nil).ApplyDiscount), ctx, req)
+}
+
+// ArchiveProduct mocks base method.
+func (m *MockBillingUseCase) ArchiveProduct(ctx context.Context, productID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveProduct", ctx, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveProduct indicates an expected call of ArchiveProduct.
+func (mr *MockBillingUseCaseMockRecorder) ArchiveProduct(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveProduct", reflect.TypeOf((*MockBillingUseCase)(nil).ArchiveProduct), ctx, productID)
+}
+
+// ArchiveSKU mocks base method.
+func (m *MockBillingUseCase) ArchiveSKU(ctx context.Context, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveSKU", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveSKU indicates an expected call of ArchiveSKU.
+func (mr *MockBillingUseCaseMockRecorder) ArchiveSKU(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveSKU", reflect.TypeOf((*MockBillingUseCase)(nil).ArchiveSKU), ctx, code)
+}
+
+// CancelSubscription mocks base method.
+func (m *MockBillingUseCase) CancelSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelSubscription indicates an expected call of CancelSubscription.
+func (mr *MockBillingUseCaseMockRecorder) CancelSubscription(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSubscription", reflect.TypeOf((*MockBillingUseCase)(nil).CancelSubscription), ctx, subscriptionID)
+}
+
+// ChangeSubscriptionPlan mocks base method.
+func (m *MockBillingUseCase) ChangeSubscriptionPlan(ctx context.Context, req usecases.ChangePlanRequest) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeSubscriptionPlan", ctx, req)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeSubscriptionPlan indicates an expected call of ChangeSubscriptionPlan.
+func (mr *MockBillingUseCaseMockRecorder) ChangeSubscriptionPlan(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeSubscriptionPlan", reflect.TypeOf((*MockBillingUseCase)(nil).ChangeSubscriptionPlan), ctx, req)
+}
+
 // CloseBill mocks base method.
 func (m *MockBillingUseCase) CloseBill(ctx context.Context, req usecases.CloseBillRequest) (domain.Bill, error) {
 	m.ctrl.T.Helper()
@@ -72,6 +160,35 @@ func (mr *MockBillingUseCaseMockRecorder) CloseBill(ctx, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseBill", reflect.TypeOf((*MockBillingUseCase)(nil).CloseBill), ctx, req)
 }
 
+// ConfirmPayment mocks base method.
+func (m *MockBillingUseCase) ConfirmPayment(ctx context.Context, req usecases.ConfirmPaymentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmPayment", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmPayment indicates an expected call of ConfirmPayment.
+func (mr *MockBillingUseCaseMockRecorder) ConfirmPayment(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmPayment", reflect.TypeOf((*MockBillingUseCase)(nil).ConfirmPayment), ctx, req)
+}
+
+// CreateAccount mocks base method.
+func (m *MockBillingUseCase) CreateAccount(ctx context.Context, req usecases.CreateAccountRequest) (domain.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, req)
+	ret0, _ := ret[0].(domain.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockBillingUseCaseMockRecorder) CreateAccount(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockBillingUseCase)(nil).CreateAccount), ctx, req)
+}
+
 // CreateBill mocks base method.
 func (m *MockBillingUseCase) CreateBill(ctx context.Context, req usecases.CreateBillRequest) (string, error) {
 	m.ctrl.T.Helper()
@@ -87,6 +204,125 @@ func (mr *MockBillingUseCaseMockRecorder) CreateBill(ctx, req any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBill", reflect.TypeOf((*MockBillingUseCase)(nil).CreateBill), ctx, req)
 }
 
+// CreateCoupon mocks base method.
+func (m *MockBillingUseCase) CreateCoupon(ctx context.Context, req usecases.CreateCouponRequest) (domain.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCoupon", ctx, req)
+	ret0, _ := ret[0].(domain.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCoupon indicates an expected call of CreateCoupon.
+func (mr *MockBillingUseCaseMockRecorder) CreateCoupon(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCoupon", reflect.TypeOf((*MockBillingUseCase)(nil).CreateCoupon), ctx, req)
+}
+
+// CreateProduct mocks base method.
+func (m *MockBillingUseCase) CreateProduct(ctx context.Context, req usecases.CreateProductRequest) (domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", ctx, req)
+	ret0, _ := ret[0].(domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockBillingUseCaseMockRecorder) CreateProduct(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockBillingUseCase)(nil).CreateProduct), ctx, req)
+}
+
+// CreateSKU mocks base method.
+func (m *MockBillingUseCase) CreateSKU(ctx context.Context, req usecases.CreateSKURequest) (domain.SKU, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSKU", ctx, req)
+	ret0, _ := ret[0].(domain.SKU)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSKU indicates an expected call of CreateSKU.
+func (mr *MockBillingUseCaseMockRecorder) CreateSKU(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSKU", reflect.TypeOf((*MockBillingUseCase)(nil).CreateSKU), ctx, req)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockBillingUseCase) CreateSubscription(ctx context.Context, req usecases.CreateSubscriptionRequest) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, req)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockBillingUseCaseMockRecorder) CreateSubscription(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockBillingUseCase)(nil).CreateSubscription), ctx, req)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockBillingUseCase) DeleteAccount(ctx context.Context, accountID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, accountID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockBillingUseCaseMockRecorder) DeleteAccount(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockBillingUseCase)(nil).DeleteAccount), ctx, accountID)
+}
+
+// GetAccount mocks base method.
+func (m *MockBillingUseCase) GetAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", ctx, accountID)
+	ret0, _ := ret[0].(domain.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockBillingUseCaseMockRecorder) GetAccount(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockBillingUseCase)(nil).GetAccount), ctx, accountID)
+}
+
+// GetAccountBills mocks base method.
+func (m *MockBillingUseCase) GetAccountBills(ctx context.Context, req usecases.GetAccountBillsRequest) ([]domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBills", ctx, req)
+	ret0, _ := ret[0].([]domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBills indicates an expected call of GetAccountBills.
+func (mr *MockBillingUseCaseMockRecorder) GetAccountBills(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBills", reflect.TypeOf((*MockBillingUseCase)(nil).GetAccountBills), ctx, req)
+}
+
+// GetAuditTrail mocks base method.
+func (m *MockBillingUseCase) GetAuditTrail(ctx context.Context, billingID string) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditTrail", ctx, billingID)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditTrail indicates an expected call of GetAuditTrail.
+func (mr *MockBillingUseCaseMockRecorder) GetAuditTrail(ctx, billingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditTrail", reflect.TypeOf((*MockBillingUseCase)(nil).GetAuditTrail), ctx, billingID)
+}
+
 // GetBill mocks base method.
 func (m *MockBillingUseCase) GetBill(ctx context.Context, billingID string) (domain.Bill, error) {
 	m.ctrl.T.Helper()
@@ -102,6 +338,306 @@ func (mr *MockBillingUseCaseMockRecorder) GetBill(ctx, billingID any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBill", reflect.TypeOf((*MockBillingUseCase)(nil).GetBill), ctx, billingID)
 }
 
+// GetCoupon mocks base method.
+func (m *MockBillingUseCase) GetCoupon(ctx context.Context, code string) (domain.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoupon", ctx, code)
+	ret0, _ := ret[0].(domain.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCoupon indicates an expected call of GetCoupon.
+func (mr *MockBillingUseCaseMockRecorder) GetCoupon(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoupon", reflect.TypeOf((*MockBillingUseCase)(nil).GetCoupon), ctx, code)
+}
+
+// GetInvoice mocks base method.
+func (m *MockBillingUseCase) GetInvoice(ctx context.Context, billingID string) (domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvoice", ctx, billingID)
+	ret0, _ := ret[0].(domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvoice indicates an expected call of GetInvoice.
+func (mr *MockBillingUseCaseMockRecorder) GetInvoice(ctx, billingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvoice", reflect.TypeOf((*MockBillingUseCase)(nil).GetInvoice), ctx, billingID)
+}
+
+// GetProduct mocks base method.
+func (m *MockBillingUseCase) GetProduct(ctx context.Context, productID string) (domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProduct", ctx, productID)
+	ret0, _ := ret[0].(domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProduct indicates an expected call of GetProduct.
+func (mr *MockBillingUseCaseMockRecorder) GetProduct(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockBillingUseCase)(nil).GetProduct), ctx, productID)
+}
+
+// GetSKU mocks base method.
+func (m *MockBillingUseCase) GetSKU(ctx context.Context, req usecases.GetSKURequest) (domain.SKU, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSKU", ctx, req)
+	ret0, _ := ret[0].(domain.SKU)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSKU indicates an expected call of GetSKU.
+func (mr *MockBillingUseCaseMockRecorder) GetSKU(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSKU", reflect.TypeOf((*MockBillingUseCase)(nil).GetSKU), ctx, req)
+}
+
+// GetSubscription mocks base method.
+func (m *MockBillingUseCase) GetSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscription indicates an expected call of GetSubscription.
+func (mr *MockBillingUseCaseMockRecorder) GetSubscription(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockBillingUseCase)(nil).GetSubscription), ctx, subscriptionID)
+}
+
+// GetUsage mocks base method.
+func (m *MockBillingUseCase) GetUsage(ctx context.Context, billingID string) ([]domain.UsageTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", ctx, billingID)
+	ret0, _ := ret[0].([]domain.UsageTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockBillingUseCaseMockRecorder) GetUsage(ctx, billingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockBillingUseCase)(nil).GetUsage), ctx, billingID)
+}
+
+// GetWallet mocks base method.
+func (m *MockBillingUseCase) GetWallet(ctx context.Context, accountID string) (domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, accountID)
+	ret0, _ := ret[0].(domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockBillingUseCaseMockRecorder) GetWallet(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockBillingUseCase)(nil).GetWallet), ctx, accountID)
+}
+
+// IssueCreditNote mocks base method.
+func (m *MockBillingUseCase) IssueCreditNote(ctx context.Context, req usecases.IssueCreditNoteRequest) (domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueCreditNote", ctx, req)
+	ret0, _ := ret[0].(domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueCreditNote indicates an expected call of IssueCreditNote.
+func (mr *MockBillingUseCaseMockRecorder) IssueCreditNote(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueCreditNote", reflect.TypeOf((*MockBillingUseCase)(nil).IssueCreditNote), ctx, req)
+}
+
+// ListProducts mocks base method.
+func (m *MockBillingUseCase) ListProducts(ctx context.Context) ([]domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProducts", ctx)
+	ret0, _ := ret[0].([]domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProducts indicates an expected call of ListProducts.
+func (mr *MockBillingUseCaseMockRecorder) ListProducts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockBillingUseCase)(nil).ListProducts), ctx)
+}
+
+// PauseSubscription mocks base method.
+func (m *MockBillingUseCase) PauseSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseSubscription indicates an expected call of PauseSubscription.
+func (mr *MockBillingUseCaseMockRecorder) PauseSubscription(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseSubscription", reflect.TypeOf((*MockBillingUseCase)(nil).PauseSubscription), ctx, subscriptionID)
+}
+
+// RecordPayment mocks base method.
+func (m *MockBillingUseCase) RecordPayment(ctx context.Context, req usecases.RecordPaymentRequest) (domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPayment", ctx, req)
+	ret0, _ := ret[0].(domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordPayment indicates an expected call of RecordPayment.
+func (mr *MockBillingUseCaseMockRecorder) RecordPayment(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPayment", reflect.TypeOf((*MockBillingUseCase)(nil).RecordPayment), ctx, req)
+}
+
+// RecordUsage mocks base method.
+func (m *MockBillingUseCase) RecordUsage(ctx context.Context, req usecases.RecordUsageRequest) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordUsage", ctx, req)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordUsage indicates an expected call of RecordUsage.
+func (mr *MockBillingUseCaseMockRecorder) RecordUsage(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordUsage", reflect.TypeOf((*MockBillingUseCase)(nil).RecordUsage), ctx, req)
+}
+
+// ReopenBill mocks base method.
+func (m *MockBillingUseCase) ReopenBill(ctx context.Context, req usecases.ReopenBillRequest) (domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenBill", ctx, req)
+	ret0, _ := ret[0].(domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReopenBill indicates an expected call of ReopenBill.
+func (mr *MockBillingUseCaseMockRecorder) ReopenBill(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenBill", reflect.TypeOf((*MockBillingUseCase)(nil).ReopenBill), ctx, req)
+}
+
+// ResumeSubscription mocks base method.
+func (m *MockBillingUseCase) ResumeSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeSubscription indicates an expected call of ResumeSubscription.
+func (mr *MockBillingUseCaseMockRecorder) ResumeSubscription(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSubscription", reflect.TypeOf((*MockBillingUseCase)(nil).ResumeSubscription), ctx, subscriptionID)
+}
+
+// TopUpWallet mocks base method.
+func (m *MockBillingUseCase) TopUpWallet(ctx context.Context, req usecases.TopUpWalletRequest) (domain.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopUpWallet", ctx, req)
+	ret0, _ := ret[0].(domain.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopUpWallet indicates an expected call of TopUpWallet.
+func (mr *MockBillingUseCaseMockRecorder) TopUpWallet(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopUpWallet", reflect.TypeOf((*MockBillingUseCase)(nil).TopUpWallet), ctx, req)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockBillingUseCase) UpdateAccount(ctx context.Context, req usecases.UpdateAccountRequest) (domain.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", ctx, req)
+	ret0, _ := ret[0].(domain.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockBillingUseCaseMockRecorder) UpdateAccount(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockBillingUseCase)(nil).UpdateAccount), ctx, req)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockBillingUseCase) UpdateProduct(ctx context.Context, req usecases.UpdateProductRequest) (domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, req)
+	ret0, _ := ret[0].(domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockBillingUseCaseMockRecorder) UpdateProduct(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockBillingUseCase)(nil).UpdateProduct), ctx, req)
+}
+
+// UpdateSKU mocks base method.
+func (m *MockBillingUseCase) UpdateSKU(ctx context.Context, req usecases.UpdateSKURequest) (domain.SKU, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSKU", ctx, req)
+	ret0, _ := ret[0].(domain.SKU)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSKU indicates an expected call of UpdateSKU.
+func (mr *MockBillingUseCaseMockRecorder) UpdateSKU(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSKU", reflect.TypeOf((*MockBillingUseCase)(nil).UpdateSKU), ctx, req)
+}
+
+// VoidBill mocks base method.
+func (m *MockBillingUseCase) VoidBill(ctx context.Context, req usecases.VoidBillRequest) (domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidBill", ctx, req)
+	ret0, _ := ret[0].(domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidBill indicates an expected call of VoidBill.
+func (mr *MockBillingUseCaseMockRecorder) VoidBill(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidBill", reflect.TypeOf((*MockBillingUseCase)(nil).VoidBill), ctx, req)
+}
+
+// VoidItem mocks base method.
+func (m *MockBillingUseCase) VoidItem(ctx context.Context, req usecases.VoidItemRequest) (domain.Bill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidItem", ctx, req)
+	ret0, _ := ret[0].(domain.Bill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidItem indicates an expected call of VoidItem.
+func (mr *MockBillingUseCaseMockRecorder) VoidItem(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidItem", reflect.TypeOf((*MockBillingUseCase)(nil).VoidItem), ctx, req)
+}
+
 // MockWorkflowClient is a mock of WorkflowClient interface.
 type MockWorkflowClient struct {
 	ctrl     *gomock.Controller
@@ -126,6 +662,21 @@ func (m *MockWorkflowClient) EXPECT() *MockWorkflowClientMockRecorder {
 	return m.recorder
 }
 
+// ExecuteCreditNoteWorkflow mocks base method.
+func (m *MockWorkflowClient) ExecuteCreditNoteWorkflow(ctx context.Context, note domain.CreditNote) (domain.CreditNote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteCreditNoteWorkflow", ctx, note)
+	ret0, _ := ret[0].(domain.CreditNote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteCreditNoteWorkflow indicates an expected call of ExecuteCreditNoteWorkflow.
+func (mr *MockWorkflowClientMockRecorder) ExecuteCreditNoteWorkflow(ctx, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteCreditNoteWorkflow", reflect.TypeOf((*MockWorkflowClient)(nil).ExecuteCreditNoteWorkflow), ctx, note)
+}
+
 // IsWorkflowRunning mocks base method.
 func (m *MockWorkflowClient) IsWorkflowRunning(ctx context.Context, workflowID string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -141,6 +692,21 @@ func (mr *MockWorkflowClientMockRecorder) IsWorkflowRunning(ctx, workflowID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsWorkflowRunning", reflect.TypeOf((*MockWorkflowClient)(nil).IsWorkflowRunning), ctx, workflowID)
 }
 
+// QuerySubscriptionWorkflow mocks base method.
+func (m *MockWorkflowClient) QuerySubscriptionWorkflow(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuerySubscriptionWorkflow", ctx, subscriptionID)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuerySubscriptionWorkflow indicates an expected call of QuerySubscriptionWorkflow.
+func (mr *MockWorkflowClientMockRecorder) QuerySubscriptionWorkflow(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuerySubscriptionWorkflow", reflect.TypeOf((*MockWorkflowClient)(nil).QuerySubscriptionWorkflow), ctx, subscriptionID)
+}
+
 // QueryWorkflow mocks base method.
 func (m *MockWorkflowClient) QueryWorkflow(ctx context.Context, workflowID string) (domain.Bill, error) {
 	m.ctrl.T.Helper()
@@ -170,6 +736,20 @@ func (mr *MockWorkflowClientMockRecorder) SignalWorkflow(ctx, workflowID, signal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignalWorkflow", reflect.TypeOf((*MockWorkflowClient)(nil).SignalWorkflow), ctx, workflowID, signal, data)
 }
 
+// StartSubscriptionWorkflow mocks base method.
+func (m *MockWorkflowClient) StartSubscriptionWorkflow(ctx context.Context, subscription *domain.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartSubscriptionWorkflow", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartSubscriptionWorkflow indicates an expected call of StartSubscriptionWorkflow.
+func (mr *MockWorkflowClientMockRecorder) StartSubscriptionWorkflow(ctx, subscription any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSubscriptionWorkflow", reflect.TypeOf((*MockWorkflowClient)(nil).StartSubscriptionWorkflow), ctx, subscription)
+}
+
 // StartWorkflow mocks base method.
 func (m *MockWorkflowClient) StartWorkflow(ctx context.Context, workflowID string, bill *domain.Bill) error {
 	m.ctrl.T.Helper()
@@ -183,3 +763,17 @@ func (mr *MockWorkflowClientMockRecorder) StartWorkflow(ctx, workflowID, bill an
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartWorkflow", reflect.TypeOf((*MockWorkflowClient)(nil).StartWorkflow), ctx, workflowID, bill)
 }
+
+// WaitWorkflow mocks base method.
+func (m *MockWorkflowClient) WaitWorkflow(ctx context.Context, workflowID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitWorkflow", ctx, workflowID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitWorkflow indicates an expected call of WaitWorkflow.
+func (mr *MockWorkflowClientMockRecorder) WaitWorkflow(ctx, workflowID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitWorkflow", reflect.TypeOf((*MockWorkflowClient)(nil).WaitWorkflow), ctx, workflowID)
+}
@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.app/billing/domain"
+	"encore.app/pkg/conversion"
+)
+
+// RecordPayment records a payment against a closed bill. Payments in another
+// currency than the bill currency are converted to the bill currency before
+// they are applied to the outstanding balance.
+func (u *billingUseCase) RecordPayment(ctx context.Context, req RecordPaymentRequest) (domain.Bill, error) {
+	if err := u.validateRecordPaymentRequest(req); err != nil {
+		return domain.Bill{}, err
+	}
+
+	bill, err := u.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return domain.Bill{}, err
+	}
+
+	paymentCurrency := domain.Currency(req.Currency)
+	if paymentCurrency == "" {
+		paymentCurrency = bill.Currency
+	}
+
+	billAmount, rate, err := conversion.ConvertAmount(req.Amount, string(paymentCurrency), string(bill.Currency))
+	if err != nil {
+		return domain.Bill{}, fmt.Errorf("%w: %v", domain.ErrFailedToConvertBill, err)
+	}
+
+	payment := domain.Payment{
+		BillingID:  bill.BillingID,
+		Amount:     req.Amount,
+		Currency:   paymentCurrency,
+		Rate:       rate,
+		BillAmount: billAmount,
+		Reference:  req.Reference,
+		PaidAt:     u.clock.Now(),
+	}
+
+	if err := bill.ApplyPayment(payment); err != nil {
+		if errors.Is(err, domain.ErrInvalidPrice) {
+			return domain.Bill{}, domain.ValidationError{Field: "amount", Message: "amount is too small to be converted to the bill currency"}
+		}
+		return domain.Bill{}, err
+	}
+
+	if err := u.repo.SavePayments(ctx, &bill); err != nil {
+		if errors.Is(err, domain.ErrPaymentConflict) || errors.Is(err, domain.ErrBillNotClosed) {
+			return domain.Bill{}, err
+		}
+		return domain.Bill{}, fmt.Errorf("failed to save payment: %w", err)
+	}
+
+	if bill.IsPaid() {
+		u.stopDunning(ctx, bill.BillingID)
+	}
+
+	return bill, nil
+}
+
+// stopDunning signals the dunning workflow of a settled bill to stop. The
+// workflow may have completed already and checks the bill before every step
+// anyway, so a failed signal is ignored.
+func (u *billingUseCase) stopDunning(ctx context.Context, billingID string) {
+	_ = u.workflowClient.SignalWorkflow(ctx, domain.DunningWorkflowID(billingID), domain.SignalBillSettled, nil)
+}
+
+// ConfirmPayment forwards the confirmation of a pending provider payment to
+// the payment workflow of the bill.
+func (u *billingUseCase) ConfirmPayment(ctx context.Context, req ConfirmPaymentRequest) error {
+	if err := u.validateConfirmPaymentRequest(req); err != nil {
+		return err
+	}
+
+	confirmation := domain.ProviderPayment{
+		ID:            req.PaymentID,
+		BillingID:     req.BillingID,
+		Status:        domain.ProviderPaymentStatus(req.Status),
+		DeclineReason: req.DeclineReason,
+		UpdatedAt:     u.clock.Now(),
+	}
+
+	workflowID := domain.PaymentWorkflowID(req.BillingID)
+	if err := u.workflowClient.SignalWorkflow(ctx, workflowID, domain.SignalPaymentConfirmation, confirmation); err != nil {
+		return fmt.Errorf("failed to confirm payment: %w", err)
+	}
+
+	return nil
+}
+
+func (u *billingUseCase) validateConfirmPaymentRequest(req ConfirmPaymentRequest) error {
+	if req.BillingID == "" {
+		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if req.PaymentID == "" {
+		return domain.ValidationError{Field: "paymentId", Message: "payment ID is required"}
+	}
+	switch domain.ProviderPaymentStatus(req.Status) {
+	case domain.ProviderPaymentAuthorized, domain.ProviderPaymentCaptured, domain.ProviderPaymentDeclined:
+	default:
+		return domain.ValidationError{Field: "status", Message: "status must be AUTHORIZED, CAPTURED or DECLINED"}
+	}
+	return nil
+}
+
+func (u *billingUseCase) validateRecordPaymentRequest(req RecordPaymentRequest) error {
+	if req.BillingID == "" {
+		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if req.Amount <= 0 {
+		return domain.ValidationError{Field: "amount", Message: "amount must be greater than 0"}
+	}
+	if req.Currency != "" && req.Currency != "USD" && req.Currency != "GEL" {
+		return domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"}
+	}
+	return nil
+}
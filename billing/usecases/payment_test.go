@@ -0,0 +1,239 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	mock_usecases "encore.app/billing/usecases/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *billingUseCaseTestSuite) TestRecordPayment() {
+	closedBill := func() domain.Bill {
+		return domain.Bill{
+			ID:        1,
+			BillingID: "mock-billing-id",
+			Status:    domain.BillStatusClosed,
+			Currency:  domain.CurrencyUSD,
+			Total:     1000,
+			Items:     []domain.Item{{ID: 10, Name: "Sparkling", Price: 1000}},
+			ClosedAt:  &mockTime,
+		}
+	}
+	partiallyPaidBill := func(payments ...domain.Payment) domain.Bill {
+		bill := closedBill()
+		bill.Status = domain.BillStatusPartiallyPaid
+		bill.Payments = payments
+		return bill
+	}
+
+	testCases := []struct {
+		condition    string
+		req          usecases.RecordPaymentRequest
+		expectedBill domain.Bill
+		expectedErr  error
+		doMock       func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "billing id is empty",
+			req:         usecases.RecordPaymentRequest{Amount: 100},
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "amount is not positive",
+			req:         usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 0},
+			expectedErr: domain.ValidationError{Field: "amount", Message: "amount must be greater than 0"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "currency is invalid",
+			req:         usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 100, Currency: "EUR"},
+			expectedErr: domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "bill is open",
+			req:         usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 100},
+			expectedErr: domain.ErrBillNotClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				bill := closedBill()
+				bill.Status = domain.BillStatusOpen
+				bill.ClosedAt = nil
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(bill, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "payment exceeds the outstanding balance",
+			req:         usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 700},
+			expectedErr: domain.ErrPaymentExceedsBalance,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(partiallyPaidBill(domain.Payment{ID: 1, Amount: 400, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 400}), nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition: "partial payment in the bill currency",
+			req:       usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 400, Reference: "R-1"},
+			expectedBill: partiallyPaidBill(
+				domain.Payment{ID: 1, BillingID: "mock-billing-id", Amount: 400, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 400, Reference: "R-1", PaidAt: mockTime},
+			),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(), nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+
+				expected := partiallyPaidBill(
+					domain.Payment{BillingID: "mock-billing-id", Amount: 400, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 400, Reference: "R-1", PaidAt: mockTime},
+				)
+				mockRepo.EXPECT().SavePayments(ctx, &expected).DoAndReturn(func(_ context.Context, bill *domain.Bill) error {
+					bill.Payments[0].ID = 1
+					return nil
+				}).Times(1)
+			},
+		},
+		{
+			condition: "payment in another currency settles the balance",
+			req:       usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 1667, Currency: "GEL"},
+			expectedBill: func() domain.Bill {
+				bill := partiallyPaidBill(
+					domain.Payment{ID: 1, Amount: 400, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 400},
+					domain.Payment{ID: 2, BillingID: "mock-billing-id", Amount: 1667, Currency: domain.CurrencyGEL, Rate: 0.36, BillAmount: 600, PaidAt: mockTime},
+				)
+				bill.Status = domain.BillStatusPaid
+				return bill
+			}(),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(partiallyPaidBill(domain.Payment{ID: 1, Amount: 400, Currency: domain.CurrencyUSD, Rate: 1, BillAmount: 400}), nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SavePayments(ctx, gomock.Cond(func(b *domain.Bill) bool { return b.Status == domain.BillStatusPaid })).DoAndReturn(func(_ context.Context, bill *domain.Bill) error {
+					bill.Payments[1].ID = 2
+					return nil
+				}).Times(1)
+				mockWorkflow.EXPECT().SignalWorkflow(ctx, "dunning-mock-billing-id", domain.SignalBillSettled, nil).Return(errors.New("workflow not found")).Times(1)
+			},
+		},
+		{
+			condition:   "concurrent payment",
+			req:         usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 400},
+			expectedErr: domain.ErrPaymentConflict,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(), nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SavePayments(ctx, gomock.Cond(func(b *domain.Bill) bool { return b.Status == domain.BillStatusPartiallyPaid })).Return(domain.ErrPaymentConflict).Times(1)
+			},
+		},
+		{
+			condition:   "bill reopened concurrently",
+			req:         usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 400},
+			expectedErr: domain.ErrBillNotClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(), nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SavePayments(ctx, gomock.Any()).Return(domain.ErrBillNotClosed).Times(1)
+			},
+		},
+		{
+			condition:   "failed to save payment",
+			req:         usecases.RecordPaymentRequest{BillingID: "mock-billing-id", Amount: 400},
+			expectedErr: fmt.Errorf("failed to save payment: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "mock-billing-id").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "mock-billing-id").Return(closedBill(), nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SavePayments(ctx, gomock.Cond(func(b *domain.Bill) bool { return b.Status == domain.BillStatusPartiallyPaid })).Return(errors.New("some-err")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, nil, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			bill, err := uc.RecordPayment(ctx, tc.req)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedBill, bill)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestConfirmPayment() {
+	testCases := []struct {
+		condition   string
+		req         usecases.ConfirmPaymentRequest
+		expectedErr error
+		doMock      func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "billing id is empty",
+			req:         usecases.ConfirmPaymentRequest{PaymentID: "fake_000001", Status: "CAPTURED"},
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock:      func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient) {},
+		},
+		{
+			condition:   "payment id is empty",
+			req:         usecases.ConfirmPaymentRequest{BillingID: "mock-billing-id", Status: "CAPTURED"},
+			expectedErr: domain.ValidationError{Field: "paymentId", Message: "payment ID is required"},
+			doMock:      func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient) {},
+		},
+		{
+			condition:   "status is still pending",
+			req:         usecases.ConfirmPaymentRequest{BillingID: "mock-billing-id", PaymentID: "fake_000001", Status: "PENDING"},
+			expectedErr: domain.ValidationError{Field: "status", Message: "status must be AUTHORIZED, CAPTURED or DECLINED"},
+			doMock:      func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient) {},
+		},
+		{
+			condition: "confirmation forwarded to the payment workflow",
+			req:       usecases.ConfirmPaymentRequest{BillingID: "mock-billing-id", PaymentID: "fake_000001", Status: "DECLINED", DeclineReason: "expired card"},
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().SignalWorkflow(ctx, "payment-mock-billing-id", domain.SignalPaymentConfirmation, domain.ProviderPayment{
+					ID:            "fake_000001",
+					BillingID:     "mock-billing-id",
+					Status:        domain.ProviderPaymentDeclined,
+					DeclineReason: "expired card",
+					UpdatedAt:     mockTime,
+				}).Return(nil).Times(1)
+			},
+		},
+		{
+			condition:   "failed to signal the payment workflow",
+			req:         usecases.ConfirmPaymentRequest{BillingID: "mock-billing-id", PaymentID: "fake_000001", Status: "CAPTURED"},
+			expectedErr: fmt.Errorf("failed to confirm payment: %w", errors.New("some-err")),
+			doMock: func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().SignalWorkflow(ctx, "payment-mock-billing-id", domain.SignalPaymentConfirmation, gomock.Any()).Return(errors.New("some-err")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, nil, suite.mockClock)
+			ctx := context.Background()
+
+			tc.doMock(ctx, suite.mockWorkflowClient)
+
+			err := uc.ConfirmPayment(ctx, tc.req)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.app/billing/domain"
+)
+
+// CreateSubscription creates a new subscription and starts its workflow,
+// which opens the bill of the first cycle right away.
+func (u *billingUseCase) CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (domain.Subscription, error) {
+	if err := u.validateCreateSubscriptionRequest(req); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	if _, err := u.repo.GetAccount(ctx, req.AccountID); err != nil {
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			return domain.Subscription{}, err
+		}
+		return domain.Subscription{}, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	subscription := domain.Subscription{
+		SubscriptionID: u.idGenerator.GenerateBillingID("Sub"),
+		AccountID:      req.AccountID,
+		Status:         domain.SubscriptionStatusActive,
+		Currency:       domain.Currency(req.Currency),
+		Region:         req.Region,
+		Recurrence:     domain.Recurrence(req.Recurrence),
+		Timezone:       req.Timezone,
+		Items:          req.Items,
+		CreatedAt:      u.clock.Now(),
+	}
+
+	if err := u.workflowClient.StartSubscriptionWorkflow(ctx, &subscription); err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to start subscription workflow: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription retrieves a subscription by ID, from its workflow while it
+// runs and from the database otherwise.
+func (u *billingUseCase) GetSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	if subscriptionID == "" {
+		return domain.Subscription{}, domain.ValidationError{Field: "subscriptionID", Message: "subscription ID is required"}
+	}
+
+	subscription, err := u.workflowClient.QuerySubscriptionWorkflow(ctx, subscriptionID)
+	if err == nil {
+		return subscription, nil
+	}
+
+	subscription, err = u.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			return domain.Subscription{}, err
+		}
+		return domain.Subscription{}, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// PauseSubscription pauses an active subscription. The bill of the current
+// cycle is still closed at the end of its period, but no new bill is opened
+// until the subscription is resumed.
+func (u *billingUseCase) PauseSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	return u.changeSubscription(ctx, subscriptionID, domain.SignalPauseSubscription, func(s *domain.Subscription, now time.Time) error {
+		return s.Pause(now)
+	})
+}
+
+// ResumeSubscription resumes a paused subscription. A new cycle starts
+// right away, unless the period of the current cycle has not ended yet.
+func (u *billingUseCase) ResumeSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	return u.changeSubscription(ctx, subscriptionID, domain.SignalResumeSubscription, func(s *domain.Subscription, _ time.Time) error {
+		return s.Resume()
+	})
+}
+
+// CancelSubscription cancels a subscription. The bill of the current cycle
+// is still closed at the end of its period, and no new bill is opened.
+func (u *billingUseCase) CancelSubscription(ctx context.Context, subscriptionID string) (domain.Subscription, error) {
+	return u.changeSubscription(ctx, subscriptionID, domain.SignalCancelSubscription, func(s *domain.Subscription, now time.Time) error {
+		return s.Cancel(now)
+	})
+}
+
+// ChangeSubscriptionPlan replaces the plan items of a subscription, billed in
+// full from the next cycle on. The change is prorated on the open bill of the
+// current cycle by the subscription workflow: the unused part of the period
+// on the current plan is credited and its remaining part on the new plan is
+// charged.
+func (u *billingUseCase) ChangeSubscriptionPlan(ctx context.Context, req ChangePlanRequest) (domain.Subscription, error) {
+	if err := validatePlanItems(req.Items); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	switch req.ProrationPrecision {
+	case "":
+		req.ProrationPrecision = u.prorationPrecision
+	case domain.ProrationPrecisionDay, domain.ProrationPrecisionSecond:
+	default:
+		return domain.Subscription{}, domain.ValidationError{Field: "prorationPrecision", Message: "proration precision must be DAY or SECOND"}
+	}
+
+	subscription, err := u.GetSubscription(ctx, req.SubscriptionID)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	req.RequestedAt = u.clock.Now()
+	if _, err := subscription.ChangePlan(req.Items, nil, req.RequestedAt, req.ProrationPrecision); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	if err := u.workflowClient.SignalWorkflow(ctx, req.SubscriptionID, domain.SignalChangeSubscriptionPlan, req); err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to signal subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// changeSubscription checks that the change is allowed by the current state
+// of the subscription and signals it to the subscription workflow.
+func (u *billingUseCase) changeSubscription(
+	ctx context.Context,
+	subscriptionID string,
+	signal string,
+	apply func(s *domain.Subscription, now time.Time) error,
+) (domain.Subscription, error) {
+	subscription, err := u.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	now := u.clock.Now()
+	if err := apply(&subscription, now); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	req := SubscriptionRequest{SubscriptionID: subscriptionID, RequestedAt: now}
+	if err := u.workflowClient.SignalWorkflow(ctx, subscriptionID, signal, req); err != nil {
+		return domain.Subscription{}, fmt.Errorf("failed to signal subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (u *billingUseCase) validateCreateSubscriptionRequest(req CreateSubscriptionRequest) error {
+	if req.AccountID == "" {
+		return domain.ValidationError{Field: "accountId", Message: "account ID is required"}
+	}
+	if req.Currency != "USD" && req.Currency != "GEL" {
+		return domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"}
+	}
+
+	loc := time.UTC
+	if req.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.Timezone); err != nil {
+			return domain.ValidationError{Field: "timezone", Message: "timezone must be a valid IANA time zone"}
+		}
+	}
+	if _, err := domain.PeriodEnd(domain.Recurrence(req.Recurrence), time.Time{}, loc); err != nil {
+		return domain.ValidationError{Field: "recurrence", Message: "recurrence must be END_OF_DAY, END_OF_WEEK or END_OF_MONTH"}
+	}
+
+	return validatePlanItems(req.Items)
+}
+
+func validatePlanItems(items []domain.PlanItem) error {
+	if len(items) == 0 {
+		return domain.ValidationError{Field: "items", Message: "at least one plan item is required"}
+	}
+	for _, item := range items {
+		if item.Name == "" {
+			return domain.ValidationError{Field: "items", Message: "item name is required"}
+		}
+		if item.UnitPrice <= 0 {
+			return domain.ValidationError{Field: "items", Message: "unit price must be greater than 0"}
+		}
+		if item.Quantity < 0 {
+			return domain.ValidationError{Field: "items", Message: "quantity must be greater than 0"}
+		}
+		if _, err := domain.LineAmount(max(item.Quantity, 1), item.UnitPrice); err != nil {
+			return domain.ValidationError{Field: "items", Message: "quantity multiplied by unit price is too large"}
+		}
+	}
+
+	return nil
+}
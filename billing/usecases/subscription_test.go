@@ -0,0 +1,438 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	mock_usecases "encore.app/billing/usecases/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func (suite *billingUseCaseTestSuite) TestCreateSubscription() {
+	planItems := []domain.PlanItem{{Name: "Pro plan", UnitPrice: 4900}}
+	validReq := usecases.CreateSubscriptionRequest{
+		AccountID:  "Acc-1",
+		Currency:   "USD",
+		Recurrence: "END_OF_MONTH",
+		Items:      planItems,
+	}
+	withReq := func(change func(req *usecases.CreateSubscriptionRequest)) usecases.CreateSubscriptionRequest {
+		req := validReq
+		change(&req)
+		return req
+	}
+
+	testCases := []struct {
+		condition            string
+		req                  usecases.CreateSubscriptionRequest
+		expectedSubscription domain.Subscription
+		expectedErr          error
+		doMock               func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "account id is empty",
+			req:         withReq(func(req *usecases.CreateSubscriptionRequest) { req.AccountID = "" }),
+			expectedErr: domain.ValidationError{Field: "accountId", Message: "account ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "currency is invalid",
+			req:         withReq(func(req *usecases.CreateSubscriptionRequest) { req.Currency = "EUR" }),
+			expectedErr: domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "recurrence is invalid",
+			req:         withReq(func(req *usecases.CreateSubscriptionRequest) { req.Recurrence = "HOURLY" }),
+			expectedErr: domain.ValidationError{Field: "recurrence", Message: "recurrence must be END_OF_DAY, END_OF_WEEK or END_OF_MONTH"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "timezone is invalid",
+			req:         withReq(func(req *usecases.CreateSubscriptionRequest) { req.Timezone = "Mars/Olympus" }),
+			expectedErr: domain.ValidationError{Field: "timezone", Message: "timezone must be a valid IANA time zone"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "no plan items",
+			req:         withReq(func(req *usecases.CreateSubscriptionRequest) { req.Items = nil }),
+			expectedErr: domain.ValidationError{Field: "items", Message: "at least one plan item is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition: "plan item without price",
+			req: withReq(func(req *usecases.CreateSubscriptionRequest) {
+				req.Items = []domain.PlanItem{{Name: "Free plan"}}
+			}),
+			expectedErr: domain.ValidationError{Field: "items", Message: "unit price must be greater than 0"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition: "plan item amount overflows",
+			req: withReq(func(req *usecases.CreateSubscriptionRequest) {
+				req.Items = []domain.PlanItem{{Name: "Seats", Quantity: 1 << 40, UnitPrice: 1 << 40}}
+			}),
+			expectedErr: domain.ValidationError{Field: "items", Message: "quantity multiplied by unit price is too large"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "account not found",
+			req:         validReq,
+			expectedErr: domain.ErrAccountNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockRepo.EXPECT().GetAccount(ctx, "Acc-1").Return(domain.Account{}, domain.ErrAccountNotFound).Times(1)
+			},
+		},
+		{
+			condition: "subscription created",
+			req:       validReq,
+			expectedSubscription: domain.Subscription{
+				SubscriptionID: "Sub-1",
+				AccountID:      "Acc-1",
+				Status:         domain.SubscriptionStatusActive,
+				Currency:       domain.CurrencyUSD,
+				Recurrence:     domain.RecurrenceEndOfMonth,
+				Items:          planItems,
+				CreatedAt:      mockTime,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockRepo.EXPECT().GetAccount(ctx, "Acc-1").Return(domain.Account{ID: "Acc-1"}, nil).Times(1)
+				suite.mockIDGenerator.EXPECT().GenerateBillingID("Sub").Return("Sub-1").Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockWorkflow.EXPECT().StartSubscriptionWorkflow(ctx, &domain.Subscription{
+					SubscriptionID: "Sub-1",
+					AccountID:      "Acc-1",
+					Status:         domain.SubscriptionStatusActive,
+					Currency:       domain.CurrencyUSD,
+					Recurrence:     domain.RecurrenceEndOfMonth,
+					Items:          planItems,
+					CreatedAt:      mockTime,
+				}).Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			subscription, err := uc.CreateSubscription(ctx, tc.req)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedSubscription, subscription)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestGetSubscription() {
+	subscription := domain.Subscription{SubscriptionID: "Sub-1", Status: domain.SubscriptionStatusActive}
+
+	testCases := []struct {
+		condition            string
+		subscriptionID       string
+		expectedSubscription domain.Subscription
+		expectedErr          error
+		doMock               func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:      "subscription id is empty",
+			subscriptionID: "",
+			expectedErr:    domain.ValidationError{Field: "subscriptionID", Message: "subscription ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:            "from the workflow",
+			subscriptionID:       "Sub-1",
+			expectedSubscription: subscription,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(subscription, nil).Times(1)
+			},
+		},
+		{
+			condition:            "from the database",
+			subscriptionID:       "Sub-1",
+			expectedSubscription: subscription,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(domain.Subscription{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetSubscription(ctx, "Sub-1").Return(subscription, nil).Times(1)
+			},
+		},
+		{
+			condition:      "subscription not found",
+			subscriptionID: "Sub-1",
+			expectedErr:    domain.ErrSubscriptionNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(domain.Subscription{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetSubscription(ctx, "Sub-1").Return(domain.Subscription{}, domain.ErrSubscriptionNotFound).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			got, err := uc.GetSubscription(ctx, tc.subscriptionID)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedSubscription, got)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestChangeSubscription() {
+	withStatus := func(status domain.SubscriptionStatus) domain.Subscription {
+		return domain.Subscription{SubscriptionID: "Sub-1", Status: status}
+	}
+	pause := func(uc usecases.BillingUseCase, ctx context.Context) (domain.Subscription, error) {
+		return uc.PauseSubscription(ctx, "Sub-1")
+	}
+	resume := func(uc usecases.BillingUseCase, ctx context.Context) (domain.Subscription, error) {
+		return uc.ResumeSubscription(ctx, "Sub-1")
+	}
+	cancel := func(uc usecases.BillingUseCase, ctx context.Context) (domain.Subscription, error) {
+		return uc.CancelSubscription(ctx, "Sub-1")
+	}
+
+	testCases := []struct {
+		condition      string
+		current        domain.Subscription
+		change         func(uc usecases.BillingUseCase, ctx context.Context) (domain.Subscription, error)
+		signal         string
+		expectedStatus domain.SubscriptionStatus
+		expectedErr    error
+	}{
+		{
+			condition:      "pause active subscription",
+			current:        withStatus(domain.SubscriptionStatusActive),
+			change:         pause,
+			signal:         domain.SignalPauseSubscription,
+			expectedStatus: domain.SubscriptionStatusPaused,
+		},
+		{
+			condition:   "pause paused subscription",
+			current:     withStatus(domain.SubscriptionStatusPaused),
+			change:      pause,
+			expectedErr: domain.ErrSubscriptionPaused,
+		},
+		{
+			condition:      "resume paused subscription",
+			current:        withStatus(domain.SubscriptionStatusPaused),
+			change:         resume,
+			signal:         domain.SignalResumeSubscription,
+			expectedStatus: domain.SubscriptionStatusActive,
+		},
+		{
+			condition:   "resume active subscription",
+			current:     withStatus(domain.SubscriptionStatusActive),
+			change:      resume,
+			expectedErr: domain.ErrSubscriptionNotPaused,
+		},
+		{
+			condition:      "cancel active subscription",
+			current:        withStatus(domain.SubscriptionStatusActive),
+			change:         cancel,
+			signal:         domain.SignalCancelSubscription,
+			expectedStatus: domain.SubscriptionStatusCancelled,
+		},
+		{
+			condition:   "cancel cancelled subscription",
+			current:     withStatus(domain.SubscriptionStatusCancelled),
+			change:      cancel,
+			expectedErr: domain.ErrSubscriptionCancelled,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+
+			suite.mockWorkflowClient.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(tc.current, nil).Times(1)
+			suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			if tc.signal != "" {
+				suite.mockWorkflowClient.EXPECT().SignalWorkflow(ctx, "Sub-1", tc.signal, usecases.SubscriptionRequest{
+					SubscriptionID: "Sub-1",
+					RequestedAt:    mockTime,
+				}).Return(nil).Times(1)
+			}
+
+			subscription, err := tc.change(uc, ctx)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, subscription.Status)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestChangeSubscriptionSignalFails() {
+	uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+	ctx := context.Background()
+
+	suite.mockWorkflowClient.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(domain.Subscription{SubscriptionID: "Sub-1", Status: domain.SubscriptionStatusActive}, nil).Times(1)
+	suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+	suite.mockWorkflowClient.EXPECT().SignalWorkflow(ctx, "Sub-1", domain.SignalPauseSubscription, gomock.Any()).Return(errors.New("workflow completed")).Times(1)
+
+	_, err := uc.PauseSubscription(ctx, "Sub-1")
+	suite.ErrorContains(err, "failed to signal subscription")
+}
+
+func (suite *billingUseCaseTestSuite) TestChangeSubscriptionPlan() {
+	newPlan := []domain.PlanItem{{Name: "Business plan", UnitPrice: 9900}}
+	active := domain.Subscription{
+		SubscriptionID: "Sub-1",
+		Status:         domain.SubscriptionStatusActive,
+		Items:          []domain.PlanItem{{Name: "Pro plan", UnitPrice: 4900}},
+	}
+	expectSignal := func(ctx context.Context, mockWorkflow *mock_usecases.MockWorkflowClient, precision domain.ProrationPrecision) {
+		mockWorkflow.EXPECT().SignalWorkflow(ctx, "Sub-1", domain.SignalChangeSubscriptionPlan, usecases.ChangePlanRequest{
+			SubscriptionID:     "Sub-1",
+			Items:              newPlan,
+			ProrationPrecision: precision,
+			RequestedAt:        mockTime,
+		}).Return(nil).Times(1)
+	}
+
+	testCases := []struct {
+		condition   string
+		options     []usecases.Option
+		argument    usecases.ChangePlanRequest
+		doMock      func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+		expectedErr error
+	}{
+		{
+			condition:   "no plan items",
+			argument:    usecases.ChangePlanRequest{SubscriptionID: "Sub-1"},
+			expectedErr: domain.ValidationError{Field: "items", Message: "at least one plan item is required"},
+		},
+		{
+			condition: "invalid plan item",
+			argument: usecases.ChangePlanRequest{
+				SubscriptionID: "Sub-1",
+				Items:          []domain.PlanItem{{Name: "Business plan"}},
+			},
+			expectedErr: domain.ValidationError{Field: "items", Message: "unit price must be greater than 0"},
+		},
+		{
+			condition: "unknown proration precision",
+			argument: usecases.ChangePlanRequest{
+				SubscriptionID:     "Sub-1",
+				Items:              newPlan,
+				ProrationPrecision: "HOUR",
+			},
+			expectedErr: domain.ValidationError{Field: "prorationPrecision", Message: "proration precision must be DAY or SECOND"},
+		},
+		{
+			condition: "subscription not found",
+			argument:  usecases.ChangePlanRequest{SubscriptionID: "Sub-1", Items: newPlan},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(domain.Subscription{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetSubscription(ctx, "Sub-1").Return(domain.Subscription{}, domain.ErrSubscriptionNotFound).Times(1)
+			},
+			expectedErr: domain.ErrSubscriptionNotFound,
+		},
+		{
+			condition: "cancelled subscription",
+			argument:  usecases.ChangePlanRequest{SubscriptionID: "Sub-1", Items: newPlan},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(domain.Subscription{SubscriptionID: "Sub-1", Status: domain.SubscriptionStatusCancelled}, nil).Times(1)
+			},
+			expectedErr: domain.ErrSubscriptionCancelled,
+		},
+		{
+			condition: "prorated by day by default",
+			argument:  usecases.ChangePlanRequest{SubscriptionID: "Sub-1", Items: newPlan},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(active, nil).Times(1)
+				expectSignal(ctx, mockWorkflow, domain.ProrationPrecisionDay)
+			},
+		},
+		{
+			condition: "prorated with the configured precision",
+			options:   []usecases.Option{usecases.WithProrationPrecision(domain.ProrationPrecisionSecond)},
+			argument:  usecases.ChangePlanRequest{SubscriptionID: "Sub-1", Items: newPlan},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(active, nil).Times(1)
+				expectSignal(ctx, mockWorkflow, domain.ProrationPrecisionSecond)
+			},
+		},
+		{
+			condition: "prorated with the requested precision",
+			options:   []usecases.Option{usecases.WithProrationPrecision(domain.ProrationPrecisionSecond)},
+			argument: usecases.ChangePlanRequest{
+				SubscriptionID:     "Sub-1",
+				Items:              newPlan,
+				ProrationPrecision: domain.ProrationPrecisionDay,
+			},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(active, nil).Times(1)
+				expectSignal(ctx, mockWorkflow, domain.ProrationPrecisionDay)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock, tc.options...)
+			ctx := context.Background()
+
+			if tc.doMock != nil {
+				suite.mockClock.EXPECT().Now().Return(mockTime).AnyTimes()
+				tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+			}
+
+			subscription, err := uc.ChangeSubscriptionPlan(ctx, tc.argument)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, newPlan, subscription.Items)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestChangeSubscriptionPlanSignalFails() {
+	uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+	ctx := context.Background()
+
+	suite.mockWorkflowClient.EXPECT().QuerySubscriptionWorkflow(ctx, "Sub-1").Return(domain.Subscription{SubscriptionID: "Sub-1", Status: domain.SubscriptionStatusActive}, nil).Times(1)
+	suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+	suite.mockWorkflowClient.EXPECT().SignalWorkflow(ctx, "Sub-1", domain.SignalChangeSubscriptionPlan, gomock.Any()).Return(errors.New("workflow completed")).Times(1)
+
+	_, err := uc.ChangeSubscriptionPlan(ctx, usecases.ChangePlanRequest{
+		SubscriptionID: "Sub-1",
+		Items:          []domain.PlanItem{{Name: "Business plan", UnitPrice: 9900}},
+	})
+	suite.ErrorContains(err, "failed to signal subscription")
+}
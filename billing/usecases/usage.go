@@ -0,0 +1,152 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"encore.app/billing/domain"
+)
+
+// maxUsageEvents is the maximum number of usage events recorded at once.
+const maxUsageEvents = 1000
+
+// RecordUsage records usage events of metered features for an open bill and
+// returns how many of them were new: events already recorded with the same
+// event ID are skipped, so that retried events are only counted once. Usage is
+// recorded without signalling the bill workflow, which bills it with one line
+// item per meter when the bill closes.
+func (u *billingUseCase) RecordUsage(ctx context.Context, req RecordUsageRequest) (int64, error) {
+	if err := u.validateRecordUsageRequest(req); err != nil {
+		return 0, err
+	}
+
+	bill, err := u.GetBill(ctx, req.BillingID)
+	if err != nil {
+		return 0, err
+	}
+
+	if bill.IsClosed() {
+		return 0, domain.ErrBillClosed
+	}
+
+	if bill.IsVoided() {
+		return 0, domain.ErrBillVoided
+	}
+
+	now := u.clock.Now()
+	events := make([]domain.UsageEvent, 0, len(req.Events))
+	for _, event := range req.Events {
+		if _, found := u.meters.Find(event.Meter, bill.Currency); !found {
+			return 0, domain.ValidationError{
+				Field:   "meter",
+				Message: fmt.Sprintf("unknown meter %q for currency %s", event.Meter, bill.Currency),
+			}
+		}
+
+		event.BillingID = req.BillingID
+		if event.Timestamp.IsZero() {
+			event.Timestamp = now
+		}
+		event.RecordedAt = now
+		events = append(events, event)
+	}
+
+	if err := u.checkUsageSpendingCap(ctx, bill, events); err != nil {
+		return 0, err
+	}
+
+	recorded, err := u.repo.SaveUsageEvents(ctx, req.BillingID, events)
+	if err != nil {
+		if errors.Is(err, domain.ErrBillNotFound) || errors.Is(err, domain.ErrBillClosed) ||
+			errors.Is(err, domain.ErrBillVoided) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	return recorded, nil
+}
+
+// checkUsageSpendingCap returns ErrSpendingCapExceeded when billing the
+// usage recorded for a capped bill together with events would push its total
+// past the cap. Usage recorded concurrently is capped when the bill closes.
+func (u *billingUseCase) checkUsageSpendingCap(ctx context.Context, bill domain.Bill, events []domain.UsageEvent) error {
+	if bill.MaxTotal <= 0 {
+		return nil
+	}
+
+	totals, err := u.repo.GetUsageTotalsByBillID(ctx, bill.BillingID)
+	if err != nil {
+		return fmt.Errorf("failed to get usage: %w", err)
+	}
+	for _, event := range events {
+		found := false
+		for i := range totals {
+			if totals[i].Meter == event.Meter {
+				totals[i].Quantity += event.Quantity
+				found = true
+			}
+		}
+		if !found {
+			totals = append(totals, domain.UsageTotal{Meter: event.Meter, Quantity: event.Quantity})
+		}
+	}
+
+	for _, total := range totals {
+		meter, found := u.meters.Find(total.Meter, bill.Currency)
+		if !found {
+			continue
+		}
+		item, capped, err := bill.CapUsage(meter, total)
+		if err != nil {
+			return fmt.Errorf("failed to price usage: %w", err)
+		}
+		if capped {
+			return domain.ErrSpendingCapExceeded
+		}
+		bill.SetItem(item)
+	}
+
+	return nil
+}
+
+// GetUsage returns the usage recorded for a bill so far, aggregated per meter.
+func (u *billingUseCase) GetUsage(ctx context.Context, billingID string) ([]domain.UsageTotal, error) {
+	if _, err := u.GetBill(ctx, billingID); err != nil {
+		return nil, err
+	}
+
+	totals, err := u.repo.GetUsageTotalsByBillID(ctx, billingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	return totals, nil
+}
+
+func (u *billingUseCase) validateRecordUsageRequest(req RecordUsageRequest) error {
+	if req.BillingID == "" {
+		return domain.ValidationError{Field: "billingID", Message: "billing ID is required"}
+	}
+	if len(req.Events) == 0 {
+		return domain.ValidationError{Field: "events", Message: "at least one usage event is required"}
+	}
+	if len(req.Events) > maxUsageEvents {
+		return domain.ValidationError{Field: "events", Message: fmt.Sprintf("at most %d usage events can be recorded at once", maxUsageEvents)}
+	}
+
+	for _, event := range req.Events {
+		if event.EventID == "" {
+			return domain.ValidationError{Field: "eventId", Message: "event ID is required"}
+		}
+		if event.Meter == "" {
+			return domain.ValidationError{Field: "meter", Message: "meter is required"}
+		}
+		if event.Quantity <= 0 {
+			return domain.ValidationError{Field: "quantity", Message: "quantity must be greater than 0"}
+		}
+	}
+
+	return nil
+}
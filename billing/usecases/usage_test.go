@@ -0,0 +1,220 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	mock_usecases "encore.app/billing/usecases/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var usageMeters = domain.NewMeterCatalog([]domain.Meter{
+	{Code: "api_calls", Name: "API calls", Unit: "call", Currency: domain.CurrencyUSD, Price: domain.Price{Model: domain.PriceModelPerUnit, UnitPrice: 1}},
+})
+
+func (suite *billingUseCaseTestSuite) TestRecordUsage() {
+	openBill := domain.Bill{BillingID: "B-1", Status: domain.BillStatusOpen, Currency: domain.CurrencyUSD}
+	cappedBill := domain.Bill{BillingID: "B-1", Status: domain.BillStatusOpen, Currency: domain.CurrencyUSD, MaxTotal: 200,
+		Items: []domain.Item{{ID: 1, Name: "Setup fee", Price: 100}},
+	}
+	eventTime := mockTime.Add(-2 * time.Minute)
+	errDatabase := errors.New("unexpected error")
+
+	testCases := []struct {
+		condition        string
+		req              usecases.RecordUsageRequest
+		expectedRecorded int64
+		expectedErr      error
+		doMock           func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "billing id is empty",
+			req:         usecases.RecordUsageRequest{Events: []domain.UsageEvent{{EventID: "e-1", Meter: "api_calls", Quantity: 1}}},
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "no events",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1"},
+			expectedErr: domain.ValidationError{Field: "events", Message: "at least one usage event is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "too many events",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1", Events: make([]domain.UsageEvent, 1001)},
+			expectedErr: domain.ValidationError{Field: "events", Message: "at most 1000 usage events can be recorded at once"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "event id is empty",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{{Meter: "api_calls", Quantity: 1}}},
+			expectedErr: domain.ValidationError{Field: "eventId", Message: "event ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "quantity is not positive",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{{EventID: "e-1", Meter: "api_calls"}}},
+			expectedErr: domain.ValidationError{Field: "quantity", Message: "quantity must be greater than 0"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "unknown meter",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{{EventID: "e-1", Meter: "egress", Quantity: 1}}},
+			expectedErr: domain.ValidationError{Field: "meter", Message: `unknown meter "egress" for currency USD`},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(openBill, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+			},
+		},
+		{
+			condition:   "bill is closed",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{{EventID: "e-1", Meter: "api_calls", Quantity: 1}}},
+			expectedErr: domain.ErrBillClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "B-1").Return(domain.Bill{BillingID: "B-1", Status: domain.BillStatusClosed}, nil).Times(1)
+			},
+		},
+		{
+			condition:   "bill closed while recording",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{{EventID: "e-1", Meter: "api_calls", Quantity: 1}}},
+			expectedErr: domain.ErrBillClosed,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(openBill, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SaveUsageEvents(ctx, "B-1", gomock.Len(1)).Return(int64(0), domain.ErrBillClosed).Times(1)
+			},
+		},
+		{
+			condition: "events recorded, duplicates skipped",
+			req: usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{
+				{EventID: "e-1", Meter: "api_calls", Quantity: 10, Timestamp: eventTime},
+				{EventID: "e-2", Meter: "api_calls", Quantity: 5},
+			}},
+			expectedRecorded: 1,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(openBill, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SaveUsageEvents(ctx, "B-1", []domain.UsageEvent{
+					{BillingID: "B-1", EventID: "e-1", Meter: "api_calls", Quantity: 10, Timestamp: eventTime, RecordedAt: mockTime},
+					{BillingID: "B-1", EventID: "e-2", Meter: "api_calls", Quantity: 5, Timestamp: mockTime, RecordedAt: mockTime},
+				}).Return(int64(1), nil).Times(1)
+			},
+		},
+		{
+			condition:   "usage would exceed the spending cap",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{{EventID: "e-3", Meter: "api_calls", Quantity: 50}}},
+			expectedErr: domain.ErrSpendingCapExceeded,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(cappedBill, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().GetUsageTotalsByBillID(ctx, "B-1").Return([]domain.UsageTotal{{Meter: "api_calls", Quantity: 60, Events: 2}}, nil).Times(1)
+			},
+		},
+		{
+			condition:        "usage within the spending cap",
+			req:              usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{{EventID: "e-3", Meter: "api_calls", Quantity: 40}}},
+			expectedRecorded: 1,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(cappedBill, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().GetUsageTotalsByBillID(ctx, "B-1").Return([]domain.UsageTotal{{Meter: "api_calls", Quantity: 60, Events: 2}}, nil).Times(1)
+				mockRepo.EXPECT().SaveUsageEvents(ctx, "B-1", gomock.Len(1)).Return(int64(1), nil).Times(1)
+			},
+		},
+		{
+			condition:   "repository fails",
+			req:         usecases.RecordUsageRequest{BillingID: "B-1", Events: []domain.UsageEvent{{EventID: "e-1", Meter: "api_calls", Quantity: 1}}},
+			expectedErr: errDatabase,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(openBill, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().SaveUsageEvents(ctx, "B-1", gomock.Len(1)).Return(int64(0), errDatabase).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock,
+				usecases.WithMeters(usageMeters),
+			)
+			ctx := context.Background()
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			recorded, err := uc.RecordUsage(ctx, tc.req)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRecorded, recorded)
+		})
+	}
+}
+
+func (suite *billingUseCaseTestSuite) TestGetUsage() {
+	totals := []domain.UsageTotal{{Meter: "api_calls", Quantity: 15, Events: 2, LastRecordedAt: mockTime}}
+
+	testCases := []struct {
+		condition      string
+		billingID      string
+		expectedTotals []domain.UsageTotal
+		expectedErr    error
+		doMock         func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient)
+	}{
+		{
+			condition:   "billing id is empty",
+			expectedErr: domain.ValidationError{Field: "billingID", Message: "billing ID is required"},
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+			},
+		},
+		{
+			condition:   "bill not found",
+			billingID:   "B-1",
+			expectedErr: domain.ErrBillNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(domain.Bill{}, domain.ErrWorkflowNotFound).Times(1)
+				mockRepo.EXPECT().GetBill(ctx, "B-1").Return(domain.Bill{}, domain.ErrBillNotFound).Times(1)
+			},
+		},
+		{
+			condition:      "usage per meter",
+			billingID:      "B-1",
+			expectedTotals: totals,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository, mockWorkflow *mock_usecases.MockWorkflowClient) {
+				mockWorkflow.EXPECT().QueryWorkflow(ctx, "B-1").Return(domain.Bill{BillingID: "B-1", Status: domain.BillStatusOpen}, nil).Times(1)
+				mockRepo.EXPECT().GetUsageTotalsByBillID(ctx, "B-1").Return(totals, nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			tc.doMock(ctx, suite.mockRepository, suite.mockWorkflowClient)
+
+			got, err := uc.GetUsage(ctx, tc.billingID)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTotals, got)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"encore.app/billing/domain"
+)
+
+// TopUpWallet adds prepaid credit to the wallet of an account, which is
+// applied to the bills of the account in the same currency when they close.
+func (u *billingUseCase) TopUpWallet(ctx context.Context, req TopUpWalletRequest) (domain.Wallet, error) {
+	req.Reference = strings.TrimSpace(req.Reference)
+	if req.AccountID == "" {
+		return domain.Wallet{}, domain.ValidationError{Field: "accountId", Message: "account ID is required"}
+	}
+	if req.Currency != string(domain.CurrencyUSD) && req.Currency != string(domain.CurrencyGEL) {
+		return domain.Wallet{}, domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"}
+	}
+	if req.Amount <= 0 {
+		return domain.Wallet{}, domain.ValidationError{Field: "amount", Message: "amount must be greater than 0"}
+	}
+
+	if _, err := u.GetAccount(ctx, req.AccountID); err != nil {
+		return domain.Wallet{}, err
+	}
+
+	entry := domain.WalletEntry{
+		AccountID: req.AccountID,
+		Currency:  domain.Currency(req.Currency),
+		Type:      domain.WalletEntryTypeTopUp,
+		Amount:    req.Amount,
+		Reference: req.Reference,
+		CreatedAt: u.clock.Now(),
+	}
+	if err := u.repo.TopUpWallet(ctx, &entry); err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to top up wallet: %w", err)
+	}
+
+	return u.getWallet(ctx, req.AccountID)
+}
+
+// GetWallet returns the credit balances of an account and the ledger of its
+// top-ups and of the credit applied to its bills.
+func (u *billingUseCase) GetWallet(ctx context.Context, accountID string) (domain.Wallet, error) {
+	if _, err := u.GetAccount(ctx, accountID); err != nil {
+		return domain.Wallet{}, err
+	}
+
+	return u.getWallet(ctx, accountID)
+}
+
+func (u *billingUseCase) getWallet(ctx context.Context, accountID string) (domain.Wallet, error) {
+	wallet, err := u.repo.GetWallet(ctx, accountID)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	return wallet, nil
+}
@@ -0,0 +1,92 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"encore.app/billing/domain"
+	mock_domain "encore.app/billing/domain/mock"
+	"encore.app/billing/usecases"
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *billingUseCaseTestSuite) TestTopUpWallet() {
+	mockAccountID := "Acc-account-id"
+	account := domain.Account{ID: mockAccountID, Name: "Acme"}
+	wallet := domain.Wallet{
+		AccountID: mockAccountID,
+		Balances: []domain.WalletBalance{
+			{Currency: domain.CurrencyUSD, Balance: 5000, UpdatedAt: mockTime},
+		},
+		Entries: []domain.WalletEntry{
+			{ID: 1, AccountID: mockAccountID, Currency: domain.CurrencyUSD, Type: domain.WalletEntryTypeTopUp, Amount: 5000, Reference: "wire-42", CreatedAt: mockTime},
+		},
+	}
+
+	testCases := []struct {
+		condition      string
+		argument       usecases.TopUpWalletRequest
+		expectedWallet domain.Wallet
+		expectedErr    error
+		doMock         func(ctx context.Context, mockRepo *mock_domain.MockRepository)
+	}{
+		{
+			condition:   "validation failed: account id is empty",
+			argument:    usecases.TopUpWalletRequest{Currency: "USD", Amount: 5000},
+			expectedErr: domain.ValidationError{Field: "accountId", Message: "account ID is required"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: currency is not supported",
+			argument:    usecases.TopUpWalletRequest{AccountID: mockAccountID, Currency: "EUR", Amount: 5000},
+			expectedErr: domain.ValidationError{Field: "currency", Message: "currency must be USD or GEL"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "validation failed: amount is not positive",
+			argument:    usecases.TopUpWalletRequest{AccountID: mockAccountID, Currency: "USD", Amount: 0},
+			expectedErr: domain.ValidationError{Field: "amount", Message: "amount must be greater than 0"},
+			doMock:      func(ctx context.Context, mockRepo *mock_domain.MockRepository) {},
+		},
+		{
+			condition:   "account not found",
+			argument:    usecases.TopUpWalletRequest{AccountID: mockAccountID, Currency: "USD", Amount: 5000},
+			expectedErr: domain.ErrAccountNotFound,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(domain.Account{}, domain.ErrAccountNotFound).Times(1)
+			},
+		},
+		{
+			condition:      "success",
+			argument:       usecases.TopUpWalletRequest{AccountID: mockAccountID, Currency: "USD", Amount: 5000, Reference: " wire-42 "},
+			expectedWallet: wallet,
+			doMock: func(ctx context.Context, mockRepo *mock_domain.MockRepository) {
+				mockRepo.EXPECT().GetAccount(ctx, mockAccountID).Return(account, nil).Times(1)
+				suite.mockClock.EXPECT().Now().Return(mockTime).Times(1)
+				mockRepo.EXPECT().TopUpWallet(ctx, &domain.WalletEntry{
+					AccountID: mockAccountID,
+					Currency:  domain.CurrencyUSD,
+					Type:      domain.WalletEntryTypeTopUp,
+					Amount:    5000,
+					Reference: "wire-42",
+					CreatedAt: mockTime,
+				}).Return(nil).Times(1)
+				mockRepo.EXPECT().GetWallet(ctx, mockAccountID).Return(wallet, nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.condition, func(t *testing.T) {
+			uc := usecases.NewBillingUseCase(suite.mockRepository, suite.mockWorkflowClient, suite.mockIDGenerator, suite.mockClock)
+			ctx := context.Background()
+			assertion := assert.New(t)
+
+			tc.doMock(ctx, suite.mockRepository)
+
+			wallet, err := uc.TopUpWallet(ctx, tc.argument)
+			assertion.Equal(tc.expectedErr, err)
+			assertion.Equal(tc.expectedWallet, wallet)
+		})
+	}
+}
@@ -27,7 +27,7 @@ require (
 	github.com/rogpeppe/go-internal v1.10.0 // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
 	github.com/stretchr/testify v1.9.0
-	go.temporal.io/api v1.21.0 // indirect
+	go.temporal.io/api v1.21.0
 	go.uber.org/atomic v1.10.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,44 @@
+package pdf
+
+// widths holds the advance widths, in 1/1000 of the font size, of the
+// printable ASCII characters (0x20 to 0x7E) from the Adobe font metrics of
+// the standard fonts.
+var widths = map[Font][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// defaultWidth approximates the width of characters outside of printable ASCII.
+const defaultWidth = 556
+
+// TextWidth returns the width in points of text written with font at size.
+func TextWidth(font Font, size float64, text string) float64 {
+	table, found := widths[font]
+	if !found {
+		table = widths[Helvetica]
+	}
+
+	var total int
+	for _, c := range encode(text) {
+		if c >= 0x20 && c < 0x7F {
+			total += table[c-0x20]
+		} else {
+			total += defaultWidth
+		}
+	}
+	return float64(total) * size / 1000
+}
@@ -0,0 +1,186 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page dimensions in points, the PDF user space unit (1/72 inch).
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font is one of the standard Type 1 fonts every PDF reader provides,
+// so documents do not need to embed font files.
+type Font string
+
+const (
+	// Helvetica is the regular sans-serif standard font.
+	Helvetica Font = "Helvetica"
+	// HelveticaBold is the bold sans-serif standard font.
+	HelveticaBold Font = "Helvetica-Bold"
+)
+
+// fonts lists the supported fonts with their resource name in the page dictionaries.
+var fonts = []struct {
+	font Font
+	name string
+}{
+	{Helvetica, "F1"},
+	{HelveticaBold, "F2"},
+}
+
+// Document is a minimal PDF writer for text documents made of A4 pages.
+// Text is encoded with WinAnsiEncoding; characters outside of it are
+// written as "?".
+type Document struct {
+	pages []*Page
+}
+
+// Page is a page of a Document. Coordinates are expressed in points from
+// the bottom-left corner of the page.
+type Page struct {
+	content bytes.Buffer
+	font    Font
+	size    float64
+}
+
+// New creates an empty Document.
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage appends a new page to the document, using 10pt Helvetica until
+// SetFont is called.
+func (d *Document) AddPage() *Page {
+	page := &Page{font: Helvetica, size: 10}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// SetFont sets the font and size in points of the text written next.
+func (p *Page) SetFont(font Font, size float64) {
+	p.font = font
+	p.size = size
+}
+
+// Text writes text with its baseline starting at (x, y).
+func (p *Page) Text(x, y float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		fontName(p.font), number(p.size), number(x), number(y), escape(encode(text)))
+}
+
+// TextRight writes text with its baseline ending at (x, y), to align
+// amounts in columns.
+func (p *Page) TextRight(x, y float64, text string) {
+	p.Text(x-TextWidth(p.font, p.size, text), y, text)
+}
+
+// Line draws a line of 0.5pt from (x1, y1) to (x2, y2).
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %s %s m %s %s l S\n", number(x1), number(y1), number(x2), number(y2))
+}
+
+// WriteTo writes the document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = []*Page{{}}
+	}
+
+	// objects are numbered from 1: the catalog, the page tree, the fonts,
+	// then a page and its content stream for every page.
+	firstPage := 3 + len(fonts)
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	var fontResources strings.Builder
+	for i, f := range fonts {
+		fmt.Fprintf(&fontResources, "/%s %d 0 R ", f.name, 3+i)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	for _, f := range fonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.font))
+	}
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			number(PageWidth), number(PageHeight), fontResources.String(), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// Bytes returns the document as PDF bytes.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	_, _ = d.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+func fontName(font Font) string {
+	for _, f := range fonts {
+		if f.font == font {
+			return f.name
+		}
+	}
+	return fonts[0].name
+}
+
+// number formats a coordinate or size without trailing zeros.
+func number(f float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.2f", f), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// encode converts text to WinAnsiEncoding, which matches Latin-1 apart from
+// the 0x80-0x9F range, of which only the euro sign is supported.
+func encode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '€':
+			encoded = append(encoded, 0x80)
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			encoded = append(encoded, byte(r))
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// escape escapes the characters delimiting PDF literal strings.
+func escape(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		if c == '\\' || c == '(' || c == ')' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
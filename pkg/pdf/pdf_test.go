@@ -0,0 +1,61 @@
+package pdf_test
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"encore.app/pkg/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument(t *testing.T) {
+	doc := pdf.New()
+	page := doc.AddPage()
+	page.SetFont(pdf.HelveticaBold, 18)
+	page.Text(50, 800, "Invoice (copy)")
+	page.SetFont(pdf.Helvetica, 10)
+	page.TextRight(545, 780, `C:\total €5.00 ₾`)
+	page.Line(50, 770, 545.5, 770)
+	doc.AddPage()
+
+	out := doc.Bytes()
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+	assert.Contains(t, string(out), "BT /F2 18 Tf 50 800 Td (Invoice \\(copy\\)) Tj ET")
+	assert.Contains(t, string(out), "(C:\\\\total \x805.00 ?) Tj")
+	assert.Contains(t, string(out), "0.5 w 50 770 m 545.5 770 l S")
+
+	// every xref entry points at the object it lists
+	xref := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	assert.Len(t, xref, 8)
+	for i, entry := range xref {
+		offset, err := strconv.Atoi(string(entry[1]))
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(strconv.Itoa(i+1)+" 0 obj")), "object %d", i+1)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		font pdf.Font
+		size float64
+		text string
+		want float64
+	}{
+		{name: "digits", font: pdf.Helvetica, size: 10, text: "$1.00", want: 25.02},
+		{name: "bold letters", font: pdf.HelveticaBold, size: 10, text: "Total", want: 23.89},
+		{name: "outside ASCII", font: pdf.Helvetica, size: 10, text: "€", want: 5.56},
+		{name: "empty", font: pdf.Helvetica, size: 10, text: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, pdf.TextWidth(tt.font, tt.size, tt.text), 0.001)
+		})
+	}
+}